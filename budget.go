@@ -0,0 +1,210 @@
+package ring
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Budget bounds the memory retained by the elements of several rings
+// together, where per ring capacities alone cannot bound the memory of
+// the whole process.
+//
+// Rings are registered with the budget, and Enforce makes them shed
+// their oldest elements when the bytes retained by all of them exceed
+// the limit.  Each ring sheds a part of the excess proportional to the
+// bytes it retains.  Shed elements are counted as dropped, see Stats
+// and Observer.
+//
+// Insert and InsertContext enforce the budget of the ring, if it is
+// registered with one, once the bytes inserted since the last
+// enforcement may have taken the rings over the limit.  Other ways of
+// adding or growing elements, like ImportLog or UpdateAt, do not, so
+// Enforce should be called after them.
+type Budget struct {
+	mu    sync.Mutex
+	limit int
+	sizer func(v interface{}) int
+	rings []*Ring
+	// upper bound of the bytes retained by the rings: the ones measured
+	// by the last enforcement, plus the ones inserted since
+	estimate int
+}
+
+// NewBudget returns a new budget of limit bytes, estimating the bytes
+// retained by each element with sizer.
+func NewBudget(limit int, sizer func(v interface{}) int) (*Budget, error) {
+	if limit < 1 {
+		return nil, fmt.Errorf("budget limit must be > 0, got %d", limit)
+	}
+
+	if sizer == nil {
+		return nil, errors.New("budget sizer must not be nil")
+	}
+
+	return &Budget{
+		limit: limit,
+		sizer: sizer,
+	}, nil
+}
+
+// Register adds r to the rings bounded by the budget.  Registering a
+// ring twice has no effect.  A ring must not be registered with more
+// than one budget at a time.
+func (b *Budget) Register(r *Ring) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, registered := range b.rings {
+		if registered == r {
+			return
+		}
+	}
+
+	b.rings = append(b.rings, r)
+	b.estimate += r.retained(b.sizer)
+
+	r.mu.Lock()
+	r.budget = b
+	r.unlock()
+}
+
+// Unregister removes r from the rings bounded by the budget.
+func (b *Budget) Unregister(r *Ring) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, registered := range b.rings {
+		if registered == r {
+			b.rings = append(b.rings[:i], b.rings[i+1:]...)
+
+			r.mu.Lock()
+			r.budget = nil
+			r.unlock()
+
+			return
+		}
+	}
+}
+
+// Used returns the bytes retained by the elements of the registered
+// rings.
+func (b *Budget) Used() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var used int
+	for _, r := range b.rings {
+		used += r.retained(b.sizer)
+	}
+
+	return used
+}
+
+// Enforce makes the registered rings shed their oldest elements until
+// the bytes they retain are within the limit, and returns how many
+// elements were shed.  Inserting in the rings enforces the budget when
+// needed, see Budget, so it is only meant to be called after changing
+// them in other ways.
+//
+// The rings are not locked all at once, so elements inserted
+// concurrently may leave them over the limit again.
+func (b *Budget) Enforce() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sizes := make([]int, len(b.rings))
+
+	var used int
+
+	for i, r := range b.rings {
+		sizes[i] = r.retained(b.sizer)
+		used += sizes[i]
+	}
+
+	b.estimate = used
+
+	excess := used - b.limit
+	if excess <= 0 {
+		return 0
+	}
+
+	var shed int
+
+	for i, r := range b.rings {
+		// rounded up, so the shares add up to at least the excess
+		share := (excess*sizes[i] + used - 1) / used
+		n, bytes := r.shed(share, b.sizer)
+		shed += n
+		b.estimate -= bytes
+	}
+
+	return shed
+}
+
+// accounts for v, just inserted in a registered ring, and enforces the
+// budget if the rings may be over the limit.
+func (b *Budget) inserted(v interface{}) {
+	size := b.sizer(v)
+
+	b.mu.Lock()
+	b.estimate += size
+	over := b.estimate > b.limit
+	b.mu.Unlock()
+
+	if over {
+		b.Enforce()
+	}
+}
+
+// returns the bytes retained by the elements in the ring.
+func (r *Ring) retained(sizer func(v interface{}) int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var size int
+	for i := 0; i < r.len; i++ {
+		size += sizer(r.buf.get((r.head + i) % r.buf.size()))
+	}
+
+	return size
+}
+
+// drops the oldest elements until they add up to at least n bytes, or
+// the ring is empty, and returns how many were dropped and their bytes.
+func (r *Ring) shed(n int, sizer func(v interface{}) int) (int, int) {
+	var (
+		dropped []interface{}
+		bytes   int
+	)
+
+	r.mu.Lock()
+
+	for bytes < n {
+		v, ok := r.extract()
+		if !ok {
+			break
+		}
+
+		bytes += sizer(v)
+
+		if r.drops != nil {
+			r.drops.add(r.now())
+		}
+
+		dropped = append(dropped, v)
+	}
+
+	r.unlock()
+
+	atomic.AddUint64(&r.dropped, uint64(len(dropped)))
+
+	if r.observer != nil {
+		for _, v := range dropped {
+			r.observer.OnDrop(v)
+		}
+	}
+
+	return len(dropped), bytes
+}
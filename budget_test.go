@@ -0,0 +1,179 @@
+package ring_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestBudget(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid arguments":  budgetInvalidArguments,
+		"within limit":       budgetWithinLimit,
+		"sheds proportional": budgetShedsProportionally,
+		"unregister":         budgetUnregister,
+		"shed are dropped":   budgetShedAreDropped,
+		"insert enforces":    budgetInsertEnforces,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// estimates the size of string elements as their length.
+func stringSize(v interface{}) int {
+	return len(v.(string))
+}
+
+// returns a budget with the given limit, sizing elements with
+// stringSize, or fails the test.
+func newBudget(t *testing.T, limit int) *ring.Budget {
+	t.Helper()
+
+	b, err := ring.NewBudget(limit, stringSize)
+	if err != nil {
+		t.Fatalf("creating budget: %v", err)
+	}
+
+	return b
+}
+
+// returns a ring with the given elements registered with b, or fails
+// the test.
+func newBudgeted(t *testing.T, b *ring.Budget, opts []ring.Option, elements ...string) *ring.Ring {
+	t.Helper()
+
+	r, err := ring.New(10, opts...)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	for _, e := range elements {
+		r.Insert(e)
+	}
+
+	b.Register(r)
+
+	return r
+}
+
+// asserts the elements in the ring.
+func assertStrings(t *testing.T, r *ring.Ring, want string) {
+	t.Helper()
+
+	if got := fmt.Sprint(r.ToSlice()); got != want {
+		t.Errorf("want elements %s, got %s", want, got)
+	}
+}
+
+// tests that limits smaller than 1 and nil sizers are invalid.
+func budgetInvalidArguments(t *testing.T) {
+	if _, err := ring.NewBudget(0, stringSize); err == nil {
+		t.Error("zero limit: unexpected success")
+	}
+
+	if _, err := ring.NewBudget(10, nil); err == nil {
+		t.Error("nil sizer: unexpected success")
+	}
+}
+
+// tests that nothing is shed while within the limit.
+func budgetWithinLimit(t *testing.T) {
+	b := newBudget(t, 10)
+	r := newBudgeted(t, b, nil, "aaaa", "bbbbbb")
+
+	if got := b.Used(); got != 10 {
+		t.Errorf("want 10 bytes used, got %d", got)
+	}
+
+	if got := b.Enforce(); got != 0 {
+		t.Errorf("want nothing shed, got %d", got)
+	}
+
+	assertStrings(t, r, "[aaaa bbbbbb]")
+}
+
+// tests that rings shed a part of the excess proportional to their
+// size, oldest elements first.
+func budgetShedsProportionally(t *testing.T) {
+	b := newBudget(t, 18)
+	big := newBudgeted(t, b, nil, "a0", "a1", "a2", "a3", "a4", "a5", "a6", "a7")
+	small := newBudgeted(t, b, nil, "b0", "b1", "b2", "b3")
+
+	// 24 bytes used, 6 over the limit: 4 bytes from big and 2 from small
+	if got := b.Enforce(); got != 3 {
+		t.Errorf("want 3 elements shed, got %d", got)
+	}
+
+	assertStrings(t, big, "[a2 a3 a4 a5 a6 a7]")
+	assertStrings(t, small, "[b1 b2 b3]")
+
+	if got := b.Used(); got != 18 {
+		t.Errorf("want 18 bytes used, got %d", got)
+	}
+}
+
+// tests that unregistered rings are not bounded by the budget.
+func budgetUnregister(t *testing.T) {
+	b := newBudget(t, 4)
+	kept := newBudgeted(t, b, nil, "aa", "bb")
+	gone := newBudgeted(t, b, nil, "cc", "dd")
+
+	b.Register(kept) // no effect
+	b.Unregister(gone)
+
+	if got := b.Used(); got != 4 {
+		t.Errorf("want 4 bytes used, got %d", got)
+	}
+
+	if got := b.Enforce(); got != 0 {
+		t.Errorf("want nothing shed, got %d", got)
+	}
+
+	assertStrings(t, kept, "[aa bb]")
+	assertStrings(t, gone, "[cc dd]")
+}
+
+// tests that shed elements are counted and notified as dropped.
+func budgetShedAreDropped(t *testing.T) {
+	o := &recorder{}
+	b := newBudget(t, 1)
+	r := newBudgeted(t, b, []ring.Option{ring.WithObserver(o)}, "a", "b")
+
+	b.Enforce()
+
+	if got := r.Stats(); got.Dropped != 1 || got.Extracted != 0 {
+		t.Errorf("want 1 dropped and 0 extracted, got %d and %d", got.Dropped, got.Extracted)
+	}
+
+	assertEvents(t, o, "[insert a insert b drop a]")
+}
+
+// tests that inserting in a registered ring past the limit sheds the
+// oldest elements, without calling Enforce.
+func budgetInsertEnforces(t *testing.T) {
+	b := newBudget(t, 8)
+	first := newBudgeted(t, b, nil, "a0", "a1")
+	second := newBudgeted(t, b, nil, "b0")
+
+	second.Insert("b1")
+	assertStrings(t, first, "[a0 a1]")
+	assertStrings(t, second, "[b0 b1]")
+
+	// 10 bytes used, 2 over the limit: 1 byte from each ring
+	first.Insert("a2")
+	assertStrings(t, first, "[a1 a2]")
+	assertStrings(t, second, "[b1]")
+
+	b.Unregister(first)
+	first.Insert("a3")
+	assertStrings(t, first, "[a1 a2 a3]")
+}
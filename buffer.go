@@ -0,0 +1,39 @@
+package ring
+
+import "context"
+
+// Buffer is a bounded buffer of elements, like Ring.  Code using a ring
+// can accept a Buffer instead, so it also works with other
+// implementations, like remote rings, or fakes in tests.
+//
+// It is implemented by Ring, ringsock.Client and ringtest.Model.  The
+// rings of typed elements, like Ints, Float64s, Bytes, DeltaInts, the
+// ones generated by ringgen, and the ones in files of ringfile and
+// ringmmap, do not implement it, as their methods take and return their
+// element types, and some of them return errors.
+type Buffer interface {
+	// Insert adds a new element to the buffer, dropping the oldest one
+	// if the buffer is full.
+	Insert(v interface{})
+	// Extract extracts and returns the oldest element in the buffer.
+	Extract() (interface{}, bool)
+	// Peek returns the oldest element in the buffer.
+	Peek() (interface{}, bool)
+	// Len returns the amount of elements in the buffer.
+	Len() int
+	// Cap returns the capacity of the buffer.
+	Cap() int
+}
+
+// BlockingBuffer is a Buffer that can also wait for elements to be
+// inserted, for consumers that block on an empty buffer.  Insertions
+// never block, as they drop the oldest element when the buffer is full.
+type BlockingBuffer interface {
+	Buffer
+	// ExtractContext extracts and returns the oldest element in the
+	// buffer, waiting for one to be inserted if the buffer is empty.
+	// It returns the context error if ctx is done before that.
+	ExtractContext(ctx context.Context) (interface{}, error)
+}
+
+var _ BlockingBuffer = (*Ring)(nil)
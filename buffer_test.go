@@ -0,0 +1,103 @@
+package ring_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestBuffer(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"ring is a buffer":    bufferRing,
+		"fake is a buffer":    bufferFake,
+		"blocking extraction": bufferBlockingExtraction,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// fakeBuffer is a Buffer that records the inserted elements and never
+// holds any, like a test could substitute for a ring.
+type fakeBuffer struct {
+	inserted []interface{}
+}
+
+func (f *fakeBuffer) Insert(v interface{})         { f.inserted = append(f.inserted, v) }
+func (f *fakeBuffer) Extract() (interface{}, bool) { return nil, false }
+func (f *fakeBuffer) Peek() (interface{}, bool)    { return nil, false }
+func (f *fakeBuffer) Len() int                     { return 0 }
+func (f *fakeBuffer) Cap() int                     { return 1 }
+
+// an example of code accepting a buffer: it inserts the values and
+// returns how many elements the buffer holds.
+func fill(b ring.Buffer, vs ...interface{}) int {
+	for _, v := range vs {
+		b.Insert(v)
+	}
+
+	return b.Len()
+}
+
+// tests that rings can be used as buffers.
+func bufferRing(t *testing.T) {
+	r := newRing(t, 2)
+
+	if got := fill(r, 1, 2, 3); got != 2 {
+		t.Errorf("want 2 elements, got %d", got)
+	}
+
+	assertContents(t, r, 2, 3)
+}
+
+// tests that fakes can be used as buffers.
+func bufferFake(t *testing.T) {
+	f := &fakeBuffer{}
+
+	if got := fill(f, 1, 2, 3); got != 0 {
+		t.Errorf("want 0 elements, got %d", got)
+	}
+
+	if len(f.inserted) != 3 {
+		t.Errorf("want 3 insertions, got %d", len(f.inserted))
+	}
+}
+
+// tests that rings can be used as blocking buffers.
+func bufferBlockingExtraction(t *testing.T) {
+	var b ring.BlockingBuffer = newRing(t, 2)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		b.Insert("x")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	v, err := b.ExtractContext(ctx)
+	if err != nil {
+		t.Fatalf("extracting: %v", err)
+	}
+
+	if v != "x" {
+		t.Errorf("want x, got %v", v)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := b.ExtractContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want deadline exceeded, got %v", err)
+	}
+}
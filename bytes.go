@@ -0,0 +1,164 @@
+package ring
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+)
+
+// the size of the length prefix of each element in a Bytes ring.
+const bytesPrefixSize = 4
+
+// Bytes is a concurrent bounded circular buffer of byte slices.  When
+// there is not enough room for a new element, it drops the oldest ones
+// to make room for it.
+//
+// Elements are copied back to back into a single arena, each one
+// preceded by its length, so storing them needs no allocations and
+// holds no pointers for the garbage collector to scan.
+type Bytes struct {
+	mu    sync.Mutex
+	arena []byte
+	head  int // offset of the oldest element
+	used  int // bytes of the arena in use
+	len   int // how many elements are stored in the ring
+}
+
+// NewBytes returns a new byte slice ring with an arena of the given
+// size in bytes.  Each element takes 4 bytes of the arena besides its
+// own length.
+func NewBytes(size int) (*Bytes, error) {
+	if size <= bytesPrefixSize {
+		return nil, fmt.Errorf("arena size must be > %d, got %d", bytesPrefixSize, size)
+	}
+
+	return &Bytes{
+		arena: make([]byte, size),
+	}, nil
+}
+
+// copies p into the arena at offset off, wrapping around its end.
+func (b *Bytes) write(off int, p []byte) {
+	n := copy(b.arena[off:], p)
+	copy(b.arena, p[n:])
+}
+
+// copies len(p) bytes from the arena at offset off into p, wrapping
+// around its end.
+func (b *Bytes) read(off int, p []byte) {
+	n := copy(p, b.arena[off:])
+	copy(p[n:], b.arena)
+}
+
+// returns the length of the oldest element.
+func (b *Bytes) headLen() int {
+	var prefix [bytesPrefixSize]byte
+
+	b.read(b.head, prefix[:])
+
+	return int(binary.BigEndian.Uint32(prefix[:]))
+}
+
+// Insert adds a copy of p to the ring, dropping the oldest elements if
+// there is not enough room for it.  It fails if p is too big to fit in
+// the arena.
+func (b *Bytes) Insert(p []byte) error {
+	need := bytesPrefixSize + len(p)
+	if need > len(b.arena) {
+		return fmt.Errorf("element of %d bytes does not fit in an arena of %d bytes",
+			len(p), len(b.arena))
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for b.used+need > len(b.arena) {
+		b.drop()
+	}
+
+	var prefix [bytesPrefixSize]byte
+
+	binary.BigEndian.PutUint32(prefix[:], uint32(len(p)))
+
+	tail := (b.head + b.used) % len(b.arena)
+	b.write(tail, prefix[:])
+	b.write((tail+bytesPrefixSize)%len(b.arena), p)
+
+	b.used += need
+	b.len++
+
+	return nil
+}
+
+// removes the oldest element.
+func (b *Bytes) drop() {
+	n := bytesPrefixSize + b.headLen()
+	b.head = (b.head + n) % len(b.arena)
+	b.used -= n
+	b.len--
+}
+
+// Extract removes the oldest element from the ring and returns it
+// appended to dst.  It returns false if the ring is empty.
+func (b *Bytes) Extract(dst []byte) ([]byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	dst, ok := b.peek(dst)
+	if ok {
+		b.drop()
+	}
+
+	return dst, ok
+}
+
+// Peek returns the oldest element in the ring appended to dst.  It
+// returns false if the ring is empty.
+func (b *Bytes) Peek(dst []byte) ([]byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.peek(dst)
+}
+
+func (b *Bytes) peek(dst []byte) ([]byte, bool) {
+	if b.len == 0 {
+		return dst, false
+	}
+
+	n := b.headLen()
+
+	if cap(dst)-len(dst) < n {
+		grown := make([]byte, len(dst), len(dst)+n)
+		copy(grown, dst)
+		dst = grown
+	}
+
+	start := len(dst)
+	dst = dst[:start+n]
+	b.read((b.head+bytesPrefixSize)%len(b.arena), dst[start:])
+
+	return dst, true
+}
+
+// Len returns the amount of elements in the ring.
+func (b *Bytes) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.len
+}
+
+// Used returns the bytes of the arena in use, including the length
+// prefixes of the elements.
+func (b *Bytes) Used() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.used
+}
+
+// Size returns the size of the arena in bytes.
+func (b *Bytes) Size() int {
+	return len(b.arena)
+}
@@ -0,0 +1,179 @@
+package ring_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestBytes(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid size":       bytesInvalidSize,
+		"insert and extract": bytesInsertAndExtract,
+		"drops oldest":       bytesDropsOldest,
+		"wraps around":       bytesWrapsAround,
+		"too big":            bytesTooBig,
+		"appends to dst":     bytesAppendsToDst,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a byte slice ring with the given arena size or fails the
+// test.
+func newBytes(t *testing.T, size int) *ring.Bytes {
+	t.Helper()
+
+	b, err := ring.NewBytes(size)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	return b
+}
+
+// inserts the strings in the ring or fails the test.
+func insertBytes(t *testing.T, b *ring.Bytes, ss ...string) {
+	t.Helper()
+
+	for _, s := range ss {
+		if err := b.Insert([]byte(s)); err != nil {
+			t.Fatalf("inserting %q: %v", s, err)
+		}
+	}
+}
+
+// asserts that extracting from the ring returns the wanted strings and
+// leaves it empty.
+func assertDrainBytes(t *testing.T, b *ring.Bytes, want ...string) {
+	t.Helper()
+
+	if got := b.Len(); got != len(want) {
+		t.Fatalf("wrong length, want %d, got %d", len(want), got)
+	}
+
+	for _, w := range want {
+		got, ok := b.Extract(nil)
+		if !ok {
+			t.Fatalf("when extracting %q: unexpected empty ring", w)
+		}
+
+		if string(got) != w {
+			t.Errorf("unexpected extracted value, want %q, got %q", w, got)
+		}
+	}
+
+	if _, ok := b.Extract(nil); ok {
+		t.Fatal("want empty ring after draining")
+	}
+
+	if got := b.Used(); got != 0 {
+		t.Errorf("want empty arena after draining, got %d bytes used", got)
+	}
+}
+
+// tests that arenas too small for any element are invalid.
+func bytesInvalidSize(t *testing.T) {
+	for _, size := range []int{4, 0, -1} {
+		if _, err := ring.NewBytes(size); err == nil {
+			t.Errorf("unexpected success with size %d", size)
+		}
+	}
+}
+
+// tests the ring returns the elements in order.
+func bytesInsertAndExtract(t *testing.T) {
+	b := newBytes(t, 64)
+
+	insertBytes(t, b, "a", "", "bc")
+
+	if got := b.Used(); got != 3*4+3 {
+		t.Errorf("want 15 bytes used, got %d", got)
+	}
+
+	got, ok := b.Peek(nil)
+	if !ok || string(got) != "a" {
+		t.Fatalf("want to peek %q, got %q, %t", "a", got, ok)
+	}
+
+	assertDrainBytes(t, b, "a", "", "bc")
+}
+
+// tests that the oldest elements are dropped to make room for new ones.
+func bytesDropsOldest(t *testing.T) {
+	b := newBytes(t, 16)
+
+	insertBytes(t, b, "aaaa", "bbbb") // 16 bytes
+	insertBytes(t, b, "c")            // drops aaaa
+	assertDrainBytes(t, b, "bbbb", "c")
+
+	insertBytes(t, b, "d", "e", "f", "12345678")
+	assertDrainBytes(t, b, "12345678")
+}
+
+// tests that elements and their length prefixes can wrap around the end
+// of the arena.
+func bytesWrapsAround(t *testing.T) {
+	b := newBytes(t, 18) // room for 3 elements of 1 or 2 bytes
+
+	var want []string
+
+	for i := 0; i < 100; i++ {
+		s := fmt.Sprint(i)
+		insertBytes(t, b, s)
+
+		want = append(want, s)
+		if len(want) > 3 {
+			want = want[1:]
+		}
+
+		got, ok := b.Peek(nil)
+		if !ok || string(got) != want[0] {
+			t.Fatalf("after inserting %q: want to peek %q, got %q, %t", s, want[0], got, ok)
+		}
+	}
+
+	assertDrainBytes(t, b, "97", "98", "99")
+}
+
+// tests that elements that do not fit in the arena are rejected.
+func bytesTooBig(t *testing.T) {
+	b := newBytes(t, 8)
+
+	insertBytes(t, b, "a")
+
+	if err := b.Insert([]byte("12345")); err == nil {
+		t.Fatal("unexpected success")
+	}
+
+	insertBytes(t, b, "1234")
+	assertDrainBytes(t, b, "1234")
+}
+
+// tests that extracted elements are appended to the given slice.
+func bytesAppendsToDst(t *testing.T) {
+	b := newBytes(t, 32)
+
+	insertBytes(t, b, "world")
+
+	dst := make([]byte, 0, 64)
+	dst = append(dst, "hello, "...)
+
+	got, ok := b.Extract(dst)
+	if !ok || string(got) != "hello, world" {
+		t.Fatalf("want %q, got %q, %t", "hello, world", got, ok)
+	}
+
+	if &got[0] != &dst[0] {
+		t.Error("want extraction to reuse the given slice")
+	}
+}
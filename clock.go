@@ -0,0 +1,50 @@
+package ring
+
+import "time"
+
+// Clock tells the time to the time-based features of rings and groups,
+// like timestamps, ages, drop history buckets, idle TTLs and background
+// goroutines.  Tests can use a manual clock to advance the time
+// deterministically instead of sleeping, see WithClock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTicker returns a ticker sending the time on its channel every
+	// d, like time.NewTicker.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks at intervals, like time.Ticker.
+type Ticker interface {
+	// C returns the channel the ticks are delivered on.
+	C() <-chan time.Time
+	// Stop turns off the ticker.
+	Stop()
+}
+
+// returns the current time, as told by the clock of the ring.
+func (r *Ring) now() time.Time {
+	if r.clock == nil { // the zero Ring
+		return time.Now()
+	}
+
+	return r.clock.Now()
+}
+
+// SystemClock is the Clock of the time package, the default one.  The
+// packages built on rings use it as their default clock too.
+type SystemClock struct{}
+
+// Now implements Clock.
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// NewTicker implements Clock.
+func (SystemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time { return t.Ticker.C }
@@ -0,0 +1,160 @@
+package ring_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestClock(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"ages":           clockAges,
+		"drop history":   clockDropHistory,
+		"stale alarm":    clockStaleAlarm,
+		"group idle TTL": clockGroupIdleTTL,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// an arbitrary start time for the manual clocks, at the start of an
+// hour.
+var epoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// returns a ring with the given capacity and options, using the given
+// clock, or fails the test.
+func newClockedRing(t *testing.T, cap int, clock ring.Clock, opts ...ring.Option) *ring.Ring {
+	t.Helper()
+
+	r, err := ring.New(cap, append(opts, ring.WithClock(clock))...)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	t.Cleanup(func() { _ = r.Close() })
+
+	return r
+}
+
+// tests that ages are measured with the clock.
+func clockAges(t *testing.T) {
+	clock := ringtest.NewClock(epoch)
+	r := newClockedRing(t, 3, clock, ring.WithTimestamps())
+
+	r.Insert(1)
+	clock.Advance(time.Minute)
+	r.Insert(2)
+	clock.Advance(time.Second)
+
+	if age, _ := r.OldestAge(); age != time.Minute+time.Second {
+		t.Errorf("want oldest age 1m1s, got %v", age)
+	}
+
+	if age, _ := r.NewestAge(); age != time.Second {
+		t.Errorf("want newest age 1s, got %v", age)
+	}
+
+	if got := r.ExtractOlderThan(epoch.Add(30 * time.Second)); fmt.Sprint(got) != "[1]" {
+		t.Errorf("want [1] extracted, got %v", got)
+	}
+}
+
+// tests that drops are counted in the buckets of the clock time.
+func clockDropHistory(t *testing.T) {
+	clock := ringtest.NewClock(epoch)
+	r := newClockedRing(t, 1, clock, ring.WithDropHistory(3, time.Hour))
+
+	r.Insert(1)
+	r.Insert(2)
+	clock.Advance(time.Hour)
+	r.Insert(3)
+	r.Insert(4)
+	r.Insert(5)
+
+	want := []ring.DropBucket{
+		{Start: epoch.Add(-time.Hour), Drops: 0},
+		{Start: epoch, Drops: 1},
+		{Start: epoch.Add(time.Hour), Drops: 3},
+	}
+
+	if got := r.DropHistory(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("want history %v, got %v", want, got)
+	}
+}
+
+// tests that the stale alarm goes off when the clock advances past the
+// threshold, without sleeping.
+func clockStaleAlarm(t *testing.T) {
+	clock := ringtest.NewClock(epoch)
+	alarms := make(chan time.Duration, 10)
+
+	r := newClockedRing(t, 3, clock, ring.WithStaleAlarm(time.Minute, func(age time.Duration) {
+		alarms <- age
+	}))
+
+	r.Insert(1)
+	clock.WaitTickers(1)
+
+	clock.Advance(30 * time.Second)
+	clock.Advance(31 * time.Second)
+
+	select {
+	case age := <-alarms:
+		if age != 61*time.Second {
+			t.Errorf("want age 1m1s, got %v", age)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stale alarm did not go off")
+	}
+}
+
+// tests that idle keys are evicted when the clock advances past the
+// TTL, without sleeping.
+func clockGroupIdleTTL(t *testing.T) {
+	clock := ringtest.NewClock(epoch)
+	evicted := make(chan string, 10)
+
+	g, err := ring.NewGroup(10, 100,
+		ring.WithGroupClock(clock),
+		ring.WithIdleTTL(time.Minute),
+		ring.WithOnKeyEvicted(func(key string, elements []interface{}) {
+			evicted <- fmt.Sprint(key, elements)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("creating group: %v", err)
+	}
+
+	defer g.Close()
+
+	g.Insert("idle", 1)
+	clock.WaitTickers(1)
+
+	clock.Advance(45 * time.Second)
+	g.Insert("active", 2)
+	clock.Advance(30 * time.Second)
+
+	select {
+	case got := <-evicted:
+		if got != "idle[1]" {
+			t.Errorf("want idle[1] evicted, got %s", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("idle key not evicted")
+	}
+
+	if got := fmt.Sprint(g.Keys()); got != "[active]" {
+		t.Errorf("want keys [active], got %s", got)
+	}
+}
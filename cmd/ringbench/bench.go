@@ -0,0 +1,320 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+// buffer is what the harness needs from the measured implementations.
+type buffer interface {
+	Insert(v interface{})
+	Extract() (interface{}, bool)
+	Len() int
+}
+
+// implementation is a buffer implementation measured by the harness.
+type implementation struct {
+	name string
+	new  func(cap int) (buffer, error)
+}
+
+// the measured implementations, new ones can be added here.  Package
+// ring has no RWMutex, single producer single consumer or lock-free
+// multiple producer multiple consumer rings to measure, as all its
+// writes are exclusive and its rings are safe for any number of
+// goroutines.
+var implementations = []implementation{
+	{
+		name: "mutex",
+		new: func(cap int) (buffer, error) {
+			return ring.New(cap)
+		},
+	},
+	{
+		name: "spin",
+		new: func(cap int) (buffer, error) {
+			return ring.New(cap, ring.WithSpinLock(spins))
+		},
+	},
+	{
+		name: "lockfree",
+		new: func(cap int) (buffer, error) {
+			return ring.New(cap, ring.WithLockFreeReads())
+		},
+	},
+	{
+		name: "sharded",
+		new:  newShardedBuffer,
+	},
+	{
+		name: "chan",
+		new:  newChanBuffer,
+	},
+}
+
+// the spins of the spin implementation before blocking.
+const spins = 100
+
+// returns the implementation with the given name.
+func lookup(name string) (implementation, error) {
+	for _, impl := range implementations {
+		if impl.name == name {
+			return impl, nil
+		}
+	}
+
+	return implementation{}, fmt.Errorf("unknown implementation %q", name)
+}
+
+// chanBuffer is a buffered Go channel dropping its oldest elements when
+// full, the baseline the rings are compared to.
+type chanBuffer chan interface{}
+
+func newChanBuffer(cap int) (buffer, error) {
+	if cap < 1 {
+		return nil, fmt.Errorf("channel capacity must be > 0, got %d", cap)
+	}
+
+	return make(chanBuffer, cap), nil
+}
+
+func (c chanBuffer) Insert(v interface{}) {
+	for {
+		select {
+		case c <- v:
+			return
+		default:
+		}
+
+		// full, drop the oldest element, unless a consumer did
+		select {
+		case <-c:
+		default:
+		}
+	}
+}
+
+func (c chanBuffer) Extract() (interface{}, bool) {
+	select {
+	case v := <-c:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
+func (c chanBuffer) Len() int { return len(c) }
+
+// shardedBuffer spreads its elements over several rings, one per
+// processor, in turns, to reduce the contention on their locks, at the
+// cost of extracting the elements only roughly in order.  When full, it
+// drops the oldest element of the ring it inserts in.
+type shardedBuffer struct {
+	shards   []*ring.Ring
+	inserts  uint32 // insertions, to take turns
+	extracts uint32 // extractions, to take turns
+}
+
+func newShardedBuffer(cap int) (buffer, error) {
+	if cap < 1 {
+		return nil, fmt.Errorf("sharded buffer capacity must be > 0, got %d", cap)
+	}
+
+	n := runtime.GOMAXPROCS(0)
+	if n > cap {
+		n = cap
+	}
+
+	b := &shardedBuffer{shards: make([]*ring.Ring, n)}
+
+	for i := range b.shards {
+		// the capacity is split as evenly as possible
+		shardCap := cap / n
+		if i < cap%n {
+			shardCap++
+		}
+
+		r, err := ring.New(shardCap)
+		if err != nil {
+			return nil, err
+		}
+
+		b.shards[i] = r
+	}
+
+	return b, nil
+}
+
+func (b *shardedBuffer) Insert(v interface{}) {
+	i := atomic.AddUint32(&b.inserts, 1) % uint32(len(b.shards))
+	b.shards[i].Insert(v)
+}
+
+func (b *shardedBuffer) Extract() (interface{}, bool) {
+	i := int(atomic.AddUint32(&b.extracts, 1) % uint32(len(b.shards)))
+
+	// starting with its turn, the first shard with elements
+	for j := 0; j < len(b.shards); j++ {
+		if v, ok := b.shards[(i+j)%len(b.shards)].Extract(); ok {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+func (b *shardedBuffer) Len() int {
+	var n int
+	for _, r := range b.shards {
+		n += r.Len()
+	}
+
+	return n
+}
+
+// run is a benchmark run: a number of producers inserting a total
+// number of elements in a buffer, while a number of consumers extract
+// them.
+type run struct {
+	impl      implementation
+	producers int
+	consumers int
+	cap       int
+	ops       int // total insertions
+}
+
+// result is the measurements of a run.
+type result struct {
+	elapsed   time.Duration
+	extracted int64           // elements extracted by the consumers
+	latencies []time.Duration // sample of insertion latencies, sorted
+}
+
+// every how many insertions the latency is sampled.
+const sampleEvery = 64
+
+// executes the run and returns its measurements.
+func (r run) execute() (result, error) {
+	if r.producers < 1 || r.consumers < 0 {
+		return result{}, errors.New("at least one producer and no negative consumers needed")
+	}
+
+	b, err := r.impl.new(r.cap)
+	if err != nil {
+		return result{}, err
+	}
+
+	var (
+		produced  int32 // 1 when all the producers are done
+		extracted int64
+		consumers sync.WaitGroup
+		producers sync.WaitGroup
+		mu        sync.Mutex
+		samples   []time.Duration
+	)
+
+	consumers.Add(r.consumers)
+
+	for i := 0; i < r.consumers; i++ {
+		go func() {
+			defer consumers.Done()
+
+			var n int64
+
+			for {
+				if _, ok := b.Extract(); ok {
+					n++
+					continue
+				}
+
+				if atomic.LoadInt32(&produced) == 1 && b.Len() == 0 {
+					break
+				}
+
+				runtime.Gosched()
+			}
+
+			atomic.AddInt64(&extracted, n)
+		}()
+	}
+
+	start := time.Now()
+
+	producers.Add(r.producers)
+
+	for i := 0; i < r.producers; i++ {
+		ops := r.ops / r.producers
+		if i < r.ops%r.producers {
+			ops++
+		}
+
+		go func(ops int) {
+			defer producers.Done()
+
+			local := make([]time.Duration, 0, ops/sampleEvery+1)
+
+			for j := 0; j < ops; j++ {
+				if j%sampleEvery != 0 {
+					b.Insert(j)
+					continue
+				}
+
+				t := time.Now()
+				b.Insert(j)
+				local = append(local, time.Since(t))
+			}
+
+			mu.Lock()
+			samples = append(samples, local...)
+			mu.Unlock()
+		}(ops)
+	}
+
+	producers.Wait()
+	atomic.StoreInt32(&produced, 1)
+	consumers.Wait()
+
+	elapsed := time.Since(start)
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	return result{
+		elapsed:   elapsed,
+		extracted: extracted,
+		latencies: samples,
+	}, nil
+}
+
+// returns the latency at the given quantile of the sorted sample.
+func quantile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	return sorted[int(q*float64(len(sorted)-1))]
+}
+
+// writes the result of the run in the format of the benchmarks of the
+// go test command, which benchstat understands.
+func (r run) report(w io.Writer, res result) error {
+	ops := float64(r.ops)
+
+	_, err := fmt.Fprintf(w, "BenchmarkRing/impl=%s/producers=%d/consumers=%d/cap=%d-%d\t%d\t%.2f ns/op\t%.0f p50-ns\t%.0f p99-ns\t%.3f extracted/op\n",
+		r.impl.name, r.producers, r.consumers, r.cap, runtime.GOMAXPROCS(0),
+		r.ops,
+		float64(res.elapsed.Nanoseconds())/ops,
+		float64(quantile(res.latencies, 0.50).Nanoseconds()),
+		float64(quantile(res.latencies, 0.99).Nanoseconds()),
+		float64(res.extracted)/ops,
+	)
+
+	return err
+}
@@ -0,0 +1,132 @@
+// Command ringbench measures the throughput and latency of the buffer
+// implementations under different numbers of concurrent producers and
+// consumers, to choose the right one for a given machine.
+//
+// Usage:
+//
+//	ringbench [-impl mutex,spin,lockfree,sharded,chan] [-producers 1,2,4] [-consumers 1,2,4]
+//		[-cap 1024] [-ops 1000000] [-count 1]
+//
+// Each run inserts ops elements in total, split among the producers,
+// while the consumers extract them, and reports:
+//
+//	ns/op          elapsed time per insertion
+//	p50-ns p99-ns  latency quantiles of a sample of the insertions
+//	extracted/op   fraction of the elements extracted before being
+//	               dropped by a full buffer
+//
+// The output has the format of the benchmarks of the go test command,
+// so runs with -count > 1 can be compared with benchstat.
+//
+// The implementations are mutex, the Ring of package ring, spin, the
+// same with the WithSpinLock option, lockfree, the same with the
+// WithLockFreeReads option, sharded, a Ring per processor taking turns,
+// and chan, a buffered Go channel dropping its oldest elements, as the
+// baseline.  Other implementations can be measured by adding them to
+// the implementations table.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	var names []string
+	for _, impl := range implementations {
+		names = append(names, impl.name)
+	}
+
+	impls := flag.String("impl", strings.Join(names, ","), "comma-separated implementations to measure")
+	producers := flag.String("producers", "1,2,4", "comma-separated numbers of producers")
+	consumers := flag.String("consumers", "1,2,4", "comma-separated numbers of consumers")
+	cap := flag.Int("cap", 1024, "capacity of the buffers")
+	ops := flag.Int("ops", 1000000, "insertions per run")
+	count := flag.Int("count", 1, "repetitions of each run")
+	flag.Parse()
+
+	runs, err := plan(*impls, *producers, *consumers, *cap, *ops)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ringbench:", err)
+		os.Exit(2)
+	}
+
+	for _, r := range runs {
+		for i := 0; i < *count; i++ {
+			res, err := r.execute()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "ringbench:", err)
+				os.Exit(1)
+			}
+
+			if err := r.report(os.Stdout, res); err != nil {
+				fmt.Fprintln(os.Stderr, "ringbench:", err)
+				os.Exit(1)
+			}
+		}
+	}
+}
+
+// returns the runs for all the combinations of the comma-separated
+// implementations and numbers of producers and consumers.
+func plan(impls, producers, consumers string, cap, ops int) ([]run, error) {
+	if ops < 1 {
+		return nil, fmt.Errorf("ops must be > 0, got %d", ops)
+	}
+
+	ps, err := parseInts(producers, 1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid producers: %w", err)
+	}
+
+	cs, err := parseInts(consumers, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid consumers: %w", err)
+	}
+
+	var runs []run
+
+	for _, name := range strings.Split(impls, ",") {
+		impl, err := lookup(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range ps {
+			for _, c := range cs {
+				runs = append(runs, run{
+					impl:      impl,
+					producers: p,
+					consumers: c,
+					cap:       cap,
+					ops:       ops,
+				})
+			}
+		}
+	}
+
+	return runs, nil
+}
+
+// parses comma-separated integers, each of them at least min.
+func parseInts(s string, min int) ([]int, error) {
+	var result []int
+
+	for _, f := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, err
+		}
+
+		if n < min {
+			return nil, fmt.Errorf("must be >= %d, got %d", min, n)
+		}
+
+		result = append(result, n)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+)
+
+func TestBench(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"plan":             benchPlan,
+		"invalid plans":    benchInvalidPlans,
+		"implementations":  benchImplementations,
+		"no consumers":     benchNoConsumers,
+		"benchstat output": benchBenchstatOutput,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns the planned runs or fails the test.
+func mustPlan(t *testing.T, impls, producers, consumers string, ops int) []run {
+	t.Helper()
+
+	runs, err := plan(impls, producers, consumers, 16, ops)
+	if err != nil {
+		t.Fatalf("planning: %v", err)
+	}
+
+	return runs
+}
+
+// executes the run or fails the test.
+func mustExecute(t *testing.T, r run) result {
+	t.Helper()
+
+	res, err := r.execute()
+	if err != nil {
+		t.Fatalf("executing %+v: %v", r, err)
+	}
+
+	return res
+}
+
+// tests that runs are planned for every combination.
+func benchPlan(t *testing.T) {
+	runs := mustPlan(t, "mutex, chan", "1,2", "0,1,3", 100)
+
+	if len(runs) != 12 {
+		t.Fatalf("want 12 runs, got %d", len(runs))
+	}
+
+	if r := runs[11]; r.impl.name != "chan" || r.producers != 2 || r.consumers != 3 {
+		t.Errorf("unexpected last run %+v", r)
+	}
+}
+
+// tests that invalid arguments are rejected.
+func benchInvalidPlans(t *testing.T) {
+	for _, args := range [][3]string{
+		{"nope", "1", "1"},
+		{"mutex", "0", "1"},
+		{"mutex", "1", "-1"},
+		{"mutex", "x", "1"},
+	} {
+		if _, err := plan(args[0], args[1], args[2], 16, 100); err == nil {
+			t.Errorf("%v: unexpected success", args)
+		}
+	}
+
+	if _, err := plan("mutex", "1", "1", 16, 0); err == nil {
+		t.Error("zero ops: unexpected success")
+	}
+}
+
+// tests that every implementation passes all the inserted elements to
+// the consumers or drops them, and never extracts more.
+func benchImplementations(t *testing.T) {
+	for _, r := range mustPlan(t, "mutex,spin,lockfree,sharded,chan", "1,3", "1,2", 10000) {
+		res := mustExecute(t, r)
+
+		if res.extracted < 1 || res.extracted > int64(r.ops) {
+			t.Errorf("%+v: unexpected %d elements extracted", r, res.extracted)
+		}
+
+		if want := (r.ops + sampleEvery - 1) / sampleEvery; len(res.latencies) < want-r.producers ||
+			len(res.latencies) > want+r.producers {
+			t.Errorf("%+v: want about %d latency samples, got %d", r, want, len(res.latencies))
+		}
+	}
+}
+
+// tests that runs without consumers just fill the buffers.
+func benchNoConsumers(t *testing.T) {
+	for _, r := range mustPlan(t, "mutex,spin,lockfree,sharded,chan", "2", "0", 1000) {
+		if res := mustExecute(t, r); res.extracted != 0 {
+			t.Errorf("%s: want nothing extracted, got %d", r.impl.name, res.extracted)
+		}
+	}
+}
+
+// tests that the reports have the format of go test benchmarks.
+func benchBenchstatOutput(t *testing.T) {
+	r := mustPlan(t, "mutex", "1", "1", 1000)[0]
+
+	var b bytes.Buffer
+	if err := r.report(&b, mustExecute(t, r)); err != nil {
+		t.Fatalf("reporting: %v", err)
+	}
+
+	re := regexp.MustCompile(`^BenchmarkRing/impl=mutex/producers=1/consumers=1/cap=16-\d+\t1000\t[\d.]+ ns/op\t\d+ p50-ns\t\d+ p99-ns\t[\d.]+ extracted/op\n$`)
+	if !re.Match(b.Bytes()) {
+		t.Errorf("unexpected report %q", b.String())
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// the default interval between snapshots of the persistent rings.
+const defaultSnapshotEvery = 10 * time.Second
+
+// config is the contents of the configuration file, for example:
+//
+//	{
+//		"addr": "localhost:7070",
+//		"dir": "/var/lib/ringd",
+//		"snapshot_every": "30s",
+//		"rings": [
+//			{"name": "events", "cap": 1000, "labels": {"env": "prod"}},
+//			{"name": "errors", "cap": 100}
+//		]
+//	}
+//
+// If dir is empty, the rings are not persistent.  Otherwise, it is
+// created on start if missing, and the failures to checkpoint the rings
+// to it are logged.
+type config struct {
+	Addr          string       `json:"addr"`
+	Dir           string       `json:"dir"`
+	SnapshotEvery duration     `json:"snapshot_every"`
+	Rings         []ringConfig `json:"rings"`
+}
+
+// the configuration of a ring.
+type ringConfig struct {
+	Name   string            `json:"name"`
+	Cap    int               `json:"cap"`
+	Labels map[string]string `json:"labels"`
+}
+
+// duration is a time.Duration written as a string, like "10s".
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string: %w", err)
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = duration(v)
+
+	return nil
+}
+
+// reads and validates the configuration file at path.
+func loadConfig(path string) (*config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var c config
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+
+	return &c, nil
+}
+
+// checks the configuration and sets the defaults.
+func (c *config) validate() error {
+	if c.Addr == "" {
+		return errors.New("missing addr")
+	}
+
+	if c.SnapshotEvery < 0 {
+		return fmt.Errorf("snapshot_every must be >= 0, got %v", time.Duration(c.SnapshotEvery))
+	}
+
+	if c.SnapshotEvery == 0 {
+		c.SnapshotEvery = duration(defaultSnapshotEvery)
+	}
+
+	if len(c.Rings) == 0 {
+		return errors.New("no rings")
+	}
+
+	seen := make(map[string]bool, len(c.Rings))
+
+	for i, rc := range c.Rings {
+		// names are used in URLs and file names
+		if rc.Name == "" || rc.Name == "." || rc.Name == ".." || strings.ContainsAny(rc.Name, `/\?#%`) {
+			return fmt.Errorf("ring %d: invalid name %q", i, rc.Name)
+		}
+
+		if seen[rc.Name] {
+			return fmt.Errorf("ring %d: duplicated name %q", i, rc.Name)
+		}
+
+		seen[rc.Name] = true
+
+		if rc.Cap < 1 {
+			return fmt.Errorf("ring %q: capacity must be > 0, got %d", rc.Name, rc.Cap)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringhttp"
+)
+
+// daemon hosts the configured rings and serves them over HTTP:
+//
+//	GET /rings                   the list of rings, see ringhttp.RegistryHandler
+//	/rings/{name}/items...       the REST API, see ringhttp.RESTHandler
+//	GET /rings/{name}/events     the elements as server-sent events, see
+//	                             ringhttp.EventsHandler
+//
+// Elements are JSON documents, stored as their compact encoding.
+type daemon struct {
+	rings   []*ring.Ring
+	names   []string
+	handler http.Handler
+}
+
+// creates and registers the configured rings, restoring the persistent
+// ones from their snapshots.
+func newDaemon(c *config) (*daemon, error) {
+	if c.Dir != "" {
+		if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+			return nil, fmt.Errorf("creating snapshot directory: %w", err)
+		}
+	}
+
+	d := &daemon{}
+	mux := http.NewServeMux()
+
+	for _, rc := range c.Rings {
+		opts := []ring.Option{
+			ring.WithName(rc.Name),
+			ring.WithLabels(rc.Labels),
+		}
+
+		if c.Dir != "" {
+			name := rc.Name
+			path := filepath.Join(c.Dir, name+".snap")
+			opts = append(opts,
+				ring.WithRestore(path),
+				ring.WithAutoSnapshot(path, time.Duration(c.SnapshotEvery)),
+				ring.WithSnapshotErrorHook(func(err error) {
+					log.Printf("checkpointing ring %q: %v", name, err)
+				}),
+			)
+		}
+
+		r, err := ring.New(rc.Cap, opts...)
+		if err != nil {
+			_ = d.close()
+			return nil, fmt.Errorf("creating ring %q: %w", rc.Name, err)
+		}
+
+		d.rings = append(d.rings, r)
+
+		if err := ring.Register(rc.Name, r); err != nil {
+			_ = d.close()
+			return nil, err
+		}
+
+		d.names = append(d.names, rc.Name)
+
+		prefix := "/rings/" + rc.Name
+		rest := ringhttp.RESTHandler(r, encode, decode)
+		mux.Handle(prefix+"/items", http.StripPrefix(prefix, rest))
+		mux.Handle(prefix+"/items/", http.StripPrefix(prefix, rest))
+		mux.Handle(prefix+"/events", ringhttp.EventsHandler(r, encode))
+	}
+
+	mux.Handle("/rings", ringhttp.RegistryHandler(render))
+	d.handler = mux
+
+	return d, nil
+}
+
+// unregisters and closes the rings, writing their last snapshots.  It
+// returns the first error closing them.
+func (d *daemon) close() error {
+	for _, name := range d.names {
+		ring.Unregister(name)
+	}
+
+	var first error
+
+	for _, r := range d.rings {
+		if err := r.Close(); err != nil && first == nil {
+			first = fmt.Errorf("closing ring %q: %w", r.Name(), err)
+		}
+	}
+
+	return first
+}
+
+// returns the stored encoding of an element.
+func encode(v interface{}) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected element type %T", v)
+	}
+
+	return b, nil
+}
+
+// checks that p is a JSON document and returns its compact encoding,
+// which is what gets stored in the rings.
+func decode(p []byte) (interface{}, error) {
+	var b bytes.Buffer
+	if err := json.Compact(&b, p); err != nil {
+		return nil, err
+	}
+
+	if b.Len() == 0 {
+		return nil, errors.New("empty element")
+	}
+
+	return b.Bytes(), nil
+}
+
+// renders the stored elements as the JSON they hold.
+func render(v interface{}) interface{} {
+	if b, ok := v.([]byte); ok {
+		return json.RawMessage(b)
+	}
+
+	return v
+}
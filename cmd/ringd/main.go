@@ -0,0 +1,95 @@
+// Command ringd is a small standalone service hosting named rings of
+// recent events, configured in a file, and serving them over HTTP.
+//
+// Usage:
+//
+//	ringd -config ringd.json
+//
+// See the config type for the format of the configuration file, and the
+// daemon type for the served endpoints.  Rings in a configuration with a
+// directory are persistent: they are checkpointed periodically and on
+// shutdown, and restored on start.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// the time allowed to the in-flight requests on shutdown.
+const shutdownTimeout = 5 * time.Second
+
+func main() {
+	path := flag.String("config", "ringd.json", "path of the configuration file")
+	flag.Parse()
+
+	if err := run(*path); err != nil {
+		fmt.Fprintln(os.Stderr, "ringd:", err)
+		os.Exit(1)
+	}
+}
+
+// serves the rings configured in the file at path until SIGINT or
+// SIGTERM.
+func run(path string) error {
+	c, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+
+	d, err := newDaemon(c)
+	if err != nil {
+		return err
+	}
+
+	// canceled on shutdown, to end the streams of events
+	base, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
+
+	srv := &http.Server{
+		Addr:        c.Addr,
+		Handler:     d.handler,
+		BaseContext: func(net.Listener) context.Context { return base },
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	defer signal.Stop(signals)
+
+	served := make(chan error, 1)
+
+	go func() { served <- srv.ListenAndServe() }()
+
+	log.Printf("serving %d rings on %s", len(c.Rings), c.Addr)
+
+	select {
+	case err = <-served:
+	case sig := <-signals:
+		log.Printf("%v received, shutting down", sig)
+		cancelBase()
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		err = srv.Shutdown(ctx)
+		cancel()
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+
+	if closeErr := d.close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRingd(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"load config":      ringdLoadConfig,
+		"invalid configs":  ringdInvalidConfigs,
+		"serves rings":     ringdServesRings,
+		"rejects non JSON": ringdRejectsNonJSON,
+		"persistent rings": ringdPersistentRings,
+		"unusable dir":     ringdUnusableDir,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a temporary directory removed at the end of the test, or
+// fails the test.
+func tempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "ringd")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %v", err)
+	}
+
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	return dir
+}
+
+// writes the configuration file and returns its path, or fails the
+// test.
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(tempDir(t), "ringd.json")
+
+	if err := ioutil.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+
+	return path
+}
+
+// returns a daemon for the given configuration, or fails the test.  The
+// daemon is closed at the end of the test.
+func start(t *testing.T, c *config) *daemon {
+	t.Helper()
+
+	if err := c.validate(); err != nil {
+		t.Fatalf("invalid config: %v", err)
+	}
+
+	d, err := newDaemon(c)
+	if err != nil {
+		t.Fatalf("creating daemon: %v", err)
+	}
+
+	t.Cleanup(func() { _ = d.close() })
+
+	return d
+}
+
+// sends a request to the daemon and returns the status and body of the
+// response.
+func send(d *daemon, method, url, body string) (int, string) {
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	d.handler.ServeHTTP(rec, req)
+
+	return rec.Code, rec.Body.String()
+}
+
+// tests that the configuration file is parsed and defaults are set.
+func ringdLoadConfig(t *testing.T) {
+	path := writeConfig(t, `{
+		"addr": "localhost:0",
+		"rings": [{"name": "a", "cap": 3, "labels": {"env": "test"}}]
+	}`)
+
+	c, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("loading config: %v", err)
+	}
+
+	if c.Addr != "localhost:0" || len(c.Rings) != 1 || c.Rings[0].Labels["env"] != "test" {
+		t.Errorf("unexpected config %+v", c)
+	}
+
+	if got := time.Duration(c.SnapshotEvery); got != defaultSnapshotEvery {
+		t.Errorf("want default snapshot interval, got %v", got)
+	}
+}
+
+// tests that invalid configurations are rejected.
+func ringdInvalidConfigs(t *testing.T) {
+	for _, contents := range []string{
+		`not json`,
+		`{"rings": [{"name": "a", "cap": 1}]}`,
+		`{"addr": ":0"}`,
+		`{"addr": ":0", "rings": [{"name": "a", "cap": 0}]}`,
+		`{"addr": ":0", "rings": [{"name": "a/b", "cap": 1}]}`,
+		`{"addr": ":0", "rings": [{"name": "..", "cap": 1}]}`,
+		`{"addr": ":0", "rings": [{"name": "a", "cap": 1}, {"name": "a", "cap": 1}]}`,
+		`{"addr": ":0", "snapshot_every": "soon", "rings": [{"name": "a", "cap": 1}]}`,
+		`{"addr": ":0", "snapshot_every": "-1s", "rings": [{"name": "a", "cap": 1}]}`,
+	} {
+		if _, err := loadConfig(writeConfig(t, contents)); err == nil {
+			t.Errorf("%s: unexpected success", contents)
+		}
+	}
+}
+
+// tests that the rings are served through the REST API and the
+// registry.
+func ringdServesRings(t *testing.T) {
+	d := start(t, &config{
+		Addr:  ":0",
+		Rings: []ringConfig{{Name: "ringd-serves", Cap: 2}},
+	})
+
+	for _, body := range []string{`{"a": 1}`, `2`, `[3]`} {
+		if status, resp := send(d, http.MethodPost, "/rings/ringd-serves/items", body); status != http.StatusNoContent {
+			t.Fatalf("posting %s: unexpected status %d: %s", body, status, resp)
+		}
+	}
+
+	if _, got := send(d, http.MethodGet, "/rings/ringd-serves/items", ""); got != "[2,[3]]" {
+		t.Errorf("want [2,[3]], got %s", got)
+	}
+
+	if _, got := send(d, http.MethodDelete, "/rings/ringd-serves/items/head", ""); got != "2" {
+		t.Errorf("want 2, got %s", got)
+	}
+
+	if _, got := send(d, http.MethodGet, "/rings?name=ringd-serves", ""); !strings.Contains(got, "[3]") {
+		t.Errorf("want [3] in the ring contents, got %s", got)
+	}
+
+	if _, got := send(d, http.MethodGet, "/rings", ""); !strings.Contains(got, `"name":"ringd-serves"`) {
+		t.Errorf("want the ring in the list, got %s", got)
+	}
+}
+
+// tests that elements that are not JSON documents are rejected.
+func ringdRejectsNonJSON(t *testing.T) {
+	d := start(t, &config{
+		Addr:  ":0",
+		Rings: []ringConfig{{Name: "ringd-rejects", Cap: 2}},
+	})
+
+	for _, body := range []string{``, `{`, `hello`} {
+		if status, _ := send(d, http.MethodPost, "/rings/ringd-rejects/items", body); status != http.StatusBadRequest {
+			t.Errorf("posting %q: want status 400, got %d", body, status)
+		}
+	}
+}
+
+// tests that rings in a configuration with a directory keep their
+// elements across restarts.
+func ringdPersistentRings(t *testing.T) {
+	c := &config{
+		Addr:          ":0",
+		Dir:           filepath.Join(tempDir(t), "snapshots"), // created on start
+		SnapshotEvery: duration(time.Hour),
+		Rings:         []ringConfig{{Name: "ringd-persistent", Cap: 2}},
+	}
+
+	d := start(t, c)
+	send(d, http.MethodPost, "/rings/ringd-persistent/items", `"kept"`)
+
+	if err := d.close(); err != nil {
+		t.Fatalf("closing daemon: %v", err)
+	}
+
+	d = start(t, c)
+
+	if _, got := send(d, http.MethodGet, "/rings/ringd-persistent/items", ""); got != `["kept"]` {
+		t.Errorf("want [\"kept\"], got %s", got)
+	}
+}
+
+// tests that the daemon fails to start if its snapshot directory cannot
+// be created.
+func ringdUnusableDir(t *testing.T) {
+	c := &config{
+		Addr:          ":0",
+		Dir:           filepath.Join(writeConfig(t, "{}"), "snapshots"), // under a file
+		SnapshotEvery: duration(time.Hour),
+		Rings:         []ringConfig{{Name: "ringd-unusable", Cap: 2}},
+	}
+
+	if d, err := newDaemon(c); err == nil {
+		_ = d.close()
+		t.Fatal("unexpected success")
+	}
+}
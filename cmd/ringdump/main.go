@@ -0,0 +1,183 @@
+// Command ringdump prints the contents of persisted ring files and
+// checks their integrity, to inspect the leftovers of a crash without
+// writing Go.
+//
+// Usage:
+//
+//	ringdump [-max bytes] file...
+//
+// It understands the log files of ringfile.Log, printing each insertion
+// and extraction with its offset and sequence number, and the logs
+// written by ring.Ring.ExportLog and the snapshots written by
+// ring.Ring.Checkpoint, printing each element with its position.  None
+// of the formats records when elements were inserted, so only the
+// modification time of the file is printed.
+// The elements of rings created with compression or encryption are
+// printed as stored.
+//
+// Elements of exported logs and snapshots are decoded with
+// encoding/gob, so elements of types other than the basic ones cannot
+// be decoded, and are reported as corrupt.  Snapshots are checked
+// whole before printing their elements, so nothing is printed for a
+// damaged one.
+//
+// The exit status is 1 if any of the files is corrupt or cannot be
+// read.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringfile"
+)
+
+func main() {
+	max := flag.Int("max", 64, "maximum bytes of each element to print, 0 for all")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: ringdump [-max bytes] file...")
+		os.Exit(2)
+	}
+
+	status := 0
+
+	for _, path := range flag.Args() {
+		if err := dumpFile(os.Stdout, path, *max); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+
+			status = 1
+		}
+	}
+
+	os.Exit(status)
+}
+
+// prints the contents of the file at path to w, returning an error if
+// it cannot be read, or it is corrupt.
+func dumpFile(w io.Writer, path string, max int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	br := bufio.NewReader(f)
+
+	magic, err := br.Peek(8)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	var (
+		kind string
+		dump func(w io.Writer, r io.Reader, max int) (string, error)
+	)
+
+	switch {
+	case bytes.HasPrefix(magic, []byte("RINGLOG")):
+		kind, dump = "ringfile log", dumpRingfileLog
+	case bytes.HasPrefix(magic, []byte("RINGEXP")):
+		kind, dump = "exported ring log", dumpExportedLog
+	case bytes.HasPrefix(magic, []byte("RINGSNAP")):
+		kind, dump = "ring snapshot", dumpSnapshot
+	default:
+		return errors.New("unknown file format")
+	}
+
+	fmt.Fprintf(w, "%s: %s, %d bytes, modified %s\n",
+		path, kind, info.Size(), info.ModTime().Format(time.RFC3339))
+
+	summary, err := dump(w, br, max)
+	if err != nil {
+		return fmt.Errorf("CORRUPT: %w", err)
+	}
+
+	fmt.Fprintf(w, "%s: ok, %s\n", path, summary)
+
+	return nil
+}
+
+// prints the records of a ringfile log, returning a summary of its
+// contents.
+func dumpRingfileLog(w io.Writer, r io.Reader, max int) (string, error) {
+	var records, inserted, extracted int
+
+	err := ringfile.Inspect(r, func(rec ringfile.Record) error {
+		records++
+
+		if !rec.Insert {
+			fmt.Fprintf(w, "  offset %d: extract #%d\n", rec.Offset, extracted)
+			extracted++
+
+			return nil
+		}
+
+		fmt.Fprintf(w, "  offset %d: insert #%d, %d bytes: %s\n",
+			rec.Offset, inserted, len(rec.Payload), preview(rec.Payload, max))
+		inserted++
+
+		return nil
+	})
+
+	stored := inserted - extracted
+	if stored < 0 {
+		stored = 0
+	}
+
+	return fmt.Sprintf("%d records, %d elements stored", records, stored), err
+}
+
+// prints the elements of an exported log, returning a summary of its
+// contents.
+func dumpExportedLog(w io.Writer, r io.Reader, max int) (string, error) {
+	var n int
+
+	err := ring.ScanLog(r, func(i uint64, v interface{}) error {
+		n++
+
+		fmt.Fprintf(w, "  #%d: %s\n", i, preview([]byte(fmt.Sprintf("%v", v)), max))
+
+		return nil
+	})
+
+	return fmt.Sprintf("%d elements", n), err
+}
+
+// prints the elements of a snapshot, returning a summary of its
+// contents.
+func dumpSnapshot(w io.Writer, r io.Reader, max int) (string, error) {
+	var n int
+
+	err := ring.ScanSnapshot(r, func(i uint64, v interface{}) error {
+		n++
+
+		fmt.Fprintf(w, "  #%d: %s\n", i, preview([]byte(fmt.Sprintf("%v", v)), max))
+
+		return nil
+	})
+
+	return fmt.Sprintf("%d elements", n), err
+}
+
+// returns p quoted, and shortened to max bytes if max > 0.
+func preview(p []byte, max int) string {
+	if max > 0 && len(p) > max {
+		return fmt.Sprintf("%q...", p[:max])
+	}
+
+	return fmt.Sprintf("%q", p)
+}
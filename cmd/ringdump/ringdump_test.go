@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringfile"
+)
+
+func TestDump(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"ringfile log":      dumpRingfile,
+		"exported log":      dumpExported,
+		"snapshot":          dumpSnapshotFile,
+		"damaged snapshot":  dumpDamagedSnapshot,
+		"truncated log":     dumpTruncated,
+		"unknown format":    dumpUnknownFormat,
+		"long elements cut": dumpLongElementsCut,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns the path to a file in a temporary directory removed at the
+// end of the test.
+func tempPath(t *testing.T, name string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "ringdump")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %v", err)
+	}
+
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	return filepath.Join(dir, name)
+}
+
+// writes a ringfile log with the given elements, extracting the given
+// number of them, and returns its path, or fails the test.
+func writeRingfileLog(t *testing.T, extract int, elements ...string) string {
+	t.Helper()
+
+	path := tempPath(t, "ring.log")
+
+	r, err := ringfile.Open(path, 10)
+	if err != nil {
+		t.Fatalf("opening ring: %v", err)
+	}
+
+	for _, e := range elements {
+		if err := r.Insert([]byte(e)); err != nil {
+			t.Fatalf("inserting: %v", err)
+		}
+	}
+
+	for i := 0; i < extract; i++ {
+		if _, _, err := r.Extract(); err != nil {
+			t.Fatalf("extracting: %v", err)
+		}
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("closing ring: %v", err)
+	}
+
+	return path
+}
+
+// dumps the file at path, returning the output and the error.
+func dump(path string, max int) (string, error) {
+	var b bytes.Buffer
+	err := dumpFile(&b, path, max)
+
+	return b.String(), err
+}
+
+// asserts that the output contains the given lines, in order.
+func assertLines(t *testing.T, out string, lines ...string) {
+	t.Helper()
+
+	rest := out
+
+	for _, line := range lines {
+		i := strings.Index(rest, line+"\n")
+		if i < 0 {
+			t.Fatalf("want line %q in output:\n%s", line, out)
+		}
+
+		rest = rest[i+len(line):]
+	}
+}
+
+// tests that the records of a ringfile log are printed.
+func dumpRingfile(t *testing.T) {
+	path := writeRingfileLog(t, 1, "a", "bb")
+
+	out, err := dump(path, 0)
+	if err != nil {
+		t.Fatalf("dumping: %v\n%s", err, out)
+	}
+
+	assertLines(t, out,
+		"  offset 8: insert #0, 1 bytes: \"a\"",
+		"  offset 18: insert #1, 2 bytes: \"bb\"",
+		"  offset 29: extract #0",
+		path+": ok, 3 records, 1 elements stored",
+	)
+
+	if !strings.HasPrefix(out, path+": ringfile log, ") {
+		t.Errorf("unexpected header in output:\n%s", out)
+	}
+}
+
+// tests that the elements of an exported log are printed.
+func dumpExported(t *testing.T) {
+	r, err := ring.New(3)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	r.Insert("x")
+	r.Insert(42)
+
+	var b bytes.Buffer
+	if err := r.ExportLog(&b); err != nil {
+		t.Fatalf("exporting: %v", err)
+	}
+
+	path := tempPath(t, "ring.exp")
+	if err := ioutil.WriteFile(path, b.Bytes(), 0o600); err != nil {
+		t.Fatalf("writing log: %v", err)
+	}
+
+	out, err := dump(path, 0)
+	if err != nil {
+		t.Fatalf("dumping: %v\n%s", err, out)
+	}
+
+	assertLines(t, out,
+		`  #0: "x"`,
+		`  #1: "42"`,
+		path+": ok, 2 elements",
+	)
+}
+
+// writes a snapshot of a ring with the given elements and returns its
+// path, or fails the test.
+func writeSnapshot(t *testing.T, elements ...interface{}) string {
+	t.Helper()
+
+	r, err := ring.New(3)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	for _, e := range elements {
+		r.Insert(e)
+	}
+
+	path := tempPath(t, "ring.snap")
+	if err := r.Checkpoint(path); err != nil {
+		t.Fatalf("checkpointing: %v", err)
+	}
+
+	return path
+}
+
+// tests that the elements of a snapshot are printed.
+func dumpSnapshotFile(t *testing.T) {
+	path := writeSnapshot(t, "x", 42)
+
+	out, err := dump(path, 0)
+	if err != nil {
+		t.Fatalf("dumping: %v\n%s", err, out)
+	}
+
+	assertLines(t, out,
+		`  #0: "x"`,
+		`  #1: "42"`,
+		path+": ok, 2 elements",
+	)
+
+	if !strings.HasPrefix(out, path+": ring snapshot, ") {
+		t.Errorf("unexpected header in output:\n%s", out)
+	}
+}
+
+// tests that snapshots with a wrong checksum are reported as corrupt.
+func dumpDamagedSnapshot(t *testing.T) {
+	path := writeSnapshot(t, "x", 42)
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading snapshot: %v", err)
+	}
+
+	b[len(b)-1] ^= 0xff
+
+	if err := ioutil.WriteFile(path, b, 0o600); err != nil {
+		t.Fatalf("writing snapshot: %v", err)
+	}
+
+	out, err := dump(path, 0)
+	if err == nil || !strings.Contains(err.Error(), "CORRUPT") {
+		t.Errorf("want corrupt error, got %v", err)
+	}
+
+	if strings.Contains(out, "#0") {
+		t.Errorf("unexpected elements in output:\n%s", out)
+	}
+}
+
+// tests that truncated logs are reported as corrupt, after printing the
+// records before the damage.
+func dumpTruncated(t *testing.T) {
+	path := writeRingfileLog(t, 0, "a", "bbbb")
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("checking log: %v", err)
+	}
+
+	if err := os.Truncate(path, info.Size()-3); err != nil {
+		t.Fatalf("truncating log: %v", err)
+	}
+
+	out, err := dump(path, 0)
+	if err == nil || !strings.Contains(err.Error(), "CORRUPT") {
+		t.Errorf("want corrupt error, got %v", err)
+	}
+
+	assertLines(t, out, `  offset 8: insert #0, 1 bytes: "a"`)
+}
+
+// tests that other files are rejected.
+func dumpUnknownFormat(t *testing.T) {
+	path := tempPath(t, "other")
+	if err := ioutil.WriteFile(path, []byte("hello"), 0o600); err != nil {
+		t.Fatalf("writing file: %v", err)
+	}
+
+	if _, err := dump(path, 0); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that elements longer than the maximum are shortened.
+func dumpLongElementsCut(t *testing.T) {
+	path := writeRingfileLog(t, 0, "abcdef")
+
+	out, err := dump(path, 3)
+	if err != nil {
+		t.Fatalf("dumping: %v", err)
+	}
+
+	assertLines(t, out, `  offset 8: insert #0, 6 bytes: "abc"...`)
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// params are the parameters of the generated code.
+type params struct {
+	Package string
+	Imports []string
+	Type    string  // element type
+	Name    string  // generated type, see name
+	Fields  []field // fields of the element type, for the struct of arrays layout
+}
+
+// a field of a struct element type, stored in its own array.
+type field struct {
+	Name string
+	Type string
+}
+
+// the methods of the generated types, which the per field methods must
+// not collide with.
+var methods = map[string]bool{
+	"Insert": true, "Extract": true, "Peek": true,
+	"Len": true, "Cap": true, "ToSlice": true,
+}
+
+// parses a comma-separated list of name:type fields.
+func parseFields(s string) ([]field, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var result []field
+
+	for _, f := range strings.Split(s, ",") {
+		i := strings.Index(f, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid field %q, want name:type", f)
+		}
+
+		result = append(result, field{
+			Name: strings.TrimSpace(f[:i]),
+			Type: strings.TrimSpace(f[i+1:]),
+		})
+	}
+
+	return result, nil
+}
+
+// returns the name of the generated type: the given one or the element
+// type, without its package and capitalized, followed by Ring.
+func (p params) name() string {
+	if p.Name != "" {
+		return p.Name
+	}
+
+	t := p.Type[strings.LastIndex(p.Type, ".")+1:]
+	r, size := utf8.DecodeRuneInString(t)
+
+	return string(unicode.ToUpper(r)) + t[size:] + "Ring"
+}
+
+// checks the parameters.
+func (p params) validate() error {
+	if !token.IsIdentifier(p.Package) {
+		return fmt.Errorf("invalid package name %q", p.Package)
+	}
+
+	if p.Type == "" {
+		return errors.New("missing element type")
+	}
+
+	if !token.IsIdentifier(p.name()) {
+		return fmt.Errorf("invalid type name %q", p.name())
+	}
+
+	for _, path := range p.Imports {
+		if path == "" {
+			return errors.New("empty import path")
+		}
+	}
+
+	seen := make(map[string]bool, len(p.Fields))
+
+	for _, f := range p.Fields {
+		if !token.IsIdentifier(f.Name) || f.Type == "" {
+			return fmt.Errorf("invalid field %q of type %q", f.Name, f.Type)
+		}
+
+		if seen[f.Name] || methods[f.Name+"Slice"] {
+			return fmt.Errorf("field name %q collides with another field or method", f.Name)
+		}
+
+		seen[f.Name] = true
+	}
+
+	return nil
+}
+
+// returns the formatted source code of the ring.
+func generate(p params) ([]byte, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	p.Name = p.name()
+
+	tmpl := ringTemplate
+	if len(p.Fields) > 0 {
+		tmpl = fieldsTemplate
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, p); err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("invalid generated code, check the element type: %w", err)
+	}
+
+	return src, nil
+}
+
+var ringTemplate = template.Must(template.New("ring").Parse(`// Code generated by ringgen -type {{.Type}} -name {{.Name}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+	"sync"
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+
+// {{.Name}} is a concurrent bounded circular buffer of elements of type
+// {{.Type}}.  When at maximum capacity, it drops the oldest elements to
+// make room for the new ones.
+type {{.Name}} struct {
+	mu   sync.Mutex
+	buf  []{{.Type}} // elements storage
+	len  int // how many elements are stored in the ring
+	head int // index of the next element to be extracted
+}
+
+// New{{.Name}} returns a new ring with the given capacity.
+func New{{.Name}}(cap int) (*{{.Name}}, error) {
+	if cap < 1 {
+		return nil, fmt.Errorf("ring capacity must be > 0, got %d", cap)
+	}
+
+	return &{{.Name}}{
+		buf: make([]{{.Type}}, cap),
+	}, nil
+}
+
+// Insert adds a new element to the ring. If the ring is already at
+// maximum capacity, the oldest element is dropped to make room for the
+// new one.
+func (r *{{.Name}}) Insert(v {{.Type}}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == len(r.buf) {
+		r.head = (r.head + 1) % len(r.buf)
+		r.len--
+	}
+
+	r.buf[(r.head+r.len)%len(r.buf)] = v
+	r.len++
+}
+
+// Extract extracts and returns the oldest element in the ring.
+func (r *{{.Name}}) Extract() ({{.Type}}, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var zero {{.Type}}
+
+	if r.len == 0 {
+		return zero, false
+	}
+
+	v := r.buf[r.head]
+	r.buf[r.head] = zero
+	r.head = (r.head + 1) % len(r.buf)
+	r.len--
+
+	return v, true
+}
+
+// Peek returns the oldest element in the ring.
+func (r *{{.Name}}) Peek() ({{.Type}}, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		var zero {{.Type}}
+		return zero, false
+	}
+
+	return r.buf[r.head], true
+}
+
+// Len returns the amount of elements in the ring.
+func (r *{{.Name}}) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.len
+}
+
+// Cap returns the capacity of the ring.
+func (r *{{.Name}}) Cap() int {
+	return len(r.buf)
+}
+
+// ToSlice returns the elements in the ring, from oldest to newest,
+// without extracting them.
+func (r *{{.Name}}) ToSlice() []{{.Type}} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]{{.Type}}, r.len)
+	for i := range result {
+		result[i] = r.buf[(r.head+i)%len(r.buf)]
+	}
+
+	return result
+}
+`))
+
+var fieldsTemplate = template.Must(template.New("fields").Parse(`// Code generated by ringgen -type {{.Type}} -name {{.Name}} -fields{{range $i, $f := .Fields}}{{if $i}},{{else}} {{end}}{{$f.Name}}:{{$f.Type}}{{end}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+	"sync"
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+
+// {{.Name}} is a concurrent bounded circular buffer of elements of type
+// {{.Type}}.  When at maximum capacity, it drops the oldest elements to
+// make room for the new ones.
+//
+// Each field of the elements is stored in its own array, so scanning a
+// single field, with the methods named after it, does not read the
+// others.
+type {{.Name}} struct {
+	mu   sync.Mutex
+	size int // capacity of the ring
+	len  int // how many elements are stored in the ring
+	head int // index of the next element to be extracted
+{{range .Fields}}
+	field{{.Name}} []{{.Type}}
+{{- end}}
+}
+
+// New{{.Name}} returns a new ring with the given capacity.
+func New{{.Name}}(cap int) (*{{.Name}}, error) {
+	if cap < 1 {
+		return nil, fmt.Errorf("ring capacity must be > 0, got %d", cap)
+	}
+
+	return &{{.Name}}{
+		size: cap,
+{{- range .Fields}}
+		field{{.Name}}: make([]{{.Type}}, cap),
+{{- end}}
+	}, nil
+}
+
+// returns the element at index i.
+func (r *{{.Name}}) at(i int) {{.Type}} {
+	return {{.Type}}{
+{{- range .Fields}}
+		{{.Name}}: r.field{{.Name}}[i],
+{{- end}}
+	}
+}
+
+// Insert adds a new element to the ring. If the ring is already at
+// maximum capacity, the oldest element is dropped to make room for the
+// new one.
+func (r *{{.Name}}) Insert(v {{.Type}}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == r.size {
+		r.head = (r.head + 1) % r.size
+		r.len--
+	}
+
+	i := (r.head + r.len) % r.size
+{{- range .Fields}}
+	r.field{{.Name}}[i] = v.{{.Name}}
+{{- end}}
+	r.len++
+}
+
+// Extract extracts and returns the oldest element in the ring.
+func (r *{{.Name}}) Extract() ({{.Type}}, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		var zero {{.Type}}
+		return zero, false
+	}
+
+	v := r.at(r.head)
+{{range .Fields}}
+	var zero{{.Name}} {{.Type}}
+	r.field{{.Name}}[r.head] = zero{{.Name}}
+{{end}}
+	r.head = (r.head + 1) % r.size
+	r.len--
+
+	return v, true
+}
+
+// Peek returns the oldest element in the ring.
+func (r *{{.Name}}) Peek() ({{.Type}}, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		var zero {{.Type}}
+		return zero, false
+	}
+
+	return r.at(r.head), true
+}
+
+// Len returns the amount of elements in the ring.
+func (r *{{.Name}}) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.len
+}
+
+// Cap returns the capacity of the ring.
+func (r *{{.Name}}) Cap() int {
+	return r.size
+}
+
+// ToSlice returns the elements in the ring, from oldest to newest,
+// without extracting them.
+func (r *{{.Name}}) ToSlice() []{{.Type}} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]{{.Type}}, r.len)
+	for i := range result {
+		result[i] = r.at((r.head + i) % r.size)
+	}
+
+	return result
+}
+{{range .Fields}}
+// {{.Name}}Slice returns the {{.Name}} field of the elements in the ring,
+// from oldest to newest, without extracting them.  It only reads the
+// array of that field.
+func (r *{{$.Name}}) {{.Name}}Slice() []{{.Type}} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]{{.Type}}, r.len)
+	n := copy(result, r.field{{.Name}}[r.head:])
+	copy(result[n:], r.field{{.Name}})
+
+	return result
+}
+{{end}}`))
@@ -0,0 +1,85 @@
+// Command ringgen generates a concurrent bounded circular buffer for a
+// given element type, storing the elements unboxed, instead of as
+// interface{} values like the Ring of package ring does.  It is meant
+// for hot paths where the allocations and type assertions of boxing
+// matter.
+//
+// Usage:
+//
+//	ringgen -type T [-name Name] [-fields name:type,...] [-import path,...] [-package pkg] [-o file]
+//
+// For example, in a file of package metrics:
+//
+//	//go:generate ringgen -type float64 -name Samples
+//
+// generates samples_ring.go in package metrics, with the type Samples,
+// its constructor NewSamples, and the methods Insert, Extract, Peek,
+// Len, Cap and ToSlice, which work like the ones of ring.Ring.
+//
+// The type can be qualified, like time.Duration, if its package is
+// given with -import.  By default, the package is the one running
+// go:generate, the name is the type name followed by "Ring", and the
+// file is the lowercase name followed by "_ring.go".
+//
+// If the element type is a struct, -fields can list its fields and
+// their types, to store each field in its own array instead of storing
+// whole elements, so scans over a single field only read the memory of
+// that field.  For example:
+//
+//	//go:generate ringgen -type Event -fields Time:time.Time,Value:float64 -import time
+//
+// generates, besides the usual methods, TimeSlice and ValueSlice,
+// returning the values of a single field from oldest to newest.  Field
+// types cannot contain commas.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+func main() {
+	typ := flag.String("type", "", "element type, required")
+	name := flag.String("name", "", "name of the generated type, the element type followed by Ring by default")
+	imports := flag.String("import", "", "comma-separated import paths needed by the element type")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "package of the generated file, the one running go:generate by default")
+	fields := flag.String("fields", "", "comma-separated name:type fields of a struct element type, stored in parallel arrays")
+	out := flag.String("o", "", "output file, the lowercase name followed by _ring.go by default")
+	flag.Parse()
+
+	fieldList, err := parseFields(*fields)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ringgen:", err)
+		os.Exit(2)
+	}
+
+	p := params{
+		Package: *pkg,
+		Type:    *typ,
+		Name:    *name,
+		Fields:  fieldList,
+	}
+
+	if *imports != "" {
+		p.Imports = strings.Split(*imports, ",")
+	}
+
+	src, err := generate(p)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ringgen:", err)
+		os.Exit(2)
+	}
+
+	path := *out
+	if path == "" {
+		path = strings.ToLower(p.name()) + "_ring.go"
+	}
+
+	if err := ioutil.WriteFile(path, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "ringgen:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"default name":       generateDefaultName,
+		"compiles":           generateCompiles,
+		"qualified type":     generateQualifiedType,
+		"invalid parameters": generateInvalidParameters,
+		"fields":             generateFields,
+		"parse fields":       generateParseFields,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// generates the code for p and type-checks it along with the extra
+// source files, or fails the test.  It returns the package.
+func typeCheck(t *testing.T, p params, extra ...string) *types.Package {
+	t.Helper()
+
+	src, err := generate(p)
+	if err != nil {
+		t.Fatalf("generating: %v", err)
+	}
+
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, "ring.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parsing generated code: %v\n%s", err, src)
+	}
+
+	if !ast.IsGenerated(f) {
+		t.Error("the generated code is not marked as generated")
+	}
+
+	files := []*ast.File{f}
+
+	for i, src := range extra {
+		f, err := parser.ParseFile(fset, fmt.Sprintf("extra%d.go", i), src, 0)
+		if err != nil {
+			t.Fatalf("parsing extra source: %v", err)
+		}
+
+		files = append(files, f)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+
+	pkg, err := conf.Check(p.Package, fset, files, nil)
+	if err != nil {
+		t.Fatalf("type-checking generated code: %v\n%s", err, src)
+	}
+
+	return pkg
+}
+
+// asserts that the method of the named type has the given signature.
+func assertMethod(t *testing.T, pkg *types.Package, typ, method, want string) {
+	t.Helper()
+
+	obj := pkg.Scope().Lookup(typ)
+	if obj == nil {
+		t.Fatalf("missing type %s", typ)
+	}
+
+	m, _, _ := types.LookupFieldOrMethod(obj.Type(), true, pkg, method)
+	if m == nil {
+		t.Fatalf("missing method %s.%s", typ, method)
+	}
+
+	got := types.TypeString(m.Type(), types.RelativeTo(pkg))
+	if got != want {
+		t.Errorf("%s.%s: want %s, got %s", typ, method, want, got)
+	}
+}
+
+// tests the default name of the generated type.
+func generateDefaultName(t *testing.T) {
+	tests := map[string]string{
+		"int":           "IntRing",
+		"time.Duration": "DurationRing",
+		"event":         "EventRing",
+	}
+
+	for typ, want := range tests {
+		if got := (params{Type: typ}).name(); got != want {
+			t.Errorf("%s: want %s, got %s", typ, want, got)
+		}
+	}
+}
+
+// tests that the generated code compiles and stores the elements
+// unboxed.
+func generateCompiles(t *testing.T) {
+	pkg := typeCheck(t, params{Package: "metrics", Type: "float64", Name: "Samples"})
+
+	if pkg.Scope().Lookup("NewSamples") == nil {
+		t.Error("missing constructor NewSamples")
+	}
+
+	assertMethod(t, pkg, "Samples", "Insert", "func(v float64)")
+	assertMethod(t, pkg, "Samples", "Extract", "func() (float64, bool)")
+	assertMethod(t, pkg, "Samples", "Peek", "func() (float64, bool)")
+	assertMethod(t, pkg, "Samples", "Len", "func() int")
+	assertMethod(t, pkg, "Samples", "Cap", "func() int")
+	assertMethod(t, pkg, "Samples", "ToSlice", "func() []float64")
+}
+
+// tests that element types from other packages are imported.
+func generateQualifiedType(t *testing.T) {
+	pkg := typeCheck(t, params{Package: "x", Type: "time.Duration", Imports: []string{"time"}})
+
+	assertMethod(t, pkg, "DurationRing", "Extract", "func() (time.Duration, bool)")
+}
+
+// tests that invalid parameters are rejected.
+func generateInvalidParameters(t *testing.T) {
+	for _, p := range []params{
+		{Package: "", Type: "int"},
+		{Package: "x", Type: ""},
+		{Package: "x", Type: "int", Name: "not valid"},
+		{Package: "x", Type: "int", Imports: []string{""}},
+		{Package: "x", Type: "[[int"},
+		{Package: "x", Type: "T", Fields: []field{{Name: "not valid", Type: "int"}}},
+		{Package: "x", Type: "T", Fields: []field{{Name: "A", Type: ""}}},
+		{Package: "x", Type: "T", Fields: []field{{Name: "A", Type: "int"}, {Name: "A", Type: "int"}}},
+		{Package: "x", Type: "T", Fields: []field{{Name: "To", Type: "int"}}},
+	} {
+		if src, err := generate(p); err == nil {
+			t.Errorf("%+v: unexpected success:\n%s", p, src)
+		}
+	}
+}
+
+// tests that the fields of struct elements are stored and returned in
+// their own arrays.
+func generateFields(t *testing.T) {
+	p := params{
+		Package: "metrics",
+		Type:    "Event",
+		Imports: []string{"time"},
+		Fields:  []field{{Name: "Time", Type: "time.Time"}, {Name: "Value", Type: "float64"}},
+	}
+
+	pkg := typeCheck(t, p, `package metrics
+
+import "time"
+
+type Event struct {
+	Time  time.Time
+	Value float64
+}
+`)
+
+	assertMethod(t, pkg, "EventRing", "Insert", "func(v Event)")
+	assertMethod(t, pkg, "EventRing", "Extract", "func() (Event, bool)")
+	assertMethod(t, pkg, "EventRing", "ToSlice", "func() []Event")
+	assertMethod(t, pkg, "EventRing", "TimeSlice", "func() []time.Time")
+	assertMethod(t, pkg, "EventRing", "ValueSlice", "func() []float64")
+
+	obj := pkg.Scope().Lookup("EventRing")
+	st := obj.Type().Underlying().(*types.Struct)
+
+	for i := 0; i < st.NumFields(); i++ {
+		if types.Identical(st.Field(i).Type(), types.NewSlice(pkg.Scope().Lookup("Event").Type())) {
+			t.Errorf("whole elements stored in field %s", st.Field(i).Name())
+		}
+	}
+}
+
+// tests the parsing of the -fields flag.
+func generateParseFields(t *testing.T) {
+	got, err := parseFields("Time:time.Time, Value:float64")
+	if err != nil {
+		t.Fatalf("parsing: %v", err)
+	}
+
+	want := []field{{Name: "Time", Type: "time.Time"}, {Name: "Value", Type: "float64"}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	if got, err := parseFields(""); got != nil || err != nil {
+		t.Errorf("want no fields, got %v, %v", got, err)
+	}
+
+	if _, err := parseFields("Time"); err == nil {
+		t.Error("unexpected success without a type")
+	}
+}
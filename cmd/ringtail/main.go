@@ -0,0 +1,145 @@
+// Command ringtail copies its standard input to its output keeping only
+// the last lines, like tail, but using memory bounded by the number of
+// lines kept.  It is meant for the output of long-running jobs, where
+// only the end is interesting, as an alternative to tee.
+//
+// Usage:
+//
+//	job | ringtail [-n lines] [-o file]
+//
+// The kept lines are written when the input ends.  They are also
+// written, and forgotten, when ringtail receives SIGUSR1, except on
+// windows, and before exiting on SIGINT or SIGTERM.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringio"
+)
+
+func main() {
+	n := flag.Int("n", 10, "number of lines to keep")
+	path := flag.String("o", "", "file to write the lines to, instead of the standard output")
+	flag.Parse()
+
+	out := io.Writer(os.Stdout)
+
+	if *path != "" {
+		f, err := os.Create(*path)
+		if err != nil {
+			fail(err)
+		}
+
+		defer f.Close()
+
+		out = f
+	}
+
+	t, err := newTail(*n, out)
+	if err != nil {
+		fail(err)
+	}
+
+	go t.onSignals()
+
+	if err := t.copy(os.Stdin); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "ringtail:", err)
+	os.Exit(1)
+}
+
+// tail keeps the last lines written to it, until flushed to its output.
+type tail struct {
+	r *ring.Ring
+	w *ringio.Writer
+
+	mu  sync.Mutex // serializes the flushes
+	out io.Writer
+}
+
+// returns a tail keeping the last n lines and flushing them to out.
+func newTail(n int, out io.Writer) (*tail, error) {
+	r, err := ring.New(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tail{
+		r:   r,
+		w:   ringio.NewWriter(r),
+		out: out,
+	}, nil
+}
+
+// copies in to the tail until it ends, then flushes the kept lines,
+// including the last one, even if it has no newline.
+func (t *tail) copy(in io.Reader) error {
+	if _, err := io.Copy(t.w, in); err != nil {
+		return err
+	}
+
+	t.w.Flush()
+
+	return t.flush()
+}
+
+// writes the kept lines to the output, forgetting them.
+func (t *tail) flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for {
+		v, ok := t.r.Extract()
+		if !ok {
+			return nil
+		}
+
+		if _, err := fmt.Fprintln(t.out, v); err != nil {
+			return err
+		}
+	}
+}
+
+// flushes the kept lines on the flush signals, and exits after flushing
+// them on SIGINT and SIGTERM.
+func (t *tail) onSignals() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, append(flushSignals, os.Interrupt, syscall.SIGTERM)...)
+
+	for sig := range signals {
+		exit := !isFlushSignal(sig)
+		if exit {
+			t.w.Flush()
+		}
+
+		if err := t.flush(); err != nil {
+			fail(err)
+		}
+
+		if exit {
+			os.Exit(0)
+		}
+	}
+}
+
+func isFlushSignal(sig os.Signal) bool {
+	for _, s := range flushSignals {
+		if s == sig {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTail(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid lines": tailInvalidLines,
+		"keeps last":    tailKeepsLast,
+		"last line":     tailLastLine,
+		"flush forgets": tailFlushForgets,
+		"short input":   tailShortInput,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a tail of n lines writing to the returned builder, or fails
+// the test.
+func newTestTail(t *testing.T, n int) (*tail, *strings.Builder) {
+	t.Helper()
+
+	var out strings.Builder
+
+	tl, err := newTail(n, &out)
+	if err != nil {
+		t.Fatalf("creating tail: %v", err)
+	}
+
+	return tl, &out
+}
+
+// copies in to a tail of n lines and returns its output, or fails the
+// test.
+func run(t *testing.T, n int, in string) string {
+	t.Helper()
+
+	tl, out := newTestTail(t, n)
+
+	if err := tl.copy(strings.NewReader(in)); err != nil {
+		t.Fatalf("copying: %v", err)
+	}
+
+	return out.String()
+}
+
+// tests that keeping less than one line is invalid.
+func tailInvalidLines(t *testing.T) {
+	if _, err := newTail(0, &strings.Builder{}); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that only the last lines are written.
+func tailKeepsLast(t *testing.T) {
+	if got := run(t, 2, "1\n2\n3\n4\n"); got != "3\n4\n" {
+		t.Errorf("want 3 and 4, got %q", got)
+	}
+}
+
+// tests that the last line is written even without a newline.
+func tailLastLine(t *testing.T) {
+	if got := run(t, 2, "1\n2\n3"); got != "2\n3\n" {
+		t.Errorf("want 2 and 3, got %q", got)
+	}
+}
+
+// tests that flushed lines are not written again.
+func tailFlushForgets(t *testing.T) {
+	tl, out := newTestTail(t, 3)
+
+	_, _ = tl.w.Write([]byte("a\nb\n"))
+
+	if err := tl.flush(); err != nil {
+		t.Fatalf("flushing: %v", err)
+	}
+
+	if err := tl.copy(strings.NewReader("c\n")); err != nil {
+		t.Fatalf("copying: %v", err)
+	}
+
+	if got := out.String(); got != "a\nb\nc\n" {
+		t.Errorf("want a, b and c, got %q", got)
+	}
+}
+
+// tests that inputs with less lines than kept are written whole.
+func tailShortInput(t *testing.T) {
+	if got := run(t, 10, "x\ny\n"); got != "x\ny\n" {
+		t.Errorf("want x and y, got %q", got)
+	}
+
+	if got := run(t, 10, ""); got != "" {
+		t.Errorf("want nothing, got %q", got)
+	}
+}
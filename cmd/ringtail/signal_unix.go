@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// the signals making ringtail flush the kept lines without exiting.
+var flushSignals = []os.Signal{syscall.SIGUSR1}
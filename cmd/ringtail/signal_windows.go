@@ -0,0 +1,7 @@
+package main
+
+import "os"
+
+// the signals making ringtail flush the kept lines without exiting,
+// there is no SIGUSR1 on windows.
+var flushSignals []os.Signal
@@ -0,0 +1,59 @@
+// Command ringtop shows the rings of a process, like top does for
+// processes: their length, capacity, insertion and drop rates, and the
+// age of their oldest element, refreshed periodically.
+//
+// Usage:
+//
+//	ringtop [-interval 1s] url
+//
+// The url is where the process serves ringhttp.RegistryHandler, like
+// http://localhost:7070/rings for ringd.  Rings are sorted by drop rate,
+// so the ones losing elements come first.  The age of the oldest
+// element is only shown for rings created with ring.WithTimestamps.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// clears the terminal and moves the cursor home.
+const clearScreen = "\x1b[H\x1b[2J"
+
+func main() {
+	interval := flag.Duration("interval", time.Second, "time between refreshes")
+	flag.Parse()
+
+	if flag.NArg() != 1 || *interval <= 0 {
+		fmt.Fprintln(os.Stderr, "usage: ringtop [-interval 1s] url")
+		os.Exit(2)
+	}
+
+	url := flag.Arg(0)
+	client := &http.Client{Timeout: *interval}
+	m := newMonitor()
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	for {
+		entries, err := fetch(context.Background(), client, url)
+		now := time.Now()
+
+		fmt.Print(clearScreen)
+		fmt.Printf("%s  %s\n\n", url, now.Format("15:04:05"))
+
+		if err != nil {
+			fmt.Println("error:", err)
+		} else if err := render(os.Stdout, m.sample(entries, now)); err != nil {
+			fmt.Fprintln(os.Stderr, "ringtop:", err)
+			os.Exit(1)
+		}
+
+		<-ticker.C
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+// entry is a ring in the list served by ringhttp.RegistryHandler.
+type entry struct {
+	Name    string
+	Stats   ring.Stats
+	HeadAge *float64 `json:"head_age"`
+}
+
+// fetches the list of rings served at url.
+func fetch(ctx context.Context, client *http.Client, url string) ([]entry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var entries []entry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("decoding rings: %w", err)
+	}
+
+	return entries, nil
+}
+
+// row is a line of the table shown by ringtop.
+type row struct {
+	name       string
+	len, cap   int
+	insertRate float64 // per second, since the previous sample
+	dropRate   float64 // per second, since the previous sample
+	headAge    *time.Duration
+}
+
+// monitor computes the rates of the rings between samples.
+type monitor struct {
+	prev     map[string]ring.Stats
+	prevTime time.Time
+}
+
+func newMonitor() *monitor {
+	return &monitor{prev: make(map[string]ring.Stats)}
+}
+
+// returns the rows for a sample of the rings taken at the given time,
+// sorted by drop rate and then by name.  Rates are 0 for rings not in
+// the previous sample.
+func (m *monitor) sample(entries []entry, now time.Time) []row {
+	elapsed := now.Sub(m.prevTime).Seconds()
+	current := make(map[string]ring.Stats, len(entries))
+	rows := make([]row, 0, len(entries))
+
+	for _, e := range entries {
+		current[e.Name] = e.Stats
+
+		r := row{
+			name: e.Name,
+			len:  e.Stats.Len,
+			cap:  e.Stats.Cap,
+		}
+
+		if prev, ok := m.prev[e.Name]; ok && elapsed > 0 {
+			r.insertRate = rate(prev.Inserted, e.Stats.Inserted, elapsed)
+			r.dropRate = rate(prev.Dropped, e.Stats.Dropped, elapsed)
+		}
+
+		if e.HeadAge != nil {
+			age := time.Duration(*e.HeadAge * float64(time.Second))
+			r.headAge = &age
+		}
+
+		rows = append(rows, r)
+	}
+
+	m.prev = current
+	m.prevTime = now
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].dropRate != rows[j].dropRate {
+			return rows[i].dropRate > rows[j].dropRate
+		}
+
+		return rows[i].name < rows[j].name
+	})
+
+	return rows
+}
+
+// returns the rate of increase of a counter, which could have been
+// reset in the meantime, like the dropped elements.
+func rate(prev, cur uint64, seconds float64) float64 {
+	if cur < prev {
+		return float64(cur) / seconds
+	}
+
+	return float64(cur-prev) / seconds
+}
+
+// writes the rows as a table.
+func render(w io.Writer, rows []row) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintln(tw, "NAME\tLEN\tCAP\tFILL\tINSERT/s\tDROP/s\tHEAD AGE\t")
+
+	for _, r := range rows {
+		age := "-"
+		if r.headAge != nil {
+			age = r.headAge.Round(time.Millisecond).String()
+		}
+
+		fill := 0.0
+		if r.cap > 0 {
+			fill = 100 * float64(r.len) / float64(r.cap)
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.0f%%\t%.1f\t%.1f\t%s\t\n",
+			strings.ReplaceAll(r.name, "\t", " "), r.len, r.cap, fill, r.insertRate, r.dropRate, age)
+	}
+
+	return tw.Flush()
+}
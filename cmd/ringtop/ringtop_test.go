@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringhttp"
+)
+
+func TestMonitor(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"fetch":         monitorFetch,
+		"fetch error":   monitorFetchError,
+		"rates":         monitorRates,
+		"counter reset": monitorCounterReset,
+		"render":        monitorRender,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// tests that the rings are fetched from the registry handler.
+func monitorFetch(t *testing.T) {
+	r, err := ring.New(2, ring.WithTimestamps())
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	r.Insert(1)
+
+	if err := ring.Register("ringtop fetch", r); err != nil {
+		t.Fatalf("registering: %v", err)
+	}
+
+	defer ring.Unregister("ringtop fetch")
+
+	srv := httptest.NewServer(ringhttp.RegistryHandler(nil))
+	defer srv.Close()
+
+	entries, err := fetch(context.Background(), srv.Client(), srv.URL)
+	if err != nil {
+		t.Fatalf("fetching: %v", err)
+	}
+
+	for _, e := range entries {
+		if e.Name == "ringtop fetch" {
+			if e.Stats.Len != 1 || e.Stats.Cap != 2 || e.HeadAge == nil {
+				t.Errorf("unexpected entry %+v", e)
+			}
+
+			return
+		}
+	}
+
+	t.Errorf("ring not fetched in %+v", entries)
+}
+
+// tests that failed requests are reported.
+func monitorFetchError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	if _, err := fetch(context.Background(), srv.Client(), srv.URL); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that the rates are computed between samples, and rows are
+// sorted by drop rate.
+func monitorRates(t *testing.T) {
+	m := newMonitor()
+	start := time.Now()
+
+	rows := m.sample([]entry{
+		{Name: "a", Stats: ring.Stats{Inserted: 10}},
+		{Name: "b", Stats: ring.Stats{Inserted: 10, Dropped: 5}},
+	}, start)
+
+	if rows[0].insertRate != 0 || rows[0].dropRate != 0 {
+		t.Errorf("want no rates in the first sample, got %+v", rows[0])
+	}
+
+	rows = m.sample([]entry{
+		{Name: "a", Stats: ring.Stats{Inserted: 30}},
+		{Name: "b", Stats: ring.Stats{Inserted: 14, Dropped: 9}},
+		{Name: "c", Stats: ring.Stats{Inserted: 100}},
+	}, start.Add(2*time.Second))
+
+	want := []struct {
+		name       string
+		insertRate float64
+		dropRate   float64
+	}{
+		{"b", 2, 2},
+		{"a", 10, 0},
+		{"c", 0, 0},
+	}
+
+	if len(rows) != len(want) {
+		t.Fatalf("want %d rows, got %d", len(want), len(rows))
+	}
+
+	for i, w := range want {
+		if r := rows[i]; r.name != w.name || r.insertRate != w.insertRate || r.dropRate != w.dropRate {
+			t.Errorf("row %d: want %+v, got %+v", i, w, r)
+		}
+	}
+}
+
+// tests that counters reset between samples do not give negative
+// rates.
+func monitorCounterReset(t *testing.T) {
+	m := newMonitor()
+	start := time.Now()
+
+	m.sample([]entry{{Name: "a", Stats: ring.Stats{Dropped: 100}}}, start)
+	rows := m.sample([]entry{{Name: "a", Stats: ring.Stats{Dropped: 3}}}, start.Add(time.Second))
+
+	if rows[0].dropRate != 3 {
+		t.Errorf("want drop rate 3, got %v", rows[0].dropRate)
+	}
+}
+
+// tests that rows are rendered as a table.
+func monitorRender(t *testing.T) {
+	age := 1500 * time.Millisecond
+
+	var b strings.Builder
+
+	err := render(&b, []row{
+		{name: "events", len: 5, cap: 10, insertRate: 2.5, dropRate: 1, headAge: &age},
+		{name: "errors", len: 0, cap: 4},
+	})
+	if err != nil {
+		t.Fatalf("rendering: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("want 3 lines, got:\n%s", b.String())
+	}
+
+	for i, want := range [][]string{
+		{"NAME", "LEN", "CAP", "FILL", "INSERT/s", "DROP/s", "HEAD", "AGE"},
+		{"events", "5", "10", "50%", "2.5", "1.0", "1.5s"},
+		{"errors", "0", "4", "0%", "0.0", "0.0", "-"},
+	} {
+		if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("line %d: want %q, got %q", i, want, got)
+		}
+	}
+}
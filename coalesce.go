@@ -0,0 +1,57 @@
+package ring
+
+import "sync/atomic"
+
+// ExtractWithCount extracts and returns the oldest element in the
+// ring, along with how many times in a row it was inserted, see
+// WithCoalesce.  The count is always 1 if the ring was not created with
+// the WithCoalesce option.
+func (r *Ring) ExtractWithCount() (interface{}, int, bool) {
+	r.mu.Lock()
+
+	count := r.count(r.head)
+
+	v, ok := r.extract()
+	if !ok {
+		r.unlock()
+		return nil, 0, false
+	}
+
+	r.unlock()
+
+	atomic.AddUint64(&r.extracted, 1)
+
+	if r.observer != nil {
+		r.observer.OnExtract(v)
+	}
+
+	return v, count, true
+}
+
+// ToSliceWithCounts returns the elements in the ring, from oldest to
+// newest, without extracting them, along with how many times in a row
+// each one was inserted, see WithCoalesce.
+func (r *Ring) ToSliceWithCounts() ([]interface{}, []int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elements := make([]interface{}, r.len)
+	counts := make([]int, r.len)
+
+	for i := range elements {
+		j := (r.head + i) % r.buf.size()
+		elements[i] = r.buf.get(j)
+		counts[i] = r.count(j)
+	}
+
+	return elements, counts
+}
+
+// returns how many times in a row the element at index i was inserted.
+func (r *Ring) count(i int) int {
+	if r.counts == nil {
+		return 1
+	}
+
+	return r.counts[i]
+}
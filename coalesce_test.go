@@ -0,0 +1,127 @@
+package ring_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestCoalesce(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"collapses consecutive": coalesceCollapsesConsecutive,
+		"extract with count":    coalesceExtractWithCount,
+		"disabled":              coalesceDisabled,
+		"panicking equal":       coalescePanickingEqual,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+func equal(a, b interface{}) bool {
+	return a == b
+}
+
+// returns a coalescing ring with the given capacity and elements or
+// fails the test.
+func newCoalescing(t *testing.T, cap int, vs ...interface{}) *ring.Ring {
+	t.Helper()
+
+	r, err := ring.New(cap, ring.WithCoalesce(equal))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	for _, v := range vs {
+		r.Insert(v)
+	}
+
+	return r
+}
+
+// asserts the elements in the ring and their counts.
+func assertCounts(t *testing.T, r *ring.Ring, want string) {
+	t.Helper()
+
+	elements, counts := r.ToSliceWithCounts()
+	if got := fmt.Sprint(elements, counts); got != want {
+		t.Errorf("want elements and counts %s, got %s", want, got)
+	}
+}
+
+// tests that consecutive equal elements take a single place in the
+// ring.
+func coalesceCollapsesConsecutive(t *testing.T) {
+	r := newCoalescing(t, 3, "a", "a", "b", "a", "a", "a", "c", "c")
+
+	assertCounts(t, r, "[b a c] [1 3 2]")
+
+	r.Insert("d")
+	assertCounts(t, r, "[a c d] [3 2 1]")
+}
+
+// tests extracting elements with their counts.
+func coalesceExtractWithCount(t *testing.T) {
+	r := newCoalescing(t, 3, 1, 1, 2)
+
+	for _, want := range []struct{ v, count int }{{1, 2}, {2, 1}} {
+		v, count, ok := r.ExtractWithCount()
+		if !ok || v != want.v || count != want.count {
+			t.Errorf("want to extract %d with count %d, got %v with count %d, %t",
+				want.v, want.count, v, count, ok)
+		}
+	}
+
+	if _, _, ok := r.ExtractWithCount(); ok {
+		t.Error("want empty ring after draining")
+	}
+
+	// extracted elements do not coalesce with new ones
+	r.Insert(2)
+	assertCounts(t, r, "[2] [1]")
+}
+
+// tests that rings without the WithCoalesce option keep duplicates.
+func coalesceDisabled(t *testing.T) {
+	r := newRing(t, 3, "a", "a")
+
+	assertCounts(t, r, "[a a] [1 1]")
+}
+
+// tests that a panic in the comparison reaches the caller and does not
+// leave the ring locked.
+func coalescePanickingEqual(t *testing.T) {
+	r, err := ring.New(3, ring.WithCoalesce(func(a, b interface{}) bool {
+		if b == "boom" {
+			panic("boom")
+		}
+
+		return a == b
+	}))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	r.Insert(1)
+
+	func() {
+		defer func() {
+			if p := recover(); p != "boom" {
+				t.Errorf("want panic boom, got %v", p)
+			}
+		}()
+
+		r.Insert("boom")
+	}()
+
+	r.Insert(2)
+	assertCounts(t, r, "[1 2] [1 1]")
+}
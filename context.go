@@ -0,0 +1,122 @@
+package ring
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Tracer records events in the trace span of a context, for example by
+// calling AddEvent on the OpenTelemetry span returned by
+// trace.SpanFromContext.
+//
+// Rings created with the WithTracer option record these events:
+//
+//   - "ring.insert", when InsertContext inserts an element.
+//   - "ring.drop", when InsertContext drops the oldest element to make
+//     room for the new one.
+//   - "ring.extract", when ExtractContext extracts an element, with a
+//     "wait" attribute holding how long it was blocked waiting for it, as
+//     a time.Duration.
+type Tracer interface {
+	Event(ctx context.Context, name string, attrs ...Attr)
+}
+
+// Attr is an attribute of a tracing event.
+type Attr struct {
+	Key   string
+	Value interface{}
+}
+
+// InsertContext works like Insert, also recording its events in the
+// trace span of ctx, if the ring was created with the WithTracer
+// option.
+func (r *Ring) InsertContext(ctx context.Context, v interface{}) {
+	in := r.lockedInsert(v)
+
+	if in.softLimit {
+		r.soft.fn()
+	}
+
+	if in.isDropped {
+		if r.tracer != nil {
+			r.tracer.Event(ctx, "ring.drop")
+		}
+
+		if r.observer != nil {
+			r.observer.OnDrop(in.dropped)
+		}
+	}
+
+	if !in.shed {
+		if r.tracer != nil {
+			r.tracer.Event(ctx, "ring.insert")
+		}
+
+		if r.observer != nil {
+			r.observer.OnInsert(v)
+		}
+	}
+
+	if in.isDropped && r.rollup != nil {
+		r.rollup.add([]interface{}{in.dropped})
+	}
+
+	if in.budget != nil && !in.shed {
+		in.budget.inserted(v)
+	}
+}
+
+// ExtractContext extracts and returns the oldest element in the ring,
+// waiting for one to be inserted if the ring is empty.  It returns the
+// context error if ctx is done before that.
+func (r *Ring) ExtractContext(ctx context.Context) (interface{}, error) {
+	start := r.now()
+
+	for {
+		r.mu.Lock()
+
+		v, ok := r.extract()
+		if ok {
+			r.unlock()
+
+			atomic.AddUint64(&r.extracted, 1)
+
+			if r.tracer != nil {
+				r.tracer.Event(ctx, "ring.extract", Attr{Key: "wait", Value: r.now().Sub(start)})
+			}
+
+			if r.observer != nil {
+				r.observer.OnExtract(v)
+			}
+
+			return v, nil
+		}
+
+		if r.ready == nil {
+			r.ready = make(chan struct{})
+		}
+
+		ready := r.ready
+		r.mu.Unlock()
+
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// NextInsert returns a channel closed when the next element is inserted
+// in the ring, for example to wake up a consumer waiting for the ring to
+// fill up to some length.
+func (r *Ring) NextInsert() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ready == nil {
+		r.ready = make(chan struct{})
+	}
+
+	return r.ready
+}
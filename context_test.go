@@ -0,0 +1,154 @@
+package ring_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestContext(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"extract available": contextExtractAvailable,
+		"extract waits":     contextExtractWaits,
+		"extract cancelled": contextExtractCancelled,
+		"tracer":            contextTracer,
+		"next insert":       contextNextInsert,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// tests that extracting from a non empty ring does not wait.
+func contextExtractAvailable(t *testing.T) {
+	r := newRing(t, 2, 1)
+
+	v, err := r.ExtractContext(context.Background())
+	if err != nil || v != 1 {
+		t.Fatalf("want to extract 1, got %v, %v", v, err)
+	}
+
+	assertEmpty(t, r)
+}
+
+// tests that extracting from an empty ring waits for an insertion.
+func contextExtractWaits(t *testing.T) {
+	r := newRing(t, 2)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		r.Insert(1)
+	}()
+
+	v, err := r.ExtractContext(context.Background())
+	if err != nil || v != 1 {
+		t.Fatalf("want to extract 1, got %v, %v", v, err)
+	}
+}
+
+// tests that waiting for an element stops when the context is done.
+func contextExtractCancelled(t *testing.T) {
+	r := newRing(t, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := r.ExtractContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded error, got %v", err)
+	}
+
+	// the ring still works after an abandoned wait
+	r.Insert(1)
+	assertPeek(t, r, 1)
+}
+
+type ctxKey struct{}
+
+// a tracer recording the events and the context values they were
+// recorded for.
+type tracer struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (tr *tracer) Event(ctx context.Context, name string, attrs ...ring.Attr) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	event := fmt.Sprintf("%v:%s", ctx.Value(ctxKey{}), name)
+
+	for _, a := range attrs {
+		if _, ok := a.Value.(time.Duration); !ok {
+			event += fmt.Sprintf(" %s=%v", a.Key, a.Value)
+		} else {
+			event += " " + a.Key
+		}
+	}
+
+	tr.events = append(tr.events, event)
+}
+
+// tests that context-aware operations record their events.
+func contextTracer(t *testing.T) {
+	tr := &tracer{}
+
+	r, err := ring.New(1, ring.WithTracer(tr))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "span")
+
+	r.InsertContext(ctx, 1)
+	r.InsertContext(ctx, 2)
+	r.Insert(3) // not traced
+
+	if _, err := r.ExtractContext(ctx); err != nil {
+		t.Fatalf("extracting: %v", err)
+	}
+
+	want := "[span:ring.insert span:ring.drop span:ring.insert span:ring.extract wait]"
+	if got := fmt.Sprint(tr.events); got != want {
+		t.Errorf("want events %s, got %s", want, got)
+	}
+}
+
+// tests that the channel returned by NextInsert is closed only after an
+// insertion.
+func contextNextInsert(t *testing.T) {
+	r := newRing(t, 2, 1)
+	next := r.NextInsert()
+
+	r.Extract()
+
+	select {
+	case <-next:
+		t.Fatal("closed before inserting")
+	default:
+	}
+
+	r.Insert(2)
+
+	select {
+	case <-next:
+	default:
+		t.Fatal("not closed after inserting")
+	}
+
+	if r.NextInsert() == next {
+		t.Error("same channel returned after inserting")
+	}
+}
@@ -0,0 +1,123 @@
+//go:build ringdebug
+// +build ringdebug
+
+package ring
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// checks the invariants of the ring, which must be locked, and panics
+// with a dump of its internal state if any of them does not hold.
+//
+// It is only compiled with the ringdebug build tag, to catch the
+// corruption of rings early, for example when developing new variants.
+// Without the tag, it does nothing.
+func (r *Ring) checkInvariants() {
+	if violations := r.violations(); len(violations) > 0 {
+		panic(fmt.Sprintf("ring: invariants violated: %s\n%s",
+			strings.Join(violations, "; "), r.debugDump()))
+	}
+}
+
+// returns the invariants of the ring that do not hold.
+func (r *Ring) violations() []string {
+	var result []string
+
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			result = append(result, fmt.Sprintf(format, args...))
+		}
+	}
+
+	if r.buf.size() == 0 { // the zero Ring, before its first insertion
+		check(r.len == 0 && r.head == 0, "no buffer but length %d and head %d", r.len, r.head)
+		return result
+	}
+
+	n := r.buf.size()
+
+	check(n > 0, "capacity %d <= 0", n)
+	check(r.buf.allocated() <= n, "%d slots allocated > capacity %d", r.buf.allocated(), n)
+	check(r.len >= 0 && r.len <= n, "length %d out of [0, %d]", r.len, n)
+	check(r.len+r.reserved() <= n, "length %d + reserved %d > capacity %d", r.len, r.reserved(), n)
+	check(r.head >= 0 && r.head < n, "head %d out of [0, %d)", r.head, n)
+	check(r.maxLen >= r.len, "max length %d < length %d", r.maxLen, r.len)
+	check(r.peakLen >= r.len, "peak length %d < length %d", r.peakLen, r.len)
+	check(r.maxLen >= r.peakLen, "max length %d < peak length %d", r.maxLen, r.peakLen)
+	check(r.seq >= uint64(r.len), "sequence number %d < length %d", r.seq, r.len)
+	// the inserted elements are counted before inserting them, and the
+	// extracted and dropped ones after, so it holds while in progress
+	extracted, dropped := atomic.LoadUint64(&r.extracted), atomic.LoadUint64(&r.dropped)
+	inserted := atomic.LoadUint64(&r.inserted)
+	check(extracted+dropped+uint64(r.len) <= inserted,
+		"extracted %d + dropped %d + length %d > inserted %d", extracted, dropped, r.len, inserted)
+
+	if len(result) > 0 {
+		// the checks of the slots could go out of range
+		return result
+	}
+
+	if r.times != nil {
+		check(len(r.times) == n, "timestamps length %d != capacity %d", len(r.times), n)
+	}
+
+	if r.counts != nil {
+		check(len(r.counts) == n, "counts length %d != capacity %d", len(r.counts), n)
+	}
+
+	if len(result) > 0 {
+		return result
+	}
+
+	for i := r.len; i < n; i++ {
+		j := (r.head + i) % n
+		check(r.buf.get(j) == nil, "free slot %d retains %#v", j, r.buf.get(j))
+	}
+
+	for i := 0; i < r.len; i++ {
+		j := (r.head + i) % n
+
+		if r.times != nil {
+			check(!r.times[j].IsZero(), "occupied slot %d has no timestamp", j)
+		}
+
+		if r.counts != nil {
+			check(r.counts[j] >= 1, "occupied slot %d has count %d < 1", j, r.counts[j])
+		}
+	}
+
+	return result
+}
+
+// returns a description of the internal state of the ring, which must be
+// locked.
+func (r *Ring) debugDump() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "len=%d cap=%d head=%d seq=%d\n", r.len, r.buf.size(), r.head, r.seq)
+	fmt.Fprintf(&b, "inserted=%d extracted=%d dropped=%d maxLen=%d peakLen=%d\n",
+		atomic.LoadUint64(&r.inserted), atomic.LoadUint64(&r.extracted),
+		atomic.LoadUint64(&r.dropped), r.maxLen, r.peakLen)
+
+	for i := 0; i < r.buf.size(); i++ {
+		v := r.buf.get(i)
+		occupied := (i-r.head+r.buf.size())%r.buf.size() < r.len
+
+		fmt.Fprintf(&b, "slot %d: occupied=%t value=%#v", i, occupied, v)
+
+		if r.times != nil {
+			fmt.Fprintf(&b, " time=%v", r.times[i])
+		}
+
+		if r.counts != nil {
+			fmt.Fprintf(&b, " count=%d", r.counts[i])
+		}
+
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
@@ -0,0 +1,109 @@
+//go:build ringdebug
+// +build ringdebug
+
+package ring
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInvariants(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"valid rings":        invariantsValidRings,
+		"length over cap":    invariantsLengthOverCap,
+		"head out of range":  invariantsHeadOutOfRange,
+		"missing timestamps": invariantsMissingTimestamps,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a ring with the given capacity and options, or fails the
+// test.
+func newDebugRing(t *testing.T, cap int, opts ...Option) *Ring {
+	t.Helper()
+
+	r, err := New(cap, opts...)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	return r
+}
+
+// asserts that fn panics with a message containing want and a dump of
+// the ring.
+func assertViolation(t *testing.T, want string, fn func()) {
+	t.Helper()
+
+	defer func() {
+		t.Helper()
+
+		p := recover()
+		if p == nil {
+			t.Fatal("want panic, got none")
+		}
+
+		msg, ok := p.(string)
+		if !ok || !strings.Contains(msg, want) || !strings.Contains(msg, "slot 0:") {
+			t.Errorf("want panic containing %q and a dump, got %v", want, p)
+		}
+	}()
+
+	fn()
+}
+
+// tests that the operations on valid rings do not panic.
+func invariantsValidRings(t *testing.T) {
+	r := newDebugRing(t, 3, WithTimestamps(), WithCoalesce(func(a, b interface{}) bool { return a == b }))
+
+	for i := 0; i < 10; i++ {
+		r.Insert(i % 4)
+		r.Insert(i % 4)
+
+		if i%3 == 0 {
+			r.Extract()
+		}
+	}
+
+	r.Clear()
+	r.Insert(1)
+}
+
+// tests that lengths over the capacity are detected.
+func invariantsLengthOverCap(t *testing.T) {
+	r := newDebugRing(t, 2)
+	r.len = 3
+
+	assertViolation(t, "length 4 out of [0, 2]", func() { r.Insert(1) })
+}
+
+// tests that heads out of the buffer are detected.
+func invariantsHeadOutOfRange(t *testing.T) {
+	r := newDebugRing(t, 2, WithTimestamps())
+	r.head = 1
+	r.Insert(1)
+	r.head = -1
+
+	assertViolation(t, "head -1 out of [0, 2)", func() { r.Insert(2) })
+}
+
+// tests that occupied slots without timestamps are detected.
+func invariantsMissingTimestamps(t *testing.T) {
+	r := newDebugRing(t, 3, WithTimestamps())
+	r.Insert(1)
+	r.Insert(2)
+	r.times[r.head] = time.Time{}
+
+	assertViolation(t, "has no timestamp", func() { r.Insert(3) })
+}
@@ -0,0 +1,105 @@
+package ring
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Decimating is a concurrent bounded history of elements that, instead
+// of dropping the oldest elements when full, drops every other element,
+// halving its resolution.  From then on, it only keeps every second
+// element inserted, then every fourth after filling up again, and so
+// on, so its fixed capacity spans an ever-growing history, like the
+// one shown in a sparkline.
+type Decimating struct {
+	mu     sync.Mutex
+	buf    []interface{} // the kept elements, from oldest to newest
+	stride uint64        // only every stride-th element is kept
+	seen   uint64        // how many elements were inserted
+}
+
+// NewDecimating returns a new decimating history with the given
+// capacity, which must be at least 2.
+func NewDecimating(cap int) (*Decimating, error) {
+	if cap < 2 {
+		return nil, fmt.Errorf("capacity must be > 1, got %d", cap)
+	}
+
+	return &Decimating{
+		buf:    make([]interface{}, 0, cap),
+		stride: 1,
+	}, nil
+}
+
+// Insert adds a new element to the history, if it is one of the
+// elements kept at the current resolution.  If the history is full, it
+// is decimated first.
+func (d *Decimating) Insert(v interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i := d.seen
+	d.seen++
+
+	if i%d.stride != 0 {
+		return
+	}
+
+	if len(d.buf) == cap(d.buf) {
+		d.decimate()
+
+		if i%d.stride != 0 {
+			return
+		}
+	}
+
+	d.buf = append(d.buf, v)
+}
+
+// drops every other element and halves the resolution.
+func (d *Decimating) decimate() {
+	n := 0
+
+	for i := 0; i < len(d.buf); i += 2 {
+		d.buf[n] = d.buf[i]
+		n++
+	}
+
+	for i := n; i < len(d.buf); i++ {
+		d.buf[i] = nil
+	}
+
+	d.buf = d.buf[:n]
+	d.stride *= 2
+}
+
+// Stride returns how many inserted elements each kept element stands
+// for: 1 until the history fills up for the first time, then 2, 4 and
+// so on.
+func (d *Decimating) Stride() uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.stride
+}
+
+// Len returns the amount of elements in the history.
+func (d *Decimating) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return len(d.buf)
+}
+
+// Cap returns the capacity of the history.
+func (d *Decimating) Cap() int {
+	return cap(d.buf)
+}
+
+// ToSlice returns the elements in the history, from oldest to newest.
+func (d *Decimating) ToSlice() []interface{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]interface{}(nil), d.buf...)
+}
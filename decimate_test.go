@@ -0,0 +1,97 @@
+package ring_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestDecimating(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid capacity": decimatingInvalidCapacity,
+		"until full":       decimatingUntilFull,
+		"decimates":        decimatingDecimates,
+		"spans history":    decimatingSpansHistory,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a decimating history with the given capacity and the ints
+// from 0 to n-1 or fails the test.
+func newDecimating(t *testing.T, cap, n int) *ring.Decimating {
+	t.Helper()
+
+	d, err := ring.NewDecimating(cap)
+	if err != nil {
+		t.Fatalf("creating history: %v", err)
+	}
+
+	for i := 0; i < n; i++ {
+		d.Insert(i)
+	}
+
+	return d
+}
+
+// asserts the contents and stride of the history.
+func assertDecimating(t *testing.T, d *ring.Decimating, want string, stride uint64) {
+	t.Helper()
+
+	if got := fmt.Sprint(d.ToSlice()); got != want || d.Stride() != stride {
+		t.Errorf("want %s with stride %d, got %s with stride %d", want, stride, got, d.Stride())
+	}
+}
+
+// tests that capacities smaller than 2 are invalid.
+func decimatingInvalidCapacity(t *testing.T) {
+	if _, err := ring.NewDecimating(1); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that all elements are kept until the history is full.
+func decimatingUntilFull(t *testing.T) {
+	assertDecimating(t, newDecimating(t, 4, 4), "[0 1 2 3]", 1)
+}
+
+// tests that full histories halve their resolution.
+func decimatingDecimates(t *testing.T) {
+	assertDecimating(t, newDecimating(t, 4, 5), "[0 2 4]", 2)
+	assertDecimating(t, newDecimating(t, 4, 7), "[0 2 4 6]", 2)
+	assertDecimating(t, newDecimating(t, 4, 8), "[0 2 4 6]", 2)
+	assertDecimating(t, newDecimating(t, 4, 9), "[0 4 8]", 4)
+	assertDecimating(t, newDecimating(t, 5, 6), "[0 2 4]", 2)
+}
+
+// tests that the history spans all the inserted elements at evenly
+// spaced points.
+func decimatingSpansHistory(t *testing.T) {
+	d := newDecimating(t, 10, 10000)
+
+	got := d.ToSlice()
+	stride := int(d.Stride())
+
+	if len(got) < d.Cap()/2 || len(got) > d.Cap() {
+		t.Fatalf("unexpected length %d", len(got))
+	}
+
+	for i, v := range got {
+		if v != i*stride {
+			t.Fatalf("element %d: want %d, got %v", i, i*stride, v)
+		}
+	}
+
+	if last := got[len(got)-1].(int); 10000-last > stride {
+		t.Errorf("the history ends at %d, more than the stride %d from the end", last, stride)
+	}
+}
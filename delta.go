@@ -0,0 +1,176 @@
+package ring
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+)
+
+// DeltaInts is a concurrent bounded circular buffer of ints that stores
+// each element as its difference to the previous one, zig-zag and
+// varint encoded, in an arena of bytes.  When there is not enough room
+// for a new element, it drops the oldest ones to make room for it.
+//
+// Series of close values, like timestamps or counters, take one or two
+// bytes per element, instead of the eight bytes per element of Ints,
+// so the same memory holds many more of them.  The elements are
+// decoded transparently when read.
+type DeltaInts struct {
+	mu     sync.Mutex
+	arena  []byte // the encoded differences, but for the oldest element
+	head   int    // offset of the difference of the second oldest element
+	used   int    // bytes of the arena in use
+	len    int    // how many elements are stored in the ring
+	oldest int    // value of the oldest element
+	newest int    // value of the newest element
+}
+
+// NewDeltaInts returns a new delta encoded int ring with an arena of
+// the given size in bytes, which must be at least 10, the size of the
+// longest encoded difference.
+func NewDeltaInts(size int) (*DeltaInts, error) {
+	if size < binary.MaxVarintLen64 {
+		return nil, fmt.Errorf("arena size must be >= %d, got %d", binary.MaxVarintLen64, size)
+	}
+
+	return &DeltaInts{
+		arena: make([]byte, size),
+	}, nil
+}
+
+// reads the difference at offset off, returning it and its encoded
+// length.
+func (r *DeltaInts) read(off int) (int, int) {
+	var (
+		buf [binary.MaxVarintLen64]byte
+		n   int
+	)
+
+	for n < len(buf) {
+		buf[n] = r.arena[(off+n)%len(r.arena)]
+		n++
+
+		if buf[n-1] < 0x80 {
+			break
+		}
+	}
+
+	delta, _ := binary.Varint(buf[:n])
+
+	return int(delta), n
+}
+
+// Insert adds a new element to the ring, dropping the oldest elements
+// if there is not enough room for it.
+func (r *DeltaInts) Insert(v int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		r.oldest, r.newest, r.len = v, v, 1
+		return
+	}
+
+	var buf [binary.MaxVarintLen64]byte
+
+	n := binary.PutVarint(buf[:], int64(v-r.newest))
+
+	for r.used+n > len(r.arena) {
+		r.drop()
+	}
+
+	tail := (r.head + r.used) % len(r.arena)
+	c := copy(r.arena[tail:], buf[:n])
+	copy(r.arena, buf[c:n])
+
+	r.used += n
+	r.len++
+	r.newest = v
+}
+
+// removes the oldest element.
+func (r *DeltaInts) drop() {
+	if r.len == 1 {
+		r.len = 0
+		return
+	}
+
+	delta, n := r.read(r.head)
+
+	r.oldest += delta
+	r.head = (r.head + n) % len(r.arena)
+	r.used -= n
+	r.len--
+}
+
+// Extract extracts and returns the oldest element in the ring.
+func (r *DeltaInts) Extract() (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		return 0, false
+	}
+
+	result := r.oldest
+	r.drop()
+
+	return result, true
+}
+
+// Peek returns the oldest element in the ring.
+func (r *DeltaInts) Peek() (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		return 0, false
+	}
+
+	return r.oldest, true
+}
+
+// Len returns the amount of elements in the ring.
+func (r *DeltaInts) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.len
+}
+
+// Used returns the bytes of the arena in use.
+func (r *DeltaInts) Used() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.used
+}
+
+// Size returns the size of the arena in bytes.
+func (r *DeltaInts) Size() int {
+	return len(r.arena)
+}
+
+// ToSlice returns the elements in the ring, from oldest to newest,
+// without extracting them.
+func (r *DeltaInts) ToSlice() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]int, 0, r.len)
+	if r.len == 0 {
+		return result
+	}
+
+	v, off := r.oldest, r.head
+	result = append(result, v)
+
+	for len(result) < r.len {
+		delta, n := r.read(off)
+		v += delta
+		off = (off + n) % len(r.arena)
+		result = append(result, v)
+	}
+
+	return result
+}
@@ -0,0 +1,147 @@
+package ring_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestDeltaInts(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid size":       deltaIntsInvalidSize,
+		"insert and extract": deltaIntsInsertAndExtract,
+		"compact":            deltaIntsCompact,
+		"drops oldest":       deltaIntsDropsOldest,
+		"extreme values":     deltaIntsExtremeValues,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a delta encoded ring with the given arena size and elements
+// or fails the test.
+func newDeltaInts(t *testing.T, size int, vs ...int) *ring.DeltaInts {
+	t.Helper()
+
+	r, err := ring.NewDeltaInts(size)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	for _, v := range vs {
+		r.Insert(v)
+	}
+
+	return r
+}
+
+// tests that arenas too small for any difference are invalid.
+func deltaIntsInvalidSize(t *testing.T) {
+	if _, err := ring.NewDeltaInts(9); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests the ring returns the elements in order.
+func deltaIntsInsertAndExtract(t *testing.T) {
+	r := newDeltaInts(t, 16, 100, 90, 95)
+
+	if got := fmt.Sprint(r.ToSlice()); got != "[100 90 95]" {
+		t.Errorf("want contents [100 90 95], got %s", got)
+	}
+
+	if v, ok := r.Peek(); !ok || v != 100 {
+		t.Errorf("want to peek 100, got %d, %t", v, ok)
+	}
+
+	for _, want := range []int{100, 90, 95} {
+		if v, ok := r.Extract(); !ok || v != want {
+			t.Errorf("want to extract %d, got %d, %t", want, v, ok)
+		}
+	}
+
+	if _, ok := r.Extract(); ok || r.Len() != 0 || r.Used() != 0 {
+		t.Error("want empty ring after draining")
+	}
+}
+
+// tests that close values take a byte each.
+func deltaIntsCompact(t *testing.T) {
+	const start = 1_600_000_000
+
+	r := newDeltaInts(t, 1000)
+
+	for i := 0; i < 1001; i++ {
+		r.Insert(start + i*10)
+	}
+
+	if r.Len() != 1001 || r.Used() != 1000 {
+		t.Errorf("want 1001 elements in 1000 bytes, got %d in %d", r.Len(), r.Used())
+	}
+}
+
+// tests that the oldest elements are dropped to make room for new
+// ones, against a model of the ring.
+func deltaIntsDropsOldest(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	r := newDeltaInts(t, 50)
+
+	var model []int
+
+	v := 0
+
+	for i := 0; i < 1000; i++ {
+		if rnd.Intn(5) == 0 && len(model) > 0 {
+			got, _ := r.Extract()
+			if got != model[0] {
+				t.Fatalf("want to extract %d, got %d", model[0], got)
+			}
+
+			model = model[1:]
+
+			continue
+		}
+
+		v += rnd.Intn(1<<uint(rnd.Intn(20))) - 1000
+		r.Insert(v)
+		model = append(model, v)
+
+		// the ring drops the oldest elements, so it holds a suffix of
+		// the model
+		contents := r.ToSlice()
+		model = model[len(model)-len(contents):]
+
+		if fmt.Sprint(contents) != fmt.Sprint(model) {
+			t.Fatalf("want contents %v, got %v", model, contents)
+		}
+
+		if r.Used() > r.Size() {
+			t.Fatalf("arena overflow: %d bytes used", r.Used())
+		}
+	}
+}
+
+// tests that differences overflowing an int are handled.
+func deltaIntsExtremeValues(t *testing.T) {
+	const (
+		maxInt = int(^uint(0) >> 1)
+		minInt = -maxInt - 1
+	)
+
+	r := newDeltaInts(t, 32, minInt, maxInt, 0, minInt)
+
+	want := fmt.Sprint([]int{minInt, maxInt, 0, minInt})
+	if got := fmt.Sprint(r.ToSlice()); got != want {
+		t.Errorf("want contents %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,54 @@
+package ring
+
+import (
+	"time"
+
+	"github.com/alcortesm/ring/internal/buckets"
+)
+
+// DropBucket is the amount of elements dropped in a time interval.
+type DropBucket struct {
+	Start time.Time // the start of the interval
+	Drops uint64    // elements dropped in the interval
+}
+
+// counts drops in a ring of time buckets.
+type dropHistory struct {
+	window *buckets.Window
+}
+
+func newDropHistory(n int, width time.Duration) *dropHistory {
+	return &dropHistory{window: buckets.New(n, width)}
+}
+
+// counts a drop at t.
+func (h *dropHistory) add(t time.Time) {
+	h.window.Add(t, 1)
+}
+
+// returns the buckets up to now, from oldest to newest.
+func (h *dropHistory) history(now time.Time) []DropBucket {
+	var result []DropBucket
+
+	h.window.Each(now, func(start time.Time, count int64) {
+		result = append(result, DropBucket{Start: start, Drops: uint64(count)})
+	})
+
+	return result
+}
+
+// DropHistory returns the amount of elements dropped to make room for
+// new ones in each of the time buckets configured with the
+// WithDropHistory option, from oldest to newest, the last one being the
+// current one.  It returns nil if the ring was not created with that
+// option.
+func (r *Ring) DropHistory() []DropBucket {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.drops == nil {
+		return nil
+	}
+
+	return r.drops.history(r.now())
+}
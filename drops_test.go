@@ -0,0 +1,81 @@
+package ring_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestDropHistory(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"disabled":          dropHistoryDisabled,
+		"invalid arguments": dropHistoryInvalidArguments,
+		"counts drops":      dropHistoryCountsDrops,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// tests that rings without the WithDropHistory option have no history.
+func dropHistoryDisabled(t *testing.T) {
+	if got := newRing(t, 1, 1, 2).DropHistory(); got != nil {
+		t.Errorf("unexpected history %v", got)
+	}
+}
+
+// tests that buckets and their width must be positive.
+func dropHistoryInvalidArguments(t *testing.T) {
+	if _, err := ring.New(1, ring.WithDropHistory(0, time.Second)); err == nil {
+		t.Error("unexpected success with no buckets")
+	}
+
+	if _, err := ring.New(1, ring.WithDropHistory(10, 0)); err == nil {
+		t.Error("unexpected success with empty buckets")
+	}
+}
+
+// tests that the drops are counted in the current bucket.
+func dropHistoryCountsDrops(t *testing.T) {
+	r, err := ring.New(1, ring.WithDropHistory(5, time.Hour))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	r.Insert(1)
+	r.Insert(2)
+	r.Insert(3)
+
+	history := r.DropHistory()
+	if len(history) != 5 {
+		t.Fatalf("want 5 buckets, got %d", len(history))
+	}
+
+	var total uint64
+	for _, b := range history {
+		total += b.Drops
+	}
+
+	// the drops could happen right at the start of an hour, but
+	// reading the history later would start a new bucket
+	current, previous := history[4], history[3]
+	if total != 2 || current.Drops+previous.Drops != 2 {
+		t.Errorf("want 2 drops in the last buckets, got %+v", history)
+	}
+
+	if now := time.Now(); now.Before(current.Start) || now.Sub(current.Start) >= time.Hour {
+		t.Errorf("the current bucket starts at %v, at %v", current.Start, now)
+	}
+
+	if current.Start.Sub(previous.Start) != time.Hour {
+		t.Errorf("want buckets an hour apart, got %v and %v", previous.Start, current.Start)
+	}
+}
@@ -0,0 +1,92 @@
+package ring
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// DumpOptions configures the output of Dump.
+type DumpOptions struct {
+	// Redact, if not nil, is called with each element before dumping it,
+	// and its result is dumped instead, so sensitive data can be masked.
+	Redact func(v interface{}) interface{}
+}
+
+// the format of a dump.
+type dump struct {
+	Len      int           `json:"len"`
+	Cap      int           `json:"cap"`
+	Dropped  uint64        `json:"dropped"`
+	Elements []dumpElement `json:"elements"`
+}
+
+type dumpElement struct {
+	Index int    `json:"index"` // position in the buffer
+	Seq   uint64 `json:"seq"`   // position in the stream of stored elements
+	Age   string `json:"age,omitempty"`
+	Count int    `json:"count,omitempty"`
+	Value string `json:"value"`
+}
+
+// Dump writes a description of the ring and its elements to w, as an
+// indented JSON document, for debugging and support purposes.
+//
+// For each element, from oldest to newest, it includes its index in
+// the internal buffer, its sequence number since the creation of the
+// ring, its age if the ring was created with the WithTimestamps option,
+// its count if it was created with the WithCoalesce option, and its
+// value formatted with the %+v verb.
+func (r *Ring) Dump(w io.Writer, opts DumpOptions) error {
+	r.mu.Lock()
+
+	d := dump{
+		Len:      r.len,
+		Cap:      r.capacity(),
+		Dropped:  atomic.LoadUint64(&r.dropped),
+		Elements: make([]dumpElement, r.len),
+	}
+
+	values := make([]interface{}, r.len)
+	now := r.now()
+
+	for i := range d.Elements {
+		j := (r.head + i) % r.buf.size()
+
+		e := &d.Elements[i]
+		e.Index = j
+		e.Seq = r.seq - uint64(r.len-i)
+
+		if r.times != nil {
+			e.Age = now.Sub(r.times[j]).String()
+		}
+
+		if r.counts != nil {
+			e.Count = r.counts[j]
+		}
+
+		values[i] = r.buf.get(j)
+	}
+
+	r.mu.Unlock()
+
+	// the elements are formatted without holding the lock, as their
+	// String methods or the redaction could be slow or use the ring
+	for i, v := range values {
+		if opts.Redact != nil {
+			v = opts.Redact(v)
+		}
+
+		d.Elements[i].Value = fmt.Sprintf("%+v", v)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(d); err != nil {
+		return fmt.Errorf("writing dump: %w", err)
+	}
+
+	return nil
+}
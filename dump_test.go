@@ -0,0 +1,115 @@
+package ring_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestDump(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"plain":     dumpPlain,
+		"redaction": dumpRedaction,
+		"options":   dumpOptions,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns the dump of the ring or fails the test.
+func dumpString(t *testing.T, r *ring.Ring, opts ring.DumpOptions) string {
+	t.Helper()
+
+	var b strings.Builder
+	if err := r.Dump(&b, opts); err != nil {
+		t.Fatalf("dumping: %v", err)
+	}
+
+	return b.String()
+}
+
+// tests the dump of a ring without options.
+func dumpPlain(t *testing.T) {
+	r := newRing(t, 2, "a", "b", "c")
+
+	want := `{
+  "len": 2,
+  "cap": 2,
+  "dropped": 1,
+  "elements": [
+    {
+      "index": 1,
+      "seq": 1,
+      "value": "b"
+    },
+    {
+      "index": 0,
+      "seq": 2,
+      "value": "c"
+    }
+  ]
+}
+`
+	if got := dumpString(t, r, ring.DumpOptions{}); got != want {
+		t.Errorf("want dump:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+// tests that elements can be redacted.
+func dumpRedaction(t *testing.T) {
+	type user struct {
+		Name     string
+		Password string
+	}
+
+	r := newRing(t, 2, user{Name: "alice", Password: "secret"})
+
+	got := dumpString(t, r, ring.DumpOptions{
+		Redact: func(v interface{}) interface{} {
+			u := v.(user)
+			u.Password = "REDACTED"
+
+			return u
+		},
+	})
+
+	if strings.Contains(got, "secret") || !strings.Contains(got, "{Name:alice Password:REDACTED}") {
+		t.Errorf("unexpected dump:\n%s", got)
+	}
+}
+
+// tests that ages and counts are dumped when available.
+func dumpOptions(t *testing.T) {
+	r, err := ring.New(3, ring.WithTimestamps(), ring.WithCoalesce(equal))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	r.Insert("a")
+	r.Insert("a")
+
+	var d struct {
+		Elements []struct {
+			Age   string
+			Count int
+		}
+	}
+
+	if err := json.Unmarshal([]byte(dumpString(t, r, ring.DumpOptions{})), &d); err != nil {
+		t.Fatalf("decoding dump: %v", err)
+	}
+
+	if len(d.Elements) != 1 || d.Elements[0].Age == "" || d.Elements[0].Count != 2 {
+		t.Errorf("unexpected elements %+v", d.Elements)
+	}
+}
@@ -0,0 +1,250 @@
+package ring
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sync/atomic"
+)
+
+const (
+	exportMagic   = "RINGEXP"
+	exportVersion = 1
+)
+
+// ErrCorruptLog is returned when importing a log that is incomplete or
+// damaged.
+var ErrCorruptLog = errors.New("corrupt ring log")
+
+// the payload of an exported record.
+type exportRecord struct {
+	V interface{}
+}
+
+// ExportLog writes the contents of the ring to w, from oldest to
+// newest, without extracting them, in the following format:
+//
+//	magic    7 bytes, the string "RINGEXP"
+//	version  1 byte
+//	count    8 bytes, big endian, the number of records
+//	records  count records, from oldest to newest element
+//
+// Each record is made of:
+//
+//	length   4 bytes, big endian
+//	payload  length bytes
+//	crc      4 bytes, big endian, the IEEE CRC-32 of the payload
+//
+// The payload of each record is a self-contained gob stream holding a
+// struct with a single field V, of type interface{}, set to the
+// element.
+//
+// Elements are encoded with encoding/gob, so their concrete types must
+// be registered with gob.Register, unless they are basic types.
+func (r *Ring) ExportLog(w io.Writer) error {
+	elements := r.ToSlice()
+
+	bw := bufio.NewWriter(w)
+
+	header := make([]byte, len(exportMagic)+1+8)
+	copy(header, exportMagic)
+	header[len(exportMagic)] = exportVersion
+	binary.BigEndian.PutUint64(header[len(exportMagic)+1:], uint64(len(elements)))
+
+	if _, err := bw.Write(header); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	var payload bytes.Buffer
+
+	for i, v := range elements {
+		payload.Reset()
+
+		if err := gob.NewEncoder(&payload).Encode(exportRecord{V: v}); err != nil {
+			return fmt.Errorf("encoding element %d: %w", i, err)
+		}
+
+		var b [4]byte
+
+		binary.BigEndian.PutUint32(b[:], uint32(payload.Len()))
+		_, _ = bw.Write(b[:])
+		_, _ = bw.Write(payload.Bytes())
+		binary.BigEndian.PutUint32(b[:], crc32.ChecksumIEEE(payload.Bytes()))
+
+		if _, err := bw.Write(b[:]); err != nil {
+			return fmt.Errorf("writing element %d: %w", i, err)
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("writing log: %w", err)
+	}
+
+	return nil
+}
+
+// ImportLog reads a log written by ExportLog and inserts its elements
+// in the ring, from oldest to newest, as if calling Insert for each of
+// them.  The whole log is validated before inserting anything, if it
+// is incomplete or damaged, it returns an error wrapping ErrCorruptLog
+// and the ring is left untouched.
+func (r *Ring) ImportLog(rd io.Reader) error {
+	elements, err := readLog(bufio.NewReader(rd))
+	if err != nil {
+		return fmt.Errorf("importing log: %w", err)
+	}
+
+	atomic.AddUint64(&r.inserted, uint64(len(elements)))
+
+	r.mu.Lock()
+	dropped := r.insertAll(elements)
+	r.unlock()
+
+	atomic.AddUint64(&r.dropped, dropped)
+
+	return nil
+}
+
+// reads and decodes all the elements in a log.
+func readLog(rd io.Reader) ([]interface{}, error) {
+	var elements []interface{}
+
+	err := scanLog(rd, func(_ uint64, v interface{}) error {
+		elements = append(elements, v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return elements, nil
+}
+
+// ScanLog reads a log written by ExportLog and calls fn with the
+// position and value of each of its elements, from oldest to newest, as
+// they are read.  Unlike ImportLog, it does not validate the whole log
+// first: the elements before a damaged record are passed to fn, and
+// then it returns an error wrapping ErrCorruptLog.  It also stops at the
+// first error returned by fn, and returns it.
+//
+// It is meant to inspect logs, for example after a crash.
+func ScanLog(rd io.Reader, fn func(i uint64, v interface{}) error) error {
+	return scanLog(bufio.NewReader(rd), fn)
+}
+
+// works like ScanLog, but rd must be buffered.
+func scanLog(rd io.Reader, fn func(i uint64, v interface{}) error) error {
+	header := make([]byte, len(exportMagic)+1+8)
+
+	if _, err := io.ReadFull(rd, header); err != nil {
+		return corruptLog(err)
+	}
+
+	if string(header[:len(exportMagic)]) != exportMagic {
+		return errors.New("not a ring log")
+	}
+
+	if v := header[len(exportMagic)]; v != exportVersion {
+		return fmt.Errorf("unsupported log version %d", v)
+	}
+
+	count := binary.BigEndian.Uint64(header[len(exportMagic)+1:])
+
+	for i := uint64(0); i < count; i++ {
+		var b [4]byte
+
+		if _, err := io.ReadFull(rd, b[:]); err != nil {
+			return corruptLog(err)
+		}
+
+		// copy instead of allocating length bytes upfront, so a damaged
+		// length does not trigger a huge allocation.
+		var payload bytes.Buffer
+		if _, err := io.CopyN(&payload, rd, int64(binary.BigEndian.Uint32(b[:]))); err != nil {
+			return corruptLog(err)
+		}
+
+		if _, err := io.ReadFull(rd, b[:]); err != nil {
+			return corruptLog(err)
+		}
+
+		if crc32.ChecksumIEEE(payload.Bytes()) != binary.BigEndian.Uint32(b[:]) {
+			return fmt.Errorf("%w: checksum mismatch in record %d", ErrCorruptLog, i)
+		}
+
+		var rec exportRecord
+		if err := gob.NewDecoder(&payload).Decode(&rec); err != nil {
+			return fmt.Errorf("%w: decoding record %d: %v", ErrCorruptLog, i, err)
+		}
+
+		if err := fn(i, rec.V); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// wraps errors from incomplete reads as corruption errors.
+func corruptLog(err error) error {
+	if err == io.ErrUnexpectedEOF || err == io.EOF {
+		return fmt.Errorf("%w: truncated", ErrCorruptLog)
+	}
+
+	return err
+}
+
+// DrainTo extracts the elements in the ring, from oldest to newest,
+// encoding each of them to w with enc before extracting it, and returns
+// the amount of elements extracted.  The ring stays locked while
+// draining, so the elements drained are exactly the ones in the ring
+// when it was called, and w receives them in order, without the
+// elements inserted meanwhile.
+//
+// If enc fails, draining stops and DrainTo returns the error, along
+// with the amount of elements drained so far, which is the position in
+// the drain of the element that failed.  That element, and all the ones
+// after it, stay in the ring, so none is lost, although part of the
+// failed one could have been written to w already.
+//
+// As the ring stays locked until all the elements are encoded, enc must
+// not call any method of the ring, or it deadlocks, and w should
+// rather be buffered.
+func (r *Ring) DrainTo(w io.Writer, enc func(w io.Writer, v interface{}) error) (int, error) {
+	drained, err := r.drainTo(w, enc)
+
+	atomic.AddUint64(&r.extracted, uint64(len(drained)))
+
+	if r.observer != nil {
+		for _, v := range drained {
+			r.observer.OnExtract(v)
+		}
+	}
+
+	return len(drained), err
+}
+
+// returns the elements drained to w by DrainTo, and the error of the
+// encoding that failed, if any.
+func (r *Ring) drainTo(w io.Writer, enc func(w io.Writer, v interface{}) error) ([]interface{}, error) {
+	r.mu.Lock()
+	defer r.unlock() // enc could panic
+
+	var result []interface{}
+
+	for r.len > 0 {
+		if err := enc(w, r.buf.get(r.head)); err != nil {
+			return result, fmt.Errorf("encoding element %d: %w", len(result), err)
+		}
+
+		v, _ := r.extract()
+		result = append(result, v)
+	}
+
+	return result, nil
+}
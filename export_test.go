@@ -0,0 +1,299 @@
+package ring_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestExportLog(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"export and import":  exportAndImport,
+		"import inserts":     exportImportInserts,
+		"empty ring":         exportEmptyRing,
+		"truncated log":      exportTruncatedLog,
+		"damaged log":        exportDamagedLog,
+		"not a log":          exportNotALog,
+		"export keeps items": exportKeepsElements,
+		"scan":               exportScan,
+		"scan damaged log":   exportScanDamagedLog,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns the exported log of a ring with the given elements or fails
+// the test.
+func exportLog(t *testing.T, vs ...interface{}) []byte {
+	t.Helper()
+
+	var b bytes.Buffer
+
+	if err := newRing(t, 8, vs...).ExportLog(&b); err != nil {
+		t.Fatalf("exporting: %v", err)
+	}
+
+	return b.Bytes()
+}
+
+// tests that importing an exported log recovers the elements.
+func exportAndImport(t *testing.T) {
+	log := exportLog(t, 1, "two", 3.5, []byte("four"))
+
+	r := newRing(t, 8)
+	if err := r.ImportLog(bytes.NewReader(log)); err != nil {
+		t.Fatalf("importing: %v", err)
+	}
+
+	assertContents(t, r, 1, "two", 3.5, []byte("four"))
+}
+
+// tests that importing inserts after the current elements, dropping
+// the oldest ones if needed.
+func exportImportInserts(t *testing.T) {
+	log := exportLog(t, 3, 4)
+
+	r := newRing(t, 3, 1, 2)
+	if err := r.ImportLog(bytes.NewReader(log)); err != nil {
+		t.Fatalf("importing: %v", err)
+	}
+
+	assertContents(t, r, 2, 3, 4)
+}
+
+// tests exporting and importing an empty ring.
+func exportEmptyRing(t *testing.T) {
+	log := exportLog(t)
+
+	r := newRing(t, 3, 1)
+	if err := r.ImportLog(bytes.NewReader(log)); err != nil {
+		t.Fatalf("importing: %v", err)
+	}
+
+	assertContents(t, r, 1)
+}
+
+// asserts that importing the log fails as corrupt without modifying
+// the ring.
+func assertCorruptLog(t *testing.T, log []byte) {
+	t.Helper()
+
+	r := newRing(t, 3, "old")
+
+	err := r.ImportLog(bytes.NewReader(log))
+	if !errors.Is(err, ring.ErrCorruptLog) {
+		t.Errorf("want corrupt log error, got %v", err)
+	}
+
+	assertContents(t, r, "old")
+}
+
+// tests that incomplete logs are detected.
+func exportTruncatedLog(t *testing.T) {
+	log := exportLog(t, 1, 2, 3)
+
+	for _, keep := range []int{0, 5, 16, 20, len(log) - 1} {
+		assertCorruptLog(t, log[:keep])
+	}
+}
+
+// tests that damaged logs are detected.
+func exportDamagedLog(t *testing.T) {
+	log := exportLog(t, 1, 2, 3)
+	log[len(log)-6] ^= 0xff
+
+	assertCorruptLog(t, log)
+}
+
+// tests that importing something else fails.
+func exportNotALog(t *testing.T) {
+	r := newRing(t, 3)
+
+	err := r.ImportLog(bytes.NewReader([]byte("hello, world, this is not a log")))
+	if err == nil {
+		t.Fatal("unexpected success")
+	}
+
+	assertEmpty(t, r)
+}
+
+// tests that exporting does not extract elements.
+func exportKeepsElements(t *testing.T) {
+	r := newRing(t, 3, 1, 2)
+
+	var b bytes.Buffer
+	if err := r.ExportLog(&b); err != nil {
+		t.Fatalf("exporting: %v", err)
+	}
+
+	assertContents(t, r, 1, 2)
+}
+
+// returns the elements scanned from the log, as position:value, and
+// the error of the scan.
+func scanLog(log []byte) (string, error) {
+	var got []string
+
+	err := ring.ScanLog(bytes.NewReader(log), func(i uint64, v interface{}) error {
+		got = append(got, fmt.Sprintf("%d:%v", i, v))
+		return nil
+	})
+
+	return fmt.Sprint(got), err
+}
+
+// tests that scanning a log returns its elements in order.
+func exportScan(t *testing.T) {
+	got, err := scanLog(exportLog(t, "a", 2, "c"))
+	if err != nil {
+		t.Fatalf("scanning: %v", err)
+	}
+
+	if got != "[0:a 1:2 2:c]" {
+		t.Errorf("want [0:a 1:2 2:c], got %s", got)
+	}
+}
+
+// tests that scanning a damaged log returns the elements before the
+// damage and then fails as corrupt.
+func exportScanDamagedLog(t *testing.T) {
+	log := exportLog(t, 1, 2, 3)
+	log[len(log)-6] ^= 0xff
+
+	got, err := scanLog(log)
+	if !errors.Is(err, ring.ErrCorruptLog) {
+		t.Errorf("want corrupt log error, got %v", err)
+	}
+
+	if got != "[0:1 1:2]" {
+		t.Errorf("want [0:1 1:2], got %s", got)
+	}
+}
+
+func TestDrainTo(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"drains all":        drainToDrainsAll,
+		"empty ring":        drainToEmptyRing,
+		"encoding error":    drainToEncodingError,
+		"observer":          drainToObserver,
+		"panicking encoder": drainToPanickingEncoder,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// encodes elements as lines, failing with the "bad" ones.
+func encodeLine(w io.Writer, v interface{}) error {
+	if v == "bad" {
+		return errBadElement
+	}
+
+	_, err := fmt.Fprintln(w, v)
+
+	return err
+}
+
+var errBadElement = errors.New("bad element")
+
+// tests that DrainTo encodes and extracts all the elements, from oldest
+// to newest.
+func drainToDrainsAll(t *testing.T) {
+	r := newRing(t, 3, "a", "b", "c", "d")
+
+	var b bytes.Buffer
+
+	n, err := r.DrainTo(&b, encodeLine)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if n != 3 || b.String() != "b\nc\nd\n" {
+		t.Errorf("want 3 elements drained as %q, got %d as %q", "b\nc\nd\n", n, b.String())
+	}
+
+	assertEmpty(t, r)
+
+	if got := r.Stats().Extracted; got != 3 {
+		t.Errorf("want 3 extracted, got %d", got)
+	}
+}
+
+// tests that draining empty rings writes nothing.
+func drainToEmptyRing(t *testing.T) {
+	var b bytes.Buffer
+
+	n, err := newRing(t, 2).DrainTo(&b, encodeLine)
+	if n != 0 || err != nil || b.Len() != 0 {
+		t.Errorf("want nothing drained, got %d, %v, %q", n, err, b.String())
+	}
+}
+
+// tests that encoding errors stop the drain, reporting its position and
+// keeping the failed element and the ones after it.
+func drainToEncodingError(t *testing.T) {
+	r := newRing(t, 4, "a", "b", "bad", "c")
+
+	var b bytes.Buffer
+
+	n, err := r.DrainTo(&b, encodeLine)
+	if !errors.Is(err, errBadElement) {
+		t.Errorf("want %v, got %v", errBadElement, err)
+	}
+
+	if n != 2 || b.String() != "a\nb\n" {
+		t.Errorf("want 2 elements drained as %q, got %d as %q", "a\nb\n", n, b.String())
+	}
+
+	ringtest.AssertContents(t, r, "bad", "c")
+}
+
+// tests that the observer is notified of the drained elements.
+func drainToObserver(t *testing.T) {
+	o := &recorder{}
+	r := newObserved(t, 3, o)
+	r.Insert("a")
+	r.Insert("bad")
+
+	if _, err := r.DrainTo(ioutil.Discard, encodeLine); err == nil {
+		t.Error("unexpected success")
+	}
+
+	assertEvents(t, o, "[insert a insert bad extract a]")
+}
+
+// tests that the ring stays usable after a panic of the encoder.
+func drainToPanickingEncoder(t *testing.T) {
+	r := newRing(t, 2, "a", "b")
+
+	func() {
+		defer func() { _ = recover() }()
+
+		_, _ = r.DrainTo(ioutil.Discard, func(io.Writer, interface{}) error {
+			panic("boom")
+		})
+	}()
+
+	ringtest.AssertContents(t, r, "a", "b")
+}
@@ -0,0 +1,43 @@
+package ring
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format implements fmt.Formatter.
+//
+// The %v and %s verbs print a summary of the ring with its length and
+// capacity.  The %+v verb also prints its elements, from oldest to
+// newest.  The %#v verb prints a Go expression that rebuilds the ring,
+// see GoString.
+func (r *Ring) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('#'):
+		fmt.Fprint(f, r.GoString())
+	case verb == 'v' && f.Flag('+'):
+		elements := r.ToSlice()
+		fmt.Fprintf(f, "ring(len=%d, cap=%d)%+v", len(elements), r.Cap(), elements)
+	case verb == 'v' || verb == 's':
+		fmt.Fprintf(f, "ring(len=%d, cap=%d)", r.Len(), r.Cap())
+	default:
+		fmt.Fprintf(f, "%%!%c(*ring.Ring)", verb)
+	}
+}
+
+// GoString implements fmt.GoStringer.  It returns a Go expression
+// that, if the elements print as Go literals with %#v, rebuilds a ring
+// with the same capacity and elements.
+func (r *Ring) GoString() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "func() *ring.Ring { r, _ := ring.New(%d); ", r.Cap())
+
+	for _, v := range r.ToSlice() {
+		fmt.Fprintf(&b, "r.Insert(%#v); ", v)
+	}
+
+	b.WriteString("return r }()")
+
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package ring_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	r, err := ring.New(4)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	r.Insert(1)
+	r.Insert("two")
+
+	for format, want := range map[string]string{
+		"%v":  "ring(len=2, cap=4)",
+		"%s":  "ring(len=2, cap=4)",
+		"%+v": "ring(len=2, cap=4)[1 two]",
+		"%#v": `func() *ring.Ring { r, _ := ring.New(4); r.Insert(1); r.Insert("two"); return r }()`,
+		"%d":  "%!d(*ring.Ring)",
+	} {
+		format, want := format, want
+
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			if got := fmt.Sprintf(format, r); got != want {
+				t.Errorf("want %s, got %s", want, got)
+			}
+		})
+	}
+
+	t.Run("GoString", func(t *testing.T) {
+		t.Parallel()
+
+		if got, want := r.GoString(), fmt.Sprintf("%#v", r); got != want {
+			t.Errorf("want %s, got %s", want, got)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		empty, err := ring.New(1)
+		if err != nil {
+			t.Fatalf("creating ring: %v", err)
+		}
+
+		want := "ring(len=0, cap=1)[]"
+		if got := fmt.Sprintf("%+v", empty); got != want {
+			t.Errorf("want %s, got %s", want, got)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package ring
+
+import "fmt"
+
+// ReadFrames returns the overlapping frames of n consecutive elements
+// in the ring, from oldest to newest, each one starting hop elements
+// after the previous one, as needed for windowed signal processing.
+//
+// Only the first hop elements of each returned frame are extracted, so
+// the rest of its elements remain in the ring to be part of the next
+// frames.  The elements that do not complete a frame also remain in the
+// ring.
+//
+// It fails unless 1 <= hop <= n <= Cap().
+func (r *Float64s) ReadFrames(n, hop int) ([][]float64, error) {
+	if n < 1 || n > cap(r.buf) {
+		return nil, fmt.Errorf("frame size must be in [1, %d], got %d", cap(r.buf), n)
+	}
+
+	if hop < 1 || hop > n {
+		return nil, fmt.Errorf("hop must be in [1, %d], got %d", n, hop)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var frames [][]float64
+
+	for r.len >= n {
+		frame := make([]float64, n)
+		for i := range frame {
+			frame[i] = r.buf[(r.head+i)%cap(r.buf)]
+		}
+
+		frames = append(frames, frame)
+
+		for i := 0; i < hop; i++ {
+			_, _ = r.extract()
+		}
+	}
+
+	return frames, nil
+}
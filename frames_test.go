@@ -0,0 +1,84 @@
+package ring_test
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestReadFrames(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid arguments": readFramesInvalidArguments,
+		"overlapping":       readFramesOverlapping,
+		"incremental":       readFramesIncremental,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// tests that frames must fit in the ring and hops in the frames.
+func readFramesInvalidArguments(t *testing.T) {
+	r := newFloat64s(t, 4, 1, 2, 3, 4)
+
+	for _, args := range [][2]int{{0, 1}, {5, 1}, {2, 0}, {2, 3}} {
+		if _, err := r.ReadFrames(args[0], args[1]); err == nil {
+			t.Errorf("unexpected success with frame size %d and hop %d", args[0], args[1])
+		}
+	}
+
+	if r.Len() != 4 {
+		t.Errorf("want the ring untouched, got length %d", r.Len())
+	}
+}
+
+// tests reading overlapping frames.
+func readFramesOverlapping(t *testing.T) {
+	r := newFloat64s(t, 8, 1, 2, 3, 4, 5, 6, 7)
+
+	frames, err := r.ReadFrames(4, 2)
+	if err != nil {
+		t.Fatalf("reading frames: %v", err)
+	}
+
+	if got := fmt.Sprint(frames); got != "[[1 2 3 4] [3 4 5 6]]" {
+		t.Errorf("unexpected frames %s", got)
+	}
+
+	// the samples not consumed remain for the next frames
+	if got := fmt.Sprint(r.ToSlice()); got != "[5 6 7]" {
+		t.Errorf("want remaining samples [5 6 7], got %s", got)
+	}
+
+	if got := r.Sum(); got != 18 {
+		t.Errorf("want sum 18 of the remaining samples, got %v", got)
+	}
+}
+
+// tests that frames can be read as samples arrive.
+func readFramesIncremental(t *testing.T) {
+	r := newFloat64s(t, 4)
+
+	var got [][]float64
+
+	for i := 0; i < 8; i++ {
+		r.Insert(float64(i))
+
+		frames, err := r.ReadFrames(3, 1)
+		if err != nil {
+			t.Fatalf("reading frames: %v", err)
+		}
+
+		got = append(got, frames...)
+	}
+
+	if want := "[[0 1 2] [1 2 3] [2 3 4] [3 4 5] [4 5 6] [5 6 7]]"; fmt.Sprint(got) != want {
+		t.Errorf("want frames %s, got %v", want, got)
+	}
+}
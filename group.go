@@ -0,0 +1,309 @@
+package ring
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Group is a concurrent set of rings, one per key, bounded both per key
+// and in total.  Each key holds up to a capacity of elements, dropping
+// its oldest ones like a Ring does, and all the keys together hold up to
+// a shared budget of elements.
+//
+// When an insertion exceeds the budget, the oldest element of the key
+// holding the most elements is dropped.  Ties go to the key whose
+// oldest element was inserted first.
+//
+// Rings are created on the first insertion to their key, and forgotten
+// when they become empty, or when they are idle for too long, see
+// WithIdleTTL.
+type Group struct {
+	mu     sync.Mutex
+	rings  map[string]*Ring
+	used   map[string]time.Time // last insertion or extraction per key
+	cap    int                  // capacity of each ring
+	budget int                  // maximum elements in all the rings
+	len    int                  // elements in all the rings
+	seq    uint64               // sequence number of the next inserted element
+	cfg    groupConfig
+
+	closeOnce sync.Once
+	stop      chan struct{} // closed to stop the background goroutine
+	done      sync.WaitGroup
+}
+
+// GroupOption configures a group on construction.
+type GroupOption func(*groupConfig)
+
+// the configuration set by the group options.
+type groupConfig struct {
+	idleTTL   time.Duration
+	onEvicted func(key string, elements []interface{})
+	clock     Clock
+}
+
+// WithIdleTTL makes the group evict the keys with no insertions nor
+// extractions for longer than ttl, along with their elements, from a
+// background goroutine, until Close is called.  See WithOnKeyEvicted.
+func WithIdleTTL(ttl time.Duration) GroupOption {
+	return func(c *groupConfig) {
+		c.idleTTL = ttl
+	}
+}
+
+// WithOnKeyEvicted makes the group call fn with each key evicted for
+// being idle and the elements it held, from oldest to newest.  It is
+// called from the background goroutine of the group, which is not
+// locked, so fn can use it.
+func WithOnKeyEvicted(fn func(key string, elements []interface{})) GroupOption {
+	return func(c *groupConfig) {
+		c.onEvicted = fn
+	}
+}
+
+// WithGroupClock makes the group tell the time with c instead of the
+// time package, for its idle TTL and its background goroutine.  It is
+// meant for tests advancing the time deterministically.
+func WithGroupClock(c Clock) GroupOption {
+	return func(cfg *groupConfig) {
+		cfg.clock = c
+	}
+}
+
+// an element in one of the rings of a group.
+type groupElement struct {
+	seq uint64 // insertion order in the group
+	v   interface{}
+}
+
+// NewGroup returns a new group holding up to cap elements per key and
+// up to budget elements in total, with the given options.
+func NewGroup(cap, budget int, opts ...GroupOption) (*Group, error) {
+	if cap < 1 {
+		return nil, fmt.Errorf("ring capacity must be > 0, got %d", cap)
+	}
+
+	if budget < 1 {
+		return nil, fmt.Errorf("group budget must be > 0, got %d", budget)
+	}
+
+	g := &Group{
+		rings:  make(map[string]*Ring),
+		used:   make(map[string]time.Time),
+		cap:    cap,
+		budget: budget,
+		stop:   make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(&g.cfg)
+	}
+
+	if g.cfg.clock == nil {
+		g.cfg.clock = SystemClock{}
+	}
+
+	if g.cfg.idleTTL < 0 {
+		return nil, fmt.Errorf("idle TTL must be >= 0, got %v", g.cfg.idleTTL)
+	}
+
+	if g.cfg.idleTTL > 0 {
+		g.done.Add(1)
+
+		go g.evictIdle(g.cfg.idleTTL)
+	}
+
+	return g, nil
+}
+
+// Insert adds a new element to the ring of the given key.  If the ring
+// is already at maximum capacity, its oldest element is dropped to make
+// room for the new one.  Otherwise, if the group is over its budget,
+// the oldest element of the largest ring is dropped.
+func (g *Group) Insert(key string, v interface{}) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	r, ok := g.rings[key]
+	if !ok {
+		// cannot fail, the capacity was validated by NewGroup
+		r, _ = New(g.cap)
+		g.rings[key] = r
+	}
+
+	g.used[key] = g.cfg.clock.Now()
+	full := r.Len() == r.Cap()
+
+	r.Insert(groupElement{seq: g.seq, v: v})
+	g.seq++
+
+	if full {
+		return
+	}
+
+	g.len++
+
+	if g.len > g.budget {
+		g.evict()
+	}
+}
+
+// drops the oldest element of the ring with the most elements.
+func (g *Group) evict() {
+	var (
+		victim    string
+		victimLen int
+		victimSeq uint64
+	)
+
+	for key, r := range g.rings {
+		n := r.Len()
+
+		oldest, _ := r.Peek()
+		seq := oldest.(groupElement).seq
+
+		if n > victimLen || (n == victimLen && seq < victimSeq) {
+			victim, victimLen, victimSeq = key, n, seq
+		}
+	}
+
+	g.extract(victim)
+}
+
+// extracts the oldest element of the ring of the given key, forgetting
+// the ring if it becomes empty.
+func (g *Group) extract(key string) (interface{}, bool) {
+	r, ok := g.rings[key]
+	if !ok {
+		return nil, false
+	}
+
+	v, _ := r.Extract()
+	g.len--
+	g.used[key] = g.cfg.clock.Now()
+
+	if r.Len() == 0 {
+		delete(g.rings, key)
+		delete(g.used, key)
+	}
+
+	return v.(groupElement).v, true
+}
+
+// Extract extracts and returns the oldest element in the ring of the
+// given key.
+func (g *Group) Extract(key string) (interface{}, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.extract(key)
+}
+
+// Len returns the amount of elements in all the rings of the group.
+func (g *Group) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.len
+}
+
+// KeyLen returns the amount of elements in the ring of the given key.
+func (g *Group) KeyLen(key string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	r, ok := g.rings[key]
+	if !ok {
+		return 0
+	}
+
+	return r.Len()
+}
+
+// Keys returns the keys with elements in the group, in lexicographic
+// order.
+func (g *Group) Keys() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	result := make([]string, 0, len(g.rings))
+	for key := range g.rings {
+		result = append(result, key)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// Close stops the background goroutine of the group, if it was created
+// with the WithIdleTTL option.  The group can still be used after
+// closing it.
+func (g *Group) Close() {
+	g.closeOnce.Do(func() {
+		close(g.stop)
+		g.done.Wait()
+	})
+}
+
+// checks periodically for keys idle for longer than ttl, and evicts
+// them.
+func (g *Group) evictIdle(ttl time.Duration) {
+	defer g.done.Done()
+
+	every := ttl / 4
+	if every == 0 {
+		every = ttl
+	}
+
+	ticker := g.cfg.clock.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-g.stop:
+			return
+		case <-ticker.C():
+		}
+
+		type eviction struct {
+			key      string
+			elements []interface{}
+		}
+
+		var evicted []eviction
+
+		g.mu.Lock()
+
+		now := g.cfg.clock.Now()
+
+		for key, used := range g.used {
+			if now.Sub(used) <= ttl {
+				continue
+			}
+
+			r := g.rings[key]
+			elements := r.ToSlice()
+
+			for i, v := range elements {
+				elements[i] = v.(groupElement).v
+			}
+
+			g.len -= len(elements)
+			delete(g.rings, key)
+			delete(g.used, key)
+
+			evicted = append(evicted, eviction{key: key, elements: elements})
+		}
+
+		g.mu.Unlock()
+
+		if g.cfg.onEvicted != nil {
+			for _, e := range evicted {
+				g.cfg.onEvicted(e.key, e.elements)
+			}
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package ring_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestGroup(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid arguments":      groupInvalidArguments,
+		"keys are independent":   groupKeysAreIndependent,
+		"per key capacity":       groupPerKeyCapacity,
+		"budget evicts largest":  groupBudgetEvictsLargest,
+		"budget ties go oldest":  groupBudgetTiesGoOldest,
+		"empty keys forgotten":   groupEmptyKeysForgotten,
+		"extract of unknown key": groupExtractUnknownKey,
+		"negative idle TTL":      groupNegativeIdleTTL,
+		"idle keys evicted":      groupIdleKeysEvicted,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a group with the given capacity and budget or fails the test.
+func newGroup(t *testing.T, cap, budget int) *ring.Group {
+	t.Helper()
+
+	g, err := ring.NewGroup(cap, budget)
+	if err != nil {
+		t.Fatalf("creating group: %v", err)
+	}
+
+	return g
+}
+
+// asserts that extracting from the given key returns the wanted
+// elements and leaves it empty.
+func assertGroupDrain(t *testing.T, g *ring.Group, key string, want ...interface{}) {
+	t.Helper()
+
+	for _, w := range want {
+		got, ok := g.Extract(key)
+		if !ok {
+			t.Fatalf("key %q: unexpected empty ring when extracting %v", key, w)
+		}
+
+		if got != w {
+			t.Errorf("key %q: want %v, got %v", key, w, got)
+		}
+	}
+
+	if v, ok := g.Extract(key); ok {
+		t.Errorf("key %q: want empty ring, extracted %v", key, v)
+	}
+}
+
+// tests that capacities and budgets smaller than 1 are invalid.
+func groupInvalidArguments(t *testing.T) {
+	for _, args := range [][2]int{{0, 1}, {1, 0}, {-1, 10}} {
+		if _, err := ring.NewGroup(args[0], args[1]); err == nil {
+			t.Errorf("cap=%d budget=%d: unexpected success", args[0], args[1])
+		}
+	}
+}
+
+// tests that each key has its own ring.
+func groupKeysAreIndependent(t *testing.T) {
+	g := newGroup(t, 4, 100)
+
+	g.Insert("a", 1)
+	g.Insert("b", 10)
+	g.Insert("a", 2)
+
+	if got := g.Len(); got != 3 {
+		t.Errorf("want length 3, got %d", got)
+	}
+
+	if got := fmt.Sprint(g.Keys()); got != "[a b]" {
+		t.Errorf("want keys [a b], got %s", got)
+	}
+
+	assertGroupDrain(t, g, "a", 1, 2)
+	assertGroupDrain(t, g, "b", 10)
+}
+
+// tests that each key drops its own oldest elements when full, without
+// affecting the others.
+func groupPerKeyCapacity(t *testing.T) {
+	g := newGroup(t, 2, 100)
+
+	g.Insert("b", 10)
+
+	for i := 1; i <= 5; i++ {
+		g.Insert("a", i)
+	}
+
+	if got := g.Len(); got != 3 {
+		t.Errorf("want length 3, got %d", got)
+	}
+
+	assertGroupDrain(t, g, "a", 4, 5)
+	assertGroupDrain(t, g, "b", 10)
+}
+
+// tests that exceeding the budget drops the oldest element of the key
+// with the most elements.
+func groupBudgetEvictsLargest(t *testing.T) {
+	g := newGroup(t, 10, 4)
+
+	g.Insert("small", 1)
+	g.Insert("big", 10)
+	g.Insert("big", 20)
+	g.Insert("big", 30)
+	g.Insert("small", 2) // big is the largest, loses 10
+
+	if got := g.Len(); got != 4 {
+		t.Errorf("want length 4, got %d", got)
+	}
+
+	if got := g.KeyLen("big"); got != 2 {
+		t.Errorf("want 2 elements in big, got %d", got)
+	}
+
+	assertGroupDrain(t, g, "small", 1, 2)
+	assertGroupDrain(t, g, "big", 20, 30)
+}
+
+// tests that when several keys have the most elements, the one with
+// the oldest element loses it.
+func groupBudgetTiesGoOldest(t *testing.T) {
+	g := newGroup(t, 10, 4)
+
+	g.Insert("b", 1)
+	g.Insert("a", 2)
+	g.Insert("a", 3)
+	g.Insert("b", 4)
+	g.Insert("c", 5) // a and b tie, b has the oldest element
+
+	assertGroupDrain(t, g, "a", 2, 3)
+	assertGroupDrain(t, g, "b", 4)
+	assertGroupDrain(t, g, "c", 5)
+}
+
+// tests that keys whose rings become empty are forgotten.
+func groupEmptyKeysForgotten(t *testing.T) {
+	g := newGroup(t, 10, 1)
+
+	g.Insert("a", 1)
+	g.Insert("b", 2) // evicts the only element of a
+
+	if got := fmt.Sprint(g.Keys()); got != "[b]" {
+		t.Errorf("want keys [b], got %s", got)
+	}
+
+	if got := g.KeyLen("a"); got != 0 {
+		t.Errorf("want 0 elements in a, got %d", got)
+	}
+
+	g.Extract("b")
+
+	if got := len(g.Keys()); got != 0 {
+		t.Errorf("want no keys, got %d", got)
+	}
+}
+
+// tests that extracting from a key never inserted reports an empty
+// ring.
+func groupExtractUnknownKey(t *testing.T) {
+	g := newGroup(t, 1, 1)
+
+	if _, ok := g.Extract("nope"); ok {
+		t.Error("unexpected successful extraction")
+	}
+}
+
+// tests that negative idle TTLs are invalid.
+func groupNegativeIdleTTL(t *testing.T) {
+	if _, err := ring.NewGroup(1, 1, ring.WithIdleTTL(-time.Second)); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that keys idle for longer than the TTL are evicted with their
+// elements, while active keys are kept.
+func groupIdleKeysEvicted(t *testing.T) {
+	const ttl = 50 * time.Millisecond
+
+	evicted := make(chan string, 10)
+
+	g, err := ring.NewGroup(10, 100,
+		ring.WithIdleTTL(ttl),
+		ring.WithOnKeyEvicted(func(key string, elements []interface{}) {
+			evicted <- fmt.Sprint(key, elements)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("creating group: %v", err)
+	}
+
+	defer g.Close()
+
+	g.Insert("idle", 1)
+	g.Insert("idle", 2)
+
+	// keep the other key active for several TTLs
+	for start := time.Now(); time.Since(start) < 3*ttl; {
+		g.Insert("active", 0)
+		g.Extract("active")
+		g.Insert("active", 0)
+		time.Sleep(ttl / 10)
+	}
+
+	select {
+	case got := <-evicted:
+		if want := "idle[1 2]"; got != want {
+			t.Errorf("want eviction %s, got %s", want, got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("idle key not evicted")
+	}
+
+	if got := fmt.Sprint(g.Keys()); got != "[active]" {
+		t.Errorf("want keys [active], got %s", got)
+	}
+
+	if got := g.Len(); got != g.KeyLen("active") {
+		t.Errorf("want length %d, got %d", g.KeyLen("active"), got)
+	}
+}
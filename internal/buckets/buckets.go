@@ -0,0 +1,96 @@
+// Package buckets counts events in a sliding window of time split in
+// buckets, for the drop history of the rings and the counters of
+// ringstats.
+package buckets
+
+import "time"
+
+// Window counts events in a ring of time buckets of the same width,
+// the newest one holding the current time.  It is not safe for
+// concurrent use.
+type Window struct {
+	width   time.Duration
+	buckets []int64 // counts per bucket, in a ring
+	newest  int64   // time slot of the newest bucket
+	total   int64   // sum of the buckets
+}
+
+// New returns a window of n buckets of the given width, which must be
+// greater than zero.
+func New(n int, width time.Duration) *Window {
+	return &Window{
+		width:   width,
+		buckets: make([]int64, n),
+	}
+}
+
+// returns the time slot of the bucket for t.
+func (w *Window) slot(t time.Time) int64 {
+	return t.UnixNano() / int64(w.width)
+}
+
+// returns the index of the bucket for the given time slot.
+func (w *Window) index(slot int64) int {
+	n := int64(len(w.buckets))
+	return int(((slot % n) + n) % n)
+}
+
+// rolls the buckets forward to the given time slot, forgetting the
+// ones out of the window.
+func (w *Window) advance(slot int64) {
+	if slot <= w.newest {
+		return
+	}
+
+	if slot-w.newest >= int64(len(w.buckets)) {
+		for i := range w.buckets {
+			w.buckets[i] = 0
+		}
+
+		w.total = 0
+		w.newest = slot
+
+		return
+	}
+
+	for w.newest < slot {
+		w.newest++
+		i := w.index(w.newest)
+		w.total -= w.buckets[i]
+		w.buckets[i] = 0
+	}
+}
+
+// Add adds n events happening at t.  Events older than the window are
+// ignored.
+func (w *Window) Add(t time.Time, n int64) {
+	slot := w.slot(t)
+	w.advance(slot)
+
+	if slot <= w.newest-int64(len(w.buckets)) {
+		return
+	}
+
+	w.buckets[w.index(slot)] += n
+	w.total += n
+}
+
+// Total returns the amount of events in the window ending at now.
+func (w *Window) Total(now time.Time) int64 {
+	w.advance(w.slot(now))
+
+	return w.total
+}
+
+// Each calls fn with the start time and the amount of events of each
+// bucket of the window ending at now, from oldest to newest.
+func (w *Window) Each(now time.Time, fn func(start time.Time, count int64)) {
+	w.advance(w.slot(now))
+
+	n := len(w.buckets)
+
+	for i := 0; i < n; i++ {
+		slot := w.newest - int64(n-1-i)
+		fn(time.Unix(0, slot*int64(w.width)), w.buckets[w.index(slot)])
+	}
+}
@@ -0,0 +1,97 @@
+package buckets_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring/internal/buckets"
+)
+
+func TestWindow(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"total":       windowTotal,
+		"rolls":       windowRolls,
+		"ignores old": windowIgnoresOld,
+		"each":        windowEach,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// an arbitrary instant at the start of a second.
+var epoch = time.Unix(1600000000, 0)
+
+// returns the instant s seconds after the epoch.
+func at(s int) time.Time {
+	return epoch.Add(time.Duration(s) * time.Second)
+}
+
+// tests that the total adds the events of all the buckets.
+func windowTotal(t *testing.T) {
+	w := buckets.New(3, time.Second)
+	w.Add(at(0), 1)
+	w.Add(at(1), 2)
+	w.Add(at(2), 3)
+
+	if got := w.Total(at(2)); got != 6 {
+		t.Errorf("want 6 events, got %d", got)
+	}
+}
+
+// tests that the buckets out of the window are forgotten.
+func windowRolls(t *testing.T) {
+	w := buckets.New(3, time.Second)
+	w.Add(at(0), 1)
+	w.Add(at(1), 2)
+
+	if got := w.Total(at(3)); got != 2 {
+		t.Errorf("want 2 events a bucket later, got %d", got)
+	}
+
+	if got := w.Total(at(10)); got != 0 {
+		t.Errorf("want no events after the window, got %d", got)
+	}
+}
+
+// tests that events older than the window are ignored.
+func windowIgnoresOld(t *testing.T) {
+	w := buckets.New(3, time.Second)
+	w.Add(at(5), 1)
+	w.Add(at(2), 10)
+	w.Add(at(3), 2)
+
+	if got := w.Total(at(5)); got != 3 {
+		t.Errorf("want 3 events, got %d", got)
+	}
+}
+
+// tests that the buckets are listed from oldest to newest.
+func windowEach(t *testing.T) {
+	w := buckets.New(3, time.Second)
+	w.Add(at(0), 1)
+	w.Add(at(2), 3)
+
+	var (
+		starts []time.Time
+		counts []int64
+	)
+
+	w.Each(at(2), func(start time.Time, count int64) {
+		starts = append(starts, start)
+		counts = append(counts, count)
+	})
+
+	for i, want := range []int64{1, 0, 3} {
+		if !starts[i].Equal(at(i)) || counts[i] != want {
+			t.Errorf("bucket %d: want %d events at %v, got %d at %v", i, want, at(i), counts[i], starts[i])
+		}
+	}
+}
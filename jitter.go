@@ -0,0 +1,293 @@
+package ring
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// JitterBuffer is a concurrent bounded buffer of elements carrying
+// sequence numbers, like the packets of an RTP stream, that releases
+// them in sequence order, whatever the order they arrive in, like a
+// jitter buffer does.
+//
+// Elements are released as soon as all the ones before them are.  When
+// elements are missing, the ones after them are held, waiting for them
+// to arrive, until the delay of the buffer passes since the arrival of
+// the first element held after the gap.  Then, the missing elements are
+// given up as lost, and the ones after them are released.  Elements
+// arriving after their turn, once given up or already released, are
+// discarded.
+//
+// The buffer holds the elements in a window of capacity sequence
+// numbers, starting with the next one to release.  An element beyond
+// the window moves it forward, dropping the elements left behind, as a
+// Ring drops its oldest elements when full.
+type JitterBuffer struct {
+	mu    sync.Mutex
+	slots []jitterSlot // by sequence number modulo the capacity
+	delay time.Duration
+	clock Clock
+
+	next    uint64 // sequence number of the next element to release
+	highest uint64 // highest sequence number held so far
+	started bool   // if next and highest are set, by the first insertion
+	len     int
+	stats   JitterStats
+	onGap   func(g Gap)
+}
+
+// an element held by a jitter buffer.
+type jitterSlot struct {
+	v       interface{}
+	seq     uint64
+	arrived time.Time
+	full    bool
+}
+
+// JitterOption configures a jitter buffer on construction.
+type JitterOption func(*JitterBuffer)
+
+// WithJitterClock makes the jitter buffer tell the time with c instead
+// of the time package, for its delay.  It is meant for tests advancing
+// the time deterministically.
+func WithJitterClock(c Clock) JitterOption {
+	return func(b *JitterBuffer) {
+		b.clock = c
+	}
+}
+
+// WithGapHook makes the jitter buffer call fn with each gap detected,
+// when an element is inserted with a sequence number more than one past
+// the highest one held so far, which tells of elements lost or delayed
+// upstream.  Gaps are only detected once, when they open, even
+// if they are partially filled later.  It is called after unlocking the
+// buffer, so fn can use it.
+func WithGapHook(fn func(g Gap)) JitterOption {
+	return func(b *JitterBuffer) {
+		b.onGap = fn
+	}
+}
+
+// Gap is a range of missing sequence numbers in a jitter buffer, from
+// From to To, excluded.
+type Gap struct {
+	From, To uint64
+}
+
+// JitterStats are the statistics of a jitter buffer.  Inserted elements
+// are either still held or accounted for, so Extracted + Late + Dropped
+// + Len = Inserted.
+type JitterStats struct {
+	Inserted  uint64 // elements inserted
+	Extracted uint64 // elements released by Extract
+	Late      uint64 // elements discarded for arriving after their turn, or twice
+	Dropped   uint64 // elements dropped by the window moving past them
+	Lost      uint64 // sequence numbers given up without an element
+	Len       int    // elements held
+}
+
+// NewJitterBuffer returns a new jitter buffer with a window of cap
+// sequence numbers, holding the elements after a missing one for up to
+// delay.  With a delay of 0, missing elements are given up right away,
+// releasing the ones after them.
+func NewJitterBuffer(cap int, delay time.Duration, opts ...JitterOption) (*JitterBuffer, error) {
+	if cap < 1 {
+		return nil, fmt.Errorf("jitter buffer capacity must be > 0, got %d", cap)
+	}
+
+	if delay < 0 {
+		return nil, fmt.Errorf("jitter buffer delay must be >= 0, got %v", delay)
+	}
+
+	b := &JitterBuffer{
+		slots: make([]jitterSlot, cap),
+		delay: delay,
+		clock: SystemClock{},
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b, nil
+}
+
+// Insert adds a new element with the given sequence number to the
+// buffer, and reports if it is held, which it is not if it is late,
+// see JitterBuffer.  The first element inserted sets the first sequence
+// number to release.
+func (b *JitterBuffer) Insert(seq uint64, v interface{}) bool {
+	held, gap, isGap := b.insert(seq, v)
+
+	if isGap && b.onGap != nil {
+		b.onGap(gap)
+	}
+
+	return held
+}
+
+// inserts v with the given sequence number, and returns if it is held,
+// and the gap it opens, if any.
+func (b *JitterBuffer) insert(seq uint64, v interface{}) (bool, Gap, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stats.Inserted++
+
+	if !b.started {
+		b.next = seq
+		b.highest = seq
+		b.started = true
+	}
+
+	if seq < b.next {
+		b.stats.Late++
+		return false, Gap{}, false
+	}
+
+	if n := uint64(len(b.slots)); seq-b.next >= n {
+		b.advance(seq - n + 1)
+	}
+
+	s := &b.slots[seq%uint64(len(b.slots))]
+	if s.full {
+		b.stats.Late++
+		return false, Gap{}, false
+	}
+
+	*s = jitterSlot{v: v, seq: seq, arrived: b.clock.Now(), full: true}
+	b.len++
+
+	var (
+		gap   Gap
+		isGap bool
+	)
+
+	if seq > b.highest {
+		gap, isGap = Gap{From: b.highest + 1, To: seq}, seq > b.highest+1
+		b.highest = seq
+	}
+
+	return true, gap, isGap
+}
+
+// moves the window forward to start at next, dropping the elements
+// before it and giving up on the missing ones.
+func (b *JitterBuffer) advance(next uint64) {
+	skipped := next - b.next
+
+	for i := range b.slots {
+		if s := &b.slots[i]; s.full && s.seq < next {
+			*s = jitterSlot{}
+			b.len--
+			b.stats.Dropped++
+			skipped--
+		}
+	}
+
+	b.stats.Lost += skipped
+	b.next = next
+}
+
+// Extract extracts and returns the next element in sequence order, and
+// its sequence number.  It returns false if there is no element to
+// release yet: the buffer is empty, or the next element is missing and
+// the delay of the ones after it is not over.
+func (b *JitterBuffer) Extract() (interface{}, uint64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.len == 0 {
+		return nil, 0, false
+	}
+
+	n := uint64(len(b.slots))
+
+	s := &b.slots[b.next%n]
+	if !s.full {
+		s = b.firstHeld()
+		if b.clock.Now().Sub(s.arrived) < b.delay {
+			return nil, 0, false
+		}
+
+		b.stats.Lost += s.seq - b.next
+		b.next = s.seq
+	}
+
+	v, seq := s.v, s.seq
+	*s = jitterSlot{}
+	b.len--
+	b.next++
+	b.stats.Extracted++
+
+	return v, seq, true
+}
+
+// returns the held element with the lowest sequence number, the buffer
+// must not be empty.
+func (b *JitterBuffer) firstHeld() *jitterSlot {
+	n := uint64(len(b.slots))
+
+	for seq := b.next; ; seq++ {
+		if s := &b.slots[seq%n]; s.full {
+			return s
+		}
+	}
+}
+
+// Gaps returns the gaps in the elements held by the buffer: the ranges
+// of sequence numbers missing before the highest one held, from the
+// next one to release, in order.
+func (b *JitterBuffer) Gaps() []Gap {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.started {
+		return nil
+	}
+
+	var (
+		result []Gap
+		n      = uint64(len(b.slots))
+	)
+
+	for seq := b.next; seq < b.highest; seq++ {
+		if b.slots[seq%n].full {
+			continue
+		}
+
+		if k := len(result); k > 0 && result[k-1].To == seq {
+			result[k-1].To++
+		} else {
+			result = append(result, Gap{From: seq, To: seq + 1})
+		}
+	}
+
+	return result
+}
+
+// Len returns the amount of elements held by the buffer.
+func (b *JitterBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.len
+}
+
+// Cap returns the size of the window of the buffer, in sequence
+// numbers.
+func (b *JitterBuffer) Cap() int {
+	return len(b.slots)
+}
+
+// Stats returns the statistics of the buffer.
+func (b *JitterBuffer) Stats() JitterStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	result := b.stats
+	result.Len = b.len
+
+	return result
+}
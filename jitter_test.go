@@ -0,0 +1,272 @@
+package ring_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestJitterBuffer(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid arguments": jitterInvalidArguments,
+		"empty buffer":      jitterEmptyBuffer,
+		"in order":          jitterInOrder,
+		"reorders":          jitterReorders,
+		"holds gaps":        jitterHoldsGaps,
+		"zero delay":        jitterZeroDelay,
+		"late elements":     jitterLateElements,
+		"moves the window":  jitterMovesTheWindow,
+		"jumps":             jitterJumps,
+		"gaps":              jitterGaps,
+		"gap hook":          jitterGapHook,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a new jitter buffer with a manual clock, or fails the test.
+func newJitter(t *testing.T, cap int, delay time.Duration) (*ring.JitterBuffer, *ringtest.Clock) {
+	t.Helper()
+
+	clock := ringtest.NewClock(time.Unix(0, 0))
+
+	b, err := ring.NewJitterBuffer(cap, delay, ring.WithJitterClock(clock))
+	if err != nil {
+		t.Fatalf("creating jitter buffer: %v", err)
+	}
+
+	return b, clock
+}
+
+// inserts elements whose values are their sequence numbers.
+func insertSeqs(b *ring.JitterBuffer, seqs ...uint64) {
+	for _, seq := range seqs {
+		b.Insert(seq, int(seq))
+	}
+}
+
+// asserts the sequence numbers of the elements released by Extract,
+// until it returns false.
+func assertReleased(t *testing.T, b *ring.JitterBuffer, want string) {
+	t.Helper()
+
+	var got []uint64
+
+	for {
+		v, seq, ok := b.Extract()
+		if !ok {
+			break
+		}
+
+		if v != int(seq) {
+			t.Fatalf("element %v released with sequence number %d", v, seq)
+		}
+
+		got = append(got, seq)
+	}
+
+	if fmt.Sprint(got) != want {
+		t.Errorf("want %s released, got %v", want, got)
+	}
+}
+
+// asserts the statistics of b, formatted with %+v.
+func assertJitterStats(t *testing.T, b *ring.JitterBuffer, want string) {
+	t.Helper()
+
+	if got := fmt.Sprintf("%+v", b.Stats()); got != want {
+		t.Errorf("wrong stats\nwant %s\n got %s", want, got)
+	}
+}
+
+// tests that invalid capacities and delays are rejected.
+func jitterInvalidArguments(t *testing.T) {
+	if _, err := ring.NewJitterBuffer(0, time.Second); err == nil {
+		t.Error("capacity 0: unexpected success")
+	}
+
+	if _, err := ring.NewJitterBuffer(1, -time.Second); err == nil {
+		t.Error("negative delay: unexpected success")
+	}
+}
+
+// tests that empty buffers release nothing.
+func jitterEmptyBuffer(t *testing.T) {
+	b, _ := newJitter(t, 4, time.Second)
+
+	assertReleased(t, b, "[]")
+
+	if b.Len() != 0 || b.Cap() != 4 {
+		t.Errorf("want length 0 and capacity 4, got %d and %d", b.Len(), b.Cap())
+	}
+}
+
+// tests that elements inserted in order are released right away.
+func jitterInOrder(t *testing.T) {
+	b, _ := newJitter(t, 4, time.Second)
+
+	insertSeqs(b, 10, 11, 12)
+	assertReleased(t, b, "[10 11 12]")
+
+	insertSeqs(b, 13)
+	assertReleased(t, b, "[13]")
+}
+
+// tests that elements inserted out of order are released in order.
+func jitterReorders(t *testing.T) {
+	b, _ := newJitter(t, 4, time.Second)
+
+	insertSeqs(b, 10, 12, 13)
+	assertReleased(t, b, "[10]")
+
+	insertSeqs(b, 11)
+	assertReleased(t, b, "[11 12 13]")
+	assertJitterStats(t, b, "{Inserted:4 Extracted:4 Late:0 Dropped:0 Lost:0 Len:0}")
+}
+
+// tests that the elements after a gap are held for the delay, and then
+// released, giving up on the missing ones.
+func jitterHoldsGaps(t *testing.T) {
+	b, clock := newJitter(t, 8, time.Second)
+
+	insertSeqs(b, 10)
+	assertReleased(t, b, "[10]")
+
+	insertSeqs(b, 13)
+	clock.Advance(500 * time.Millisecond)
+	insertSeqs(b, 14)
+	assertReleased(t, b, "[]")
+
+	clock.Advance(500 * time.Millisecond)
+	assertReleased(t, b, "[13 14]")
+	assertJitterStats(t, b, "{Inserted:3 Extracted:3 Late:0 Dropped:0 Lost:2 Len:0}")
+}
+
+// tests that without delay, gaps are given up right away.
+func jitterZeroDelay(t *testing.T) {
+	b, _ := newJitter(t, 4, 0)
+
+	insertSeqs(b, 10, 12)
+	assertReleased(t, b, "[10 12]")
+}
+
+// tests that elements arriving after their turn, or twice, are
+// discarded.
+func jitterLateElements(t *testing.T) {
+	b, _ := newJitter(t, 4, 0)
+
+	insertSeqs(b, 10, 12)
+	assertReleased(t, b, "[10 12]")
+
+	if b.Insert(11, 11) || b.Insert(10, 10) {
+		t.Error("late element held")
+	}
+
+	b.Insert(13, 13)
+
+	if b.Insert(13, 13) {
+		t.Error("duplicated element held")
+	}
+
+	assertReleased(t, b, "[13]")
+	assertJitterStats(t, b, "{Inserted:6 Extracted:3 Late:3 Dropped:0 Lost:1 Len:0}")
+}
+
+// tests that elements beyond the window move it forward, dropping the
+// elements left behind.
+func jitterMovesTheWindow(t *testing.T) {
+	b, clock := newJitter(t, 4, time.Hour)
+
+	insertSeqs(b, 10, 11, 13)
+	assertJitterStats(t, b, "{Inserted:3 Extracted:0 Late:0 Dropped:0 Lost:0 Len:3}")
+
+	insertSeqs(b, 15) // drops 10 and 11
+	assertJitterStats(t, b, "{Inserted:4 Extracted:0 Late:0 Dropped:2 Lost:0 Len:2}")
+	assertReleased(t, b, "[]")
+
+	clock.Advance(time.Hour)
+	assertReleased(t, b, "[13 15]")
+	assertJitterStats(t, b, "{Inserted:4 Extracted:2 Late:0 Dropped:2 Lost:2 Len:0}")
+}
+
+// tests that sequence numbers far beyond the window drop all the
+// elements held, giving up on the ones skipped.
+func jitterJumps(t *testing.T) {
+	b, clock := newJitter(t, 4, time.Hour)
+
+	insertSeqs(b, 11, 12)
+	insertSeqs(b, 1000)
+	assertJitterStats(t, b, "{Inserted:3 Extracted:0 Late:0 Dropped:2 Lost:984 Len:1}")
+
+	clock.Advance(time.Hour)
+	assertReleased(t, b, "[1000]")
+	assertJitterStats(t, b, "{Inserted:3 Extracted:1 Late:0 Dropped:2 Lost:987 Len:0}")
+}
+
+// tests that Gaps returns the ranges of missing sequence numbers
+// before the highest one held.
+func jitterGaps(t *testing.T) {
+	b, clock := newJitter(t, 16, time.Second)
+
+	if got := b.Gaps(); got != nil {
+		t.Errorf("empty buffer: want no gaps, got %v", got)
+	}
+
+	insertSeqs(b, 10, 13, 14, 17, 20)
+
+	if got, want := fmt.Sprint(b.Gaps()), "[{11 13} {15 17} {18 20}]"; got != want {
+		t.Errorf("want gaps %s, got %s", want, got)
+	}
+
+	insertSeqs(b, 11, 16)
+	assertReleased(t, b, "[10 11]")
+
+	if got, want := fmt.Sprint(b.Gaps()), "[{12 13} {15 16} {18 20}]"; got != want {
+		t.Errorf("want gaps %s, got %s", want, got)
+	}
+
+	clock.Advance(time.Second)
+	assertReleased(t, b, "[13 14 16 17 20]")
+
+	if got := b.Gaps(); got != nil {
+		t.Errorf("drained buffer: want no gaps, got %v", got)
+	}
+}
+
+// tests that the gap hook is called with each new gap, once, and that
+// it can use the buffer.
+func jitterGapHook(t *testing.T) {
+	clock := ringtest.NewClock(time.Unix(0, 0))
+
+	var (
+		b   *ring.JitterBuffer
+		got []ring.Gap
+	)
+
+	hook := func(g ring.Gap) {
+		got = append(got, g)
+		_ = b.Len()
+	}
+
+	b, err := ring.NewJitterBuffer(4, time.Second, ring.WithJitterClock(clock), ring.WithGapHook(hook))
+	if err != nil {
+		t.Fatalf("creating jitter buffer: %v", err)
+	}
+
+	insertSeqs(b, 10, 11, 13, 12, 12, 16, 9, 100)
+
+	if want := "[{12 13} {14 16} {17 100}]"; fmt.Sprint(got) != want {
+		t.Errorf("want gaps %s, got %v", want, got)
+	}
+}
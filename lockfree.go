@@ -0,0 +1,46 @@
+package ring
+
+// WithLockFreeReads makes Peek, Len and Stats read a snapshot of the
+// ring published by its writers, instead of locking it, so they never
+// block nor contend with the writers, for example when monitoring many
+// busy rings.
+//
+// The writers pay for it, allocating a new snapshot on each operation.
+// The lengths and the oldest element in each snapshot are taken at the
+// same time, but they may lag behind the writers, as if read just
+// before the concurrent operations.  The counters of Stats are read as
+// usual, see Stats.
+func WithLockFreeReads() Option {
+	return func(c *config) {
+		c.lockFreeReads = true
+	}
+}
+
+// a view of the ring for lock-free reads, see WithLockFreeReads.
+type readSnapshot struct {
+	stats Stats       // without the counters, read atomically, see Stats
+	head  interface{} // the oldest element, if stats.Len > 0
+}
+
+// unlocks the ring, after publishing a new snapshot for the lock-free
+// reads, if needed.  The methods modifying the ring must unlock it with
+// it.
+func (r *Ring) unlock() {
+	if r.lockFree {
+		r.publish()
+	}
+
+	r.mu.Unlock()
+}
+
+// publishes a new snapshot of the ring, which must be locked, for the
+// lock-free reads.
+func (r *Ring) publish() {
+	s := &readSnapshot{stats: r.stats()}
+
+	if r.len > 0 {
+		s.head = r.buf.get(r.head)
+	}
+
+	r.reads.Store(s)
+}
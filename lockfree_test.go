@@ -0,0 +1,188 @@
+package ring_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestLockFreeReads(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"reads follow writes":   lockFreeReadsFollowWrites,
+		"reads do not block":    lockFreeReadsDoNotBlock,
+		"consistent snapshots":  lockFreeConsistentSnapshots,
+		"zero value is locking": lockFreeZeroValue,
+		"linearizable":          lockFreeLinearizable,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// asserts the length, oldest element and counters seen by the reads.
+func assertReads(t *testing.T, r *ring.Ring, want string) {
+	t.Helper()
+
+	v, ok := r.Peek()
+	s := r.Stats()
+
+	got := fmt.Sprintf("len=%d peek=%v,%t inserted=%d extracted=%d dropped=%d peak=%d",
+		r.Len(), v, ok, s.Inserted, s.Extracted, s.Dropped, s.PeakLen)
+	if got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+// tests that the reads see the changes of all the ways of writing.
+func lockFreeReadsFollowWrites(t *testing.T) {
+	r, err := ring.New(2, ring.WithLockFreeReads(), ring.WithTimestamps())
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	assertReads(t, r, "len=0 peek=<nil>,false inserted=0 extracted=0 dropped=0 peak=0")
+
+	r.Insert(1)
+	r.InsertContext(context.Background(), 2)
+	r.Insert(3)
+	assertReads(t, r, "len=2 peek=2,true inserted=3 extracted=0 dropped=1 peak=2")
+
+	r.Extract()
+	assertReads(t, r, "len=1 peek=3,true inserted=3 extracted=1 dropped=1 peak=2")
+
+	r.ResetDropped()
+	r.ResetPeakLen()
+	assertReads(t, r, "len=1 peek=3,true inserted=3 extracted=1 dropped=0 peak=1")
+
+	if _, err := r.ExtractContext(context.Background()); err != nil {
+		t.Fatalf("extracting: %v", err)
+	}
+
+	assertReads(t, r, "len=0 peek=<nil>,false inserted=3 extracted=2 dropped=0 peak=1")
+
+	r.Insert(4)
+	r.ExtractWithCount()
+	r.Insert(5)
+	r.ExtractOlderThan(time.Now().Add(time.Hour))
+	assertReads(t, r, "len=0 peek=<nil>,false inserted=5 extracted=4 dropped=0 peak=1")
+
+	r.Insert(6)
+	r.Clear()
+	assertReads(t, r, "len=0 peek=<nil>,false inserted=6 extracted=4 dropped=0 peak=1")
+}
+
+// tests that the reads return while a writer holds the ring locked.
+func lockFreeReadsDoNotBlock(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	// the comparison of coalescing rings runs with the ring locked
+	r, err := ring.New(3, ring.WithLockFreeReads(), ring.WithCoalesce(func(a, b interface{}) bool {
+		if b == "slow" {
+			close(entered)
+			<-release
+		}
+
+		return a == b
+	}))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	r.Insert(1)
+
+	go r.Insert("slow")
+
+	<-entered
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		// the blocked insertion is already counted, see Stats
+		assertReads(t, r, "len=1 peek=1,true inserted=2 extracted=0 dropped=0 peak=1")
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("reads blocked by the writer")
+	}
+
+	close(release)
+	<-done
+}
+
+// tests that the lengths and counters of each snapshot are consistent
+// with each other under concurrent writes.
+func lockFreeConsistentSnapshots(t *testing.T) {
+	r, err := ring.New(8, ring.WithLockFreeReads())
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < 2; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 1000; i++ {
+				r.Insert(i)
+
+				if i%3 == 0 {
+					r.Extract()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 1000; i++ {
+		s := r.Stats()
+
+		if s.Len > s.Cap || s.Extracted+s.Dropped+uint64(s.Len) > s.Inserted {
+			t.Fatalf("inconsistent stats %+v", s)
+		}
+
+		if v, ok := r.Peek(); ok && v == nil {
+			t.Fatal("peeked nil")
+		}
+	}
+
+	wg.Wait()
+}
+
+// tests that rings without the option, like the zero one, still work.
+func lockFreeZeroValue(t *testing.T) {
+	var r ring.Ring
+
+	r.Insert(1)
+	assertReads(t, &r, "len=1 peek=1,true inserted=1 extracted=0 dropped=0 peak=1")
+}
+
+// tests that rings with lock-free reads are linearizable, with the
+// reads of Peek and Len racing the writers.
+func lockFreeLinearizable(t *testing.T) {
+	ringtest.AssertLinearizable(t, func() ring.Buffer {
+		r, err := ring.New(3, ring.WithLockFreeReads())
+		if err != nil {
+			t.Fatalf("creating ring: %v", err)
+		}
+
+		return r
+	}, 50)
+}
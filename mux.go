@@ -0,0 +1,192 @@
+package ring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// MuxPolicy decides which ring a Mux extracts from.
+type MuxPolicy int
+
+const (
+	// RoundRobin extracts from each non-empty ring in turn.
+	RoundRobin MuxPolicy = iota
+	// OldestFirst extracts the element inserted first among the oldest
+	// elements of all the rings, merging them by insertion time.  All
+	// the rings must be created with the WithTimestamps option.
+	OldestFirst
+)
+
+// Mux merges several rings into a single stream of elements, for
+// example to consume the rings of several producers from a single
+// goroutine.
+//
+// Elements are extracted from the rings as if calling Extract on them,
+// so they are counted as extracted and notified to their observers.
+type Mux struct {
+	mu     sync.Mutex
+	rings  []*Ring
+	policy MuxPolicy
+	next   int // ring to try first, for RoundRobin
+}
+
+// NewMux returns a new mux extracting from the given rings with the
+// given policy.
+func NewMux(policy MuxPolicy, rings ...*Ring) (*Mux, error) {
+	if len(rings) == 0 {
+		return nil, errors.New("mux needs at least one ring")
+	}
+
+	switch policy {
+	case RoundRobin:
+	case OldestFirst:
+		for i, r := range rings {
+			if r.times == nil {
+				return nil, fmt.Errorf("ring %d has no timestamps, required by OldestFirst", i)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown mux policy %d", policy)
+	}
+
+	return &Mux{
+		rings:  append([]*Ring(nil), rings...),
+		policy: policy,
+	}, nil
+}
+
+// Extract extracts and returns an element from one of the rings, as
+// chosen by the policy of the mux.  It returns false if all the rings
+// are empty.
+func (m *Mux) Extract() (interface{}, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.policy == OldestFirst {
+		return m.extractOldest()
+	}
+
+	return m.extractNext()
+}
+
+// extracts from the first non-empty ring starting at the next one.
+func (m *Mux) extractNext() (interface{}, bool) {
+	for n := 0; n < len(m.rings); n++ {
+		i := (m.next + n) % len(m.rings)
+
+		if v, ok := m.rings[i].Extract(); ok {
+			m.next = (i + 1) % len(m.rings)
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+// extracts from the ring with the oldest element.
+func (m *Mux) extractOldest() (interface{}, bool) {
+	for {
+		var (
+			oldest     *Ring
+			oldestTime time.Time
+		)
+
+		for _, r := range m.rings {
+			t, ok := r.oldestTime()
+			if ok && (oldest == nil || t.Before(oldestTime)) {
+				oldest, oldestTime = r, t
+			}
+		}
+
+		if oldest == nil {
+			return nil, false
+		}
+
+		// the element could have been extracted by someone else in the
+		// meantime, in that case, look again
+		if v, ok := oldest.Extract(); ok {
+			return v, true
+		}
+	}
+}
+
+// ExtractContext works like Extract, but if all the rings are empty, it
+// waits for an element to be inserted in any of them.  It returns the
+// context error if ctx is done before that.
+func (m *Mux) ExtractContext(ctx context.Context) (interface{}, error) {
+	return extractContext(ctx, m.rings, m.Extract)
+}
+
+// calls extract until it succeeds, waiting for an element to be
+// inserted in any of the rings after each failure.  It returns the
+// context error if ctx is done before that.
+func extractContext(ctx context.Context, rings []*Ring, extract func() (interface{}, bool)) (interface{}, error) {
+	cases := make([]reflect.SelectCase, len(rings)+1)
+	cases[0] = reflect.SelectCase{
+		Dir:  reflect.SelectRecv,
+		Chan: reflect.ValueOf(ctx.Done()),
+	}
+
+	for {
+		if v, ok := extract(); ok {
+			return v, nil
+		}
+
+		empty := true
+
+		for i, r := range rings {
+			ready := r.readyIfEmpty()
+			if ready == nil {
+				empty = false
+				break
+			}
+
+			cases[i+1] = reflect.SelectCase{
+				Dir:  reflect.SelectRecv,
+				Chan: reflect.ValueOf(ready),
+			}
+		}
+
+		if !empty {
+			continue
+		}
+
+		if chosen, _, _ := reflect.Select(cases); chosen == 0 {
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// returns the insertion time of the oldest element in the ring.  It
+// returns false if the ring is empty.
+func (r *Ring) oldestTime() (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		return time.Time{}, false
+	}
+
+	return r.times[r.head], true
+}
+
+// returns a channel closed when an element is inserted, or nil if the
+// ring is not empty.
+func (r *Ring) readyIfEmpty() chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len > 0 {
+		return nil
+	}
+
+	if r.ready == nil {
+		r.ready = make(chan struct{})
+	}
+
+	return r.ready
+}
@@ -0,0 +1,146 @@
+package ring_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestMux(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid arguments": muxInvalidArguments,
+		"round robin":       muxRoundRobin,
+		"oldest first":      muxOldestFirst,
+		"extract waits":     muxExtractWaits,
+		"extract cancelled": muxExtractCancelled,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a mux of the given rings or fails the test.
+func newMux(t *testing.T, policy ring.MuxPolicy, rings ...*ring.Ring) *ring.Mux {
+	t.Helper()
+
+	m, err := ring.NewMux(policy, rings...)
+	if err != nil {
+		t.Fatalf("creating mux: %v", err)
+	}
+
+	return m
+}
+
+// asserts that extracting from the mux returns the wanted elements and
+// then nothing.
+func assertMuxDrain(t *testing.T, m *ring.Mux, want string) {
+	t.Helper()
+
+	var got []interface{}
+
+	for {
+		v, ok := m.Extract()
+		if !ok {
+			break
+		}
+
+		got = append(got, v)
+	}
+
+	if fmt.Sprint(got) != want {
+		t.Errorf("want to extract %s, got %v", want, got)
+	}
+}
+
+// tests that muxes need rings, a known policy, and timestamps for
+// OldestFirst.
+func muxInvalidArguments(t *testing.T) {
+	if _, err := ring.NewMux(ring.RoundRobin); err == nil {
+		t.Error("no rings: unexpected success")
+	}
+
+	if _, err := ring.NewMux(ring.MuxPolicy(42), newRing(t, 1)); err == nil {
+		t.Error("unknown policy: unexpected success")
+	}
+
+	if _, err := ring.NewMux(ring.OldestFirst, newRing(t, 1)); err == nil {
+		t.Error("no timestamps: unexpected success")
+	}
+}
+
+// tests that round robin muxes extract from each non-empty ring in
+// turn.
+func muxRoundRobin(t *testing.T) {
+	a := newRing(t, 5, "a1", "a2", "a3")
+	b := newRing(t, 5)
+	c := newRing(t, 5, "c1")
+
+	assertMuxDrain(t, newMux(t, ring.RoundRobin, a, b, c), "[a1 c1 a2 a3]")
+}
+
+// tests that oldest first muxes merge the rings by insertion time.
+func muxOldestFirst(t *testing.T) {
+	var rings [2]*ring.Ring
+
+	for i := range rings {
+		r, err := ring.New(5, ring.WithTimestamps())
+		if err != nil {
+			t.Fatalf("creating ring: %v", err)
+		}
+
+		rings[i] = r
+	}
+
+	for _, i := range []int{1, 0, 0, 1} {
+		rings[i].Insert(i)
+		time.Sleep(time.Millisecond)
+	}
+
+	assertMuxDrain(t, newMux(t, ring.OldestFirst, rings[:]...), "[1 0 0 1]")
+}
+
+// tests that extracting from empty rings waits for an insertion in any
+// of them.
+func muxExtractWaits(t *testing.T) {
+	a, b := newRing(t, 2), newRing(t, 2)
+	m := newMux(t, ring.RoundRobin, a, b)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		b.Insert(1)
+	}()
+
+	v, err := m.ExtractContext(context.Background())
+	if err != nil || v != 1 {
+		t.Fatalf("want to extract 1, got %v, %v", v, err)
+	}
+}
+
+// tests that waiting for an element stops when the context is done.
+func muxExtractCancelled(t *testing.T) {
+	a, b := newRing(t, 2), newRing(t, 2)
+	m := newMux(t, ring.RoundRobin, a, b)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := m.ExtractContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded error, got %v", err)
+	}
+
+	// the rings still work after an abandoned wait
+	a.Insert(1)
+	assertPeek(t, a, 1)
+}
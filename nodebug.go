@@ -0,0 +1,7 @@
+//go:build !ringdebug
+// +build !ringdebug
+
+package ring
+
+// does nothing without the ringdebug build tag, see debug.go.
+func (r *Ring) checkInvariants() {}
@@ -0,0 +1,446 @@
+package ring
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Ints is a concurrent bounded circular buffer of ints.  It works like
+// Ring, but stores its elements unboxed, so inserting them does not
+// allocate.
+//
+// It also keeps track of the sum, variance, minimum and maximum of its
+// elements as they are inserted and extracted, so querying them takes
+// constant time.
+type Ints struct {
+	mu   sync.Mutex
+	buf  []int // elements storage
+	len  int   // how many elements are stored in the ring
+	head int   // index of the next element to be extracted
+
+	seq     uint64   // sequence number of the next element to be inserted
+	sum     int      // sum of the elements, it may overflow
+	moments moments  // for the variance of the elements
+	mins    seqDeque // candidates for the minimum
+	maxs    seqDeque // candidates for the maximum
+}
+
+// NewInts returns a new int ring with the given capacity.
+func NewInts(cap int) (*Ints, error) {
+	if cap < 1 {
+		return nil, fmt.Errorf("ring capacity must be > 0, got %d", cap)
+	}
+
+	return &Ints{
+		buf:  make([]int, cap),
+		mins: newSeqDeque(cap),
+		maxs: newSeqDeque(cap),
+	}, nil
+}
+
+// Insert adds a new element to the ring. If the ring is already at
+// maximum capacity, the oldest element is dropped to make room for the
+// new one.
+func (r *Ints) Insert(v int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == cap(r.buf) {
+		_, _ = r.extract()
+	}
+
+	for !r.mins.empty() && r.at(r.mins.back()) >= v {
+		r.mins.popBack()
+	}
+
+	for !r.maxs.empty() && r.at(r.maxs.back()) <= v {
+		r.maxs.popBack()
+	}
+
+	r.mins.push(r.seq)
+	r.maxs.push(r.seq)
+
+	r.buf[(r.head+r.len)%cap(r.buf)] = v
+	r.len++
+	r.seq++
+	r.sum += v
+	r.moments.add(float64(v))
+}
+
+// returns the element with the given sequence number, which must be in
+// the ring.
+func (r *Ints) at(seq uint64) int {
+	oldest := r.seq - uint64(r.len)
+	return r.buf[(r.head+int(seq-oldest))%cap(r.buf)]
+}
+
+// Extract extracts and returns the oldest element in the ring.
+func (r *Ints) Extract() (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.extract()
+}
+
+func (r *Ints) extract() (int, bool) {
+	if r.len == 0 {
+		return 0, false
+	}
+
+	oldest := r.seq - uint64(r.len)
+
+	if r.mins.front() == oldest {
+		r.mins.popFront()
+	}
+
+	if r.maxs.front() == oldest {
+		r.maxs.popFront()
+	}
+
+	result := r.buf[r.head]
+	r.head = (r.head + 1) % cap(r.buf)
+	r.len--
+	r.sum -= result
+	r.moments.remove(float64(result))
+
+	// recompute the moments once per lap around the buffer, so rounding
+	// errors do not accumulate; the sum is exact
+	if r.head == 0 {
+		r.moments = moments{}
+
+		for i := 0; i < r.len; i++ {
+			r.moments.add(float64(r.buf[(r.head+i)%cap(r.buf)]))
+		}
+	}
+
+	return result, true
+}
+
+// Peek returns the oldest element in the ring.
+func (r *Ints) Peek() (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		return 0, false
+	}
+
+	return r.buf[r.head], true
+}
+
+// Len returns the amount of elements in the ring.
+func (r *Ints) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.len
+}
+
+// Cap returns the capacity of the ring.
+func (r *Ints) Cap() int {
+	return cap(r.buf)
+}
+
+// ToSlice returns the elements in the ring, from oldest to newest,
+// without extracting them.
+func (r *Ints) ToSlice() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]int, r.len)
+	for i := range result {
+		result[i] = r.buf[(r.head+i)%cap(r.buf)]
+	}
+
+	return result
+}
+
+// Float64s is a concurrent bounded circular buffer of float64s.  It
+// works like Ring, but stores its elements unboxed, so inserting them
+// does not allocate.
+//
+// It also keeps track of the sum, variance, minimum and maximum of its
+// elements as they are inserted and extracted, so querying them takes
+// constant time.  NaN elements make the minimum and maximum
+// meaningless.
+type Float64s struct {
+	mu   sync.Mutex
+	buf  []float64 // elements storage
+	len  int       // how many elements are stored in the ring
+	head int       // index of the next element to be extracted
+
+	seq     uint64   // sequence number of the next element to be inserted
+	sum     float64  // sum of the elements
+	moments moments  // for the variance of the elements
+	mins    seqDeque // candidates for the minimum
+	maxs    seqDeque // candidates for the maximum
+}
+
+// NewFloat64s returns a new float64 ring with the given capacity.
+func NewFloat64s(cap int) (*Float64s, error) {
+	if cap < 1 {
+		return nil, fmt.Errorf("ring capacity must be > 0, got %d", cap)
+	}
+
+	return &Float64s{
+		buf:  make([]float64, cap),
+		mins: newSeqDeque(cap),
+		maxs: newSeqDeque(cap),
+	}, nil
+}
+
+// Insert adds a new element to the ring. If the ring is already at
+// maximum capacity, the oldest element is dropped to make room for the
+// new one.
+func (r *Float64s) Insert(v float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == cap(r.buf) {
+		_, _ = r.extract()
+	}
+
+	for !r.mins.empty() && r.at(r.mins.back()) >= v {
+		r.mins.popBack()
+	}
+
+	for !r.maxs.empty() && r.at(r.maxs.back()) <= v {
+		r.maxs.popBack()
+	}
+
+	r.mins.push(r.seq)
+	r.maxs.push(r.seq)
+
+	r.buf[(r.head+r.len)%cap(r.buf)] = v
+	r.len++
+	r.seq++
+	r.sum += v
+	r.moments.add(v)
+}
+
+// returns the element with the given sequence number, which must be in
+// the ring.
+func (r *Float64s) at(seq uint64) float64 {
+	oldest := r.seq - uint64(r.len)
+	return r.buf[(r.head+int(seq-oldest))%cap(r.buf)]
+}
+
+// Extract extracts and returns the oldest element in the ring.
+func (r *Float64s) Extract() (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.extract()
+}
+
+func (r *Float64s) extract() (float64, bool) {
+	if r.len == 0 {
+		return 0, false
+	}
+
+	oldest := r.seq - uint64(r.len)
+
+	if r.mins.front() == oldest {
+		r.mins.popFront()
+	}
+
+	if r.maxs.front() == oldest {
+		r.maxs.popFront()
+	}
+
+	result := r.buf[r.head]
+	r.head = (r.head + 1) % cap(r.buf)
+	r.len--
+	r.sum -= result
+	r.moments.remove(result)
+
+	// recompute the sum and moments once per lap around the buffer, so
+	// rounding errors do not accumulate, and after dropping infinities
+	// or NaNs, so they do not linger in them
+	if r.head == 0 || math.IsInf(result, 0) || math.IsNaN(result) {
+		r.sum = 0
+		r.moments = moments{}
+
+		for i := 0; i < r.len; i++ {
+			v := r.buf[(r.head+i)%cap(r.buf)]
+			r.sum += v
+			r.moments.add(v)
+		}
+	}
+
+	return result, true
+}
+
+// Peek returns the oldest element in the ring.
+func (r *Float64s) Peek() (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		return 0, false
+	}
+
+	return r.buf[r.head], true
+}
+
+// Len returns the amount of elements in the ring.
+func (r *Float64s) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.len
+}
+
+// Cap returns the capacity of the ring.
+func (r *Float64s) Cap() int {
+	return cap(r.buf)
+}
+
+// ToSlice returns the elements in the ring, from oldest to newest,
+// without extracting them.
+func (r *Float64s) ToSlice() []float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]float64, r.len)
+	for i := range result {
+		result[i] = r.buf[(r.head+i)%cap(r.buf)]
+	}
+
+	return result
+}
+
+// Sum returns the sum of the elements in the ring.
+func (r *Ints) Sum() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.sum
+}
+
+// Mean returns the arithmetic mean of the elements in the ring.  It
+// returns false if the ring is empty.
+func (r *Ints) Mean() (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		return 0, false
+	}
+
+	return float64(r.sum) / float64(r.len), true
+}
+
+// Min returns the smallest element in the ring.  It returns false if
+// the ring is empty.
+func (r *Ints) Min() (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		return 0, false
+	}
+
+	return r.at(r.mins.front()), true
+}
+
+// Max returns the biggest element in the ring.  It returns false if the
+// ring is empty.
+func (r *Ints) Max() (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		return 0, false
+	}
+
+	return r.at(r.maxs.front()), true
+}
+
+// Sum returns the sum of the elements in the ring.
+func (r *Float64s) Sum() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.sum
+}
+
+// Mean returns the arithmetic mean of the elements in the ring.  It
+// returns false if the ring is empty.
+func (r *Float64s) Mean() (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		return 0, false
+	}
+
+	return r.sum / float64(r.len), true
+}
+
+// Min returns the smallest element in the ring.  It returns false if
+// the ring is empty.
+func (r *Float64s) Min() (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		return 0, false
+	}
+
+	return r.at(r.mins.front()), true
+}
+
+// Max returns the biggest element in the ring.  It returns false if the
+// ring is empty.
+func (r *Float64s) Max() (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		return 0, false
+	}
+
+	return r.at(r.maxs.front()), true
+}
+
+// Variance returns the population variance of the elements in the
+// ring.  It returns false if the ring is empty.
+func (r *Ints) Variance() (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		return 0, false
+	}
+
+	return r.moments.variance(), true
+}
+
+// StdDev returns the population standard deviation of the elements in
+// the ring.  It returns false if the ring is empty.
+func (r *Ints) StdDev() (float64, bool) {
+	v, ok := r.Variance()
+	return math.Sqrt(v), ok
+}
+
+// Variance returns the population variance of the elements in the
+// ring.  It returns false if the ring is empty.
+func (r *Float64s) Variance() (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		return 0, false
+	}
+
+	return r.moments.variance(), true
+}
+
+// StdDev returns the population standard deviation of the elements in
+// the ring.  It returns false if the ring is empty.
+func (r *Float64s) StdDev() (float64, bool) {
+	v, ok := r.Variance()
+	return math.Sqrt(v), ok
+}
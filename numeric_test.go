@@ -0,0 +1,336 @@
+package ring_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestNumeric(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid capacity": numericInvalidCapacity,
+		"ints":             numericInts,
+		"float64s":         numericFloat64s,
+		"empty stats":      numericEmptyStats,
+		"ints stats":       numericIntsStats,
+		"float64s stats":   numericFloat64sStats,
+		"variance":         numericVariance,
+		"variance drift":   numericVarianceDrift,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns an int ring with the given capacity and elements or fails
+// the test.
+func newInts(t *testing.T, cap int, vs ...int) *ring.Ints {
+	t.Helper()
+
+	r, err := ring.NewInts(cap)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	for _, v := range vs {
+		r.Insert(v)
+	}
+
+	return r
+}
+
+// returns a float64 ring with the given capacity and elements or fails
+// the test.
+func newFloat64s(t *testing.T, cap int, vs ...float64) *ring.Float64s {
+	t.Helper()
+
+	r, err := ring.NewFloat64s(cap)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	for _, v := range vs {
+		r.Insert(v)
+	}
+
+	return r
+}
+
+// tests that capacities smaller than 1 are invalid.
+func numericInvalidCapacity(t *testing.T) {
+	for _, cap := range []int{0, -1} {
+		if _, err := ring.NewInts(cap); err == nil {
+			t.Errorf("unexpected success creating ints with cap %d", cap)
+		}
+
+		if _, err := ring.NewFloat64s(cap); err == nil {
+			t.Errorf("unexpected success creating float64s with cap %d", cap)
+		}
+	}
+}
+
+// tests that int rings keep the newest elements in order.
+func numericInts(t *testing.T) {
+	r := newInts(t, 3, 1, 2, 3, 4)
+
+	if got := fmt.Sprint(r.ToSlice()); got != "[2 3 4]" {
+		t.Errorf("want contents [2 3 4], got %s", got)
+	}
+
+	if v, ok := r.Peek(); !ok || v != 2 {
+		t.Errorf("want to peek 2, got %d, %t", v, ok)
+	}
+
+	for _, want := range []int{2, 3, 4} {
+		if v, ok := r.Extract(); !ok || v != want {
+			t.Errorf("want to extract %d, got %d, %t", want, v, ok)
+		}
+	}
+
+	if _, ok := r.Extract(); ok || r.Len() != 0 {
+		t.Error("want empty ring after draining")
+	}
+}
+
+// tests that float64 rings keep the newest elements in order.
+func numericFloat64s(t *testing.T) {
+	r := newFloat64s(t, 2, 0.5, 1.5, 2.5)
+
+	if got := fmt.Sprint(r.ToSlice()); got != "[1.5 2.5]" {
+		t.Errorf("want contents [1.5 2.5], got %s", got)
+	}
+
+	for _, want := range []float64{1.5, 2.5} {
+		if v, ok := r.Extract(); !ok || v != want {
+			t.Errorf("want to extract %v, got %v, %t", want, v, ok)
+		}
+	}
+
+	if _, ok := r.Peek(); ok || r.Len() != 0 {
+		t.Error("want empty ring after draining")
+	}
+}
+
+// tests the statistics of empty rings.
+func numericEmptyStats(t *testing.T) {
+	ints := newInts(t, 2, 1)
+	ints.Extract()
+
+	if sum := ints.Sum(); sum != 0 {
+		t.Errorf("want sum 0, got %d", sum)
+	}
+
+	if _, ok := ints.Mean(); ok {
+		t.Error("unexpected mean")
+	}
+
+	if _, ok := ints.Min(); ok {
+		t.Error("unexpected min")
+	}
+
+	floats := newFloat64s(t, 2)
+
+	if _, ok := floats.Max(); ok {
+		t.Error("unexpected max")
+	}
+}
+
+// tests the statistics of int rings against the ones computed from
+// their contents.
+func numericIntsStats(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	r := newInts(t, 7)
+
+	for i := 0; i < 1000; i++ {
+		if rnd.Intn(4) == 0 {
+			r.Extract()
+		} else {
+			r.Insert(rnd.Intn(100) - 50)
+		}
+
+		contents := r.ToSlice()
+		if len(contents) == 0 {
+			continue
+		}
+
+		sum, min, max := 0, contents[0], contents[0]
+
+		for _, v := range contents {
+			sum += v
+
+			if v < min {
+				min = v
+			}
+
+			if v > max {
+				max = v
+			}
+		}
+
+		mean, _ := r.Mean()
+		gotMin, _ := r.Min()
+		gotMax, _ := r.Max()
+
+		if r.Sum() != sum || mean != float64(sum)/float64(len(contents)) ||
+			gotMin != min || gotMax != max {
+			t.Fatalf("wrong stats for %v: sum %d, mean %v, min %d, max %d",
+				contents, r.Sum(), mean, gotMin, gotMax)
+		}
+	}
+}
+
+// tests the statistics of float64 rings against the ones computed from
+// their contents.
+func numericFloat64sStats(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	r := newFloat64s(t, 5)
+
+	for i := 0; i < 1000; i++ {
+		r.Insert(rnd.NormFloat64() * 1e6)
+
+		contents := r.ToSlice()
+		sum, min, max := 0.0, contents[0], contents[0]
+
+		for _, v := range contents {
+			sum += v
+			min = math.Min(min, v)
+			max = math.Max(max, v)
+		}
+
+		gotMin, _ := r.Min()
+		gotMax, _ := r.Max()
+
+		if math.Abs(r.Sum()-sum) > 1e-6 || gotMin != min || gotMax != max {
+			t.Fatalf("wrong stats for %v: sum %v, min %v, max %v",
+				contents, r.Sum(), gotMin, gotMax)
+		}
+	}
+
+	// infinities do not linger after being dropped
+	r.Insert(math.Inf(1))
+
+	for i := 0; i < r.Cap(); i++ {
+		r.Insert(1)
+	}
+
+	if got := r.Sum(); got != 5 {
+		t.Errorf("want sum 5, got %v", got)
+	}
+}
+
+// returns the population variance of vs.
+func variance(vs []float64) float64 {
+	var mean float64
+	for _, v := range vs {
+		mean += v
+	}
+
+	mean /= float64(len(vs))
+
+	var sum float64
+	for _, v := range vs {
+		sum += (v - mean) * (v - mean)
+	}
+
+	return sum / float64(len(vs))
+}
+
+// tests the variance and standard deviation of numeric rings against
+// the ones computed from their contents.
+func numericVariance(t *testing.T) {
+	ints := newInts(t, 4, 2, 4, 4, 4, 5, 5, 7, 9)
+
+	// the variance of 5, 5, 7 and 9
+	if v, ok := ints.Variance(); !ok || math.Abs(v-2.75) > 1e-12 {
+		t.Errorf("want variance 2.75, got %v, %t", v, ok)
+	}
+
+	ints = newInts(t, 4, 2, 4, 4, 4)
+
+	if v, ok := ints.StdDev(); !ok || math.Abs(v-math.Sqrt(0.75)) > 1e-12 {
+		t.Errorf("want standard deviation %v, got %v, %t", math.Sqrt(0.75), v, ok)
+	}
+
+	for i := 0; i < 4; i++ {
+		ints.Extract()
+	}
+
+	if _, ok := ints.Variance(); ok {
+		t.Error("unexpected variance of empty ring")
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	floats := newFloat64s(t, 10)
+
+	for i := 0; i < 1000; i++ {
+		if rnd.Intn(4) == 0 {
+			floats.Extract()
+		} else {
+			floats.Insert(1e3 + rnd.NormFloat64())
+		}
+
+		contents := floats.ToSlice()
+		if len(contents) == 0 {
+			continue
+		}
+
+		got, _ := floats.Variance()
+		if want := variance(contents); math.Abs(got-want) > 1e-9 {
+			t.Fatalf("wrong variance for %v: want %v, got %v", contents, want, got)
+		}
+	}
+}
+
+// tests that the variance of numeric rings does not drift after many
+// insertions of big elements.
+func numericVarianceDrift(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	ints := newInts(t, 8)
+	floats := newFloat64s(t, 8)
+
+	for i := 0; i < 1000000; i++ {
+		v := rnd.Intn(2e9+1) - 1e9
+		ints.Insert(v)
+		floats.Insert(float64(v))
+	}
+
+	for v := 1; v <= 8; v++ {
+		ints.Insert(v)
+		floats.Insert(float64(v))
+	}
+
+	// the variance of 1 to 8
+	if v, _ := ints.Variance(); math.Abs(v-5.25) > 1e-9 {
+		t.Errorf("ints: want variance 5.25, got %v", v)
+	}
+
+	if v, _ := floats.Variance(); math.Abs(v-5.25) > 1e-9 {
+		t.Errorf("float64s: want variance 5.25, got %v", v)
+	}
+}
+
+// tests that inserting in numeric rings does not allocate.  It cannot
+// run in parallel with other tests, as they would allocate too.
+func TestNumericInsertDoesNotAlloc(t *testing.T) {
+	ints := newInts(t, 8)
+	floats := newFloat64s(t, 8)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		ints.Insert(1 << 20)
+		floats.Insert(1e6)
+	})
+
+	if allocs != 0 {
+		t.Errorf("want no allocations, got %v per insert", allocs)
+	}
+}
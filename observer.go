@@ -0,0 +1,19 @@
+package ring
+
+// Observer is notified of the changes in the contents of a ring, for
+// example to collect metrics or audit the elements going through it.
+//
+// Its methods are called after the changes are done, with the ring
+// unlocked, so they can use the ring, but concurrent changes may be
+// notified in a different order than they were done.  Restore and
+// ImportLog do not notify the observer.
+type Observer interface {
+	// OnInsert is called after v is inserted in the ring.
+	OnInsert(v interface{})
+	// OnExtract is called after v is extracted from the ring.
+	OnExtract(v interface{})
+	// OnDrop is called after v is dropped to make room for a new
+	// element, before OnInsert is called for the new element, or after
+	// v is shed by a Budget.
+	OnDrop(v interface{})
+}
@@ -0,0 +1,121 @@
+package ring_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestObserver(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"events":          observerEvents,
+		"extract methods": observerExtractMethods,
+		"called unlocked": observerCalledUnlocked,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// an observer recording the events, and calling fn on each one, if
+// not nil.
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+	fn     func()
+}
+
+func (r *recorder) record(event string, v interface{}) {
+	r.mu.Lock()
+	r.events = append(r.events, fmt.Sprint(event, v))
+	r.mu.Unlock()
+
+	if r.fn != nil {
+		r.fn()
+	}
+}
+
+func (r *recorder) OnInsert(v interface{})  { r.record("insert ", v) }
+func (r *recorder) OnExtract(v interface{}) { r.record("extract ", v) }
+func (r *recorder) OnDrop(v interface{})    { r.record("drop ", v) }
+
+// asserts the events recorded.
+func assertEvents(t *testing.T, r *recorder, want string) {
+	t.Helper()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if got := fmt.Sprint(r.events); got != want {
+		t.Errorf("want events %s, got %s", want, got)
+	}
+}
+
+// returns a ring with the given capacity and options, plus the observer
+// option, or fails the test.
+func newObserved(t *testing.T, cap int, o ring.Observer, opts ...ring.Option) *ring.Ring {
+	t.Helper()
+
+	r, err := ring.New(cap, append(opts, ring.WithObserver(o))...)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	return r
+}
+
+// tests that insertions, extractions and drops are notified.
+func observerEvents(t *testing.T) {
+	o := &recorder{}
+	r := newObserved(t, 2, o)
+
+	r.Insert(1)
+	r.Insert(2)
+	r.Insert(3)
+	r.Extract()
+	r.Extract()
+	r.Extract()
+
+	assertEvents(t, o, "[insert 1 insert 2 drop 1 insert 3 extract 2 extract 3]")
+}
+
+// tests that all the ways of extracting elements are notified.
+func observerExtractMethods(t *testing.T) {
+	o := &recorder{}
+	r := newObserved(t, 4, o, ring.WithTimestamps())
+
+	r.Insert(1)
+	r.Insert(2)
+	r.Insert(3)
+	r.ExtractWithCount()
+	r.ExtractOlderThan(time.Now().Add(time.Hour))
+
+	assertEvents(t, o, "[insert 1 insert 2 insert 3 extract 1 extract 2 extract 3]")
+}
+
+// tests that the observer can use the ring.
+func observerCalledUnlocked(t *testing.T) {
+	o := &recorder{}
+	r := newObserved(t, 2, o)
+
+	var lens []int
+
+	o.fn = func() { lens = append(lens, r.Len()) }
+
+	r.Insert(1)
+	r.Extract()
+
+	if got := fmt.Sprint(lens); got != "[1 0]" {
+		t.Errorf("want lengths [1 0] seen by the observer, got %s", got)
+	}
+}
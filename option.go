@@ -0,0 +1,176 @@
+package ring
+
+import (
+	"time"
+)
+
+// Option configures a ring on construction.
+type Option func(*config)
+
+// the configuration set by the options.
+type config struct {
+	restorePath   string
+	snapshotPath  string
+	snapshotEvery time.Duration
+	onSnapshotErr func(err error)
+	timestamps    bool
+	equal         func(a, b interface{}) bool
+	observer      Observer
+	tracer        Tracer
+	staleAfter    time.Duration
+	onStale       func(age time.Duration)
+	dropBuckets   int
+	dropWidth     time.Duration
+	name          string
+	labels        map[string]string
+	rollupParent  *Ring
+	rollupEvery   time.Duration
+	aggregate     func(elements []interface{}) interface{}
+	clock         Clock
+	softLimit     *softLimit
+	lockFreeReads bool
+	segmentSize   int
+	spins         int
+}
+
+// WithRestore makes New restore the ring from the snapshot at path, if
+// it exists.  See Restore.
+func WithRestore(path string) Option {
+	return func(c *config) {
+		c.restorePath = path
+	}
+}
+
+// WithAutoSnapshot makes the ring checkpoint itself to the file at path
+// every given interval, from a background goroutine, until Close is
+// called.  See Checkpoint.
+func WithAutoSnapshot(path string, every time.Duration) Option {
+	return func(c *config) {
+		c.snapshotPath = path
+		c.snapshotEvery = every
+	}
+}
+
+// WithSnapshotErrorHook makes the ring call fn with the errors of the
+// checkpoints of the WithAutoSnapshot option, from its background
+// goroutine, which otherwise ignores them and tries again on the next
+// interval.
+func WithSnapshotErrorHook(fn func(err error)) Option {
+	return func(c *config) {
+		c.onSnapshotErr = fn
+	}
+}
+
+// WithTimestamps makes the ring remember when each element was
+// inserted, enabling the methods that depend on it, like OldestAge.
+func WithTimestamps() Option {
+	return func(c *config) {
+		c.timestamps = true
+	}
+}
+
+// WithCoalesce makes the ring collapse consecutive equal elements into
+// one, counting how many times it was inserted in a row, like the "last
+// message repeated n times" of syslog.  Elements are compared to the
+// newest one with the given function, which is called with the ring
+// locked, so it must not use the ring.  If it panics, the panic reaches
+// the caller of Insert and the ring remains usable.
+//
+// Inserting an element equal to the newest one only increments its
+// count, it does not update its insertion time.  See ExtractWithCount
+// and ToSliceWithCounts.
+func WithCoalesce(equal func(a, b interface{}) bool) Option {
+	return func(c *config) {
+		c.equal = equal
+	}
+}
+
+// WithObserver makes the ring notify o of the elements inserted,
+// extracted and dropped.  See Observer.
+func WithObserver(o Observer) Option {
+	return func(c *config) {
+		c.observer = o
+	}
+}
+
+// WithTracer makes the context-aware operations of the ring, like
+// ExtractContext, record span events in the given tracer.  See Tracer.
+func WithTracer(t Tracer) Option {
+	return func(c *config) {
+		c.tracer = t
+	}
+}
+
+// WithStaleAlarm makes the ring call fn, from a background goroutine,
+// when its oldest element has been in the ring for longer than the
+// given threshold, usually because its consumer is stalled.  It is
+// called once per stale element, with its age, until Close is called.
+// The option implies WithTimestamps.
+func WithStaleAlarm(threshold time.Duration, fn func(age time.Duration)) Option {
+	return func(c *config) {
+		c.timestamps = true
+		c.staleAfter = threshold
+		c.onStale = fn
+	}
+}
+
+// WithDropHistory makes the ring count the elements dropped to make
+// room for new ones in n time buckets of the given width, like per
+// second in the last 5 minutes, so it is known when the drops happened.
+// See DropHistory.
+func WithDropHistory(n int, width time.Duration) Option {
+	return func(c *config) {
+		c.dropBuckets = n
+		c.dropWidth = width
+	}
+}
+
+// WithName names the ring, so it can be told apart from the other
+// rings in the program, for example by exporters of metrics.  See
+// Name.
+func WithName(name string) Option {
+	return func(c *config) {
+		c.name = name
+	}
+}
+
+// WithLabels attaches the given labels to the ring, like the labels of
+// metrics, so exporters can tell apart rings with the same name.  See
+// Labels.
+func WithLabels(labels map[string]string) Option {
+	return func(c *config) {
+		c.labels = make(map[string]string, len(labels))
+		for k, v := range labels {
+			c.labels[k] = v
+		}
+	}
+}
+
+// WithRollup makes the ring roll its oldest elements up into parent,
+// for example from per worker rings into a global history.  The
+// elements dropped to make room for new ones by Insert and
+// InsertContext are inserted in the parent instead of being lost.  If
+// every is greater than 0, all the elements in the ring are also
+// extracted and rolled up every interval, from a background goroutine,
+// and once more when Close is called.
+//
+// If aggregate is not nil, each batch of elements rolled up together is
+// inserted in the parent as the single element returned by aggregate,
+// otherwise they are inserted one by one.
+func WithRollup(parent *Ring, every time.Duration, aggregate func(elements []interface{}) interface{}) Option {
+	return func(c *config) {
+		c.rollupParent = parent
+		c.rollupEvery = every
+		c.aggregate = aggregate
+	}
+}
+
+// WithClock makes the ring tell the time with c instead of the time
+// package, for its timestamps, ages, drop history buckets and
+// background goroutines.  It is meant for tests advancing the time
+// deterministically.
+func WithClock(c Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
@@ -0,0 +1,38 @@
+package ring
+
+import "context"
+
+// Pipe starts a goroutine moving the elements of r to dst, as they are
+// inserted in r, until ctx is done, and returns a channel closed when
+// the goroutine stops.  For example, it bridges a small ring only used
+// by a producer and its consumer into a larger ring shared by many.
+//
+// The goroutine blocks while r is empty, as in ExtractContext, instead
+// of polling it.  Elements are moved one at a time, from oldest to
+// newest, counted as extracted from r and inserted into dst, and
+// notified to their observers.  Elements left in r when ctx is done
+// stay there.
+//
+// It panics if dst is r.
+func (r *Ring) Pipe(ctx context.Context, dst *Ring) <-chan struct{} {
+	if dst == r {
+		panic("ring: piping a ring into itself")
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for {
+			v, err := r.ExtractContext(ctx)
+			if err != nil {
+				return
+			}
+
+			dst.Insert(v)
+		}
+	}()
+
+	return done
+}
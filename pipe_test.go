@@ -0,0 +1,121 @@
+package ring_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestPipe(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"moves elements": pipeMovesElements,
+		"waits":          pipeWaits,
+		"stops":          pipeStops,
+		"into itself":    pipeIntoItself,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// waits for r to have n elements, failing the test if it takes too
+// long.
+func waitLen(t *testing.T, r interface{ Len() int }, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+
+	for r.Len() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("want length %d, got %d", n, r.Len())
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// tests that the elements already in the source are moved to the
+// destination, in order, counted as extracted and inserted.
+func pipeMovesElements(t *testing.T) {
+	src := newRing(t, 3, 1, 2, 3)
+	dst := newRing(t, 5, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src.Pipe(ctx, dst)
+	waitLen(t, dst, 4)
+
+	ringtest.AssertDrain(t, dst, 0, 1, 2, 3)
+	assertEmpty(t, src)
+
+	if got := src.Stats().Extracted; got != 3 {
+		t.Errorf("want 3 extracted from the source, got %d", got)
+	}
+}
+
+// tests that elements inserted in the source later are moved too.
+func pipeWaits(t *testing.T) {
+	src := newRing(t, 3)
+	dst := newRing(t, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src.Pipe(ctx, dst)
+
+	for i := 1; i <= 3; i++ {
+		time.Sleep(5 * time.Millisecond)
+		src.Insert(i)
+		waitLen(t, dst, i)
+	}
+
+	ringtest.AssertDrain(t, dst, 1, 2, 3)
+}
+
+// tests that the pipe stops when its context is done, leaving the
+// elements inserted afterwards in the source.
+func pipeStops(t *testing.T) {
+	src := newRing(t, 3, 1)
+	dst := newRing(t, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := src.Pipe(ctx, dst)
+	waitLen(t, dst, 1)
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("pipe did not stop")
+	}
+
+	src.Insert(2)
+	time.Sleep(5 * time.Millisecond)
+
+	ringtest.AssertContents(t, src, 2)
+	ringtest.AssertContents(t, dst, 1)
+}
+
+// tests that piping a ring into itself panics.
+func pipeIntoItself(t *testing.T) {
+	r := newRing(t, 2)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("want panic, got none")
+		}
+	}()
+
+	r.Pipe(context.Background(), r)
+}
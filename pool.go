@@ -0,0 +1,58 @@
+package ring
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// pools of released rings, by capacity.
+var pools sync.Map // map[int]*sync.Pool
+
+// AcquireRing returns an empty ring with the given capacity and no
+// options, reusing a ring released with ReleaseRing if possible.  It is
+// meant for workloads creating and discarding many short-lived rings,
+// where allocating the buffer of each new ring is too expensive.
+func AcquireRing(cap int) (*Ring, error) {
+	if cap < 1 {
+		return nil, fmt.Errorf("ring capacity must be > 0, got %d", cap)
+	}
+
+	if p, ok := pools.Load(cap); ok {
+		if r, ok := p.(*sync.Pool).Get().(*Ring); ok {
+			return r, nil
+		}
+	}
+
+	r, err := New(cap)
+	if err != nil {
+		return nil, err
+	}
+
+	r.pooled = true
+
+	return r, nil
+}
+
+// ReleaseRing clears r and returns it to the pool of rings used by
+// AcquireRing.  Rings not returned by AcquireRing are ignored.  The ring
+// must not be used after releasing it.
+func ReleaseRing(r *Ring) {
+	if !r.pooled {
+		return
+	}
+
+	r.mu.Lock()
+	r.clear()
+	r.slot = nil
+	atomic.StoreUint64(&r.inserted, 0)
+	r.seq = 0
+	atomic.StoreUint64(&r.extracted, 0)
+	atomic.StoreUint64(&r.dropped, 0)
+	r.maxLen = 0
+	r.peakLen = 0
+	r.mu.Unlock()
+
+	p, _ := pools.LoadOrStore(r.buf.size(), &sync.Pool{})
+	p.(*sync.Pool).Put(r)
+}
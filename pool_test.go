@@ -0,0 +1,109 @@
+package ring_test
+
+import (
+	"testing"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestPool(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid capacity":       poolInvalidCapacity,
+		"acquired are empty":     poolAcquiredAreEmpty,
+		"released are cleared":   poolReleasedAreCleared,
+		"ignores other rings":    poolIgnoresOtherRings,
+		"capacities dont mix up": poolCapacitiesDontMixUp,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// acquires a ring with the given capacity or fails the test.
+func acquire(t *testing.T, cap int) *ring.Ring {
+	t.Helper()
+
+	r, err := ring.AcquireRing(cap)
+	if err != nil {
+		t.Fatalf("acquiring ring: %v", err)
+	}
+
+	return r
+}
+
+// tests that capacities smaller than 1 are invalid.
+func poolInvalidCapacity(t *testing.T) {
+	if _, err := ring.AcquireRing(0); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that acquired rings are empty and have the requested capacity.
+func poolAcquiredAreEmpty(t *testing.T) {
+	r := acquire(t, 5)
+	defer ring.ReleaseRing(r)
+
+	assertEmpty(t, r)
+
+	if got := r.Cap(); got != 5 {
+		t.Errorf("want capacity 5, got %d", got)
+	}
+}
+
+// tests that reused rings look like new ones.
+func poolReleasedAreCleared(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		r := acquire(t, 7)
+
+		if got := r.Stats(); got != (ring.Stats{Cap: 7}) {
+			t.Fatalf("iteration %d: want the stats of a new ring, got %+v", i, got)
+		}
+
+		assertEmpty(t, r)
+
+		for j := 0; j < 10; j++ {
+			r.Insert(j)
+		}
+
+		r.Extract()
+		ring.ReleaseRing(r)
+	}
+}
+
+// tests that rings not returned by AcquireRing are not cleared nor
+// reused.
+func poolIgnoresOtherRings(t *testing.T) {
+	r := newRing(t, 11, 1, 2)
+
+	ring.ReleaseRing(r)
+
+	assertExtract(t, r, 1)
+	assertExtract(t, r, 2)
+
+	for i := 0; i < 10; i++ {
+		if acquire(t, 11) == r {
+			t.Fatal("acquired a ring not returned by AcquireRing")
+		}
+	}
+}
+
+// tests that acquired rings always have the requested capacity.
+func poolCapacitiesDontMixUp(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		small, big := acquire(t, 12), acquire(t, 13)
+
+		if small.Cap() != 12 || big.Cap() != 13 {
+			t.Fatalf("want capacities 12 and 13, got %d and %d", small.Cap(), big.Cap())
+		}
+
+		ring.ReleaseRing(big)
+		ring.ReleaseRing(small)
+	}
+}
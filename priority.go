@@ -0,0 +1,101 @@
+package ring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// PriorityMux merges several rings into a single stream of elements,
+// always extracting from the non-empty ring with the highest priority,
+// for example to serve control events before data events.
+//
+// To protect the lower priority rings from starvation, the mux can be
+// created with aging: a non-empty ring passed over that many times in
+// favour of higher priority ones is served next.
+//
+// Elements are extracted from the rings as if calling Extract on them,
+// so they are counted as extracted and notified to their observers.
+type PriorityMux struct {
+	mu    sync.Mutex
+	rings []*Ring // from highest to lowest priority
+	aging int     // skips before serving a ring, 0 to disable aging
+	skips []int   // times each ring was passed over while not empty
+}
+
+// NewPriorityMux returns a new mux extracting from the given rings,
+// from highest to lowest priority.  If aging is greater than 0, a
+// non-empty ring passed over aging times is served next.
+func NewPriorityMux(aging int, rings ...*Ring) (*PriorityMux, error) {
+	if len(rings) == 0 {
+		return nil, errors.New("priority mux needs at least one ring")
+	}
+
+	if aging < 0 {
+		return nil, fmt.Errorf("aging must be >= 0, got %d", aging)
+	}
+
+	return &PriorityMux{
+		rings: append([]*Ring(nil), rings...),
+		aging: aging,
+		skips: make([]int, len(rings)),
+	}, nil
+}
+
+// Extract extracts and returns an element from the non-empty ring with
+// the highest priority, or from the one with the highest priority among
+// the aged ones, if any.  It returns false if all the rings are empty.
+func (m *PriorityMux) Extract() (interface{}, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.aging > 0 {
+		for i, r := range m.rings {
+			if m.skips[i] < m.aging {
+				continue
+			}
+
+			if v, ok := r.Extract(); ok {
+				m.served(i)
+				return v, true
+			}
+
+			m.skips[i] = 0
+		}
+	}
+
+	for i, r := range m.rings {
+		if v, ok := r.Extract(); ok {
+			m.served(i)
+			return v, true
+		}
+
+		m.skips[i] = 0
+	}
+
+	return nil, false
+}
+
+// resets the skips of the given ring and counts a skip for the non-empty
+// rings with lower priority.
+func (m *PriorityMux) served(i int) {
+	if m.aging == 0 {
+		return
+	}
+
+	m.skips[i] = 0
+
+	for j := i + 1; j < len(m.rings); j++ {
+		if m.rings[j].Len() > 0 {
+			m.skips[j]++
+		}
+	}
+}
+
+// ExtractContext works like Extract, but if all the rings are empty, it
+// waits for an element to be inserted in any of them.  It returns the
+// context error if ctx is done before that.
+func (m *PriorityMux) ExtractContext(ctx context.Context) (interface{}, error) {
+	return extractContext(ctx, m.rings, m.Extract)
+}
@@ -0,0 +1,109 @@
+package ring_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestPriorityMux(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid arguments": priorityMuxInvalidArguments,
+		"highest first":     priorityMuxHighestFirst,
+		"aging":             priorityMuxAging,
+		"extract waits":     priorityMuxExtractWaits,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a priority mux of the given rings or fails the test.
+func newPriorityMux(t *testing.T, aging int, rings ...*ring.Ring) *ring.PriorityMux {
+	t.Helper()
+
+	m, err := ring.NewPriorityMux(aging, rings...)
+	if err != nil {
+		t.Fatalf("creating priority mux: %v", err)
+	}
+
+	return m
+}
+
+// asserts that extracting from the mux returns the wanted elements and
+// then nothing.
+func assertPriorityMuxDrain(t *testing.T, m *ring.PriorityMux, want string) {
+	t.Helper()
+
+	var got []interface{}
+
+	for {
+		v, ok := m.Extract()
+		if !ok {
+			break
+		}
+
+		got = append(got, v)
+	}
+
+	if fmt.Sprint(got) != want {
+		t.Errorf("want to extract %s, got %v", want, got)
+	}
+}
+
+// tests that priority muxes need rings and a non-negative aging.
+func priorityMuxInvalidArguments(t *testing.T) {
+	if _, err := ring.NewPriorityMux(0); err == nil {
+		t.Error("no rings: unexpected success")
+	}
+
+	if _, err := ring.NewPriorityMux(-1, newRing(t, 1)); err == nil {
+		t.Error("negative aging: unexpected success")
+	}
+}
+
+// tests that without aging, lower priority rings wait until the higher
+// priority ones are empty.
+func priorityMuxHighestFirst(t *testing.T) {
+	low := newRing(t, 5, "l1", "l2")
+	high := newRing(t, 5, "h1", "h2")
+	m := newPriorityMux(t, 0, high, low)
+
+	high.Insert("h3")
+	assertPriorityMuxDrain(t, m, "[h1 h2 h3 l1 l2]")
+}
+
+// tests that aged rings are served before higher priority ones.
+func priorityMuxAging(t *testing.T) {
+	high := newRing(t, 5, "h1", "h2", "h3", "h4", "h5")
+	low := newRing(t, 5, "l1", "l2")
+
+	assertPriorityMuxDrain(t, newPriorityMux(t, 2, high, low), "[h1 h2 l1 h3 h4 l2 h5]")
+}
+
+// tests that extracting from empty rings waits for an insertion in any
+// of them.
+func priorityMuxExtractWaits(t *testing.T) {
+	high, low := newRing(t, 2), newRing(t, 2)
+	m := newPriorityMux(t, 0, high, low)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		low.Insert(1)
+	}()
+
+	v, err := m.ExtractContext(context.Background())
+	if err != nil || v != 1 {
+		t.Fatalf("want to extract 1, got %v, %v", v, err)
+	}
+}
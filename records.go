@@ -0,0 +1,115 @@
+package ring
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Records is a concurrent bounded circular buffer of fixed-size
+// records.  All the records are stored in a single slab of bytes
+// allocated upon construction, so the ring holds no per-record heap
+// objects for the garbage collector to track.
+type Records struct {
+	mu         sync.Mutex
+	slab       []byte // records storage
+	recordSize int
+	len        int // how many records are stored in the ring
+	head       int // index of the next record to be extracted
+}
+
+// NewRecords returns a new ring with the given capacity, in records,
+// and record size, in bytes.
+func NewRecords(cap, recordSize int) (*Records, error) {
+	if cap < 1 {
+		return nil, fmt.Errorf("ring capacity must be > 0, got %d", cap)
+	}
+
+	if recordSize < 1 {
+		return nil, fmt.Errorf("record size must be > 0, got %d", recordSize)
+	}
+
+	return &Records{
+		slab:       make([]byte, cap*recordSize),
+		recordSize: recordSize,
+	}, nil
+}
+
+// returns the storage of the i-th record in the slab.
+func (r *Records) slot(i int) []byte {
+	start := i * r.recordSize
+	return r.slab[start : start+r.recordSize]
+}
+
+// InsertRecord adds a copy of the record rec to the ring.  If the ring
+// is already at maximum capacity, the oldest record is dropped to make
+// room for the new one.  The length of rec must be the record size.
+func (r *Records) InsertRecord(rec []byte) error {
+	if len(rec) != r.recordSize {
+		return fmt.Errorf("record size must be %d, got %d", r.recordSize, len(rec))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copy(r.slot((r.head+r.len)%r.Cap()), rec)
+
+	if r.len == r.Cap() {
+		r.head = (r.head + 1) % r.Cap()
+	} else {
+		r.len++
+	}
+
+	return nil
+}
+
+// ExtractRecord copies the oldest record in the ring into dst and
+// removes it from the ring.  The length of dst must be at least the
+// record size.  It returns false if the ring is empty.
+func (r *Records) ExtractRecord(dst []byte) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		return false
+	}
+
+	copy(dst, r.slot(r.head))
+	r.head = (r.head + 1) % r.Cap()
+	r.len--
+
+	return true
+}
+
+// PeekRecord copies the oldest record in the ring into dst.  The length
+// of dst must be at least the record size.  It returns false if the
+// ring is empty.
+func (r *Records) PeekRecord(dst []byte) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		return false
+	}
+
+	copy(dst, r.slot(r.head))
+
+	return true
+}
+
+// Len returns the amount of records in the ring.
+func (r *Records) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.len
+}
+
+// Cap returns the capacity of the ring, in records.
+func (r *Records) Cap() int {
+	return len(r.slab) / r.recordSize
+}
+
+// RecordSize returns the size of the records, in bytes.
+func (r *Records) RecordSize() int {
+	return r.recordSize
+}
@@ -0,0 +1,101 @@
+package ring_test
+
+import (
+	"testing"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestRecords(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid arguments":  recordsInvalidArguments,
+		"insert and extract": recordsInsertAndExtract,
+		"wrong record size":  recordsWrongRecordSize,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a record ring or fails the test.
+func newRecords(t *testing.T, cap, recordSize int) *ring.Records {
+	t.Helper()
+
+	r, err := ring.NewRecords(cap, recordSize)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	return r
+}
+
+// inserts the records in the ring or fails the test.
+func insertRecords(t *testing.T, r *ring.Records, recs ...string) {
+	t.Helper()
+
+	for _, rec := range recs {
+		if err := r.InsertRecord([]byte(rec)); err != nil {
+			t.Fatalf("inserting %q: %v", rec, err)
+		}
+	}
+}
+
+// tests that capacities and record sizes smaller than 1 are invalid.
+func recordsInvalidArguments(t *testing.T) {
+	if _, err := ring.NewRecords(0, 4); err == nil {
+		t.Error("unexpected success with no capacity")
+	}
+
+	if _, err := ring.NewRecords(4, 0); err == nil {
+		t.Error("unexpected success with empty records")
+	}
+}
+
+// tests the ring keeps the newest records in order.
+func recordsInsertAndExtract(t *testing.T) {
+	r := newRecords(t, 3, 2)
+
+	if r.Cap() != 3 || r.RecordSize() != 2 {
+		t.Fatalf("want capacity 3 and record size 2, got %d and %d", r.Cap(), r.RecordSize())
+	}
+
+	insertRecords(t, r, "aa", "bb", "cc", "dd")
+
+	dst := make([]byte, 2)
+
+	if !r.PeekRecord(dst) || string(dst) != "bb" {
+		t.Fatalf("want to peek %q, got %q", "bb", dst)
+	}
+
+	for _, want := range []string{"bb", "cc", "dd"} {
+		if !r.ExtractRecord(dst) || string(dst) != want {
+			t.Errorf("want to extract %q, got %q", want, dst)
+		}
+	}
+
+	if r.ExtractRecord(dst) || r.PeekRecord(dst) || r.Len() != 0 {
+		t.Error("want empty ring after draining")
+	}
+}
+
+// tests that records must have the record size.
+func recordsWrongRecordSize(t *testing.T) {
+	r := newRecords(t, 3, 2)
+
+	for _, rec := range []string{"", "a", "abc"} {
+		if err := r.InsertRecord([]byte(rec)); err == nil {
+			t.Errorf("unexpected success inserting %q", rec)
+		}
+	}
+
+	if r.Len() != 0 {
+		t.Errorf("want empty ring, got length %d", r.Len())
+	}
+}
@@ -0,0 +1,67 @@
+package ring
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// the process-wide registry of rings.
+var registry = struct {
+	sync.Mutex
+	rings map[string]*Ring
+}{
+	rings: make(map[string]*Ring),
+}
+
+// Register adds r to the process-wide registry of rings under the given
+// name, so it can be found with Lookup and inspected along with the
+// other registered rings, for example with ringhttp.RegistryHandler.
+// It returns an error if the name is already registered.
+func Register(name string, r *Ring) error {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.rings[name]; ok {
+		return fmt.Errorf("ring %q already registered", name)
+	}
+
+	registry.rings[name] = r
+
+	return nil
+}
+
+// Unregister removes the ring registered under the given name, if any,
+// from the process-wide registry of rings.
+func Unregister(name string) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	delete(registry.rings, name)
+}
+
+// Lookup returns the ring registered under the given name.
+func Lookup(name string) (*Ring, bool) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	r, ok := registry.rings[name]
+
+	return r, ok
+}
+
+// Registered returns the names of the registered rings, in
+// lexicographic order.
+func Registered() []string {
+	registry.Lock()
+	defer registry.Unlock()
+
+	result := make([]string, 0, len(registry.rings))
+	for name := range registry.rings {
+		result = append(result, name)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
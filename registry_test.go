@@ -0,0 +1,101 @@
+package ring_test
+
+import (
+	"testing"
+
+	"github.com/alcortesm/ring"
+)
+
+// the registry is global, so its subtests use their own names and can
+// only check for their own rings in the list of registered ones.
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"register and lookup": registryRegisterAndLookup,
+		"duplicated names":    registryDuplicatedNames,
+		"unregister":          registryUnregister,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// registers r under name or fails the test, unregistering it at the end
+// of the test.
+func register(t *testing.T, name string, r *ring.Ring) {
+	t.Helper()
+
+	if err := ring.Register(name, r); err != nil {
+		t.Fatalf("registering %q: %v", name, err)
+	}
+
+	t.Cleanup(func() { ring.Unregister(name) })
+}
+
+// reports whether name is in the list of registered rings.
+func isRegistered(name string) bool {
+	for _, registered := range ring.Registered() {
+		if registered == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tests that registered rings can be found.
+func registryRegisterAndLookup(t *testing.T) {
+	a, b := newRing(t, 1), newRing(t, 1)
+	register(t, "lookup b", b)
+	register(t, "lookup a", a)
+
+	if got, ok := ring.Lookup("lookup a"); !ok || got != a {
+		t.Errorf("want to find ring a, got %p, %t", got, ok)
+	}
+
+	if !isRegistered("lookup a") || !isRegistered("lookup b") {
+		t.Errorf("rings missing from the registered ones: %q", ring.Registered())
+	}
+
+	if _, ok := ring.Lookup("lookup c"); ok {
+		t.Error("found an unregistered ring")
+	}
+}
+
+// tests that names cannot be registered twice.
+func registryDuplicatedNames(t *testing.T) {
+	r := newRing(t, 1)
+	register(t, "duplicated", r)
+
+	if err := ring.Register("duplicated", newRing(t, 1)); err == nil {
+		t.Fatal("unexpected success")
+	}
+
+	if got, _ := ring.Lookup("duplicated"); got != r {
+		t.Error("registered ring replaced")
+	}
+}
+
+// tests that unregistered rings cannot be found, and that their names
+// can be registered again.
+func registryUnregister(t *testing.T) {
+	register(t, "unregister", newRing(t, 1))
+	ring.Unregister("unregister")
+
+	if _, ok := ring.Lookup("unregister"); ok || isRegistered("unregister") {
+		t.Error("found an unregistered ring")
+	}
+
+	r := newRing(t, 1)
+	register(t, "unregister", r)
+
+	if got, _ := ring.Lookup("unregister"); got != r {
+		t.Errorf("want the ring registered again, got %p", got)
+	}
+}
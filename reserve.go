@@ -0,0 +1,128 @@
+package ring
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Slot is the oldest element of a ring, reserved by Reserve until it is
+// committed or released.
+type Slot struct {
+	r   *Ring
+	res *reservation
+}
+
+// the element reserved in a ring, see Reserve.
+type reservation struct {
+	v     interface{}
+	time  time.Time // insertion time, if the ring has timestamps
+	count int       // insertions in a row, if the ring coalesces them
+}
+
+// Reserve reserves the oldest element in the ring for the caller, who
+// can process it before committing its extraction, or releasing it back
+// to the ring if the processing fails, so no element is lost if the
+// consumer fails.  It returns false if the ring is empty, or if another
+// element is already reserved: there is at most one reservation at a
+// time.
+//
+// Reserved elements are not in the ring for Len, Peek or the
+// extractions, but they keep taking room in it, so they are not dropped
+// to make room for new elements.  If the ring is full and its only
+// element is reserved, new elements are dropped instead.
+func (r *Ring) Reserve() (Slot, bool) {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if r.slot != nil || r.len == 0 {
+		return Slot{}, false
+	}
+
+	res := &reservation{
+		count: r.count(r.head),
+	}
+
+	if r.times != nil {
+		res.time = r.times[r.head]
+	}
+
+	res.v, _ = r.extract()
+	r.slot = res
+
+	return Slot{r: r, res: res}, true
+}
+
+// returns the number of elements reserved, see Reserve.
+func (r *Ring) reserved() int {
+	if r.slot == nil {
+		return 0
+	}
+
+	return 1
+}
+
+// Value returns the reserved element.
+func (s Slot) Value() interface{} {
+	return s.res.v
+}
+
+// Commit extracts the reserved element from the ring, counting it as
+// extracted and notifying it to the observer of the ring.  It panics if
+// the slot was already committed or released.
+func (s Slot) Commit() {
+	r := s.r
+
+	r.mu.Lock()
+	r.endReservation(s)
+	r.unlock()
+
+	atomic.AddUint64(&r.extracted, 1)
+
+	if r.observer != nil {
+		r.observer.OnExtract(s.res.v)
+	}
+}
+
+// Release returns the reserved element to the ring, as its oldest
+// element again.  It panics if the slot was already committed or
+// released.
+func (s Slot) Release() {
+	r := s.r
+
+	r.mu.Lock()
+	r.endReservation(s)
+
+	// the reservation kept room for it, so the slot before the head is
+	// free
+	r.head = (r.head - 1 + r.buf.size()) % r.buf.size()
+	r.len++
+	r.buf.set(r.head, s.res.v)
+
+	if r.times != nil {
+		r.times[r.head] = s.res.time
+	}
+
+	if r.counts != nil {
+		r.counts[r.head] = s.res.count
+	}
+
+	if r.ready != nil {
+		close(r.ready)
+		r.ready = nil
+	}
+
+	r.checkInvariants()
+	r.unlock()
+}
+
+// ends the reservation of s, which must be the current one of the
+// ring, which must be locked.  Otherwise, it unlocks the ring and
+// panics.
+func (r *Ring) endReservation(s Slot) {
+	if r.slot == nil || r.slot != s.res {
+		r.mu.Unlock()
+		panic("ring: slot already committed or released")
+	}
+
+	r.slot = nil
+}
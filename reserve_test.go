@@ -0,0 +1,201 @@
+package ring_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestReserve(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"empty ring":         reserveEmptyRing,
+		"commit":             reserveCommit,
+		"release":            reserveRelease,
+		"one at a time":      reserveOneAtATime,
+		"keeps its room":     reserveKeepsItsRoom,
+		"only room reserved": reserveOnlyRoomReserved,
+		"stale slots":        reserveStaleSlots,
+		"release wakes":      reserveReleaseWakes,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// reserves the oldest element of r, or fails the test.
+func mustReserve(t *testing.T, r *ring.Ring, want interface{}) ring.Slot {
+	t.Helper()
+
+	s, ok := r.Reserve()
+	if !ok {
+		t.Fatal("unexpected failure to reserve")
+	}
+
+	if got := s.Value(); got != want {
+		t.Fatalf("want to reserve %v, got %v", want, got)
+	}
+
+	return s
+}
+
+// tests that empty rings have nothing to reserve.
+func reserveEmptyRing(t *testing.T) {
+	if _, ok := ringtest.New(t, 2).Reserve(); ok {
+		t.Error("unexpected reservation")
+	}
+}
+
+// tests that reserved elements are out of the ring, and that committing
+// them counts them as extracted.
+func reserveCommit(t *testing.T) {
+	o := &recorder{}
+	r := newObserved(t, 3, o)
+	ringtest.Fill(r, 1, 2)
+
+	s := mustReserve(t, r, 1)
+	ringtest.AssertContents(t, r, 2)
+
+	if v, ok := r.Peek(); !ok || v != 2 {
+		t.Errorf("want to peek 2, got %v, %t", v, ok)
+	}
+
+	s.Commit()
+
+	if got := r.Stats().Extracted; got != 1 {
+		t.Errorf("want 1 extracted, got %d", got)
+	}
+
+	assertEvents(t, o, "[insert 1 insert 2 extract 1]")
+	ringtest.AssertDrain(t, r, 2)
+}
+
+// tests that released elements are the oldest again, with their counts.
+func reserveRelease(t *testing.T) {
+	r, err := ring.New(3, ring.WithCoalesce(equal), ring.WithTimestamps())
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	ringtest.Fill(r, 1, 1, 2)
+
+	mustReserve(t, r, 1).Release()
+
+	if got := r.Stats().Extracted; got != 0 {
+		t.Errorf("want nothing extracted, got %d", got)
+	}
+
+	if _, ok := r.OldestAge(); !ok {
+		t.Error("released element has no age")
+	}
+
+	if v, n, _ := r.ExtractWithCount(); v != 1 || n != 2 {
+		t.Errorf("want 1 inserted twice, got %v inserted %d times", v, n)
+	}
+
+	ringtest.AssertDrain(t, r, 2)
+}
+
+// tests that there is at most one reservation at a time.
+func reserveOneAtATime(t *testing.T) {
+	r := ringtest.New(t, 3, 1, 2)
+
+	s := mustReserve(t, r, 1)
+
+	if _, ok := r.Reserve(); ok {
+		t.Error("unexpected second reservation")
+	}
+
+	s.Commit()
+	mustReserve(t, r, 2)
+}
+
+// tests that reserved elements are not dropped to make room for new
+// ones, but keep taking room.
+func reserveKeepsItsRoom(t *testing.T) {
+	r := ringtest.New(t, 3, 1, 2, 3)
+
+	s := mustReserve(t, r, 1)
+
+	r.Insert(4) // drops 2, the oldest one not reserved
+	ringtest.AssertContents(t, r, 3, 4)
+
+	s.Release()
+	ringtest.AssertDrain(t, r, 1, 3, 4)
+}
+
+// tests that new elements are dropped if the ring is full and its only
+// element is reserved.
+func reserveOnlyRoomReserved(t *testing.T) {
+	r := ringtest.New(t, 1, 1)
+
+	s := mustReserve(t, r, 1)
+	r.Insert(2)
+
+	if got := fmt.Sprint(r.Len(), r.Dropped()); got != "0 1" {
+		t.Errorf("want length 0 and 1 dropped, got %s", got)
+	}
+
+	s.Release()
+	ringtest.AssertDrain(t, r, 1)
+}
+
+// tests that committing or releasing a slot twice panics, leaving the
+// ring usable.
+func reserveStaleSlots(t *testing.T) {
+	r := ringtest.New(t, 2, 1, 2)
+
+	s := mustReserve(t, r, 1)
+	s.Release()
+
+	for name, fn := range map[string]func(){
+		"commit":  s.Commit,
+		"release": s.Release,
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: want panic, got none", name)
+				}
+			}()
+
+			fn()
+		}()
+	}
+
+	ringtest.AssertDrain(t, r, 1, 2)
+}
+
+// tests that releasing an element wakes up the consumers waiting for
+// one.
+func reserveReleaseWakes(t *testing.T) {
+	r := ringtest.New(t, 2, 1)
+	s := mustReserve(t, r, 1)
+
+	got := make(chan interface{})
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		v, _ := r.ExtractContext(ctx)
+		got <- v
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	s.Release()
+
+	if v := <-got; v != 1 {
+		t.Errorf("want to extract 1, got %v", v)
+	}
+}
@@ -0,0 +1,87 @@
+package ring
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Reservoir is a concurrent uniform random sample of a stream of
+// elements of unbounded length.  Unlike Ring, which keeps the most
+// recent elements, every element inserted has the same probability of
+// being in the reservoir.
+type Reservoir struct {
+	mu   sync.Mutex
+	buf  []interface{} // the sample
+	seen uint64        // how many elements were inserted
+	rnd  *rand.Rand
+}
+
+// NewReservoir returns a new reservoir sampling up to cap elements,
+// that takes its random numbers from src.  If src is nil, a source
+// seeded with the current time is used.
+func NewReservoir(cap int, src rand.Source) (*Reservoir, error) {
+	if cap < 1 {
+		return nil, fmt.Errorf("reservoir capacity must be > 0, got %d", cap)
+	}
+
+	if src == nil {
+		src = rand.NewSource(time.Now().UnixNano())
+	}
+
+	return &Reservoir{
+		buf: make([]interface{}, 0, cap),
+		rnd: rand.New(src),
+	}, nil
+}
+
+// Insert offers a new element to the reservoir.  While the reservoir is
+// not full, all elements are kept.  After that, the n-th inserted
+// element replaces a random element in the reservoir with probability
+// cap/n.
+func (r *Reservoir) Insert(v interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.seen++
+
+	if len(r.buf) < cap(r.buf) {
+		r.buf = append(r.buf, v)
+		return
+	}
+
+	if i := r.rnd.Int63n(int64(r.seen)); i < int64(cap(r.buf)) {
+		r.buf[i] = v
+	}
+}
+
+// Len returns the amount of elements in the reservoir.
+func (r *Reservoir) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.buf)
+}
+
+// Cap returns the capacity of the reservoir.
+func (r *Reservoir) Cap() int {
+	return cap(r.buf)
+}
+
+// Seen returns how many elements were inserted in the reservoir.
+func (r *Reservoir) Seen() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.seen
+}
+
+// ToSlice returns the elements in the reservoir, in no particular
+// order.
+func (r *Reservoir) ToSlice() []interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]interface{}(nil), r.buf...)
+}
@@ -0,0 +1,106 @@
+package ring_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestReservoir(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid capacity": reservoirInvalidCapacity,
+		"keeps first":      reservoirKeepsFirst,
+		"uniform":          reservoirUniform,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a reservoir with the given capacity and seed or fails the
+// test.
+func newReservoir(t *testing.T, cap int, seed int64) *ring.Reservoir {
+	t.Helper()
+
+	r, err := ring.NewReservoir(cap, rand.NewSource(seed))
+	if err != nil {
+		t.Fatalf("creating reservoir: %v", err)
+	}
+
+	return r
+}
+
+// tests that capacities smaller than 1 are invalid.
+func reservoirInvalidCapacity(t *testing.T) {
+	if _, err := ring.NewReservoir(0, nil); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that all elements are kept until the reservoir is full.
+func reservoirKeepsFirst(t *testing.T) {
+	r, err := ring.NewReservoir(3, nil)
+	if err != nil {
+		t.Fatalf("creating reservoir: %v", err)
+	}
+
+	r.Insert(1)
+	r.Insert(2)
+
+	assertReservoir(t, r, 2, 2)
+
+	if got := len(r.ToSlice()); got != 2 {
+		t.Errorf("want 2 elements, got %d", got)
+	}
+}
+
+// asserts the length of the reservoir and how many elements it saw.
+func assertReservoir(t *testing.T, r *ring.Reservoir, len int, seen uint64) {
+	t.Helper()
+
+	if r.Len() != len || r.Seen() != seen {
+		t.Errorf("want length %d and %d seen, got %d and %d",
+			len, seen, r.Len(), r.Seen())
+	}
+}
+
+// tests that all elements have the same probability of being sampled.
+func reservoirUniform(t *testing.T) {
+	const (
+		cap    = 10
+		stream = 100
+		runs   = 2000
+	)
+
+	var hits [stream]int
+
+	for run := 0; run < runs; run++ {
+		r := newReservoir(t, cap, int64(run))
+
+		for i := 0; i < stream; i++ {
+			r.Insert(i)
+		}
+
+		assertReservoir(t, r, cap, stream)
+
+		for _, v := range r.ToSlice() {
+			hits[v.(int)]++
+		}
+	}
+
+	// each element is expected in 200 of the runs, with a standard
+	// deviation of about 13
+	for i, h := range hits {
+		if h < 130 || h > 270 {
+			t.Errorf("element %d sampled %d times, want about 200", i, h)
+		}
+	}
+}
@@ -2,76 +2,644 @@
 Package ring implements a bounded circular buffer.  When at maximum
 capacity, it drops the oldest elements to make room for the new ones.
 
-All operations have constant worst-case time complexity.
+Inserting, extracting and peeking elements have constant worst-case
+time complexity.
 
 Internally the ring uses a fixed size buffer allocated upon
-construction, proportional in size to the ring capacity.
+construction, proportional in size to the ring capacity, or segments
+allocated as needed for very large capacities, see WithSegments.
+
+Building with the ringdebug tag makes every insertion, extraction and
+clearing check the internal invariants of the ring, panicking with a
+dump of its state when they do not hold.  It is meant for debugging,
+as it makes each operation linear in the capacity of the ring.
 */
 package ring
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Ring is a concurrent bounded circular buffer.
+//
+// The zero Ring is an empty ring with no options, ready to use, so rings
+// can be embedded in other types without calling New.  It gets a buffer
+// of DefaultCapacity elements on its first insertion, unless SetCapacity
+// is called before.  A Ring must not be copied after first use.
 type Ring struct {
-	buf  []interface{} // elements storage
-	len  int           // how many elements are stored in the ring
-	head int           // index of the next element to be extracted
+	// counters updated atomically, outside the lock, see Stats; first
+	// in the struct to be 64-bit aligned on 32-bit platforms
+	inserted  uint64 // elements inserted since creation
+	extracted uint64 // elements extracted since creation
+	dropped   uint64 // elements dropped to make room for new ones
+
+	mu   lock  // a sync.Mutex, unless created with the WithSpinLock option
+	buf  slots // elements storage
+	len  int   // how many elements are stored in the ring
+	head int   // index of the next element to be extracted
+
+	seq     uint64 // sequence number of the next element to be stored
+	maxLen  int    // maximum length since creation
+	peakLen int    // maximum length since the last ResetPeakLen
+
+	drops *dropHistory // nil unless created with the WithDropHistory option
+
+	// insertion times of the elements, nil unless created with the
+	// WithTimestamps option
+	times []time.Time
+
+	// how many times in a row each element was inserted, nil unless
+	// created with the WithCoalesce option
+	counts []int
+	equal  func(a, b interface{}) bool
+
+	observer Observer     // nil unless created with the WithObserver option
+	tracer   Tracer       // nil unless created with the WithTracer option
+	rollup   *rollup      // nil unless created with the WithRollup option
+	soft     *softLimit   // nil unless created with the WithSoftLimit option
+	budget   *Budget      // the budget the ring is registered with, if any
+	slot     *reservation // the element reserved by Reserve, if any
+
+	// closed when an element is inserted, to wake up the goroutines
+	// waiting in ExtractContext; nil if no one is waiting
+	ready chan struct{}
+
+	closeOnce sync.Once
+	stop      chan struct{} // closed to stop the background goroutines
+	done      sync.WaitGroup
+	snapshot  string // where to checkpoint when closing, if not empty
+
+	name   string
+	labels map[string]string
+
+	pooled bool  // returned by AcquireRing
+	clock  Clock // tells the time, see WithClock
+
+	lockFree bool         // created with the WithLockFreeReads option
+	reads    atomic.Value // *readSnapshot, published by the writers if lockFree
 }
 
-// Returns a new ring with the given capacity.
-func New(cap int) (*Ring, error) {
+// DefaultCapacity is the capacity of the zero Ring, see SetCapacity.
+const DefaultCapacity = 64
+
+// Returns a new ring with the given capacity and options.
+func New(cap int, opts ...Option) (*Ring, error) {
 	if cap < 1 {
 		return nil, fmt.Errorf("ring capacity must be > 0, got %d", cap)
 	}
 
-	return &Ring{
-		buf: make([]interface{}, cap),
-	}, nil
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	if c.snapshotPath != "" && c.snapshotEvery <= 0 {
+		return nil, fmt.Errorf("snapshot interval must be > 0, got %v", c.snapshotEvery)
+	}
+
+	if c.onStale != nil && c.staleAfter <= 0 {
+		return nil, fmt.Errorf("stale threshold must be > 0, got %v", c.staleAfter)
+	}
+
+	if c.rollupParent == nil && (c.rollupEvery != 0 || c.aggregate != nil) {
+		return nil, errors.New("rollup parent must not be nil")
+	}
+
+	if c.rollupEvery < 0 {
+		return nil, fmt.Errorf("rollup interval must be >= 0, got %v", c.rollupEvery)
+	}
+
+	if err := validateSegmentSize(c.segmentSize); err != nil {
+		return nil, err
+	}
+
+	if err := validateSpins(c.spins); err != nil {
+		return nil, err
+	}
+
+	if s := c.softLimit; s != nil {
+		if s.limit < 1 || s.limit >= cap {
+			return nil, fmt.Errorf("soft limit must be in [1, %d), got %d", cap, s.limit)
+		}
+
+		if s.policy != SoftLimitNotify && s.policy != SoftLimitShed {
+			return nil, fmt.Errorf("unknown soft limit policy %d", s.policy)
+		}
+	}
+
+	if c.dropBuckets != 0 || c.dropWidth != 0 {
+		if c.dropBuckets < 1 || c.dropWidth <= 0 {
+			return nil, fmt.Errorf("drop history buckets and width must be > 0, got %d and %v",
+				c.dropBuckets, c.dropWidth)
+		}
+	}
+
+	r := &Ring{
+		buf:   newSlots(cap, c.segmentSize),
+		stop:  make(chan struct{}),
+		clock: c.clock,
+	}
+
+	if r.clock == nil {
+		r.clock = SystemClock{}
+	}
+
+	if c.spins > 0 {
+		r.mu.setSpins(c.spins)
+	}
+
+	if c.timestamps {
+		r.times = make([]time.Time, cap)
+	}
+
+	if c.dropBuckets > 0 {
+		r.drops = newDropHistory(c.dropBuckets, c.dropWidth)
+	}
+
+	r.observer = c.observer
+	r.tracer = c.tracer
+	r.name = c.name
+	r.labels = c.labels
+
+	if c.equal != nil {
+		r.counts = make([]int, cap)
+		r.equal = c.equal
+	}
+
+	if c.restorePath != "" {
+		err := r.Restore(c.restorePath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	if c.lockFreeReads {
+		r.lockFree = true
+		r.publish()
+	}
+
+	if c.snapshotPath != "" {
+		r.snapshot = c.snapshotPath
+		r.done.Add(1)
+
+		go r.autoSnapshot(c.snapshotPath, c.snapshotEvery, c.onSnapshotErr)
+	}
+
+	if c.onStale != nil {
+		r.done.Add(1)
+
+		go r.staleAlarm(c.staleAfter, c.onStale)
+	}
+
+	if c.softLimit != nil {
+		// copied, as the option could be used for several rings
+		soft := *c.softLimit
+		soft.armed = r.len < soft.limit
+		r.soft = &soft
+	}
+
+	if c.rollupParent != nil {
+		r.rollup = &rollup{
+			parent:    c.rollupParent,
+			aggregate: c.aggregate,
+		}
+
+		if c.rollupEvery > 0 {
+			r.done.Add(1)
+
+			go r.rollupEvery(c.rollupEvery)
+		}
+	}
+
+	return r, nil
+}
+
+// Collect returns a new ring with the given capacity, and no options,
+// holding the elements yielded by seq, from first to last, or only the
+// last cap ones if there are more.  It fails if cap is smaller than 1.
+//
+// The seq has the shape of a Go iterator over interface{} values: it
+// calls yield with each element, and stops when yield returns false,
+// which it never does here.
+func Collect(cap int, seq func(yield func(v interface{}) bool)) (*Ring, error) {
+	r, err := New(cap)
+	if err != nil {
+		return nil, err
+	}
+
+	seq(func(v interface{}) bool {
+		r.Insert(v)
+		return true
+	})
+
+	return r, nil
 }
 
 // returns the index where the next element will be inserted.
 func (r *Ring) tail() int {
-	return (r.head + r.len) % cap(r.buf)
+	return (r.head + r.len) % r.buf.size()
 }
 
 // Insert adds a new element to the ring. If the ring is already at
 // maximum capacity, the oldest element is dropped to make room for the
 // new one.
 func (r *Ring) Insert(v interface{}) {
+	in := r.lockedInsert(v)
+
+	if in.softLimit {
+		r.soft.fn()
+	}
+
+	if r.observer != nil {
+		if in.isDropped {
+			r.observer.OnDrop(in.dropped)
+		}
+
+		if !in.shed {
+			r.observer.OnInsert(v)
+		}
+	}
+
+	if in.isDropped && r.rollup != nil {
+		r.rollup.add([]interface{}{in.dropped})
+	}
+
+	if in.budget != nil && !in.shed {
+		in.budget.inserted(v)
+	}
+}
+
+// the outcome of inserting an element.
+type insertion struct {
+	dropped   interface{} // dropped to make room, or shed, see isDropped
+	isDropped bool
+	shed      bool    // the inserted element was dropped, see WithSoftLimit
+	softLimit bool    // the length reached the soft limit, see WithSoftLimit
+	budget    *Budget // the budget of the ring, see Budget
+}
+
+// locks the ring and inserts v, see insert, counting the insertion and
+// the drop, if any.
+//
+// The hot path unlocks without defer, which is noticeably cheaper for
+// such short critical sections.  Only rings created with the
+// WithCoalesce option call user code with the ring locked, so only they
+// pay for a deferred unlock, which keeps the ring usable if it panics.
+func (r *Ring) lockedInsert(v interface{}) insertion {
+	// counted before inserting and the drops after, see Stats
+	atomic.AddUint64(&r.inserted, 1)
+
+	var in insertion
+
+	if r.equal != nil {
+		in = r.guardedInsert(v)
+	} else {
+		r.mu.Lock()
+		in = r.insert(v)
+		in.budget = r.budget
+		r.unlock()
+	}
+
+	if in.isDropped {
+		atomic.AddUint64(&r.dropped, 1)
+	}
+
+	return in
+}
+
+// locks the ring and inserts v, unlocking it even if insert panics.
+func (r *Ring) guardedInsert(v interface{}) insertion {
+	r.mu.Lock()
+	defer r.unlock()
+
+	in := r.insert(v)
+	in.budget = r.budget
+
+	return in
+}
+
+// adds a new element to the ring, dropping the oldest one if needed,
+// or sheds it, see WithSoftLimit.  It does not update the counters of
+// the ring, see Stats.
+func (r *Ring) insert(v interface{}) insertion {
+	if r.buf.size() == 0 { // the zero Ring
+		r.buf = newSlots(DefaultCapacity, 0)
+	}
+
+	if r.soft != nil && r.soft.shed(r.len, r.buf.size()) {
+		if r.drops != nil {
+			r.drops.add(r.now())
+		}
+
+		return insertion{dropped: v, isDropped: true, shed: true}
+	}
+
+	if r.ready != nil {
+		close(r.ready)
+		r.ready = nil
+	}
+
+	if r.equal != nil && r.len > 0 {
+		newest := (r.head + r.len - 1) % r.buf.size()
+
+		if r.equal(r.buf.get(newest), v) {
+			r.counts[newest]++
+			return insertion{}
+		}
+	}
+
+	var in insertion
+
 	// if full, make room by droppin the oldest element
-	if r.len == cap(r.buf) {
-		_, _ = r.Extract()
+	if r.len+r.reserved() == r.buf.size() {
+		if r.len == 0 { // the only room is reserved, see Reserve
+			if r.drops != nil {
+				r.drops.add(r.now())
+			}
+
+			return insertion{dropped: v, isDropped: true, shed: true}
+		}
+
+		in.dropped, in.isDropped = r.extract()
+
+		if r.drops != nil {
+			r.drops.add(r.now())
+		}
 	}
 
-	r.buf[r.tail()] = v
+	if r.times != nil {
+		r.times[r.tail()] = r.now()
+	}
+
+	if r.counts != nil {
+		r.counts[r.tail()] = 1
+	}
+
+	r.buf.set(r.tail(), v)
 	r.len++
+	r.seq++
+
+	if r.len > r.maxLen {
+		r.maxLen = r.len
+	}
+
+	if r.len > r.peakLen {
+		r.peakLen = r.len
+	}
+
+	if r.soft != nil && r.soft.armed && r.len >= r.soft.limit {
+		r.soft.armed = false
+		in.softLimit = r.soft.fn != nil
+	}
+
+	r.checkInvariants()
+
+	return in
+}
+
+// inserts the elements in order, and returns how many were dropped.  It
+// does not update the counters of the ring, see Stats.
+func (r *Ring) insertAll(elements []interface{}) uint64 {
+	var dropped uint64
+
+	for _, v := range elements {
+		if r.insert(v).isDropped {
+			dropped++
+		}
+	}
+
+	return dropped
 }
 
 // Extract extracts and returns the oldest element in the ring.
 func (r *Ring) Extract() (interface{}, bool) {
+	r.mu.Lock()
+
+	v, ok := r.extract()
+	r.unlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	atomic.AddUint64(&r.extracted, 1)
+
+	if r.observer != nil {
+		r.observer.OnExtract(v)
+	}
+
+	return v, ok
+}
+
+// extracts and returns the oldest element in the ring.
+func (r *Ring) extract() (interface{}, bool) {
 	if r.len == 0 {
 		return nil, false
 	}
 
-	result := r.buf[r.head]
-	r.head = (r.head + 1) % cap(r.buf)
+	result := r.buf.get(r.head)
+	vacated := r.head
+	r.head = (r.head + 1) % r.buf.size()
 	r.len--
 
+	// do not retain it until overwritten
+	r.buf.free(vacated, r.head, r.len)
+
+	if r.soft != nil && r.len < r.soft.limit {
+		r.soft.armed = true
+	}
+
+	r.checkInvariants()
+
 	return result, true
 }
 
 // Peek returns the oldest element in the ring.
 func (r *Ring) Peek() (interface{}, bool) {
+	if r.lockFree {
+		s := r.reads.Load().(*readSnapshot)
+		return s.head, s.stats.Len > 0
+	}
+
+	r.mu.Lock()
+
 	if r.len == 0 {
+		r.mu.Unlock()
 		return nil, false
 	}
 
-	return r.buf[r.head], true
+	v := r.buf.get(r.head)
+	r.mu.Unlock()
+
+	return v, true
+}
+
+// PeekFunc calls fn with the oldest element in the ring, without
+// extracting it, and reports if there was one to call it with.  Unlike
+// Peek, fn runs while holding the lock of the ring, so no other method
+// can change the element while fn inspects it, which is what elements
+// that are mutable buffers, reused once extracted, need.
+//
+// As the ring stays locked until fn returns, fn should be quick, and it
+// must not call any method of the ring, or it deadlocks.  It always takes
+// the lock, even with WithLockFreeReads.
+func (r *Ring) PeekFunc(fn func(v interface{})) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.len == 0 {
+		return false
+	}
+
+	fn(r.buf.get(r.head))
+
+	return true
 }
 
 // Len returns the amount of elements in the ring.
 func (r *Ring) Len() int {
-	return r.len
+	if r.lockFree {
+		return r.reads.Load().(*readSnapshot).stats.Len
+	}
+
+	r.mu.Lock()
+	n := r.len
+	r.mu.Unlock()
+
+	return n
+}
+
+// Cap returns the capacity of the ring.
+func (r *Ring) Cap() int {
+	r.mu.Lock()
+	n := r.capacity()
+	r.mu.Unlock()
+
+	return n
+}
+
+// returns the capacity of the ring, which must be locked.
+func (r *Ring) capacity() int {
+	if r.buf.size() == 0 { // the zero Ring, before its first insertion
+		return DefaultCapacity
+	}
+
+	return r.buf.size()
+}
+
+// SetCapacity sets the capacity of a zero Ring, which otherwise gets
+// DefaultCapacity on its first insertion.  It fails if cap is smaller
+// than 1, or if the ring already has a buffer, because it was created
+// with New, it was already inserted into, or its capacity was already
+// set.
+func (r *Ring) SetCapacity(cap int) error {
+	if cap < 1 {
+		return fmt.Errorf("ring capacity must be > 0, got %d", cap)
+	}
+
+	r.mu.Lock()
+	defer r.unlock()
+
+	if r.buf.size() != 0 {
+		return fmt.Errorf("ring capacity already set to %d", r.buf.size())
+	}
+
+	r.buf = newSlots(cap, 0)
+
+	return nil
+}
+
+// Name returns the name of the ring, set with the WithName option.
+func (r *Ring) Name() string {
+	return r.name
+}
+
+// Labels returns a copy of the labels of the ring, set with the
+// WithLabels option.
+func (r *Ring) Labels() map[string]string {
+	result := make(map[string]string, len(r.labels))
+	for k, v := range r.labels {
+		result[k] = v
+	}
+
+	return result
+}
+
+// Dropped returns how many elements were dropped to make room for new
+// ones since the ring was created or ResetDropped was called.
+func (r *Ring) Dropped() uint64 {
+	return atomic.LoadUint64(&r.dropped)
+}
+
+// ResetDropped resets the count of dropped elements to zero and returns
+// its previous value.
+func (r *Ring) ResetDropped() uint64 {
+	return atomic.SwapUint64(&r.dropped, 0)
+}
+
+// ToSlice returns the elements in the ring, from oldest to newest,
+// without extracting them.
+func (r *Ring) ToSlice() []interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]interface{}, r.len)
+	for i := range result {
+		result[i] = r.buf.get((r.head + i) % r.buf.size())
+	}
+
+	return result
+}
+
+// Clear removes all the elements in the ring, without counting them as
+// extracted nor dropped, and without notifying the observer.
+func (r *Ring) Clear() {
+	r.mu.Lock()
+	defer r.unlock()
+
+	r.clear()
+}
+
+// removes all the elements in the ring, releasing the references to
+// them.
+func (r *Ring) clear() {
+	r.buf.reset(r.head, r.len)
+
+	r.head = 0
+	r.len = 0
+
+	if r.soft != nil {
+		r.soft.armed = true
+	}
+
+	r.checkInvariants()
+}
+
+// Close stops the background goroutines of the ring.  If the ring was
+// created with the WithAutoSnapshot option, it also writes a last
+// snapshot and returns its error.  If it was created with the
+// WithRollup option and an interval, it also rolls up its elements a
+// last time.  The ring can still be used after closing it.
+func (r *Ring) Close() error {
+	var err error
+
+	r.closeOnce.Do(func() {
+		if r.stop == nil { // the zero Ring has no background goroutines
+			return
+		}
+
+		close(r.stop)
+		r.done.Wait()
+
+		if r.snapshot != "" {
+			err = r.Checkpoint(r.snapshot)
+		}
+	})
+
+	return err
 }
@@ -1,8 +1,13 @@
 package ring_test
 
 import (
+	"context"
 	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/alcortesm/ring"
 )
@@ -19,6 +24,18 @@ func TestRing(t *testing.T) {
 		"forgets oldest":                 ringForgetsOldest,
 		"extracts OK after forgetting":   ringExtractsOKAfterForgetting,
 		"all together":                   ringAllTogether,
+		"cap":                            ringCap,
+		"to slice":                       ringToSlice,
+		"concurrent use":                 ringConcurrentUse,
+		"dropped":                        ringDropped,
+		"name and labels":                ringNameAndLabels,
+		"clear":                          ringClear,
+		"releases extracted":             ringReleasesExtracted,
+		"zero value":                     ringZeroValue,
+		"set capacity":                   ringSetCapacity,
+		"peek func":                      ringPeekFunc,
+		"peek func holds the lock":       ringPeekFuncHoldsTheLock,
+		"collect":                        ringCollect,
 	}
 
 	for name, testFn := range subtests {
@@ -350,3 +367,365 @@ func ringAllTogether(t *testing.T) {
 	assertExtract(t, r, 18) // []
 	assertEmpty(t, r)       //
 }
+
+// tests that the capacity is the one given on construction.
+func ringCap(t *testing.T) {
+	for _, cap := range []int{1, 2, 42} {
+		cap := cap
+		name := fmt.Sprintf("cap=%d", cap)
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := ring.New(cap)
+			if err != nil {
+				t.Fatalf("creating ring: %v", err)
+			}
+
+			if got := r.Cap(); got != cap {
+				t.Errorf("wrong capacity, want %d, got %d", cap, got)
+			}
+		})
+	}
+}
+
+// tests that ToSlice returns the elements from oldest to newest
+// without extracting them.
+func ringToSlice(t *testing.T) {
+	r, err := ring.New(4)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	if got := r.ToSlice(); len(got) != 0 {
+		t.Fatalf("want empty slice, got %v", got)
+	}
+
+	for i := 1; i <= 6; i++ {
+		r.Insert(i)
+	}
+
+	want := []interface{}{3, 4, 5, 6}
+	if got := r.ToSlice(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	assertLen(t, r, 4)
+	assertPeek(t, r, 3)
+}
+
+// tests that the ring can be used from several goroutines at the same
+// time, run with -race to get the most out of it.
+func ringConcurrentUse(t *testing.T) {
+	r, err := ring.New(8)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	const n = 100
+
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < n; i++ {
+			r.Insert(i)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < n; i++ {
+			_, _ = r.Extract()
+			_ = r.ToSlice()
+		}
+	}()
+
+	wg.Wait()
+
+	if got := r.Len(); got < 0 || got > r.Cap() {
+		t.Errorf("length out of bounds: %d", got)
+	}
+}
+
+// tests that the elements dropped to make room for new ones are
+// counted.
+func ringDropped(t *testing.T) {
+	r := newRing(t, 2, 1, 2)
+
+	if got := r.Dropped(); got != 0 {
+		t.Fatalf("want no dropped elements, got %d", got)
+	}
+
+	r.Insert(3)
+	r.Insert(4)
+	r.Extract()
+
+	if got := r.Dropped(); got != 2 {
+		t.Fatalf("want 2 dropped elements, got %d", got)
+	}
+
+	if got := r.ResetDropped(); got != 2 {
+		t.Fatalf("want reset to return 2, got %d", got)
+	}
+
+	r.Insert(5)
+
+	if got := r.Dropped(); got != 0 {
+		t.Fatalf("want no dropped elements after reset, got %d", got)
+	}
+}
+
+// tests the WithName and WithLabels options.
+func ringNameAndLabels(t *testing.T) {
+	labels := map[string]string{"pipeline": "ingest"}
+
+	r, err := ring.New(2, ring.WithName("requests"), ring.WithLabels(labels))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	// the ring keeps its own copy of the labels
+	labels["pipeline"] = "changed"
+
+	if got := r.Name(); got != "requests" {
+		t.Errorf("want name %q, got %q", "requests", got)
+	}
+
+	got := r.Labels()
+	if !reflect.DeepEqual(got, map[string]string{"pipeline": "ingest"}) {
+		t.Errorf("unexpected labels %v", got)
+	}
+
+	got["pipeline"] = "changed"
+
+	if r.Labels()["pipeline"] != "ingest" {
+		t.Error("labels modified through the returned map")
+	}
+
+	if r := newRing(t, 2); r.Name() != "" || len(r.Labels()) != 0 {
+		t.Errorf("unexpected name %q and labels %v", r.Name(), r.Labels())
+	}
+}
+
+// tests that clearing the ring removes all its elements, without
+// counting them as extracted nor dropped.
+func ringClear(t *testing.T) {
+	r := newRing(t, 3, 1, 2, 3, 4)
+
+	r.Clear()
+	assertEmpty(t, r)
+
+	if got := r.Stats(); got.Extracted != 0 || got.Dropped != 1 {
+		t.Errorf("want 0 extracted and 1 dropped, got %d and %d", got.Extracted, got.Dropped)
+	}
+
+	r.Insert(5)
+	r.Insert(6)
+	assertExtract(t, r, 5)
+	assertExtract(t, r, 6)
+}
+
+// tests that the ring does not keep references to the elements it
+// extracts.
+func ringReleasesExtracted(t *testing.T) {
+	r, err := ring.New(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	released := make(chan int, 2)
+
+	for i := 0; i < 2; i++ {
+		p := new([1 << 10]byte)
+		id := i
+		runtime.SetFinalizer(p, func(*[1 << 10]byte) { released <- id })
+		r.Insert(p)
+	}
+
+	r.Extract()
+	r.Extract()
+	r.Insert(0) // does not overwrite the slots of the extracted ones
+
+	deadline := time.Now().Add(time.Second)
+
+	for n := 0; n < 2; {
+		if time.Now().After(deadline) {
+			t.Fatalf("only %d of 2 elements released", n)
+		}
+
+		runtime.GC()
+
+		select {
+		case <-released:
+			n++
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	runtime.KeepAlive(r)
+}
+
+// tests that the zero ring is usable, with the default capacity.
+func ringZeroValue(t *testing.T) {
+	var s struct {
+		events ring.Ring
+	}
+
+	r := &s.events
+	assertEmpty(t, r)
+
+	if got := r.Cap(); got != ring.DefaultCapacity {
+		t.Errorf("want capacity %d, got %d", ring.DefaultCapacity, got)
+	}
+
+	for i := 0; i <= ring.DefaultCapacity; i++ {
+		r.Insert(i)
+	}
+
+	assertLen(t, r, ring.DefaultCapacity)
+
+	if got := r.Dropped(); got != 1 {
+		t.Errorf("want 1 dropped, got %d", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if v, err := r.ExtractContext(ctx); err != nil || v != 1 {
+		t.Errorf("want to extract 1, got %v, %v", v, err)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Errorf("closing: %v", err)
+	}
+}
+
+// tests that the capacity of zero rings can be set before using them,
+// and only then.
+func ringSetCapacity(t *testing.T) {
+	var r ring.Ring
+
+	if err := r.SetCapacity(0); err == nil {
+		t.Error("capacity 0: unexpected success")
+	}
+
+	if err := r.SetCapacity(2); err != nil {
+		t.Fatalf("setting capacity: %v", err)
+	}
+
+	if err := r.SetCapacity(3); err == nil {
+		t.Error("capacity already set: unexpected success")
+	}
+
+	for i := 1; i <= 3; i++ {
+		r.Insert(i)
+	}
+
+	if got := fmt.Sprint(r.ToSlice(), r.Cap()); got != "[2 3] 2" {
+		t.Errorf("want contents [2 3] and capacity 2, got %s", got)
+	}
+
+	var used ring.Ring
+	used.Insert(1)
+
+	if err := used.SetCapacity(2); err == nil {
+		t.Error("inserted into: unexpected success")
+	}
+
+	if err := newRing(t, 2).SetCapacity(3); err == nil {
+		t.Error("created with New: unexpected success")
+	}
+}
+
+// tests that PeekFunc calls its function with the oldest element, if
+// any, without extracting it.
+func ringPeekFunc(t *testing.T) {
+	r := newRing(t, 3)
+
+	var got []interface{}
+	peek := func(v interface{}) { got = append(got, v) }
+
+	if r.PeekFunc(peek) {
+		t.Error("empty ring: unexpected peek")
+	}
+
+	r.Insert(1)
+	r.Insert(2)
+
+	if !r.PeekFunc(peek) {
+		t.Error("unexpected failure to peek")
+	}
+
+	if want := []interface{}{1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want to peek %v, got %v", want, got)
+	}
+
+	assertLen(t, r, 2)
+}
+
+// tests that the oldest element can not be dropped while PeekFunc
+// inspects it.
+func ringPeekFuncHoldsTheLock(t *testing.T) {
+	r := newRing(t, 1, 1)
+	inserted := make(chan struct{})
+
+	r.PeekFunc(func(v interface{}) {
+		go func() {
+			r.Insert(2)
+			close(inserted)
+		}()
+
+		select {
+		case <-inserted:
+			t.Error("element dropped while peeking it")
+		case <-time.After(10 * time.Millisecond):
+		}
+	})
+
+	<-inserted
+	assertPeek(t, r, 2)
+}
+
+// tests that Collect builds rings from sequences, keeping only their
+// last elements.
+func ringCollect(t *testing.T) {
+	count := func(n int) func(func(interface{}) bool) {
+		return func(yield func(interface{}) bool) {
+			for i := 0; i < n; i++ {
+				if !yield(i) {
+					return
+				}
+			}
+		}
+	}
+
+	if _, err := ring.Collect(0, count(1)); err == nil {
+		t.Error("capacity 0: unexpected success")
+	}
+
+	tests := map[int]string{
+		0: "[]",
+		2: "[0 1]",
+		5: "[2 3 4]",
+	}
+
+	for n, want := range tests {
+		r, err := ring.Collect(3, count(n))
+		if err != nil {
+			t.Fatalf("%d elements: unexpected error: %v", n, err)
+		}
+
+		if got := fmt.Sprint(r.ToSlice()); got != want {
+			t.Errorf("%d elements: want %v, got %v", n, want, got)
+		}
+
+		if r.Cap() != 3 {
+			t.Errorf("%d elements: want capacity 3, got %d", n, r.Cap())
+		}
+	}
+}
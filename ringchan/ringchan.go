@@ -0,0 +1,88 @@
+/*
+Package ringchan implements channels with drop-oldest semantics.
+
+Sending to a Go channel blocks when its buffer is full.  The channels
+in this package never block senders; instead, when their buffer is
+full, they drop the oldest element to make room for the new one.
+*/
+package ringchan
+
+import (
+	"github.com/alcortesm/ring"
+)
+
+// Chan is a channel with a bounded buffer that drops its oldest
+// elements when full.  Elements are sent to In and received from Out.
+type Chan struct {
+	in  chan interface{}
+	out chan interface{}
+	buf *ring.Ring
+}
+
+// New returns a new channel with a buffer of the given capacity.
+//
+// Each channel runs a goroutine moving elements from In to Out, which
+// ends after In is closed and all the buffered elements have been
+// received from Out.
+func New(cap int) (*Chan, error) {
+	buf, err := ring.New(cap)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Chan{
+		in:  make(chan interface{}),
+		out: make(chan interface{}),
+		buf: buf,
+	}
+
+	go c.run()
+
+	return c, nil
+}
+
+// In returns the sending side of the channel.  Close it when there
+// are no more elements to send; Out will be closed once the elements
+// still in the buffer are received.
+func (c *Chan) In() chan<- interface{} {
+	return c.in
+}
+
+// Out returns the receiving side of the channel.
+func (c *Chan) Out() <-chan interface{} {
+	return c.out
+}
+
+// Len returns the amount of elements buffered in the channel.
+func (c *Chan) Len() int {
+	return c.buf.Len()
+}
+
+// moves elements from in to the buffer and from the buffer to out.
+func (c *Chan) run() {
+	defer close(c.out)
+
+	in := c.in
+
+	for in != nil || c.buf.Len() > 0 {
+		// a nil channel disables its select case
+		var out chan interface{}
+
+		next, ok := c.buf.Peek()
+		if ok {
+			out = c.out
+		}
+
+		select {
+		case v, ok := <-in:
+			if !ok {
+				in = nil
+				continue
+			}
+
+			c.buf.Insert(v)
+		case out <- next:
+			_, _ = c.buf.Extract()
+		}
+	}
+}
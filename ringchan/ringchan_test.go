@@ -0,0 +1,150 @@
+package ringchan_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring/ringchan"
+)
+
+func TestChan(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid capacity":     chanInvalidCapacity,
+		"send and receive":     chanSendAndReceive,
+		"never blocks senders": chanNeverBlocksSenders,
+		"close drains buffer":  chanCloseDrainsBuffer,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a new channel with the given capacity or fails the test.
+func newChan(t *testing.T, cap int) *ringchan.Chan {
+	t.Helper()
+
+	c, err := ringchan.New(cap)
+	if err != nil {
+		t.Fatalf("creating channel: %v", err)
+	}
+
+	return c
+}
+
+// waits until the channel buffers n elements or fails the test.
+func waitLen(t *testing.T, c *ringchan.Chan, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for c.Len() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for length %d, got %d", n, c.Len())
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// receives all the elements in the channel until it is closed.
+func receiveAll(t *testing.T, c *ringchan.Chan) []interface{} {
+	t.Helper()
+
+	var result []interface{}
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case v, ok := <-c.Out():
+			if !ok {
+				return result
+			}
+
+			result = append(result, v)
+		case <-timeout:
+			t.Fatalf("timeout receiving, got %v so far", result)
+		}
+	}
+}
+
+// tests that capacities smaller than 1 are invalid
+func chanInvalidCapacity(t *testing.T) {
+	for _, cap := range []int{0, -1, -42} {
+		cap := cap
+		name := fmt.Sprintf("cap=%d", cap)
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ringchan.New(cap)
+			if err == nil {
+				t.Fatal("unexpected success")
+			}
+		})
+	}
+}
+
+// tests that elements are received in the same order they are sent.
+func chanSendAndReceive(t *testing.T) {
+	c := newChan(t, 4)
+
+	c.In() <- 1
+	c.In() <- 2
+
+	for _, want := range []int{1, 2} {
+		if got := <-c.Out(); got != want {
+			t.Errorf("want %d, got %v", want, got)
+		}
+	}
+
+	close(c.In())
+
+	if got := receiveAll(t, c); len(got) != 0 {
+		t.Errorf("unexpected elements after close: %v", got)
+	}
+}
+
+// tests that sending to a full channel drops the oldest elements.
+func chanNeverBlocksSenders(t *testing.T) {
+	c := newChan(t, 3)
+
+	for i := 1; i <= 10; i++ {
+		select {
+		case c.In() <- i:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("blocked sending %d", i)
+		}
+	}
+
+	waitLen(t, c, 3)
+	close(c.In())
+
+	want := []interface{}{8, 9, 10}
+	if got := receiveAll(t, c); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+// tests that closing In does not lose the buffered elements.
+func chanCloseDrainsBuffer(t *testing.T) {
+	c := newChan(t, 4)
+
+	c.In() <- "a"
+	c.In() <- "b"
+	close(c.In())
+
+	want := []interface{}{"a", "b"}
+	if got := receiveAll(t, c); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
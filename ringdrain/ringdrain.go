@@ -0,0 +1,281 @@
+/*
+Package ringdrain implements a background drainer that moves the
+elements of a ring to a sink, like an exporter to a remote collector,
+retrying failed writes with exponential backoff and flushing the ring
+on shutdown.
+*/
+package ringdrain
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+// Sink receives the elements drained from a ring.
+type Sink interface {
+	// Write exports the given elements, from oldest to newest.  It
+	// should give up when ctx is done.
+	Write(ctx context.Context, elements []interface{}) error
+}
+
+// Drainer periodically extracts the elements of a ring and writes them
+// to a sink, from a background goroutine.
+//
+// Elements are written in batches.  A failed write is retried with
+// exponential backoff, and if it keeps failing, its batch is discarded
+// and draining stops until the next round, leaving the rest of the
+// elements in the ring.
+type Drainer struct {
+	r    *ring.Ring
+	sink Sink
+	cfg  config
+
+	ctx    context.Context // canceled to abort the writes in progress
+	cancel context.CancelFunc
+
+	closeOnce sync.Once
+	stop      chan struct{} // closed to stop draining
+	done      chan struct{} // closed when the background goroutine ends
+	flushErr  error         // the error of the last flush
+}
+
+// Option configures a drainer on construction.
+type Option func(*config)
+
+// the configuration set by the options.
+type config struct {
+	interval   time.Duration
+	threshold  int
+	batchSize  int
+	retries    int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	onError    func(err error, elements []interface{})
+	clock      ring.Clock
+}
+
+// WithInterval sets how often the ring is drained, 1 second by default.
+func WithInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.interval = d
+	}
+}
+
+// WithThreshold makes the drainer also drain the ring as soon as it
+// holds n elements, without waiting for the next interval.
+func WithThreshold(n int) Option {
+	return func(c *config) {
+		c.threshold = n
+	}
+}
+
+// WithBatchSize sets the maximum amount of elements written to the sink
+// at once.  By default, there is no maximum.
+func WithBatchSize(n int) Option {
+	return func(c *config) {
+		c.batchSize = n
+	}
+}
+
+// WithRetries sets how many times a failed write is retried before
+// discarding its batch, 3 by default.
+func WithRetries(n int) Option {
+	return func(c *config) {
+		c.retries = n
+	}
+}
+
+// WithBackoff sets how long to wait before retrying a failed write,
+// starting at min and doubling after each failure up to max, 100
+// milliseconds and 5 seconds by default.
+func WithBackoff(min, max time.Duration) Option {
+	return func(c *config) {
+		c.minBackoff = min
+		c.maxBackoff = max
+	}
+}
+
+// WithOnError makes the drainer call fn with the error of each batch
+// discarded after failing to write it, and its elements.
+func WithOnError(fn func(err error, elements []interface{})) Option {
+	return func(c *config) {
+		c.onError = fn
+	}
+}
+
+// WithClock makes the drainer tell the time with c instead of the time
+// package, for its interval and its backoff.  It is meant for tests
+// advancing the time deterministically.
+func WithClock(c ring.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+// New returns a new drainer moving the elements of r to sink, which
+// starts draining right away.  Call Close to stop it.
+func New(r *ring.Ring, sink Sink, opts ...Option) (*Drainer, error) {
+	c := config{
+		interval:   time.Second,
+		retries:    3,
+		minBackoff: 100 * time.Millisecond,
+		maxBackoff: 5 * time.Second,
+		clock:      ring.SystemClock{},
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	switch {
+	case c.interval <= 0:
+		return nil, fmt.Errorf("interval must be > 0, got %v", c.interval)
+	case c.threshold < 0:
+		return nil, fmt.Errorf("threshold must be >= 0, got %d", c.threshold)
+	case c.batchSize < 0:
+		return nil, fmt.Errorf("batch size must be >= 0, got %d", c.batchSize)
+	case c.retries < 0:
+		return nil, fmt.Errorf("retries must be >= 0, got %d", c.retries)
+	case c.minBackoff <= 0 || c.maxBackoff < c.minBackoff:
+		return nil, fmt.Errorf("invalid backoff range [%v, %v]", c.minBackoff, c.maxBackoff)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	d := &Drainer{
+		r:      r,
+		sink:   sink,
+		cfg:    c,
+		ctx:    ctx,
+		cancel: cancel,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	go d.run()
+
+	return d, nil
+}
+
+// drains the ring every interval, or when it reaches the threshold,
+// until stopped, and flushes it then.
+func (d *Drainer) run() {
+	defer close(d.done)
+	defer d.cancel()
+
+	ticker := d.cfg.clock.NewTicker(d.cfg.interval)
+	defer ticker.Stop()
+
+	// nil, so never ready, unless there is a threshold
+	var next <-chan struct{}
+	if d.cfg.threshold > 0 {
+		next = d.r.NextInsert()
+	}
+
+	for {
+		select {
+		case <-d.stop:
+			d.flushErr = d.drain()
+			return
+		case <-ticker.C():
+			_ = d.drain()
+		case <-next:
+			if d.r.Len() >= d.cfg.threshold {
+				_ = d.drain()
+			}
+
+			next = d.r.NextInsert()
+		}
+	}
+}
+
+// writes the elements in the ring to the sink, batch by batch, until
+// the ring is empty or a batch is discarded.  It returns the error of
+// the discarded batch.
+func (d *Drainer) drain() error {
+	for {
+		batch := d.extractBatch()
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := d.write(batch); err != nil {
+			if d.cfg.onError != nil {
+				d.cfg.onError(err, batch)
+			}
+
+			return err
+		}
+	}
+}
+
+// extracts the next batch of elements from the ring.
+func (d *Drainer) extractBatch() []interface{} {
+	var batch []interface{}
+
+	for d.cfg.batchSize == 0 || len(batch) < d.cfg.batchSize {
+		v, ok := d.r.Extract()
+		if !ok {
+			break
+		}
+
+		batch = append(batch, v)
+	}
+
+	return batch
+}
+
+// writes the batch to the sink, retrying with backoff.
+func (d *Drainer) write(batch []interface{}) error {
+	backoff := d.cfg.minBackoff
+
+	for attempt := 0; ; attempt++ {
+		err := d.sink.Write(d.ctx, batch)
+		if err == nil {
+			return nil
+		}
+
+		if attempt == d.cfg.retries {
+			return fmt.Errorf("writing %d elements, after %d attempts: %w", len(batch), attempt+1, err)
+		}
+
+		// the first tick of a ticker, as clocks have no timers
+		ticker := d.cfg.clock.NewTicker(backoff)
+
+		select {
+		case <-ticker.C():
+			ticker.Stop()
+		case <-d.ctx.Done():
+			ticker.Stop()
+			return fmt.Errorf("writing %d elements: %w", len(batch), err)
+		}
+
+		if backoff *= 2; backoff > d.cfg.maxBackoff {
+			backoff = d.cfg.maxBackoff
+		}
+	}
+}
+
+// Close stops the drainer, and flushes the ring, writing its remaining
+// elements to the sink.  If ctx is done before that, the writes in
+// progress are aborted and the context error is returned.  Otherwise,
+// it returns the error of the batch discarded during the flush, if any.
+func (d *Drainer) Close(ctx context.Context) error {
+	d.closeOnce.Do(func() {
+		close(d.stop)
+	})
+
+	select {
+	case <-d.done:
+		return d.flushErr
+	case <-ctx.Done():
+		d.cancel()
+		<-d.done
+
+		return ctx.Err()
+	}
+}
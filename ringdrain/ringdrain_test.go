@@ -0,0 +1,299 @@
+package ringdrain_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringdrain"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestDrainer(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid options":    drainerInvalidOptions,
+		"every interval":     drainerEveryInterval,
+		"threshold":          drainerThreshold,
+		"flush in batches":   drainerFlushInBatches,
+		"retries":            drainerRetries,
+		"gives up":           drainerGivesUp,
+		"close aborts flush": drainerCloseAbortsFlush,
+		"clock":              drainerClock,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// a sink recording the written batches, failing while fail returns an
+// error.
+type sink struct {
+	mu      sync.Mutex
+	batches []string
+	fail    func(ctx context.Context) error
+}
+
+func (s *sink) Write(ctx context.Context, elements []interface{}) error {
+	if s.fail != nil {
+		if err := s.fail(ctx); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batches = append(s.batches, fmt.Sprint(elements...))
+
+	return nil
+}
+
+// returns the batches written to the sink.
+func (s *sink) written() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return fmt.Sprint(s.batches)
+}
+
+// waits for the sink to receive the wanted batches or fails the test.
+func (s *sink) waitFor(t *testing.T, want string) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for s.written() != want && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if got := s.written(); got != want {
+		t.Fatalf("want written batches %s, got %s", want, got)
+	}
+}
+
+// returns a ring with the given elements or fails the test.
+func newRing(t *testing.T, vs ...interface{}) *ring.Ring {
+	t.Helper()
+
+	r, err := ring.New(10)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	for _, v := range vs {
+		r.Insert(v)
+	}
+
+	return r
+}
+
+// returns a drainer from r to s or fails the test.
+func newDrainer(t *testing.T, r *ring.Ring, s ringdrain.Sink, opts ...ringdrain.Option) *ringdrain.Drainer {
+	t.Helper()
+
+	d, err := ringdrain.New(r, s, opts...)
+	if err != nil {
+		t.Fatalf("creating drainer: %v", err)
+	}
+
+	return d
+}
+
+// closes the drainer or fails the test.
+func closeDrainer(t *testing.T, d *ringdrain.Drainer) {
+	t.Helper()
+
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatalf("closing drainer: %v", err)
+	}
+}
+
+// tests that invalid options are rejected.
+func drainerInvalidOptions(t *testing.T) {
+	for name, opt := range map[string]ringdrain.Option{
+		"interval":   ringdrain.WithInterval(0),
+		"threshold":  ringdrain.WithThreshold(-1),
+		"batch size": ringdrain.WithBatchSize(-1),
+		"retries":    ringdrain.WithRetries(-1),
+		"backoff":    ringdrain.WithBackoff(time.Second, time.Millisecond),
+	} {
+		if _, err := ringdrain.New(newRing(t), &sink{}, opt); err == nil {
+			t.Errorf("%s: unexpected success", name)
+		}
+	}
+}
+
+// tests that the ring is drained periodically.
+func drainerEveryInterval(t *testing.T) {
+	s := &sink{}
+	r := newRing(t, 1, 2)
+	d := newDrainer(t, r, s, ringdrain.WithInterval(10*time.Millisecond))
+	defer closeDrainer(t, d)
+
+	s.waitFor(t, "[1 2]")
+
+	r.Insert(3)
+	s.waitFor(t, "[1 2 3]")
+}
+
+// tests that the ring is drained as soon as it reaches the threshold.
+func drainerThreshold(t *testing.T) {
+	s := &sink{}
+	r := newRing(t)
+	d := newDrainer(t, r, s, ringdrain.WithInterval(time.Hour), ringdrain.WithThreshold(3))
+	defer closeDrainer(t, d)
+
+	r.Insert(1)
+	r.Insert(2)
+	time.Sleep(10 * time.Millisecond)
+
+	if got := s.written(); got != "[]" {
+		t.Fatalf("drained before reaching the threshold: %s", got)
+	}
+
+	r.Insert(3)
+	s.waitFor(t, "[1 2 3]")
+}
+
+// tests that closing the drainer flushes the ring in batches.
+func drainerFlushInBatches(t *testing.T) {
+	s := &sink{}
+	r := newRing(t, 1, 2, 3, 4, 5)
+	d := newDrainer(t, r, s, ringdrain.WithInterval(time.Hour), ringdrain.WithBatchSize(2))
+
+	closeDrainer(t, d)
+
+	if got := s.written(); got != "[1 2 3 4 5]" {
+		t.Errorf("want written batches [1 2 3 4 5], got %s", got)
+	}
+
+	if got := len(s.batches); got != 3 {
+		t.Errorf("want 3 batches, got %d", got)
+	}
+}
+
+// tests that failed writes are retried.
+func drainerRetries(t *testing.T) {
+	var failures int
+
+	s := &sink{fail: func(context.Context) error {
+		if failures < 2 {
+			failures++
+			return errors.New("boom")
+		}
+
+		return nil
+	}}
+
+	r := newRing(t, 1, 2)
+	d := newDrainer(t, r, s,
+		ringdrain.WithInterval(time.Hour),
+		ringdrain.WithBackoff(time.Millisecond, time.Millisecond))
+
+	closeDrainer(t, d)
+
+	if got := s.written(); got != "[1 2]" {
+		t.Errorf("want written batches [1 2], got %s", got)
+	}
+}
+
+// tests that batches failing too many times are discarded, leaving the
+// rest of the elements in the ring.
+func drainerGivesUp(t *testing.T) {
+	var (
+		attempts  int
+		discarded []interface{}
+	)
+
+	boom := errors.New("boom")
+	s := &sink{fail: func(context.Context) error {
+		attempts++
+		return boom
+	}}
+
+	r := newRing(t, 1, 2, 3)
+	d := newDrainer(t, r, s,
+		ringdrain.WithInterval(time.Hour),
+		ringdrain.WithBatchSize(1),
+		ringdrain.WithRetries(2),
+		ringdrain.WithBackoff(time.Millisecond, time.Millisecond),
+		ringdrain.WithOnError(func(err error, elements []interface{}) {
+			discarded = elements
+		}))
+
+	if err := d.Close(context.Background()); !errors.Is(err, boom) {
+		t.Fatalf("want error %v, got %v", boom, err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("want 3 attempts, got %d", attempts)
+	}
+
+	if got := fmt.Sprint(discarded); got != "[1]" {
+		t.Errorf("want discarded [1], got %s", got)
+	}
+
+	if got := fmt.Sprint(r.ToSlice()); got != "[2 3]" {
+		t.Errorf("want [2 3] left in the ring, got %s", got)
+	}
+}
+
+// tests that closing gives up flushing when its context is done.
+func drainerCloseAbortsFlush(t *testing.T) {
+	s := &sink{fail: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	d := newDrainer(t, newRing(t, 1), s, ringdrain.WithInterval(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := d.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded error, got %v", err)
+	}
+}
+
+// tests that the interval and the backoff are timed by the clock.
+func drainerClock(t *testing.T) {
+	var failed bool
+
+	s := &sink{fail: func(context.Context) error {
+		if !failed {
+			failed = true
+			return errors.New("boom")
+		}
+
+		return nil
+	}}
+
+	clock := ringtest.NewClock(time.Unix(0, 0))
+	d := newDrainer(t, newRing(t, 1), s,
+		ringdrain.WithInterval(time.Hour),
+		ringdrain.WithBackoff(time.Hour, time.Hour),
+		ringdrain.WithClock(clock))
+	defer closeDrainer(t, d)
+
+	clock.WaitTickers(1)
+	clock.Advance(time.Hour) // drains, failing
+
+	clock.WaitTickers(2)
+
+	if got := s.written(); got != "[]" {
+		t.Fatalf("written before the backoff: %s", got)
+	}
+
+	clock.Advance(time.Hour) // retries
+	s.waitFor(t, "[1]")
+}
@@ -0,0 +1,144 @@
+/*
+Package ringerr keeps the last errors of a program, like the ones shown
+in the status page of a service.
+*/
+package ringerr
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+// Entry is a recorded error.  Consecutive errors with the same message
+// are recorded as a single entry.
+type Entry struct {
+	Err   error
+	First time.Time // when the error was first recorded
+	Last  time.Time // when the error was last recorded
+	Count int       // how many times in a row the error was recorded
+}
+
+// Recorder keeps the last errors recorded in it.
+type Recorder struct {
+	mu      sync.Mutex
+	entries *ring.Ring // *Entry
+	newest  *Entry     // the newest entry in the ring, nil if empty
+	clock   ring.Clock
+}
+
+// Option configures a recorder on construction.
+type Option func(*config)
+
+// the configuration set by the options.
+type config struct {
+	clock ring.Clock
+}
+
+// WithClock makes the recorder tell the time of the errors with c
+// instead of the time package.  It is meant for tests advancing the
+// time deterministically.
+func WithClock(c ring.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+// NewRecorder returns a recorder keeping the last n entries, with the
+// given options.
+func NewRecorder(n int, opts ...Option) (*Recorder, error) {
+	c := config{clock: ring.SystemClock{}}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	entries, err := ring.New(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recorder{entries: entries, clock: c.clock}, nil
+}
+
+// Record records err, dropping the oldest entry if there are already n
+// entries.  If err has the same message as the last recorded error, its
+// entry count is incremented instead.  Nil errors are ignored.
+func (r *Recorder) Record(err error) {
+	if err == nil {
+		return
+	}
+
+	now := r.clock.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.newest != nil && r.newest.Err.Error() == err.Error() {
+		r.newest.Err = err
+		r.newest.Last = now
+		r.newest.Count++
+
+		return
+	}
+
+	r.newest = &Entry{
+		Err:   err,
+		First: now,
+		Last:  now,
+		Count: 1,
+	}
+
+	r.entries.Insert(r.newest)
+}
+
+// Entries returns copies of the entries, from oldest to newest.
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	vs := r.entries.ToSlice()
+
+	result := make([]Entry, len(vs))
+	for i, v := range vs {
+		result[i] = *v.(*Entry)
+	}
+
+	return result
+}
+
+// Last returns the last n recorded errors, from oldest to newest,
+// without repeating consecutive duplicates.
+func (r *Recorder) Last(n int) []error {
+	entries := r.Entries()
+	if n < len(entries) {
+		entries = entries[len(entries)-n:]
+	}
+
+	result := make([]error, len(entries))
+	for i, e := range entries {
+		result[i] = e.Err
+	}
+
+	return result
+}
+
+// Summary returns a human readable description of the entries, one per
+// line, from oldest to newest.
+func (r *Recorder) Summary() string {
+	var b strings.Builder
+
+	for _, e := range r.Entries() {
+		fmt.Fprintf(&b, "%s %s", e.Last.Format(time.RFC3339), e.Err)
+
+		if e.Count > 1 {
+			fmt.Fprintf(&b, " (%d times since %s)", e.Count, e.First.Format(time.RFC3339))
+		}
+
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
@@ -0,0 +1,145 @@
+package ringerr_test
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring/ringerr"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid capacity":   recorderInvalidCapacity,
+		"keeps last":         recorderKeepsLast,
+		"dedups consecutive": recorderDedupsConsecutive,
+		"last n":             recorderLastN,
+		"summary":            recorderSummary,
+		"ignores nil":        recorderIgnoresNil,
+		"clock":              recorderClock,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a recorder of n entries with the given errors or fails the
+// test.
+func newRecorder(t *testing.T, n int, msgs ...string) *ringerr.Recorder {
+	t.Helper()
+
+	r, err := ringerr.NewRecorder(n)
+	if err != nil {
+		t.Fatalf("creating recorder: %v", err)
+	}
+
+	for _, msg := range msgs {
+		r.Record(errors.New(msg))
+	}
+
+	return r
+}
+
+// tests that capacities smaller than 1 are invalid.
+func recorderInvalidCapacity(t *testing.T) {
+	if _, err := ringerr.NewRecorder(0); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that only the last entries are kept.
+func recorderKeepsLast(t *testing.T) {
+	r := newRecorder(t, 2, "a", "b", "c")
+
+	if got := fmt.Sprint(r.Last(10)); got != "[b c]" {
+		t.Errorf("want last errors [b c], got %s", got)
+	}
+}
+
+// tests that consecutive errors with the same message are counted in a
+// single entry.
+func recorderDedupsConsecutive(t *testing.T) {
+	r := newRecorder(t, 3, "a", "b", "b", "b", "a")
+
+	entries := r.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("want 3 entries, got %d", len(entries))
+	}
+
+	for i, want := range []int{1, 3, 1} {
+		if entries[i].Count != want {
+			t.Errorf("entry %d: want count %d, got %d", i, want, entries[i].Count)
+		}
+	}
+
+	b := entries[1]
+	if b.First.IsZero() || b.Last.Before(b.First) {
+		t.Errorf("unexpected timestamps: first %v, last %v", b.First, b.Last)
+	}
+}
+
+// tests getting the last n errors.
+func recorderLastN(t *testing.T) {
+	r := newRecorder(t, 5, "a", "b", "c")
+
+	if got := fmt.Sprint(r.Last(2)); got != "[b c]" {
+		t.Errorf("want last errors [b c], got %s", got)
+	}
+
+	if got := len(r.Last(0)); got != 0 {
+		t.Errorf("want no errors, got %d", got)
+	}
+}
+
+// tests the summary of the entries.
+func recorderSummary(t *testing.T) {
+	r := newRecorder(t, 5, "disk full", "timeout", "timeout")
+
+	time := `\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\S*`
+	want := regexp.MustCompile(`^` + time + ` disk full\n` +
+		time + ` timeout \(2 times since ` + time + `\)\n$`)
+
+	if got := r.Summary(); !want.MatchString(got) {
+		t.Errorf("unexpected summary:\n%s", got)
+	}
+}
+
+// tests that nil errors are not recorded.
+func recorderIgnoresNil(t *testing.T) {
+	r := newRecorder(t, 5)
+	r.Record(nil)
+
+	if got := len(r.Entries()); got != 0 {
+		t.Errorf("want no entries, got %d", got)
+	}
+}
+
+// tests that errors are recorded with the time of the clock.
+func recorderClock(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := ringtest.NewClock(start)
+
+	r, err := ringerr.NewRecorder(2, ringerr.WithClock(clock))
+	if err != nil {
+		t.Fatalf("creating recorder: %v", err)
+	}
+
+	r.Record(errors.New("boom"))
+	clock.Advance(time.Minute)
+	r.Record(errors.New("boom"))
+
+	got := r.Entries()
+	if len(got) != 1 || !got[0].First.Equal(start) || !got[0].Last.Equal(start.Add(time.Minute)) {
+		t.Errorf("want an entry first recorded at %v and last a minute later, got %+v", start, got)
+	}
+}
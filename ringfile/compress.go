@@ -0,0 +1,52 @@
+package ringfile
+
+import (
+	"bytes"
+	"compress/flate"
+	"io/ioutil"
+)
+
+// Compressor compresses the elements of a ring before writing them to
+// its backend.  Implementations must be safe for concurrent use.
+type Compressor interface {
+	// Compress returns the compressed form of p.
+	Compress(p []byte) ([]byte, error)
+	// Decompress returns the original form of the compressed p.
+	Decompress(p []byte) ([]byte, error)
+}
+
+// Flate returns a compressor using the DEFLATE format with the given
+// compression level, as defined in the compress/flate package.
+func Flate(level int) Compressor {
+	return flateCompressor{level: level}
+}
+
+type flateCompressor struct {
+	level int
+}
+
+func (c flateCompressor) Compress(p []byte) ([]byte, error) {
+	var b bytes.Buffer
+
+	w, err := flate.NewWriter(&b, c.level)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(p); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func (c flateCompressor) Decompress(p []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(p))
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
@@ -0,0 +1,134 @@
+package ringfile_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"strings"
+	"testing"
+
+	"github.com/alcortesm/ring/ringfile"
+)
+
+func TestCompression(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"flate round trip":     compressionFlateRoundTrip,
+		"invalid level":        compressionInvalidLevel,
+		"survives reopening":   compressionSurvivesReopening,
+		"shrinks the log":      compressionShrinksTheLog,
+		"survives compactions": compressionSurvivesCompactions,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// a very compressible log line.
+var logLine = strings.Repeat("level=info msg=\"request served\" status=200 ", 10)
+
+// opens a compressed ring or fails the test.
+func openCompressed(t *testing.T, path string, cap int) *ringfile.Ring {
+	t.Helper()
+
+	r, err := ringfile.Open(path, cap, ringfile.WithCompression(ringfile.Flate(flate.BestSpeed)))
+	if err != nil {
+		t.Fatalf("opening ring: %v", err)
+	}
+
+	return r
+}
+
+// tests that decompressing returns the original data.
+func compressionFlateRoundTrip(t *testing.T) {
+	c := ringfile.Flate(flate.DefaultCompression)
+
+	for _, want := range [][]byte{{}, []byte("a"), []byte(logLine)} {
+		compressed, err := c.Compress(want)
+		if err != nil {
+			t.Fatalf("compressing: %v", err)
+		}
+
+		got, err := c.Decompress(compressed)
+		if err != nil {
+			t.Fatalf("decompressing: %v", err)
+		}
+
+		if !bytes.Equal(got, want) {
+			t.Errorf("want %q, got %q", want, got)
+		}
+	}
+}
+
+// tests that invalid compression levels are reported when inserting.
+func compressionInvalidLevel(t *testing.T) {
+	r, err := ringfile.Open(logPath(t), 2, ringfile.WithCompression(ringfile.Flate(42)))
+	if err != nil {
+		t.Fatalf("opening ring: %v", err)
+	}
+
+	defer closeRing(t, r)
+
+	if err := r.Insert([]byte("a")); err == nil {
+		t.Fatal("unexpected success")
+	}
+
+	assertDrain(t, r)
+}
+
+// tests that compressed rings recover their contents when reopened.
+func compressionSurvivesReopening(t *testing.T) {
+	path := logPath(t)
+
+	r := openCompressed(t, path, 3)
+	insert(t, r, "a", logLine, "c", "d")
+	closeRing(t, r)
+
+	r = openCompressed(t, path, 3)
+	defer closeRing(t, r)
+
+	assertDrain(t, r, logLine, "c", "d")
+}
+
+// tests that compression reduces the size of the log.
+func compressionShrinksTheLog(t *testing.T) {
+	plainPath, compressedPath := logPath(t), logPath(t)
+
+	plain := open(t, plainPath, 4)
+	compressed := openCompressed(t, compressedPath, 4)
+
+	for i := 0; i < 4; i++ {
+		insert(t, plain, logLine)
+		insert(t, compressed, logLine)
+	}
+
+	closeRing(t, plain)
+	closeRing(t, compressed)
+
+	if p, c := fileSize(t, plainPath), fileSize(t, compressedPath); c*4 > p {
+		t.Errorf("compressed log too big: %d bytes, plain log is %d bytes", c, p)
+	}
+}
+
+// tests that compactions keep the elements compressed.
+func compressionSurvivesCompactions(t *testing.T) {
+	path := logPath(t)
+
+	r := openCompressed(t, path, 2)
+
+	for i := 0; i < 500; i++ {
+		insert(t, r, logLine)
+	}
+
+	closeRing(t, r)
+
+	r = openCompressed(t, path, 2)
+	defer closeRing(t, r)
+
+	assertDrain(t, r, logLine, logLine)
+}
@@ -0,0 +1,166 @@
+package ringfile_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"io/ioutil"
+	"testing"
+
+	"github.com/alcortesm/ring/ringfile"
+)
+
+func TestEncryption(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid key":          encryptionInvalidKey,
+		"survives reopening":   encryptionSurvivesReopening,
+		"no plaintext on disk": encryptionNoPlaintextOnDisk,
+		"wrong key":            encryptionWrongKey,
+		"tampered log":         encryptionTamperedLog,
+		"with compression":     encryptionWithCompression,
+		"survives compactions": encryptionSurvivesCompactions,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+var (
+	key      = []byte("0123456789abcdef0123456789abcdef")
+	otherKey = []byte("fedcba9876543210fedcba9876543210")
+)
+
+// opens an encrypted ring or fails the test.
+func openEncrypted(t *testing.T, path string, cap int, opts ...ringfile.Option) *ringfile.Ring {
+	t.Helper()
+
+	opts = append(opts, ringfile.WithEncryption(key))
+
+	r, err := ringfile.Open(path, cap, opts...)
+	if err != nil {
+		t.Fatalf("opening ring: %v", err)
+	}
+
+	return r
+}
+
+// tests that only AES key sizes are accepted.
+func encryptionInvalidKey(t *testing.T) {
+	for _, size := range []int{0, 1, 15, 33} {
+		_, err := ringfile.Open(logPath(t), 2, ringfile.WithEncryption(make([]byte, size)))
+		if err == nil {
+			t.Errorf("unexpected success with a key of %d bytes", size)
+		}
+	}
+}
+
+// tests that encrypted rings recover their contents when reopened.
+func encryptionSurvivesReopening(t *testing.T) {
+	path := logPath(t)
+
+	r := openEncrypted(t, path, 3)
+	insert(t, r, "a", "b", "c", "d")
+	closeRing(t, r)
+
+	r = openEncrypted(t, path, 3)
+	defer closeRing(t, r)
+
+	assertDrain(t, r, "b", "c", "d")
+}
+
+// tests that the elements are not written in plaintext.
+func encryptionNoPlaintextOnDisk(t *testing.T) {
+	path := logPath(t)
+
+	r := openEncrypted(t, path, 3)
+	insert(t, r, "user@example.com")
+	closeRing(t, r)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading log: %v", err)
+	}
+
+	if bytes.Contains(data, []byte("example")) {
+		t.Error("plaintext found in the log")
+	}
+}
+
+// tests that opening the ring with another key fails.
+func encryptionWrongKey(t *testing.T) {
+	path := logPath(t)
+
+	r := openEncrypted(t, path, 3)
+	insert(t, r, "a")
+	closeRing(t, r)
+
+	if _, err := ringfile.Open(path, 3, ringfile.WithEncryption(otherKey)); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that modified elements are detected.
+func encryptionTamperedLog(t *testing.T) {
+	b := &memBackend{}
+
+	r, err := ringfile.New(b, 3, ringfile.WithEncryption(key))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	insert(t, r, "a")
+
+	b.elements[0][len(b.elements[0])-1] ^= 0xff
+
+	if _, err := ringfile.New(b, 3, ringfile.WithEncryption(key)); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that encryption and compression can be combined.
+func encryptionWithCompression(t *testing.T) {
+	path := logPath(t)
+	compression := ringfile.WithCompression(ringfile.Flate(flate.BestSpeed))
+
+	r := openEncrypted(t, path, 3, compression)
+	insert(t, r, logLine, "b")
+	closeRing(t, r)
+
+	r = openEncrypted(t, path, 3, compression)
+	defer closeRing(t, r)
+
+	assertDrain(t, r, logLine, "b")
+}
+
+// tests that compactions keep the elements encrypted.
+func encryptionSurvivesCompactions(t *testing.T) {
+	path := logPath(t)
+
+	r := openEncrypted(t, path, 2)
+
+	for i := 0; i < 500; i++ {
+		insert(t, r, "secret")
+	}
+
+	closeRing(t, r)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading log: %v", err)
+	}
+
+	if bytes.Contains(data, []byte("secret")) {
+		t.Error("plaintext found in the log")
+	}
+
+	r = openEncrypted(t, path, 2)
+	defer closeRing(t, r)
+
+	assertDrain(t, r, "secret", "secret")
+}
@@ -0,0 +1,136 @@
+package ringfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+/*
+The log file starts with a header made of the magic string "RINGLOG"
+followed by a version byte.  Then comes a sequence of records:
+
+	+--------+----------------+-----------------+----------------+
+	| op (1) | length (4, BE) | payload (length) | crc32 (4, BE)  |
+	+--------+----------------+-----------------+----------------+
+
+The op is opInsert or opExtract; extract records have no payload.  The
+CRC is the IEEE CRC-32 of the op, length and payload bytes.
+*/
+
+const (
+	magic   = "RINGLOG"
+	version = 1
+
+	opInsert  byte = 'I'
+	opExtract byte = 'X'
+
+	headerSize       = len(magic) + 1
+	recordHeaderSize = 1 + 4
+	recordCRCSize    = 4
+)
+
+// ErrCorrupt is returned when reading a record of a log file that was
+// not fully written or that was damaged afterwards, see Inspect.
+var ErrCorrupt = errors.New("corrupt record")
+
+// writes the log file header.
+func writeHeader(w io.Writer) error {
+	_, err := w.Write(append([]byte(magic), version))
+	return err
+}
+
+// reads and checks the log file header.
+func readHeader(r io.Reader) error {
+	var h [headerSize]byte
+
+	if _, err := io.ReadFull(r, h[:]); err != nil {
+		return fmt.Errorf("reading header: %w", err)
+	}
+
+	if string(h[:len(magic)]) != magic {
+		return errors.New("not a ring log file")
+	}
+
+	if v := h[len(magic)]; v != version {
+		return fmt.Errorf("unsupported log version %d", v)
+	}
+
+	return nil
+}
+
+// returns the encoded record with the given op and payload.
+func encodeRecord(op byte, payload []byte) []byte {
+	b := make([]byte, recordHeaderSize+len(payload)+recordCRCSize)
+	b[0] = op
+	binary.BigEndian.PutUint32(b[1:], uint32(len(payload)))
+	copy(b[recordHeaderSize:], payload)
+
+	sum := crc32.ChecksumIEEE(b[:recordHeaderSize+len(payload)])
+	binary.BigEndian.PutUint32(b[recordHeaderSize+len(payload):], sum)
+
+	return b
+}
+
+// reads the next record, returning its op, its payload and its encoded
+// size.  It returns io.EOF if there are no more records and ErrCorrupt
+// if the record is incomplete or damaged.
+func readRecord(r *bufio.Reader) (byte, []byte, int, error) {
+	var h [recordHeaderSize]byte
+
+	n, err := io.ReadFull(r, h[:])
+	if err == io.EOF {
+		return 0, nil, 0, io.EOF
+	}
+
+	if err != nil {
+		return 0, nil, 0, corrupt(err)
+	}
+
+	op := h[0]
+	if op != opInsert && op != opExtract {
+		return 0, nil, 0, fmt.Errorf("%w: unknown op %q", ErrCorrupt, op)
+	}
+
+	length := binary.BigEndian.Uint32(h[1:])
+	if op == opExtract && length != 0 {
+		return 0, nil, 0, fmt.Errorf("%w: extract with payload", ErrCorrupt)
+	}
+
+	// copy instead of allocating length bytes upfront, so a damaged
+	// length does not trigger a huge allocation.
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, int64(length)); err != nil {
+		return 0, nil, 0, corrupt(err)
+	}
+
+	payload := buf.Bytes()
+
+	var c [recordCRCSize]byte
+	if _, err := io.ReadFull(r, c[:]); err != nil {
+		return 0, nil, 0, corrupt(err)
+	}
+
+	sum := crc32.NewIEEE()
+	_, _ = sum.Write(h[:])
+	_, _ = sum.Write(payload)
+
+	if sum.Sum32() != binary.BigEndian.Uint32(c[:]) {
+		return 0, nil, 0, fmt.Errorf("%w: checksum mismatch", ErrCorrupt)
+	}
+
+	return op, payload, n + len(payload) + recordCRCSize, nil
+}
+
+// wraps errors from incomplete reads as corruption errors.
+func corrupt(err error) error {
+	if err == io.ErrUnexpectedEOF || err == io.EOF {
+		return fmt.Errorf("%w: truncated", ErrCorrupt)
+	}
+
+	return err
+}
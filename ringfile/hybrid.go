@@ -0,0 +1,114 @@
+package ringfile
+
+import (
+	"sync"
+
+	"github.com/alcortesm/ring"
+)
+
+// Hybrid is a concurrent bounded circular buffer of byte slices that
+// keeps its newest elements in memory and spills the older ones to a
+// persistent ring, which gives a large capacity with a small memory
+// footprint.
+//
+// Only the elements in the persistent ring survive process restarts.
+type Hybrid struct {
+	mu   sync.Mutex
+	mem  *ring.Ring // newest elements
+	disk *Ring      // oldest elements
+}
+
+// NewHybrid returns a hybrid ring keeping up to memCap elements in
+// memory and the older ones in disk.  The capacity of the hybrid ring
+// is memCap plus the capacity of disk.  The hybrid ring takes ownership
+// of disk.
+func NewHybrid(memCap int, disk *Ring) (*Hybrid, error) {
+	mem, err := ring.New(memCap)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Hybrid{
+		mem:  mem,
+		disk: disk,
+	}, nil
+}
+
+// Insert adds a new element to the ring. If the memory is full, its
+// oldest element is moved to disk.  If the ring is already at maximum
+// capacity, the oldest element is dropped to make room for the new
+// one.  The ring keeps its own copy of p.
+func (h *Hybrid) Insert(p []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.mem.Len() == h.mem.Cap() {
+		oldest, _ := h.mem.Peek()
+
+		if err := h.disk.Insert(oldest.([]byte)); err != nil {
+			return err
+		}
+
+		_, _ = h.mem.Extract()
+	}
+
+	h.mem.Insert(append([]byte(nil), p...))
+
+	return nil
+}
+
+// Extract extracts and returns the oldest element in the ring.
+func (h *Hybrid) Extract() ([]byte, bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.disk.Len() > 0 {
+		return h.disk.Extract()
+	}
+
+	v, ok := h.mem.Extract()
+	if !ok {
+		return nil, false, nil
+	}
+
+	return v.([]byte), true, nil
+}
+
+// Peek returns the oldest element in the ring.  The returned slice must
+// not be modified.
+func (h *Hybrid) Peek() ([]byte, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if p, ok := h.disk.Peek(); ok {
+		return p, true
+	}
+
+	v, ok := h.mem.Peek()
+	if !ok {
+		return nil, false
+	}
+
+	return v.([]byte), true
+}
+
+// Len returns the amount of elements in the ring.
+func (h *Hybrid) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.mem.Len() + h.disk.Len()
+}
+
+// Cap returns the capacity of the ring.
+func (h *Hybrid) Cap() int {
+	return h.mem.Cap() + h.disk.Cap()
+}
+
+// Close closes the persistent ring.  The elements in memory are lost.
+func (h *Hybrid) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.disk.Close()
+}
@@ -0,0 +1,153 @@
+package ringfile_test
+
+import (
+	"testing"
+
+	"github.com/alcortesm/ring/ringfile"
+)
+
+func TestHybrid(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid capacity":  hybridInvalidCapacity,
+		"memory only":       hybridMemoryOnly,
+		"spills to disk":    hybridSpillsToDisk,
+		"forgets oldest":    hybridForgetsOldest,
+		"disk survives":     hybridDiskSurvives,
+		"extract all tiers": hybridExtractAllTiers,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a hybrid ring with the given capacities or fails the test.
+func newHybrid(t *testing.T, path string, memCap, diskCap int) *ringfile.Hybrid {
+	t.Helper()
+
+	h, err := ringfile.NewHybrid(memCap, open(t, path, diskCap))
+	if err != nil {
+		t.Fatalf("creating hybrid ring: %v", err)
+	}
+
+	return h
+}
+
+// inserts the strings in the hybrid ring or fails the test.
+func insertHybrid(t *testing.T, h *ringfile.Hybrid, ss ...string) {
+	t.Helper()
+
+	for _, s := range ss {
+		if err := h.Insert([]byte(s)); err != nil {
+			t.Fatalf("inserting %q: %v", s, err)
+		}
+	}
+}
+
+// asserts that extracting from the hybrid ring returns the wanted
+// strings and leaves it empty.
+func assertDrainHybrid(t *testing.T, h *ringfile.Hybrid, want ...string) {
+	t.Helper()
+
+	if got := h.Len(); got != len(want) {
+		t.Fatalf("wrong length, want %d, got %d", len(want), got)
+	}
+
+	for _, w := range want {
+		if p, ok := h.Peek(); !ok || string(p) != w {
+			t.Fatalf("want to peek %q, got %q, %t", w, p, ok)
+		}
+
+		got, ok, err := h.Extract()
+		if err != nil {
+			t.Fatalf("extracting %q: %v", w, err)
+		}
+
+		if !ok || string(got) != w {
+			t.Fatalf("want to extract %q, got %q, %t", w, got, ok)
+		}
+	}
+
+	if _, ok, _ := h.Extract(); ok {
+		t.Fatal("want empty ring after draining")
+	}
+}
+
+// tests that the memory capacity must be positive.
+func hybridInvalidCapacity(t *testing.T) {
+	disk := open(t, logPath(t), 2)
+	defer closeRing(t, disk)
+
+	if _, err := ringfile.NewHybrid(0, disk); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that a few elements stay in memory.
+func hybridMemoryOnly(t *testing.T) {
+	h := newHybrid(t, logPath(t), 3, 2)
+	defer h.Close()
+
+	insertHybrid(t, h, "a", "b")
+	assertDrainHybrid(t, h, "a", "b")
+}
+
+// tests that the older elements spill to disk.
+func hybridSpillsToDisk(t *testing.T) {
+	h := newHybrid(t, logPath(t), 2, 3)
+	defer h.Close()
+
+	if got := h.Cap(); got != 5 {
+		t.Fatalf("want capacity 5, got %d", got)
+	}
+
+	insertHybrid(t, h, "a", "b", "c", "d", "e")
+	assertDrainHybrid(t, h, "a", "b", "c", "d", "e")
+}
+
+// tests that the oldest elements are dropped when both tiers are full.
+func hybridForgetsOldest(t *testing.T) {
+	h := newHybrid(t, logPath(t), 2, 2)
+	defer h.Close()
+
+	insertHybrid(t, h, "a", "b", "c", "d", "e", "f")
+	assertDrainHybrid(t, h, "c", "d", "e", "f")
+}
+
+// tests that the elements in disk survive reopening.
+func hybridDiskSurvives(t *testing.T) {
+	path := logPath(t)
+
+	h := newHybrid(t, path, 2, 4)
+	insertHybrid(t, h, "a", "b", "c", "d")
+
+	if err := h.Close(); err != nil {
+		t.Fatalf("closing: %v", err)
+	}
+
+	h = newHybrid(t, path, 2, 4)
+	defer h.Close()
+
+	assertDrainHybrid(t, h, "a", "b")
+}
+
+// tests interleaving inserts and extracts across both tiers.
+func hybridExtractAllTiers(t *testing.T) {
+	h := newHybrid(t, logPath(t), 2, 2)
+	defer h.Close()
+
+	insertHybrid(t, h, "a", "b", "c") // a in disk
+
+	if p, ok, _ := h.Extract(); !ok || string(p) != "a" {
+		t.Fatalf("want to extract %q, got %q", "a", p)
+	}
+
+	insertHybrid(t, h, "d", "e") // b, c in disk
+	assertDrainHybrid(t, h, "b", "c", "d", "e")
+}
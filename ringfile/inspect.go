@@ -0,0 +1,62 @@
+package ringfile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Record is a record of a log file, see Inspect.
+type Record struct {
+	Offset int64 // position of the record in the log file
+	Insert bool  // whether it records an insertion or an extraction
+	// Payload is the stored element of an insertion, compressed and
+	// encrypted if the ring was configured to.  Nil for extractions.
+	Payload []byte
+}
+
+// Inspect reads a log file written by Log from r, and calls fn with each
+// of its records, in order.  Unlike OpenLog, it never modifies the log,
+// and reports the first incomplete or damaged record, instead of
+// discarding it: the records before it are passed to fn, and then it
+// returns an error wrapping ErrCorrupt with its offset.  It also stops
+// at the first error returned by fn, and returns it.
+//
+// It is meant to inspect logs, for example after a crash.
+func Inspect(r io.Reader, fn func(rec Record) error) error {
+	br := bufio.NewReader(r)
+
+	if err := readHeader(br); err != nil {
+		return err
+	}
+
+	offset := int64(headerSize)
+
+	for {
+		op, payload, n, err := readRecord(br)
+		if err == io.EOF {
+			return nil
+		}
+
+		if errors.Is(err, ErrCorrupt) {
+			return fmt.Errorf("at offset %d: %w", offset, err)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		rec := Record{
+			Offset:  offset,
+			Insert:  op == opInsert,
+			Payload: payload,
+		}
+
+		if err := fn(rec); err != nil {
+			return err
+		}
+
+		offset += int64(n)
+	}
+}
@@ -0,0 +1,118 @@
+package ringfile_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/alcortesm/ring/ringfile"
+)
+
+func TestInspect(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"records":        inspectRecords,
+		"torn record":    inspectTornRecord,
+		"not a log file": inspectNotALogFile,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns the records in the log file at path, formatted as
+// offset:op:payload, and the error of the inspection.
+func inspect(t *testing.T, path string) (string, error) {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading log: %v", err)
+	}
+
+	var got []string
+
+	err = ringfile.Inspect(bytes.NewReader(data), func(rec ringfile.Record) error {
+		op := "X"
+		if rec.Insert {
+			op = "I"
+		}
+
+		got = append(got, fmt.Sprintf("%d:%s:%s", rec.Offset, op, rec.Payload))
+
+		return nil
+	})
+
+	return fmt.Sprint(got), err
+}
+
+// tests that the insertions and extractions are reported in order.
+func inspectRecords(t *testing.T) {
+	path := logPath(t)
+
+	r := open(t, path, 4)
+	insert(t, r, "a", "bb")
+
+	if _, _, err := r.Extract(); err != nil {
+		t.Fatalf("extracting: %v", err)
+	}
+
+	closeRing(t, r)
+
+	got, err := inspect(t, path)
+	if err != nil {
+		t.Fatalf("inspecting: %v", err)
+	}
+
+	if want := "[8:I:a 18:I:bb 29:X:]"; got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+// tests that a torn record is reported, with the records before it,
+// and the log is not modified.
+func inspectTornRecord(t *testing.T) {
+	path := logPath(t)
+
+	r := open(t, path, 4)
+	insert(t, r, "a", "bbbb")
+	closeRing(t, r)
+
+	size := fileSize(t, path) - 3
+	if err := os.Truncate(path, size); err != nil {
+		t.Fatalf("truncating log: %v", err)
+	}
+
+	got, err := inspect(t, path)
+	if !errors.Is(err, ringfile.ErrCorrupt) {
+		t.Errorf("want corrupt error, got %v", err)
+	}
+
+	if want := "[8:I:a]"; got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+
+	if fileSize(t, path) != size {
+		t.Error("the log was modified")
+	}
+}
+
+// tests that inspecting files that are not ring logs fails.
+func inspectNotALogFile(t *testing.T) {
+	err := ringfile.Inspect(bytes.NewReader([]byte("hello, world\n")), func(ringfile.Record) error {
+		t.Error("unexpected record")
+		return nil
+	})
+	if err == nil {
+		t.Fatal("unexpected success")
+	}
+}
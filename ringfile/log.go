@@ -0,0 +1,305 @@
+package ringfile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// the log is compacted when its file is more than compactionFactor
+// times the size of a log holding just the stored elements, plus
+// minCompactionSize bytes.
+const (
+	compactionFactor  = 2
+	minCompactionSize = 1 << 10
+)
+
+// Log is a Backend storing the elements in an append-only log file.
+//
+// Every append and removal is recorded in the log.  Opening an
+// existing log replays it to recover the stored elements.  Incomplete
+// records at the end of the log, the consequence of a crash while
+// appending to it, are discarded when opening it.
+//
+// The log implements Compacter, rewriting the file with just the
+// stored elements when it grows over twice their size, so its size
+// stays proportional to the size of the stored elements.
+type Log struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	sizes  []int64  // sizes of the records of the stored elements, oldest first
+	live   int64    // size of a log with just the stored elements
+	size   int64    // size of the log file
+	loaded [][]byte // elements replayed when opening, until loaded
+}
+
+// OpenLog opens the log file at path, creating it if it does not
+// exist.
+func OpenLog(path string) (*Log, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening log: %w", err)
+	}
+
+	l := &Log{
+		path: path,
+		file: file,
+	}
+
+	if err := l.replay(); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("replaying log %s: %w", path, err)
+	}
+
+	return l, nil
+}
+
+// replay recovers the stored elements from the log file and leaves it
+// ready to append new records.
+func (l *Log) replay() error {
+	info, err := l.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	// a log shorter than its header was never written past creation
+	if info.Size() < int64(headerSize) {
+		if err := l.file.Truncate(0); err != nil {
+			return err
+		}
+
+		l.size = int64(headerSize)
+		l.live = int64(headerSize)
+
+		return writeHeader(l.file)
+	}
+
+	br := bufio.NewReader(l.file)
+
+	if err := readHeader(br); err != nil {
+		return err
+	}
+
+	offset := int64(headerSize)
+
+	var removed int
+
+	for {
+		op, payload, n, err := readRecord(br)
+		if err == io.EOF {
+			break
+		}
+
+		if errors.Is(err, ErrCorrupt) {
+			// discard the damaged tail of the log
+			if err := l.file.Truncate(offset); err != nil {
+				return fmt.Errorf("truncating damaged log: %w", err)
+			}
+
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		switch op {
+		case opInsert:
+			l.loaded = append(l.loaded, payload)
+			l.sizes = append(l.sizes, int64(n))
+		case opExtract:
+			if removed < len(l.loaded) {
+				l.loaded[removed] = nil
+				removed++
+			}
+		}
+
+		offset += int64(n)
+	}
+
+	l.loaded = l.loaded[removed:]
+	l.sizes = l.sizes[removed:]
+	l.size = offset
+	l.live = int64(headerSize)
+
+	for _, n := range l.sizes {
+		l.live += n
+	}
+
+	_, err = l.file.Seek(offset, io.SeekStart)
+
+	return err
+}
+
+// Load implements Backend.  It can only be called once.
+func (l *Log) Load(fn func(p []byte)) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, p := range l.loaded {
+		fn(p)
+	}
+
+	l.loaded = nil
+
+	return nil
+}
+
+// Append implements Backend.
+func (l *Log) Append(p []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n, err := l.append(opInsert, p)
+	if err != nil {
+		return err
+	}
+
+	l.sizes = append(l.sizes, n)
+	l.live += n
+
+	return nil
+}
+
+// RemoveOldest implements Backend.
+func (l *Log) RemoveOldest() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.sizes) == 0 {
+		return nil
+	}
+
+	if _, err := l.append(opExtract, nil); err != nil {
+		return err
+	}
+
+	l.live -= l.sizes[0]
+	l.sizes = l.sizes[1:]
+
+	return nil
+}
+
+// appends a record to the log and returns its size.
+func (l *Log) append(op byte, payload []byte) (int64, error) {
+	record := encodeRecord(op, payload)
+
+	if _, err := l.file.Write(record); err != nil {
+		// try not to leave a partial record behind, as it would hide
+		// the records appended after it.
+		if terr := l.file.Truncate(l.size); terr == nil {
+			_, _ = l.file.Seek(l.size, io.SeekStart)
+		}
+
+		return 0, fmt.Errorf("appending to log: %w", err)
+	}
+
+	l.size += int64(len(record))
+
+	return int64(len(record)), nil
+}
+
+// NeedsCompaction implements Compacter.
+func (l *Log) NeedsCompaction() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.size > compactionFactor*l.live+minCompactionSize
+}
+
+// Compact implements Compacter.  The new log is written to a temporary
+// file and then renamed over the old one, so a crash during compaction
+// never loses the log.
+func (l *Log) Compact(elements [][]byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tmpPath := l.path + ".tmp"
+
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("compacting log: %w", err)
+	}
+
+	size, err := writeLog(tmp, elements)
+	if err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+
+		return fmt.Errorf("compacting log: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, l.path); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+
+		return fmt.Errorf("compacting log: %w", err)
+	}
+
+	_ = l.file.Close()
+	l.file = tmp
+	l.sizes = l.sizes[:0]
+	l.live = int64(headerSize)
+
+	for _, p := range elements {
+		n := int64(recordHeaderSize + len(p) + recordCRCSize)
+		l.sizes = append(l.sizes, n)
+		l.live += n
+	}
+
+	l.size = size
+
+	return nil
+}
+
+// writes a complete log holding the given elements, syncs it and
+// returns its size.
+func writeLog(file *os.File, elements [][]byte) (int64, error) {
+	w := bufio.NewWriter(file)
+
+	if err := writeHeader(w); err != nil {
+		return 0, err
+	}
+
+	size := int64(headerSize)
+
+	for _, p := range elements {
+		n, err := w.Write(encodeRecord(opInsert, p))
+		if err != nil {
+			return 0, err
+		}
+
+		size += int64(n)
+	}
+
+	if err := w.Flush(); err != nil {
+		return 0, err
+	}
+
+	return size, file.Sync()
+}
+
+// Sync implements Backend.
+func (l *Log) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.file.Sync()
+}
+
+// Close implements Backend.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.file.Sync(); err != nil {
+		_ = l.file.Close()
+		return err
+	}
+
+	return l.file.Close()
+}
@@ -0,0 +1,55 @@
+package ringfile
+
+// Option configures a ring on construction.
+//
+// Options changing how the elements are stored, like compression, must
+// be the same every time a backend is opened.
+type Option func(*config)
+
+// the configuration set by the options.
+type config struct {
+	compressor Compressor
+	encrypt    bool
+	key        []byte
+	maxSize    int64
+}
+
+// WithCompression makes the ring compress its elements with c before
+// writing them to its backend.
+func WithCompression(c Compressor) Option {
+	return func(cfg *config) {
+		cfg.compressor = c
+	}
+}
+
+// WithEncryption makes the ring encrypt its elements before writing
+// them to its backend, using AES-GCM with the given key, which must be
+// 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256.  When
+// combined with compression, elements are compressed before being
+// encrypted.
+//
+// Loading elements that were not encrypted with the same key, or that
+// were modified after being written, fails.
+func WithEncryption(key []byte) Option {
+	return func(cfg *config) {
+		cfg.encrypt = true
+		cfg.key = append([]byte(nil), key...)
+	}
+}
+
+// WithMaxSize bounds the ring by the size of its elements as written to
+// the backend, that is, after compression and encryption: the oldest
+// elements are dropped to keep their total size under n bytes.
+// Inserting an element bigger than n fails.
+//
+// This bounds the storage used by the backend too, although it can be
+// bigger than n due to the backend own overhead.  For instance, the
+// records of removed elements stay in a Log until it is compacted,
+// which happens when its file grows over twice the size of a log with
+// just the stored elements, plus 1KiB, so the file stays under about
+// twice n, plus 18 bytes per stored element and 1KiB.
+func WithMaxSize(n int64) Option {
+	return func(cfg *config) {
+		cfg.maxSize = n
+	}
+}
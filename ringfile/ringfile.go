@@ -0,0 +1,384 @@
+/*
+Package ringfile implements a bounded circular buffer of byte slices
+persisted to a storage backend, so its contents survive process
+restarts.
+
+The default backend is Log, an append-only log file that is
+periodically compacted.  Other storages, like the embedded key-value
+store an application already manages, can be used by implementing the
+Backend interface.
+
+Elements can be compressed and encrypted before writing them to the
+backend, see WithCompression and WithEncryption.
+
+Besides its capacity, the ring can also be bounded by the size of its
+stored elements, see WithMaxSize.
+
+Writes to the backend are not synced to stable storage after each
+operation, call Sync for that.
+*/
+package ringfile
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/alcortesm/ring"
+)
+
+// Backend is the storage of a persistent ring.  It stores a sequence of
+// elements, appending new ones after the newest and removing them from
+// the oldest.
+//
+// The ring serializes its calls to the backend.
+type Backend interface {
+	// Load calls fn with each stored element, from oldest to newest.
+	// It is called once, before any other method.
+	Load(fn func(p []byte)) error
+	// Append stores p as the newest element.  The backend must not
+	// retain p.
+	Append(p []byte) error
+	// RemoveOldest removes the oldest stored element.
+	RemoveOldest() error
+	// Sync commits the stored elements to stable storage.
+	Sync() error
+	// Close syncs and releases the backend.
+	Close() error
+}
+
+// Compacter is implemented by backends whose storage grows with every
+// operation, and that must be rewritten from time to time to reclaim
+// the space of the removed elements.
+type Compacter interface {
+	// NeedsCompaction reports whether the backend should be compacted.
+	NeedsCompaction() bool
+	// Compact rewrites the storage with just the given elements, which
+	// are the ones currently stored, from oldest to newest.
+	Compact(elements [][]byte) error
+}
+
+// Ring is a concurrent bounded circular buffer of byte slices persisted
+// to a storage backend.
+type Ring struct {
+	mu      sync.Mutex
+	backend Backend
+	mem     *ring.Ring // the contents of the ring, as elements
+	stored  int64      // sum of the stored sizes of the elements
+	cfg     config
+	aead    cipher.AEAD // nil if not encrypting
+}
+
+// an element in the ring.
+type element struct {
+	data []byte // the original form of the element
+	size int    // the size of the element in the backend
+}
+
+// Open opens the ring persisted in the log file at path, creating it
+// if it does not exist.  If the log holds more elements than the given
+// capacity, only the newest ones are kept.
+func Open(path string, cap int, opts ...Option) (*Ring, error) {
+	if cap < 1 {
+		return nil, fmt.Errorf("ring capacity must be > 0, got %d", cap)
+	}
+
+	log, err := OpenLog(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := New(log, cap, opts...)
+	if err != nil {
+		_ = log.Close()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// New returns a ring with the given capacity persisted to the given
+// backend, loading its current contents.  If the backend holds more
+// elements than the given capacity, the oldest ones are removed from
+// it.  The ring takes ownership of the backend.
+func New(backend Backend, cap int, opts ...Option) (*Ring, error) {
+	mem, err := ring.New(cap)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Ring{
+		backend: backend,
+		mem:     mem,
+	}
+
+	for _, opt := range opts {
+		opt(&r.cfg)
+	}
+
+	if r.cfg.encrypt {
+		block, err := aes.NewCipher(r.cfg.key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key: %w", err)
+		}
+
+		r.aead, err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		loaded    int
+		decodeErr error
+	)
+
+	err = backend.Load(func(p []byte) {
+		if decodeErr != nil {
+			return
+		}
+
+		v, err := r.decode(p)
+		if err != nil {
+			decodeErr = fmt.Errorf("decoding element %d: %w", loaded, err)
+			return
+		}
+
+		mem.Insert(element{data: v, size: len(p)})
+		loaded++
+	})
+	if err == nil {
+		err = decodeErr
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("loading ring: %w", err)
+	}
+
+	for ; loaded > cap; loaded-- {
+		if err := backend.RemoveOldest(); err != nil {
+			return nil, fmt.Errorf("removing elements over capacity: %w", err)
+		}
+	}
+
+	for _, v := range mem.ToSlice() {
+		r.stored += int64(v.(element).size)
+	}
+
+	if r.cfg.maxSize > 0 {
+		if err := r.makeRoom(0); err != nil {
+			return nil, fmt.Errorf("removing elements over maximum size: %w", err)
+		}
+	}
+
+	return r, nil
+}
+
+// returns the form of p written to the backend.
+func (r *Ring) encode(p []byte) ([]byte, error) {
+	if r.cfg.compressor != nil {
+		var err error
+
+		p, err = r.cfg.compressor.Compress(p)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.aead != nil {
+		// the nonce is stored in front of the ciphertext
+		nonce := make([]byte, r.aead.NonceSize(), r.aead.NonceSize()+len(p)+r.aead.Overhead())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, fmt.Errorf("generating nonce: %w", err)
+		}
+
+		p = r.aead.Seal(nonce, nonce, p, nil)
+	}
+
+	return p, nil
+}
+
+// returns a copy of the original form of p, read from the backend.
+func (r *Ring) decode(p []byte) ([]byte, error) {
+	if r.aead != nil {
+		if len(p) < r.aead.NonceSize() {
+			return nil, errors.New("encrypted element too short")
+		}
+
+		nonce, ciphertext := p[:r.aead.NonceSize()], p[r.aead.NonceSize():]
+
+		var err error
+
+		p, err = r.aead.Open(nil, nonce, ciphertext, nil)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting: %w", err)
+		}
+	}
+
+	if r.cfg.compressor != nil {
+		return r.cfg.compressor.Decompress(p)
+	}
+
+	return append([]byte(nil), p...), nil
+}
+
+// compacts the backend if it needs it, before writing more to it.
+func (r *Ring) maybeCompact() error {
+	c, ok := r.backend.(Compacter)
+	if !ok || !c.NeedsCompaction() {
+		return nil
+	}
+
+	return r.compact(c)
+}
+
+// rewrites the backend with the current contents of the ring.
+func (r *Ring) compact(c Compacter) error {
+	contents := r.mem.ToSlice()
+
+	elements := make([][]byte, len(contents))
+
+	for i, v := range contents {
+		p, err := r.encode(v.(element).data)
+		if err != nil {
+			return fmt.Errorf("encoding element for compaction: %w", err)
+		}
+
+		elements[i] = p
+	}
+
+	return c.Compact(elements)
+}
+
+// removes the oldest element from the backend and the ring.
+func (r *Ring) removeOldest() error {
+	if err := r.backend.RemoveOldest(); err != nil {
+		return err
+	}
+
+	v, _ := r.mem.Extract()
+	r.stored -= int64(v.(element).size)
+
+	return nil
+}
+
+// removes the oldest elements until there is room for n more bytes
+// under the maximum size.
+func (r *Ring) makeRoom(n int) error {
+	if int64(n) > r.cfg.maxSize {
+		return fmt.Errorf("element size %d is over the maximum size %d", n, r.cfg.maxSize)
+	}
+
+	for r.stored+int64(n) > r.cfg.maxSize {
+		if err := r.removeOldest(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Insert adds a new element to the ring. If the ring is already at
+// maximum capacity, the oldest element is dropped to make room for the
+// new one.  The ring keeps its own copy of p.
+func (r *Ring) Insert(p []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	encoded, err := r.encode(p)
+	if err != nil {
+		return fmt.Errorf("encoding element: %w", err)
+	}
+
+	if err := r.maybeCompact(); err != nil {
+		return err
+	}
+
+	if r.cfg.maxSize > 0 {
+		if err := r.makeRoom(len(encoded)); err != nil {
+			return err
+		}
+	}
+
+	if r.mem.Len() == r.mem.Cap() {
+		if err := r.removeOldest(); err != nil {
+			return err
+		}
+	}
+
+	if err := r.backend.Append(encoded); err != nil {
+		return err
+	}
+
+	r.mem.Insert(element{
+		data: append([]byte(nil), p...),
+		size: len(encoded),
+	})
+	r.stored += int64(len(encoded))
+
+	return nil
+}
+
+// Extract extracts and returns the oldest element in the ring.
+func (r *Ring) Extract() ([]byte, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.mem.Peek()
+	if !ok {
+		return nil, false, nil
+	}
+
+	if err := r.maybeCompact(); err != nil {
+		return nil, false, err
+	}
+
+	if err := r.removeOldest(); err != nil {
+		return nil, false, err
+	}
+
+	return v.(element).data, true, nil
+}
+
+// Peek returns the oldest element in the ring.  The returned slice must
+// not be modified.
+func (r *Ring) Peek() ([]byte, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.mem.Peek()
+	if !ok {
+		return nil, false
+	}
+
+	return v.(element).data, true
+}
+
+// Len returns the amount of elements in the ring.
+func (r *Ring) Len() int {
+	return r.mem.Len()
+}
+
+// Cap returns the capacity of the ring.
+func (r *Ring) Cap() int {
+	return r.mem.Cap()
+}
+
+// Sync commits the contents of the ring to stable storage.
+func (r *Ring) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.backend.Sync()
+}
+
+// Close syncs and closes the backend.
+func (r *Ring) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.backend.Close()
+}
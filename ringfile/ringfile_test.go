@@ -0,0 +1,424 @@
+package ringfile_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alcortesm/ring/ringfile"
+)
+
+func TestRing(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid capacity":       ringInvalidCapacity,
+		"new ring is empty":      ringNewIsEmpty,
+		"insert and extract":     ringInsertAndExtract,
+		"survives reopening":     ringSurvivesReopening,
+		"reopen with less cap":   ringReopenWithLessCap,
+		"compaction bounds size": ringCompactionBoundsSize,
+		"discards torn record":   ringDiscardsTornRecord,
+		"rejects foreign files":  ringRejectsForeignFiles,
+		"custom backend":         ringCustomBackend,
+		"max size drops oldest":  ringMaxSizeDropsOldest,
+		"max size too big":       ringMaxSizeTooBig,
+		"max size when opening":  ringMaxSizeWhenOpening,
+		"max size bounds log":    ringMaxSizeBoundsLog,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns the path to a log file in a temporary directory removed at
+// the end of the test.
+func logPath(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "ringfile")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %v", err)
+	}
+
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	return filepath.Join(dir, "ring.log")
+}
+
+// opens the ring at path or fails the test.
+func open(t *testing.T, path string, cap int) *ringfile.Ring {
+	t.Helper()
+
+	r, err := ringfile.Open(path, cap)
+	if err != nil {
+		t.Fatalf("opening ring: %v", err)
+	}
+
+	return r
+}
+
+// closes the ring or fails the test.
+func closeRing(t *testing.T, r *ringfile.Ring) {
+	t.Helper()
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("closing ring: %v", err)
+	}
+}
+
+// inserts the strings in the ring or fails the test.
+func insert(t *testing.T, r *ringfile.Ring, ss ...string) {
+	t.Helper()
+
+	for _, s := range ss {
+		if err := r.Insert([]byte(s)); err != nil {
+			t.Fatalf("inserting %q: %v", s, err)
+		}
+	}
+}
+
+// asserts that extracting from the ring returns the wanted strings and
+// leaves it empty.
+func assertDrain(t *testing.T, r *ringfile.Ring, want ...string) {
+	t.Helper()
+
+	if got := r.Len(); got != len(want) {
+		t.Fatalf("wrong length, want %d, got %d", len(want), got)
+	}
+
+	for _, w := range want {
+		got, ok, err := r.Extract()
+		if err != nil {
+			t.Fatalf("extracting %q: %v", w, err)
+		}
+
+		if !ok {
+			t.Fatalf("when extracting %q: unexpected empty ring", w)
+		}
+
+		if string(got) != w {
+			t.Errorf("unexpected extracted value, want %q, got %q", w, got)
+		}
+	}
+
+	if _, ok, _ := r.Extract(); ok {
+		t.Fatal("want empty ring after draining")
+	}
+}
+
+// tests that capacities smaller than 1 are invalid
+func ringInvalidCapacity(t *testing.T) {
+	for _, cap := range []int{0, -1, -42} {
+		cap := cap
+		name := fmt.Sprintf("cap=%d", cap)
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ringfile.Open(logPath(t), cap)
+			if err == nil {
+				t.Fatal("unexpected success")
+			}
+		})
+	}
+}
+
+// tests that new rings start empty.
+func ringNewIsEmpty(t *testing.T) {
+	r := open(t, logPath(t), 4)
+	defer closeRing(t, r)
+
+	if _, ok := r.Peek(); ok {
+		t.Error("unexpected successful peek")
+	}
+
+	assertDrain(t, r)
+}
+
+// tests the ring keeps the newest elements in order.
+func ringInsertAndExtract(t *testing.T) {
+	r := open(t, logPath(t), 3)
+	defer closeRing(t, r)
+
+	insert(t, r, "a", "b", "c", "d")
+
+	got, ok := r.Peek()
+	if !ok || string(got) != "b" {
+		t.Fatalf("want to peek %q, got %q, %t", "b", got, ok)
+	}
+
+	assertDrain(t, r, "b", "c", "d")
+}
+
+// tests that the contents are recovered when reopening the ring.
+func ringSurvivesReopening(t *testing.T) {
+	path := logPath(t)
+
+	r := open(t, path, 3)
+	insert(t, r, "a", "b", "c", "d")
+
+	if _, _, err := r.Extract(); err != nil {
+		t.Fatalf("extracting: %v", err)
+	}
+
+	closeRing(t, r)
+
+	r = open(t, path, 3)
+	defer closeRing(t, r)
+
+	assertDrain(t, r, "c", "d")
+}
+
+// tests that reopening with a smaller capacity keeps the newest
+// elements.
+func ringReopenWithLessCap(t *testing.T) {
+	path := logPath(t)
+
+	r := open(t, path, 4)
+	insert(t, r, "a", "b", "c", "d")
+	closeRing(t, r)
+
+	r = open(t, path, 2)
+	defer closeRing(t, r)
+
+	assertDrain(t, r, "c", "d")
+}
+
+// returns the size of the file at path or fails the test.
+func fileSize(t *testing.T, path string) int64 {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat log: %v", err)
+	}
+
+	return info.Size()
+}
+
+// tests that the log does not grow forever.
+func ringCompactionBoundsSize(t *testing.T) {
+	path := logPath(t)
+
+	r := open(t, path, 4)
+	insert(t, r, "a", "b", "c", "d")
+
+	// without compaction, this would take about 19KB
+	for i := 0; i < 1000; i++ {
+		insert(t, r, fmt.Sprint(i%10))
+
+		if _, _, err := r.Extract(); err != nil {
+			t.Fatalf("extracting: %v", err)
+		}
+	}
+
+	if got := fileSize(t, path); got > 2048 {
+		t.Errorf("log too big after compactions: %d bytes", got)
+	}
+
+	closeRing(t, r)
+
+	r = open(t, path, 4)
+	defer closeRing(t, r)
+
+	assertDrain(t, r, "7", "8", "9")
+}
+
+// tests that an incomplete record at the end of the log is discarded
+// and the ring keeps working.
+func ringDiscardsTornRecord(t *testing.T) {
+	path := logPath(t)
+
+	r := open(t, path, 4)
+	insert(t, r, "a", "bbbb")
+	closeRing(t, r)
+
+	// remove the last bytes of the last record
+	if err := os.Truncate(path, fileSize(t, path)-3); err != nil {
+		t.Fatalf("truncating log: %v", err)
+	}
+
+	r = open(t, path, 4)
+	insert(t, r, "c")
+	closeRing(t, r)
+
+	r = open(t, path, 4)
+	defer closeRing(t, r)
+
+	assertDrain(t, r, "a", "c")
+}
+
+// tests that opening files that are not ring logs fails.
+func ringRejectsForeignFiles(t *testing.T) {
+	path := logPath(t)
+
+	if err := ioutil.WriteFile(path, []byte("hello, world\n"), 0o600); err != nil {
+		t.Fatalf("writing file: %v", err)
+	}
+
+	if _, err := ringfile.Open(path, 4); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// a backend storing its elements in memory.
+type memBackend struct {
+	elements [][]byte
+}
+
+func (b *memBackend) Load(fn func(p []byte)) error {
+	for _, p := range b.elements {
+		fn(p)
+	}
+
+	return nil
+}
+
+func (b *memBackend) Append(p []byte) error {
+	b.elements = append(b.elements, append([]byte(nil), p...))
+	return nil
+}
+
+func (b *memBackend) RemoveOldest() error {
+	b.elements = b.elements[1:]
+	return nil
+}
+
+func (b *memBackend) Sync() error  { return nil }
+func (b *memBackend) Close() error { return nil }
+
+// asserts that the backend stores the wanted strings.
+func assertStored(t *testing.T, b *memBackend, want ...string) {
+	t.Helper()
+
+	got := make([]string, len(b.elements))
+	for i, p := range b.elements {
+		got[i] = string(p)
+	}
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("want stored elements %q, got %q", want, got)
+	}
+}
+
+// tests that rings keep their backend in sync with their contents.
+func ringCustomBackend(t *testing.T) {
+	b := &memBackend{
+		elements: [][]byte{[]byte("a"), []byte("b"), []byte("c")},
+	}
+
+	r, err := ringfile.New(b, 2)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	assertStored(t, b, "b", "c")
+
+	insert(t, r, "d")
+	assertStored(t, b, "c", "d")
+
+	if _, _, err := r.Extract(); err != nil {
+		t.Fatalf("extracting: %v", err)
+	}
+
+	assertStored(t, b, "d")
+	assertDrain(t, r, "d")
+	assertStored(t, b)
+}
+
+// tests that the oldest elements are dropped to stay under the maximum
+// size.
+func ringMaxSizeDropsOldest(t *testing.T) {
+	r, err := ringfile.Open(logPath(t), 10, ringfile.WithMaxSize(6))
+	if err != nil {
+		t.Fatalf("opening ring: %v", err)
+	}
+
+	defer closeRing(t, r)
+
+	insert(t, r, "aa", "bb", "cc") // 6 bytes
+	insert(t, r, "ddd")            // drops aa and bb
+	assertDrain(t, r, "cc", "ddd")
+}
+
+// tests that elements bigger than the maximum size are rejected.
+func ringMaxSizeTooBig(t *testing.T) {
+	r, err := ringfile.Open(logPath(t), 10, ringfile.WithMaxSize(4))
+	if err != nil {
+		t.Fatalf("opening ring: %v", err)
+	}
+
+	defer closeRing(t, r)
+
+	insert(t, r, "a")
+
+	if err := r.Insert([]byte("12345")); err == nil {
+		t.Fatal("unexpected success")
+	}
+
+	assertDrain(t, r, "a")
+}
+
+// tests that opening a ring with a smaller maximum size drops the
+// oldest elements.
+func ringMaxSizeWhenOpening(t *testing.T) {
+	path := logPath(t)
+
+	r := open(t, path, 10)
+	insert(t, r, "aa", "bb", "cc")
+	closeRing(t, r)
+
+	r, err := ringfile.Open(path, 10, ringfile.WithMaxSize(4))
+	if err != nil {
+		t.Fatalf("opening ring: %v", err)
+	}
+
+	closeRing(t, r)
+
+	r = open(t, path, 10)
+	defer closeRing(t, r)
+
+	assertDrain(t, r, "bb", "cc")
+}
+
+// tests that the maximum size bounds the size of the log file, for
+// small and large elements.
+func ringMaxSizeBoundsLog(t *testing.T) {
+	const maxSize = 4096
+
+	for _, size := range []int{10, 100, 1000, 4000} {
+		path := logPath(t)
+
+		r, err := ringfile.Open(path, 1000, ringfile.WithMaxSize(maxSize))
+		if err != nil {
+			t.Fatalf("opening ring: %v", err)
+		}
+
+		for i := 0; i < 1000; i++ {
+			insert(t, r, string(make([]byte, size)))
+
+			// twice the maximum size, plus the overhead of the header
+			// and the records, plus the records written since the last
+			// compaction check
+			limit := int64(2*(maxSize+9*r.Len()+8) + 1024 + 2*(size+9))
+			if got := fileSize(t, path); got > limit {
+				t.Fatalf("element size %d: log too big after %d inserts: %d bytes, limit %d",
+					size, i+1, got, limit)
+			}
+		}
+
+		if got := r.Len(); got != maxSize/size {
+			t.Errorf("element size %d: want %d elements, got %d", size, maxSize/size, got)
+		}
+
+		closeRing(t, r)
+	}
+}
@@ -0,0 +1,169 @@
+package ringhttp
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+// Request is the summary of an HTTP request recorded by the
+// middleware.
+type Request struct {
+	Time         time.Time     `json:"time"`
+	Method       string        `json:"method"`
+	Path         string        `json:"path"`
+	Status       int           `json:"status"`
+	Latency      time.Duration `json:"latency"`
+	RequestBody  string        `json:"request_body,omitempty"`
+	ResponseBody string        `json:"response_body,omitempty"`
+}
+
+// MiddlewareOption configures a middleware on construction.
+type MiddlewareOption func(*middlewareConfig)
+
+// the configuration set by the middleware options.
+type middlewareConfig struct {
+	clock ring.Clock
+}
+
+// WithClock makes the middleware tell the time and the latency of the
+// requests with c instead of the time package.  It is meant for tests
+// advancing the time deterministically.
+func WithClock(c ring.Clock) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		cfg.clock = c
+	}
+}
+
+// Middleware returns an HTTP middleware that inserts a Request in the
+// ring r for each request served by the wrapped handler, so the ring
+// holds the last requests and can be served with Handler.
+//
+// If maxBody is greater than zero, the first maxBody bytes of the
+// request and response bodies are recorded too.  Only the parts of
+// the request body read by the wrapped handler are recorded.
+func Middleware(r *ring.Ring, maxBody int, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	c := middlewareConfig{clock: ring.SystemClock{}}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := c.clock.Now()
+
+			rw := &recorder{
+				ResponseWriter: w,
+				status:         http.StatusOK,
+				body:           limitedBuffer{max: maxBody},
+			}
+
+			var reqBody *limitedBuffer
+
+			if maxBody > 0 && req.Body != nil {
+				reqBody = &limitedBuffer{max: maxBody}
+				req.Body = teeReadCloser{
+					Reader: io.TeeReader(req.Body, reqBody),
+					Closer: req.Body,
+				}
+			}
+
+			next.ServeHTTP(rw.wrap(), req)
+
+			entry := Request{
+				Time:         start,
+				Method:       req.Method,
+				Path:         req.URL.Path,
+				Status:       rw.status,
+				Latency:      c.clock.Now().Sub(start),
+				ResponseBody: rw.body.String(),
+			}
+
+			if reqBody != nil {
+				entry.RequestBody = reqBody.String()
+			}
+
+			r.Insert(entry)
+		})
+	}
+}
+
+// a response writer that records the status and the beginning of the
+// body.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        limitedBuffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	_, _ = r.body.Write(p)
+
+	return r.ResponseWriter.Write(p)
+}
+
+// returns r extended with the optional http.Flusher and http.Hijacker
+// interfaces implemented by the wrapped writer, so handlers behind the
+// middleware see the same interfaces they would without it.
+func (r *recorder) wrap() http.ResponseWriter {
+	f, isFlusher := r.ResponseWriter.(http.Flusher)
+	h, isHijacker := r.ResponseWriter.(http.Hijacker)
+
+	switch {
+	case isFlusher && isHijacker:
+		return struct {
+			*recorder
+			http.Flusher
+			http.Hijacker
+		}{r, f, h}
+	case isFlusher:
+		return struct {
+			*recorder
+			http.Flusher
+		}{r, f}
+	case isHijacker:
+		return struct {
+			*recorder
+			http.Hijacker
+		}{r, h}
+	default:
+		return r
+	}
+}
+
+// a writer that keeps only the first max bytes written to it.
+type limitedBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); room < len(p) {
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+
+		return len(p), nil
+	}
+
+	return b.Buffer.Write(p)
+}
+
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
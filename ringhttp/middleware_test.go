@@ -0,0 +1,251 @@
+package ringhttp_test
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring/ringhttp"
+	"github.com/alcortesm/ring/ringtest"
+	"github.com/alcortesm/ring/ringws"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"records requests": middlewareRecordsRequests,
+		"keeps last":       middlewareKeepsLast,
+		"records bodies":   middlewareRecordsBodies,
+		"clock":            middlewareClock,
+		"interfaces":       middlewareInterfaces,
+		"websocket":        middlewareWebSocket,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// a handler that echoes the request body with the status in the
+// "status" query parameter.
+var echo = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+
+	if req.URL.Query().Get("status") == "404" {
+		w.WriteHeader(http.StatusNotFound)
+	}
+
+	_, _ = w.Write(body)
+})
+
+// sends a request with the given method, url and body to h.
+func send(h http.Handler, method, url, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	return rec
+}
+
+// returns the requests recorded in the ring.
+func recorded(t *testing.T, vs []interface{}) []ringhttp.Request {
+	t.Helper()
+
+	result := make([]ringhttp.Request, len(vs))
+
+	for i, v := range vs {
+		req, ok := v.(ringhttp.Request)
+		if !ok {
+			t.Fatalf("unexpected element type %T", v)
+		}
+
+		result[i] = req
+	}
+
+	return result
+}
+
+// tests that the middleware records the method, path and status of
+// the requests without altering the responses.
+func middlewareRecordsRequests(t *testing.T) {
+	r := newRing(t, 4)
+	h := ringhttp.Middleware(r, 0)(echo)
+
+	rec := send(h, http.MethodPost, "/foo", "hello")
+	if rec.Code != http.StatusOK || rec.Body.String() != "hello" {
+		t.Fatalf("response altered: %d %q", rec.Code, rec.Body)
+	}
+
+	rec = send(h, http.MethodGet, "/bar?status=404", "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("response altered: %d", rec.Code)
+	}
+
+	got := recorded(t, r.ToSlice())
+	if len(got) != 2 {
+		t.Fatalf("want 2 recorded requests, got %d", len(got))
+	}
+
+	if got[0].Method != http.MethodPost || got[0].Path != "/foo" || got[0].Status != http.StatusOK {
+		t.Errorf("unexpected first request: %+v", got[0])
+	}
+
+	if got[1].Method != http.MethodGet || got[1].Path != "/bar" || got[1].Status != http.StatusNotFound {
+		t.Errorf("unexpected second request: %+v", got[1])
+	}
+
+	for _, req := range got {
+		if req.RequestBody != "" || req.ResponseBody != "" {
+			t.Errorf("unexpected bodies recorded: %+v", req)
+		}
+
+		if req.Time.IsZero() || req.Latency < 0 {
+			t.Errorf("unexpected timing: %+v", req)
+		}
+	}
+}
+
+// tests that only the last requests are kept.
+func middlewareKeepsLast(t *testing.T) {
+	r := newRing(t, 2)
+	h := ringhttp.Middleware(r, 0)(echo)
+
+	for _, path := range []string{"/a", "/b", "/c"} {
+		send(h, http.MethodGet, path, "")
+	}
+
+	got := recorded(t, r.ToSlice())
+	if len(got) != 2 || got[0].Path != "/b" || got[1].Path != "/c" {
+		t.Errorf("unexpected recorded requests: %+v", got)
+	}
+}
+
+// tests that bodies are recorded up to the size limit.
+func middlewareRecordsBodies(t *testing.T) {
+	r := newRing(t, 2)
+	h := ringhttp.Middleware(r, 4)(echo)
+
+	rec := send(h, http.MethodPost, "/", "0123456789")
+	if rec.Body.String() != "0123456789" {
+		t.Fatalf("response altered: %q", rec.Body)
+	}
+
+	got := recorded(t, r.ToSlice())
+	if len(got) != 1 {
+		t.Fatalf("want 1 recorded request, got %d", len(got))
+	}
+
+	if got[0].RequestBody != "0123" || got[0].ResponseBody != "0123" {
+		t.Errorf("unexpected bodies recorded: %+v", got[0])
+	}
+}
+
+// tests that the time and the latency of the requests are told by the
+// clock.
+func middlewareClock(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := ringtest.NewClock(start)
+
+	slow := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		clock.Advance(time.Second)
+	})
+
+	r := newRing(t, 1)
+	send(ringhttp.Middleware(r, 0, ringhttp.WithClock(clock))(slow), http.MethodGet, "/", "")
+
+	got := recorded(t, r.ToSlice())
+	if len(got) != 1 || !got[0].Time.Equal(start) || got[0].Latency != time.Second {
+		t.Errorf("want a request at %v taking a second, got %+v", start, got)
+	}
+}
+
+// tests that the writer seen by the wrapped handler implements
+// http.Flusher and http.Hijacker only when the underlying writer does.
+func middlewareInterfaces(t *testing.T) {
+	// plain hides the optional interfaces of the wrapped writer.
+	type plain struct{ http.ResponseWriter }
+
+	type hijacker struct {
+		plain
+		http.Hijacker
+	}
+
+	type flushHijacker struct {
+		*httptest.ResponseRecorder
+		http.Hijacker
+	}
+
+	tests := map[string]struct {
+		w                         http.ResponseWriter
+		wantFlusher, wantHijacker bool
+	}{
+		"neither":  {plain{httptest.NewRecorder()}, false, false},
+		"flusher":  {httptest.NewRecorder(), true, false},
+		"hijacker": {hijacker{plain: plain{httptest.NewRecorder()}}, false, true},
+		"both":     {flushHijacker{ResponseRecorder: httptest.NewRecorder()}, true, true},
+	}
+
+	for name, test := range tests {
+		var isFlusher, isHijacker bool
+
+		h := ringhttp.Middleware(newRing(t, 1), 0)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, isFlusher = w.(http.Flusher)
+			_, isHijacker = w.(http.Hijacker)
+		}))
+		h.ServeHTTP(test.w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if isFlusher != test.wantFlusher || isHijacker != test.wantHijacker {
+			t.Errorf("%s: want flusher %t and hijacker %t, got %t and %t",
+				name, test.wantFlusher, test.wantHijacker, isFlusher, isHijacker)
+		}
+	}
+}
+
+// tests that a WebSocket handler behind the middleware can hijack the
+// connection.
+func middlewareWebSocket(t *testing.T) {
+	r := newRing(t, 1)
+	r.Insert("hello")
+
+	srv := httptest.NewServer(ringhttp.Middleware(newRing(t, 1), 0)(ringws.Handler(r, nil)))
+	t.Cleanup(srv.Close)
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatalf("dialing: %v", err)
+	}
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	_, err = io.WriteString(conn, "GET / HTTP/1.1\r\n"+
+		"Host: example.com\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n")
+	if err != nil {
+		t.Fatalf("sending handshake: %v", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("reading handshake response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("want status %d, got %d", http.StatusSwitchingProtocols, resp.StatusCode)
+	}
+}
@@ -0,0 +1,85 @@
+package ringhttp
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/alcortesm/ring"
+)
+
+// a ring in the list served by the registry handler.
+type registryEntry struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Stats  ring.Stats        `json:"stats"`
+	// HeadAge is the age of the oldest element in seconds, only for
+	// non-empty rings created with the ring.WithTimestamps option.
+	HeadAge *float64 `json:"head_age,omitempty"`
+}
+
+var registryTemplate = template.Must(template.New("registry").Parse(`<!DOCTYPE html>
+<html>
+<head><title>rings</title></head>
+<body>
+<table>
+<tr><th>name</th><th>len</th><th>cap</th><th>inserted</th><th>extracted</th><th>dropped</th></tr>
+{{- range .}}
+<tr><td><a href="?name={{.Name}}&amp;format=html">{{.Name}}</a></td><td>{{.Stats.Len}}</td><td>{{.Stats.Cap}}</td><td>{{.Stats.Inserted}}</td><td>{{.Stats.Extracted}}</td><td>{{.Stats.Dropped}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// RegistryHandler returns an HTTP handler that serves the list of rings
+// registered with ring.Register, with their stats, and the age of their
+// oldest element if they have timestamps.  If the "name" query
+// parameter is set, it serves the contents of the ring registered under
+// that name instead, like Handler does, or a 404 if there is none.
+//
+// The response format is chosen like in Handler.
+func RegistryHandler(render func(v interface{}) interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if name := req.URL.Query().Get("name"); name != "" {
+			r, ok := ring.Lookup(name)
+			if !ok {
+				http.NotFound(w, req)
+				return
+			}
+
+			Handler(r, render).ServeHTTP(w, req)
+
+			return
+		}
+
+		entries := []registryEntry{}
+
+		for _, name := range ring.Registered() {
+			// it could have been unregistered in the meantime
+			r, ok := ring.Lookup(name)
+			if !ok {
+				continue
+			}
+
+			entry := registryEntry{
+				Name:   name,
+				Labels: r.Labels(),
+				Stats:  r.Stats(),
+			}
+
+			if age, ok := r.OldestAge(); ok {
+				seconds := age.Seconds()
+				entry.HeadAge = &seconds
+			}
+
+			entries = append(entries, entry)
+		}
+
+		if wantsHTML(req) {
+			serveTemplate(w, registryTemplate, entries)
+			return
+		}
+
+		serveJSON(w, entries)
+	})
+}
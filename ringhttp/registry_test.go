@@ -0,0 +1,152 @@
+package ringhttp_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringhttp"
+)
+
+func TestRegistryHandler(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"list":         registryHandlerList,
+		"list html":    registryHandlerListHTML,
+		"drill down":   registryHandlerDrillDown,
+		"unknown name": registryHandlerUnknownName,
+		"head age":     registryHandlerHeadAge,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// registers r under name or fails the test, unregistering it at the end
+// of the test.
+func register(t *testing.T, name string, r *ring.Ring) {
+	t.Helper()
+
+	if err := ring.Register(name, r); err != nil {
+		t.Fatalf("registering %q: %v", name, err)
+	}
+
+	t.Cleanup(func() { ring.Unregister(name) })
+}
+
+// tests that the registered rings are listed with their stats.
+func registryHandlerList(t *testing.T) {
+	register(t, "list", newRing(t, 2, 1, 2, 3))
+
+	var got []struct {
+		Name  string
+		Stats ring.Stats
+	}
+
+	decode(t, serve(ringhttp.RegistryHandler(nil), "/", nil), &got)
+
+	for _, e := range got {
+		if e.Name != "list" {
+			continue
+		}
+
+		want := ring.Stats{Len: 2, Cap: 2, Inserted: 3, Dropped: 1, MaxLen: 2, PeakLen: 2}
+		if e.Stats != want {
+			t.Errorf("want stats %+v, got %+v", want, e.Stats)
+		}
+
+		return
+	}
+
+	t.Errorf("registered ring not listed in %+v", got)
+}
+
+// tests that the HTML list links to the contents of each ring.
+func registryHandlerListHTML(t *testing.T) {
+	register(t, "list html", newRing(t, 2))
+
+	rec := serve(ringhttp.RegistryHandler(nil), "/?format=html", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+
+	if want := `<a href="?name=list%20html&amp;format=html">list html</a>`; !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("link %s not found in body:\n%s", want, rec.Body)
+	}
+}
+
+// tests that the contents of a ring are served by name.
+func registryHandlerDrillDown(t *testing.T) {
+	register(t, "drill down", newRing(t, 3, "a", "b"))
+
+	var got struct {
+		Elements []string
+	}
+
+	decode(t, serve(ringhttp.RegistryHandler(nil), "/?name=drill+down", nil), &got)
+
+	if strings.Join(got.Elements, ",") != "a,b" {
+		t.Errorf("unexpected elements %q", got.Elements)
+	}
+}
+
+// tests that asking for an unregistered ring fails.
+func registryHandlerUnknownName(t *testing.T) {
+	rec := serve(ringhttp.RegistryHandler(nil), "/?name=nope", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("want status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+// tests that the age of the oldest element is listed only for rings
+// with timestamps.
+func registryHandlerHeadAge(t *testing.T) {
+	timed, err := ring.New(2, ring.WithTimestamps())
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	timed.Insert(1)
+	time.Sleep(10 * time.Millisecond)
+
+	register(t, "head age timed", timed)
+	register(t, "head age untimed", newRing(t, 2, 1))
+
+	var got []struct {
+		Name    string
+		HeadAge *float64 `json:"head_age"`
+	}
+
+	decode(t, serve(ringhttp.RegistryHandler(nil), "/", nil), &got)
+
+	seen := 0
+
+	for _, e := range got {
+		switch e.Name {
+		case "head age timed":
+			seen++
+
+			if e.HeadAge == nil || *e.HeadAge < 0.01 {
+				t.Errorf("want head age >= 0.01s, got %v", e.HeadAge)
+			}
+		case "head age untimed":
+			seen++
+
+			if e.HeadAge != nil {
+				t.Errorf("want no head age, got %v", *e.HeadAge)
+			}
+		}
+	}
+
+	if seen != 2 {
+		t.Errorf("registered rings not listed in %+v", got)
+	}
+}
@@ -0,0 +1,160 @@
+package ringhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/alcortesm/ring"
+)
+
+// the maximum size of an element inserted through the REST handler.
+const maxItemBody = 1 << 20
+
+// RESTHandler returns an HTTP handler to insert and extract the
+// elements of the ring r, for integrations from scripts and non-Go
+// services.  It serves, relative to the path it is mounted on:
+//
+//	POST /items          inserts the element in the body
+//	DELETE /items/head   extracts and returns the oldest element, or
+//	                     404 if the ring is empty, or 409 if it is
+//	                     reserved by someone else, see ring.Reserve
+//	GET /items?n=N       returns the newest N elements, or all of them
+//	                     if n is missing, from oldest to newest, as a
+//	                     JSON array, without extracting them
+//
+// Elements are encoded with encode and decoded with decode, which must
+// produce and accept JSON.  If they are nil, elements are marshaled
+// with json.Marshal and unmarshaled as interface{} with json.Unmarshal.
+// Elements that cannot be encoded are not extracted.
+func RESTHandler(r *ring.Ring, encode func(v interface{}) ([]byte, error),
+	decode func(p []byte) (interface{}, error)) http.Handler {
+	if encode == nil {
+		encode = json.Marshal
+	}
+
+	if decode == nil {
+		decode = func(p []byte) (interface{}, error) {
+			var v interface{}
+			err := json.Unmarshal(p, &v)
+
+			return v, err
+		}
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/items", func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodPost:
+			insertItem(w, req, r, decode)
+		case http.MethodGet:
+			listItems(w, req, r, encode)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// serializes the extractions, as there is at most one reservation
+	// at a time
+	var extracting sync.Mutex
+
+	mux.HandleFunc("/items/head", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodDelete {
+			w.Header().Set("Allow", "DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		extracting.Lock()
+		defer extracting.Unlock()
+
+		extractItem(w, r, encode)
+	})
+
+	return mux
+}
+
+// serves the oldest element of r, extracting it only once encoded.
+func extractItem(w http.ResponseWriter, r *ring.Ring, encode func(v interface{}) ([]byte, error)) {
+	slot, ok := r.Reserve()
+	if !ok {
+		if r.Len() == 0 {
+			http.Error(w, "ring is empty", http.StatusNotFound)
+		} else {
+			http.Error(w, "oldest element is reserved", http.StatusConflict)
+		}
+
+		return
+	}
+
+	p, err := encode(slot.Value())
+	if err != nil {
+		slot.Release()
+		http.Error(w, fmt.Sprintf("encoding element: %v", err), http.StatusInternalServerError)
+
+		return
+	}
+
+	slot.Commit()
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(p)
+}
+
+// inserts the element in the body of the request in r.
+func insertItem(w http.ResponseWriter, req *http.Request, r *ring.Ring,
+	decode func(p []byte) (interface{}, error)) {
+	p, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxItemBody))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("reading body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	v, err := decode(p)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("decoding element: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	r.Insert(v)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serves the newest elements of r, as many as the n query parameter
+// says, or all of them.
+func listItems(w http.ResponseWriter, req *http.Request, r *ring.Ring,
+	encode func(v interface{}) ([]byte, error)) {
+	elements := r.ToSlice()
+
+	if s := req.URL.Query().Get("n"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			http.Error(w, fmt.Sprintf("n must be an integer >= 0, got %q", s), http.StatusBadRequest)
+			return
+		}
+
+		if n < len(elements) {
+			elements = elements[len(elements)-n:]
+		}
+	}
+
+	items := make([]json.RawMessage, len(elements))
+
+	for i, v := range elements {
+		p, err := encode(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("encoding element: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		items[i] = p
+	}
+
+	serveJSON(w, items)
+}
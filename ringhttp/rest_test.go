@@ -0,0 +1,206 @@
+package ringhttp_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/alcortesm/ring/ringhttp"
+)
+
+func TestRESTHandler(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"insert and extract":  restInsertAndExtract,
+		"extract from empty":  restExtractFromEmpty,
+		"list newest":         restListNewest,
+		"invalid requests":    restInvalidRequests,
+		"custom codec":        restCustomCodec,
+		"extract unencodable": restExtractUnencodable,
+		"extract reserved":    restExtractReserved,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// tests that posted elements are inserted in the ring, and extracted
+// from the head.
+func restInsertAndExtract(t *testing.T) {
+	r := newRing(t, 3)
+	h := ringhttp.RESTHandler(r, nil, nil)
+
+	for _, body := range []string{`{"a":1}`, `"b"`} {
+		if rec := send(h, http.MethodPost, "/items", body); rec.Code != http.StatusNoContent {
+			t.Fatalf("posting %s: unexpected status %d: %s", body, rec.Code, rec.Body)
+		}
+	}
+
+	if r.Len() != 2 {
+		t.Fatalf("want length 2, got %d", r.Len())
+	}
+
+	for _, want := range []string{`{"a":1}`, `"b"`} {
+		rec := send(h, http.MethodDelete, "/items/head", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body)
+		}
+
+		if got := rec.Body.String(); got != want {
+			t.Errorf("want %s, got %s", want, got)
+		}
+	}
+}
+
+// tests that extracting from an empty ring is a 404.
+func restExtractFromEmpty(t *testing.T) {
+	h := ringhttp.RESTHandler(newRing(t, 1), nil, nil)
+
+	if rec := send(h, http.MethodDelete, "/items/head", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("want status 404, got %d", rec.Code)
+	}
+}
+
+// tests that listing returns the newest elements without extracting
+// them.
+func restListNewest(t *testing.T) {
+	r := newRing(t, 5, 1, 2, 3, 4)
+	h := ringhttp.RESTHandler(r, nil, nil)
+
+	for url, want := range map[string]string{
+		"/items":      "[1,2,3,4]",
+		"/items?n=2":  "[3,4]",
+		"/items?n=10": "[1,2,3,4]",
+		"/items?n=0":  "[]",
+	} {
+		rec := send(h, http.MethodGet, url, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: unexpected status %d: %s", url, rec.Code, rec.Body)
+		}
+
+		if got := rec.Body.String(); got != want {
+			t.Errorf("%s: want %s, got %s", url, want, got)
+		}
+	}
+
+	if r.Len() != 4 {
+		t.Errorf("want length 4, got %d", r.Len())
+	}
+}
+
+// tests that malformed requests are rejected without modifying the
+// ring.
+func restInvalidRequests(t *testing.T) {
+	r := newRing(t, 2, 1)
+	h := ringhttp.RESTHandler(r, nil, nil)
+
+	tests := []struct {
+		method, url, body string
+		want              int
+	}{
+		{http.MethodPost, "/items", "{not json", http.StatusBadRequest},
+		{http.MethodGet, "/items?n=-1", "", http.StatusBadRequest},
+		{http.MethodGet, "/items?n=x", "", http.StatusBadRequest},
+		{http.MethodPut, "/items", "1", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/items/head", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/other", "", http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		if rec := send(h, test.method, test.url, test.body); rec.Code != test.want {
+			t.Errorf("%s %s: want status %d, got %d", test.method, test.url, test.want, rec.Code)
+		}
+	}
+
+	if r.Len() != 1 {
+		t.Errorf("want length 1, got %d", r.Len())
+	}
+}
+
+// tests that elements are encoded and decoded with the given functions.
+func restCustomCodec(t *testing.T) {
+	type point struct{ X, Y int }
+
+	encode := func(v interface{}) ([]byte, error) {
+		p, ok := v.(point)
+		if !ok {
+			return nil, errors.New("not a point")
+		}
+
+		return json.Marshal([]int{p.X, p.Y})
+	}
+
+	decode := func(b []byte) (interface{}, error) {
+		var xy [2]int
+		err := json.Unmarshal(b, &xy)
+
+		return point{X: xy[0], Y: xy[1]}, err
+	}
+
+	r := newRing(t, 2)
+	h := ringhttp.RESTHandler(r, encode, decode)
+
+	send(h, http.MethodPost, "/items", "[1,2]")
+
+	if v, ok := r.Peek(); !ok || v != (point{X: 1, Y: 2}) {
+		t.Fatalf("want {1 2} in the ring, got %v, %t", v, ok)
+	}
+
+	if got := send(h, http.MethodGet, "/items", "").Body.String(); got != "[[1,2]]" {
+		t.Errorf("want [[1,2]], got %s", got)
+	}
+
+	r.Insert("not a point")
+
+	if rec := send(h, http.MethodGet, "/items", ""); rec.Code != http.StatusInternalServerError ||
+		!strings.Contains(rec.Body.String(), "not a point") {
+		t.Errorf("want encoding error, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+// tests that elements that cannot be encoded are not extracted.
+func restExtractUnencodable(t *testing.T) {
+	r := newRing(t, 2, func() {}, "b")
+	h := ringhttp.RESTHandler(r, nil, nil)
+
+	if rec := send(h, http.MethodDelete, "/items/head", ""); rec.Code != http.StatusInternalServerError {
+		t.Errorf("want status 500, got %d", rec.Code)
+	}
+
+	if r.Len() != 2 {
+		t.Errorf("want length 2, got %d", r.Len())
+	}
+
+	if got := r.Stats().Extracted; got != 0 {
+		t.Errorf("want nothing extracted, got %d", got)
+	}
+}
+
+// tests that extracting an element reserved by someone else is a 409.
+func restExtractReserved(t *testing.T) {
+	r := newRing(t, 2, "a", "b")
+	h := ringhttp.RESTHandler(r, nil, nil)
+
+	slot, ok := r.Reserve()
+	if !ok {
+		t.Fatal("reserving: unexpected failure")
+	}
+
+	if rec := send(h, http.MethodDelete, "/items/head", ""); rec.Code != http.StatusConflict {
+		t.Errorf("want status 409, got %d", rec.Code)
+	}
+
+	slot.Release()
+
+	if rec := send(h, http.MethodDelete, "/items/head", ""); rec.Code != http.StatusOK || rec.Body.String() != `"a"` {
+		t.Errorf("want \"a\", got %d: %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Package ringhttp provides HTTP handlers to inspect rings, intended to be
+mounted under debug endpoints.
+*/
+package ringhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/alcortesm/ring"
+)
+
+// the data served by the handler.
+type page struct {
+	Name     string            `json:"name,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Len      int               `json:"len"`
+	Cap      int               `json:"cap"`
+	Elements []interface{}     `json:"elements"`
+	Stats    ring.Stats        `json:"stats"`
+	Drops    []ring.DropBucket `json:"drops,omitempty"`
+}
+
+var pageTemplate = template.Must(template.New("ring").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{with .Name}}{{.}}{{else}}ring{{end}}</title></head>
+<body>
+{{- with .Labels}}
+<p>labels:{{range $k, $v := .}} {{$k}}="{{$v}}"{{end}}</p>
+{{- end}}
+<p>len: {{.Len}}, cap: {{.Cap}}</p>
+{{- with .Stats}}
+<p>inserted: {{.Inserted}}, extracted: {{.Extracted}}, dropped: {{.Dropped}}, max len: {{.MaxLen}}, peak len: {{.PeakLen}}</p>
+{{- end}}
+{{- with .Drops}}
+<table>
+<tr><th>since</th><th>drops</th></tr>
+{{- range .}}
+<tr><td>{{.Start.Format "2006-01-02T15:04:05Z07:00"}}</td><td>{{.Drops}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+<ol>
+{{- range .Elements}}
+<li>{{printf "%v" .}}</li>
+{{- end}}
+</ol>
+</body>
+</html>
+`))
+
+// Handler returns an HTTP handler that serves the current contents of
+// the ring r, from oldest to newest, along with its stats and, if it
+// was created with the WithDropHistory option, its drops over time.
+//
+// Each element is passed through render before serving it, which
+// allows to hide sensitive data or to convert elements to something
+// that can be encoded.  A nil render serves the elements as they are.
+//
+// The response is HTML if the "format" query parameter is "html" or
+// if the request accepts "text/html", otherwise it is JSON.
+func Handler(r *ring.Ring, render func(v interface{}) interface{}) http.Handler {
+	if render == nil {
+		render = func(v interface{}) interface{} { return v }
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		elements := r.ToSlice()
+		for i, v := range elements {
+			elements[i] = render(v)
+		}
+
+		p := page{
+			Name:     r.Name(),
+			Labels:   r.Labels(),
+			Len:      len(elements),
+			Cap:      r.Cap(),
+			Elements: elements,
+			Stats:    r.Stats(),
+			Drops:    r.DropHistory(),
+		}
+
+		if wantsHTML(req) {
+			serveTemplate(w, pageTemplate, p)
+			return
+		}
+
+		serveJSON(w, p)
+	})
+}
+
+// returns if the response to the request should be HTML.
+func wantsHTML(req *http.Request) bool {
+	switch req.URL.Query().Get("format") {
+	case "html":
+		return true
+	case "json":
+		return false
+	}
+
+	return strings.Contains(req.Header.Get("Accept"), "text/html")
+}
+
+func serveJSON(w http.ResponseWriter, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		msg := fmt.Sprintf("encoding ring contents: %v", err)
+		http.Error(w, msg, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+func serveTemplate(w http.ResponseWriter, t *template.Template, data interface{}) {
+	var b strings.Builder
+
+	if err := t.Execute(&b, data); err != nil {
+		msg := fmt.Sprintf("rendering ring contents: %v", err)
+		http.Error(w, msg, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(b.String()))
+}
@@ -0,0 +1,243 @@
+package ringhttp_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringhttp"
+)
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"json":                handlerJSON,
+		"html":                handlerHTML,
+		"html by accept":      handlerHTMLByAccept,
+		"render":              handlerRender,
+		"unencodable element": handlerUnencodable,
+		"name and labels":     handlerNameAndLabels,
+		"stats and drops":     handlerStatsAndDrops,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a ring with the given capacity and elements or fails the
+// test.
+func newRing(t *testing.T, cap int, vs ...interface{}) *ring.Ring {
+	t.Helper()
+
+	r, err := ring.New(cap)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	for _, v := range vs {
+		r.Insert(v)
+	}
+
+	return r
+}
+
+// serves the request to h and returns the recorded response.
+func serve(h http.Handler, url string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	return rec
+}
+
+// decodes the JSON body of the response into v or fails the test.
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body)
+	}
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+}
+
+// tests that the handler serves the ring contents as JSON by default.
+func handlerJSON(t *testing.T) {
+	r := newRing(t, 3, "a", "b", "c", "d")
+	rec := serve(ringhttp.Handler(r, nil), "/", nil)
+
+	var got struct {
+		Len      int
+		Cap      int
+		Elements []string
+	}
+
+	decode(t, rec, &got)
+
+	if got.Len != 3 || got.Cap != 3 {
+		t.Errorf("want len 3 and cap 3, got %d and %d", got.Len, got.Cap)
+	}
+
+	want := []string{"b", "c", "d"}
+	if !reflect.DeepEqual(got.Elements, want) {
+		t.Errorf("want elements %v, got %v", want, got.Elements)
+	}
+
+	if got := r.Len(); got != 3 {
+		t.Errorf("serving extracted elements, len is %d", got)
+	}
+}
+
+// asserts that the response is an HTML page listing the given
+// elements.
+func assertHTML(t *testing.T, rec *httptest.ResponseRecorder, elements ...string) {
+	t.Helper()
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body)
+	}
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+
+	body := rec.Body.String()
+	for _, e := range elements {
+		if !strings.Contains(body, "<li>"+e+"</li>") {
+			t.Errorf("element %q not found in body:\n%s", e, body)
+		}
+	}
+}
+
+// tests that the handler serves HTML when asked in the query.
+func handlerHTML(t *testing.T) {
+	r := newRing(t, 3, 1, 2, "<b>")
+	rec := serve(ringhttp.Handler(r, nil), "/?format=html", nil)
+	assertHTML(t, rec, "1", "2", "&lt;b&gt;")
+}
+
+// tests that the handler serves HTML to browsers.
+func handlerHTMLByAccept(t *testing.T) {
+	r := newRing(t, 3, 1, 2)
+	header := http.Header{"Accept": {"text/html,application/xhtml+xml"}}
+	rec := serve(ringhttp.Handler(r, nil), "/", header)
+	assertHTML(t, rec, "1", "2")
+}
+
+// tests that elements are rendered before serving them.
+func handlerRender(t *testing.T) {
+	r := newRing(t, 3, "secret", "public")
+	render := func(v interface{}) interface{} {
+		if v == "secret" {
+			return "REDACTED"
+		}
+
+		return v
+	}
+
+	rec := serve(ringhttp.Handler(r, render), "/?format=json", nil)
+
+	var got struct{ Elements []string }
+
+	decode(t, rec, &got)
+
+	want := []string{"REDACTED", "public"}
+	if !reflect.DeepEqual(got.Elements, want) {
+		t.Errorf("want elements %v, got %v", want, got.Elements)
+	}
+}
+
+// tests that elements that cannot be encoded are reported as errors.
+func handlerUnencodable(t *testing.T) {
+	r := newRing(t, 3, func() {})
+	rec := serve(ringhttp.Handler(r, nil), "/", nil)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("want status %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+}
+
+// tests that the name and labels of the ring are served.
+func handlerNameAndLabels(t *testing.T) {
+	r, err := ring.New(2, ring.WithName("requests"), ring.WithLabels(map[string]string{"env": "prod"}))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	var got struct {
+		Name   string
+		Labels map[string]string
+	}
+
+	decode(t, serve(ringhttp.Handler(r, nil), "/", nil), &got)
+
+	if got.Name != "requests" || got.Labels["env"] != "prod" {
+		t.Errorf("unexpected name %q and labels %v", got.Name, got.Labels)
+	}
+
+	body := serve(ringhttp.Handler(r, nil), "/?format=html", nil).Body.String()
+	if !strings.Contains(body, "<title>requests</title>") || !strings.Contains(body, `labels: env="prod"`) {
+		t.Errorf("name or labels not found in body:\n%s", body)
+	}
+}
+
+// tests that the stats and drops of the ring are served.
+func handlerStatsAndDrops(t *testing.T) {
+	r, err := ring.New(2, ring.WithDropHistory(3, time.Hour))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	for _, v := range []string{"a", "b", "c", "d", "e"} {
+		r.Insert(v)
+	}
+
+	_, _ = r.Extract()
+
+	var got struct {
+		Stats ring.Stats
+		Drops []ring.DropBucket
+	}
+
+	decode(t, serve(ringhttp.Handler(r, nil), "/", nil), &got)
+
+	want := ring.Stats{Len: 1, Cap: 2, Inserted: 5, Extracted: 1, Dropped: 3, MaxLen: 2, PeakLen: 2}
+	if got.Stats != want {
+		t.Errorf("want stats %+v, got %+v", want, got.Stats)
+	}
+
+	var drops uint64
+	for _, b := range got.Drops {
+		drops += b.Drops
+	}
+
+	if len(got.Drops) != 3 || drops != 3 {
+		t.Errorf("want 3 drops in 3 buckets, got %+v", got.Drops)
+	}
+
+	body := serve(ringhttp.Handler(r, nil), "/?format=html", nil).Body.String()
+	if !strings.Contains(body, "inserted: 5, extracted: 1, dropped: 3") || !strings.Contains(body, "<td>3</td>") {
+		t.Errorf("stats or drops not found in body:\n%s", body)
+	}
+}
@@ -0,0 +1,85 @@
+package ringhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/alcortesm/ring"
+)
+
+// EventsHandler returns an HTTP handler that streams the elements of
+// the ring r as server-sent events: first its current contents, from
+// oldest to newest, and then each new element as it is inserted, until
+// the client disconnects.
+//
+// Each event holds the result of encode as its data, and the sequence
+// number of the element as its id, see ring.Ring.Follow.  Clients
+// reconnecting with a Last-Event-ID header resume after that element.
+// If encode is nil, elements are encoded as JSON.
+func EventsHandler(r *ring.Ring, encode func(v interface{}) ([]byte, error)) http.Handler {
+	if encode == nil {
+		encode = json.Marshal
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming not supported", http.StatusInternalServerError)
+			return
+		}
+
+		var seq uint64
+
+		if id := req.Header.Get("Last-Event-ID"); id != "" {
+			last, err := strconv.ParseUint(id, 10, 64)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid Last-Event-ID %q", id), http.StatusBadRequest)
+				return
+			}
+
+			seq = last + 1
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		_ = r.Follow(req.Context(), seq, func(seq uint64, v interface{}) error {
+			data, err := encode(v)
+			if err != nil {
+				return err
+			}
+
+			if err := writeEvent(w, seq, data); err != nil {
+				return err
+			}
+
+			flusher.Flush()
+
+			return nil
+		})
+	})
+}
+
+// writes an event with the given id and data, one data field per line.
+func writeEvent(w http.ResponseWriter, id uint64, data []byte) error {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "id: %d\n", id)
+
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		b.WriteString("data: ")
+		b.Write(bytes.TrimSuffix(line, []byte("\r")))
+		b.WriteByte('\n')
+	}
+
+	b.WriteByte('\n')
+
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
@@ -0,0 +1,132 @@
+package ringhttp_test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alcortesm/ring/ringhttp"
+)
+
+func TestEventsHandler(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"replay and live":    eventsReplayAndLive,
+		"resume":             eventsResume,
+		"multiline":          eventsMultiline,
+		"invalid last event": eventsInvalidLastEvent,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// connects to h with the given Last-Event-ID, if not empty, and returns
+// a reader of the events stream, or fails the test.  The stream is
+// closed at the end of the test.
+func connect(t *testing.T, h http.Handler, lastID string) *bufio.Reader {
+	t.Helper()
+
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("creating request: %v", err)
+	}
+
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+
+	return bufio.NewReader(resp.Body)
+}
+
+// asserts that the next event in the stream has the wanted lines.
+func assertEvent(t *testing.T, br *bufio.Reader, want ...string) {
+	t.Helper()
+
+	var got []string
+
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading event: %v", err)
+		}
+
+		if line == "\n" {
+			break
+		}
+
+		got = append(got, strings.TrimSuffix(line, "\n"))
+	}
+
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("want event %q, got %q", want, got)
+	}
+}
+
+// tests that the current contents are streamed, followed by the new
+// elements.
+func eventsReplayAndLive(t *testing.T) {
+	r := newRing(t, 2, "a", "b", "c")
+	br := connect(t, ringhttp.EventsHandler(r, nil), "")
+
+	assertEvent(t, br, "id: 1", `data: "b"`)
+	assertEvent(t, br, "id: 2", `data: "c"`)
+
+	r.Insert(4)
+	assertEvent(t, br, "id: 3", "data: 4")
+}
+
+// tests that clients resume after their last event.
+func eventsResume(t *testing.T) {
+	r := newRing(t, 5, "a", "b", "c")
+	br := connect(t, ringhttp.EventsHandler(r, nil), "1")
+
+	assertEvent(t, br, "id: 2", `data: "c"`)
+}
+
+// tests that multiline data is sent in several data fields.
+func eventsMultiline(t *testing.T) {
+	r := newRing(t, 1, "x")
+	encode := func(v interface{}) ([]byte, error) {
+		return []byte("line 1\nline 2"), nil
+	}
+
+	br := connect(t, ringhttp.EventsHandler(r, encode), "")
+
+	assertEvent(t, br, "id: 0", "data: line 1", "data: line 2")
+}
+
+// tests that invalid Last-Event-ID headers are rejected.
+func eventsInvalidLastEvent(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Last-Event-ID", "nope")
+
+	rec := httptest.NewRecorder()
+	ringhttp.EventsHandler(newRing(t, 1), nil).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("want status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
@@ -0,0 +1,273 @@
+/*
+Package ringio provides io adapters over a bounded circular buffer of
+bytes.  When at maximum capacity, writes drop the oldest bytes to make
+room for the new ones, so the buffer always holds the most recent data.
+
+It also provides Writer, which keeps the most recent lines written to
+it in a ring.Ring.
+*/
+package ringio
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Buffer is a concurrent bounded circular buffer of bytes.
+type Buffer struct {
+	mu   sync.Mutex
+	buf  []byte // bytes storage
+	len  int    // how many bytes are stored in the buffer
+	head int    // index of the next byte to be read
+	// removed is how many bytes were removed from the buffer, read or
+	// dropped, since its creation.
+	removed uint64
+
+	scratch [][]byte // unused scratch buffers of ReadFrom and WriteTo
+}
+
+// the maximum size of the scratch buffers of ReadFrom and WriteTo, and
+// the maximum amount of them kept for reuse.
+const (
+	maxScratch   = 4 << 10
+	spareScratch = 2
+)
+
+// NewBuffer returns a new byte buffer with the given capacity.
+func NewBuffer(cap int) (*Buffer, error) {
+	if cap < 1 {
+		return nil, fmt.Errorf("buffer capacity must be > 0, got %d", cap)
+	}
+
+	return &Buffer{
+		buf: make([]byte, cap),
+	}, nil
+}
+
+// returns the index where the next byte will be written.
+func (b *Buffer) tail() int {
+	return (b.head + b.len) % cap(b.buf)
+}
+
+// Write implements io.Writer.  It always writes all of p, dropping the
+// oldest bytes in the buffer if needed.  If p is bigger than the
+// buffer capacity, only its last bytes are kept.
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := len(p)
+
+	// only the last cap bytes of p can survive
+	if len(p) > cap(b.buf) {
+		p = p[len(p)-cap(b.buf):]
+	}
+
+	// if there is not enough room, make it by dropping the oldest bytes
+	if over := b.len + len(p) - cap(b.buf); over > 0 {
+		b.remove(over)
+	}
+
+	tail := b.tail()
+	c := copy(b.buf[tail:], p)
+	copy(b.buf, p[c:])
+	b.len += len(p)
+
+	return n, nil
+}
+
+// read moves up to len(p) of the oldest bytes in the buffer into p.
+func (b *Buffer) read(p []byte) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := b.oldest(p)
+	b.remove(n)
+
+	return n
+}
+
+// copies up to len(p) of the oldest bytes in the buffer, which must be
+// locked, into p, and returns how many.
+func (b *Buffer) oldest(p []byte) int {
+	if len(p) > b.len {
+		p = p[:b.len]
+	}
+
+	end := b.head + len(p)
+	if end > cap(b.buf) {
+		end = cap(b.buf)
+	}
+
+	c := copy(p, b.buf[b.head:end])
+	copy(p[c:], b.buf)
+
+	return len(p)
+}
+
+// ReadFrom implements io.ReaderFrom.  It reads from r until io.EOF or
+// an error, writing what it reads into the buffer, dropping the oldest
+// bytes in the buffer if needed.  The reads are done into a scratch
+// buffer of up to 4KiB, reused between calls, without locking the
+// buffer, so it can be used while r blocks.
+func (b *Buffer) ReadFrom(r io.Reader) (int64, error) {
+	scratch := b.getScratch()
+	defer b.putScratch(scratch)
+
+	var total int64
+
+	for {
+		n, err := r.Read(scratch)
+		total += int64(n)
+
+		_, _ = b.Write(scratch[:n])
+
+		if err == io.EOF {
+			return total, nil
+		}
+
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// WriteTo implements io.WriterTo.  It drains the buffer into w, copying
+// its bytes into a scratch buffer of up to 4KiB, reused between calls,
+// to write them without locking the buffer, so it can be used while w
+// blocks.  Only the bytes successfully written to w are removed from
+// the buffer, unless they were already removed by a read or dropped by
+// a write meanwhile.
+func (b *Buffer) WriteTo(w io.Writer) (int64, error) {
+	scratch := b.getScratch()
+	defer b.putScratch(scratch)
+
+	var total int64
+
+	for {
+		n, from := b.peek(scratch)
+		if n == 0 {
+			return total, nil
+		}
+
+		written, err := w.Write(scratch[:n])
+		total += int64(written)
+
+		b.discard(from + uint64(written))
+
+		if err != nil {
+			return total, err
+		}
+
+		if written < n {
+			return total, io.ErrShortWrite
+		}
+	}
+}
+
+// returns a scratch buffer for ReadFrom and WriteTo, reusing an unused
+// one if possible, see putScratch.
+func (b *Buffer) getScratch() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if n := len(b.scratch); n > 0 {
+		p := b.scratch[n-1]
+		b.scratch = b.scratch[:n-1]
+
+		return p
+	}
+
+	n := cap(b.buf)
+	if n > maxScratch {
+		n = maxScratch
+	}
+
+	return make([]byte, n)
+}
+
+// keeps a scratch buffer no longer used for reuse, unless there are
+// enough of them already.
+func (b *Buffer) putScratch(p []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.scratch) < spareScratch {
+		b.scratch = append(b.scratch, p)
+	}
+}
+
+// copies up to len(p) of the oldest bytes in the buffer into p, without
+// removing them, and returns how many and the count of bytes removed
+// from the buffer before them, see discard.
+func (b *Buffer) peek(p []byte) (int, uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.oldest(p), b.removed
+}
+
+// removes the oldest bytes in the buffer until the given count of bytes
+// have been removed from it, if they were not already.
+func (b *Buffer) discard(upTo uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if upTo <= b.removed {
+		return
+	}
+
+	b.remove(int(upTo - b.removed))
+}
+
+// removes the n oldest bytes in the buffer, which must be locked.
+func (b *Buffer) remove(n int) {
+	b.head = (b.head + n) % cap(b.buf)
+	b.len -= n
+	b.removed += uint64(n)
+}
+
+// Len returns the amount of bytes in the buffer.
+func (b *Buffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.len
+}
+
+// Cap returns the capacity of the buffer.
+func (b *Buffer) Cap() int {
+	return cap(b.buf)
+}
+
+// Reader is an io.Reader that drains a Buffer.
+type Reader struct {
+	b *Buffer
+}
+
+// NewReader returns a reader that drains the given buffer.
+func NewReader(b *Buffer) *Reader {
+	return &Reader{b: b}
+}
+
+// Read implements io.Reader.  It extracts the oldest bytes in the
+// buffer into p.  It returns io.EOF when the buffer is empty, but
+// reading again after new writes to the buffer will succeed.
+func (r *Reader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	n := r.b.read(p)
+	if n == 0 {
+		return 0, io.EOF
+	}
+
+	return n, nil
+}
+
+// WriteTo implements io.WriterTo by draining the buffer into w.
+func (r *Reader) WriteTo(w io.Writer) (int64, error) {
+	return r.b.WriteTo(w)
+}
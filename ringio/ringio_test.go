@@ -0,0 +1,422 @@
+package ringio_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/alcortesm/ring/ringio"
+)
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid capacity":       readerInvalidCapacity,
+		"empty buffer is EOF":    readerEmptyIsEOF,
+		"reads what was written": readerReadsWritten,
+		"forgets oldest":         readerForgetsOldest,
+		"big write keeps last":   readerBigWriteKeepsLast,
+		"small reads wrap":       readerSmallReadsWrap,
+		"reads after EOF":        readerReadsAfterEOF,
+		"read from":              readerReadFrom,
+		"read from error":        readerReadFromError,
+		"write to":               readerWriteTo,
+		"write to short write":   readerWriteToShortWrite,
+		"read from unlocked":     readerReadFromUnlocked,
+		"write to unlocked":      readerWriteToUnlocked,
+		"big buffer round trip":  readerBigBufferRoundTrip,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a new buffer with the given capacity or fails the test.
+func newBuffer(t *testing.T, cap int) *ringio.Buffer {
+	t.Helper()
+
+	b, err := ringio.NewBuffer(cap)
+	if err != nil {
+		t.Fatalf("creating buffer: %v", err)
+	}
+
+	return b
+}
+
+// writes s to the buffer b or fails the test.
+func write(t *testing.T, b *ringio.Buffer, s string) {
+	t.Helper()
+
+	n, err := b.Write([]byte(s))
+	if err != nil {
+		t.Fatalf("writing %q: %v", s, err)
+	}
+
+	if n != len(s) {
+		t.Fatalf("short write of %q: %d bytes", s, n)
+	}
+}
+
+// asserts that draining r returns the bytes we want.
+func assertDrain(t *testing.T, r io.Reader, want string) {
+	t.Helper()
+
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("reading: %v", err)
+	}
+
+	if string(got) != want {
+		t.Errorf("unexpected contents, want %q, got %q", want, got)
+	}
+}
+
+// tests that capacities smaller than 1 are invalid
+func readerInvalidCapacity(t *testing.T) {
+	for _, cap := range []int{0, -1, -42} {
+		cap := cap
+		name := fmt.Sprintf("cap=%d", cap)
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ringio.NewBuffer(cap)
+			if err == nil {
+				t.Fatal("unexpected success")
+			}
+		})
+	}
+}
+
+// tests that reading from an empty buffer returns io.EOF.
+func readerEmptyIsEOF(t *testing.T) {
+	r := ringio.NewReader(newBuffer(t, 4))
+
+	n, err := r.Read(make([]byte, 4))
+	if n != 0 || err != io.EOF {
+		t.Fatalf("want 0, EOF, got %d, %v", n, err)
+	}
+}
+
+// tests that you can read the bytes written if they fit in the buffer.
+func readerReadsWritten(t *testing.T) {
+	b := newBuffer(t, 8)
+	write(t, b, "abc")
+	write(t, b, "de")
+
+	if got := b.Len(); got != 5 {
+		t.Fatalf("wrong length, want 5, got %d", got)
+	}
+
+	assertDrain(t, ringio.NewReader(b), "abcde")
+
+	if got := b.Len(); got != 0 {
+		t.Fatalf("wrong length after draining, want 0, got %d", got)
+	}
+}
+
+// tests that the buffer drops the oldest bytes when full.
+func readerForgetsOldest(t *testing.T) {
+	b := newBuffer(t, 4)
+	write(t, b, "abc")
+	write(t, b, "def")
+	assertDrain(t, ringio.NewReader(b), "cdef")
+}
+
+// tests that writes bigger than the capacity keep their last bytes.
+func readerBigWriteKeepsLast(t *testing.T) {
+	b := newBuffer(t, 4)
+	write(t, b, "ab")
+	write(t, b, "0123456789")
+	assertDrain(t, ringio.NewReader(b), "6789")
+}
+
+// tests that small reads return the bytes in order across the end of
+// the internal buffer.
+func readerSmallReadsWrap(t *testing.T) {
+	b := newBuffer(t, 5)
+	write(t, b, "abcd")
+	write(t, b, "efg") // drops "ab"
+
+	r := ringio.NewReader(b)
+	var got bytes.Buffer
+	p := make([]byte, 2)
+
+	for {
+		n, err := r.Read(p)
+		got.Write(p[:n])
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("reading: %v", err)
+		}
+	}
+
+	if got.String() != "cdefg" {
+		t.Errorf("unexpected contents, want %q, got %q", "cdefg", got.String())
+	}
+}
+
+// tests that a reader can keep reading after new writes.
+func readerReadsAfterEOF(t *testing.T) {
+	b := newBuffer(t, 4)
+	r := ringio.NewReader(b)
+
+	write(t, b, "ab")
+	assertDrain(t, r, "ab")
+
+	write(t, b, "cde")
+	assertDrain(t, r, "cde")
+}
+
+// tests that ReadFrom keeps the last bytes read, whatever the size of
+// the reads.
+func readerReadFrom(t *testing.T) {
+	for name, r := range map[string]io.Reader{
+		"one read":     strings.NewReader("0123456789"),
+		"byte by byte": iotest.OneByteReader(strings.NewReader("0123456789")),
+		"half reads":   iotest.HalfReader(strings.NewReader("0123456789")),
+	} {
+		r := r
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			b := newBuffer(t, 4)
+			write(t, b, "ab")
+
+			n, err := b.ReadFrom(r)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if n != 10 {
+				t.Errorf("wrong count, want 10, got %d", n)
+			}
+
+			assertDrain(t, ringio.NewReader(b), "6789")
+		})
+	}
+}
+
+// tests that ReadFrom returns the errors from the reader, keeping the
+// bytes read so far.
+func readerReadFromError(t *testing.T) {
+	b := newBuffer(t, 8)
+	r := iotest.TimeoutReader(iotest.OneByteReader(strings.NewReader("abc")))
+
+	n, err := b.ReadFrom(r)
+	if err != iotest.ErrTimeout {
+		t.Fatalf("want timeout error, got %v", err)
+	}
+
+	if n != 1 {
+		t.Errorf("wrong count, want 1, got %d", n)
+	}
+
+	assertDrain(t, ringio.NewReader(b), "a")
+}
+
+// counts the calls to its Write method.
+type countingWriter struct {
+	bytes.Buffer
+	calls int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.calls++
+	return w.Buffer.Write(p)
+}
+
+// tests that WriteTo drains the buffer in a single write.
+func readerWriteTo(t *testing.T) {
+	b := newBuffer(t, 5)
+	write(t, b, "abcd")
+	write(t, b, "efg") // wraps around the end of the storage
+
+	var w countingWriter
+
+	n, err := io.Copy(&w, ringio.NewReader(b))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if n != 5 {
+		t.Errorf("wrong count, want 5, got %d", n)
+	}
+
+	if w.String() != "cdefg" {
+		t.Errorf("unexpected contents, want %q, got %q", "cdefg", w.String())
+	}
+
+	if w.calls != 1 {
+		t.Errorf("wrong number of writes, want 1, got %d", w.calls)
+	}
+
+	if got := b.Len(); got != 0 {
+		t.Errorf("wrong length after draining, want 0, got %d", got)
+	}
+}
+
+// accepts only the first few bytes of each write.
+type shortWriter struct {
+	bytes.Buffer
+	max int
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.max {
+		p = p[:w.max]
+	}
+
+	return w.Buffer.Write(p)
+}
+
+// tests that WriteTo keeps the bytes that could not be written.
+func readerWriteToShortWrite(t *testing.T) {
+	b := newBuffer(t, 8)
+	write(t, b, "abcde")
+
+	w := shortWriter{max: 2}
+
+	n, err := b.WriteTo(&w)
+	if err != io.ErrShortWrite {
+		t.Fatalf("want short write error, got %v", err)
+	}
+
+	if n != 2 {
+		t.Errorf("wrong count, want 2, got %d", n)
+	}
+
+	assertDrain(t, ringio.NewReader(b), "cde")
+}
+
+// tests that the buffer can be used while ReadFrom blocks reading.
+func readerReadFromUnlocked(t *testing.T) {
+	b := newBuffer(t, 4)
+	pr, pw := io.Pipe()
+
+	done := make(chan error)
+	go func() {
+		_, err := b.ReadFrom(pr)
+		done <- err
+	}()
+
+	if _, err := pw.Write([]byte("ab")); err != nil {
+		t.Fatalf("writing to pipe: %v", err)
+	}
+
+	for b.Len() != 2 {
+		runtime.Gosched()
+	}
+
+	// ReadFrom is blocked reading again
+	write(t, b, "cdef")
+	assertDrain(t, ringio.NewReader(b), "cdef")
+
+	if _, err := pw.Write([]byte("gh")); err != nil {
+		t.Fatalf("writing to pipe: %v", err)
+	}
+
+	pw.Close()
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertDrain(t, ringio.NewReader(b), "gh")
+}
+
+// tests that the buffer can be used while WriteTo blocks writing, and
+// that the bytes dropped meanwhile are not removed twice.
+func readerWriteToUnlocked(t *testing.T) {
+	b := newBuffer(t, 4)
+	write(t, b, "abc")
+
+	pr, pw := io.Pipe()
+
+	done := make(chan error)
+	go func() {
+		_, err := b.WriteTo(pw)
+		pw.Close()
+		done <- err
+	}()
+
+	got := make([]byte, 2)
+	if _, err := io.ReadFull(pr, got); err != nil {
+		t.Fatalf("reading from pipe: %v", err)
+	}
+
+	// WriteTo is blocked writing "c", drop "abc" under it
+	write(t, b, "defg")
+
+	rest, err := ioutil.ReadAll(pr)
+	if err != nil {
+		t.Fatalf("reading from pipe: %v", err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := "abcdefg"; string(got)+string(rest) != want {
+		t.Errorf("want %q written, got %q", want, string(got)+string(rest))
+	}
+}
+
+// tests that buffers bigger than the scratch buffers of ReadFrom and
+// WriteTo are copied in full.
+func readerBigBufferRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	b := newBuffer(t, len(data))
+
+	if _, err := b.ReadFrom(bytes.NewReader(data)); err != nil {
+		t.Fatalf("reading: %v", err)
+	}
+
+	var w bytes.Buffer
+	if _, err := b.WriteTo(&w); err != nil {
+		t.Fatalf("writing: %v", err)
+	}
+
+	if !bytes.Equal(w.Bytes(), data) {
+		t.Errorf("want %d bytes back, got %d different ones", len(data), w.Len())
+	}
+}
+
+// discards its writes without allocating.
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) { return len(p), nil }
+
+// tests that ReadFrom and WriteTo reuse their scratch buffers.  It
+// cannot run in parallel with other tests, as they would allocate too.
+func TestCopyDoesNotAlloc(t *testing.T) {
+	b := newBuffer(t, 1<<20)
+	data := bytes.Repeat([]byte("x"), 1<<16)
+	r := bytes.NewReader(data)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Reset(data)
+		_, _ = b.ReadFrom(r)
+		_, _ = b.WriteTo(discard{})
+	})
+
+	if allocs > 0 {
+		t.Errorf("want no allocations, got %v per copy", allocs)
+	}
+}
@@ -0,0 +1,83 @@
+package ringio
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/alcortesm/ring"
+)
+
+// the maximum length of a line inserted by a Writer, longer lines are
+// split.
+const maxLine = 64 << 10
+
+// Writer is an io.Writer that splits the written data into lines and
+// inserts each of them in a ring, or any other ring.Buffer, as a string
+// without its trailing newline, so the ring always holds the most
+// recent lines.
+//
+// The last line written is kept by the writer until its newline is
+// written, or until Flush is called.  Lines longer than 64KiB are split,
+// to keep the memory used by the writer bounded.
+type Writer struct {
+	mu      sync.Mutex
+	r       ring.Buffer
+	partial []byte // the last line, until its newline is written
+}
+
+// NewWriter returns a writer inserting the written lines in r.
+func NewWriter(r ring.Buffer) *Writer {
+	return &Writer{r: r}
+}
+
+// Write implements io.Writer.  It always writes all of p.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(p)
+
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.append(p)
+			break
+		}
+
+		w.append(p[:i])
+		w.flush()
+		p = p[i+1:]
+	}
+
+	return n, nil
+}
+
+// appends p to the partial line, inserting it in pieces of maxLine
+// bytes while it is longer than that.
+func (w *Writer) append(p []byte) {
+	for len(w.partial)+len(p) > maxLine {
+		k := maxLine - len(w.partial)
+		w.partial = append(w.partial, p[:k]...)
+		w.flush()
+		p = p[k:]
+	}
+
+	w.partial = append(w.partial, p...)
+}
+
+// Flush inserts the last line written, if its newline was not written
+// yet.
+func (w *Writer) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.partial) > 0 {
+		w.flush()
+	}
+}
+
+// inserts the partial line.
+func (w *Writer) flush() {
+	w.r.Insert(string(w.partial))
+	w.partial = w.partial[:0]
+}
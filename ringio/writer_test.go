@@ -0,0 +1,156 @@
+package ringio_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringio"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"inserts lines":      writerInsertsLines,
+		"keeps last lines":   writerKeepsLastLines,
+		"lines across":       writerLinesAcrossWrites,
+		"flush partial":      writerFlushPartial,
+		"splits long lines":  writerSplitsLongLines,
+		"lines at the limit": writerKeepsLinesAtTheLimit,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a ring with the given capacity or fails the test.
+func newRing(t *testing.T, cap int) *ring.Ring {
+	t.Helper()
+
+	r, err := ring.New(cap)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	return r
+}
+
+// writes each string to w, failing the test on errors.
+func writeLines(t *testing.T, w *ringio.Writer, ss ...string) {
+	t.Helper()
+
+	for _, s := range ss {
+		n, err := w.Write([]byte(s))
+		if err != nil {
+			t.Fatalf("writing %q: %v", s, err)
+		}
+
+		if n != len(s) {
+			t.Fatalf("writing %q: want %d bytes written, got %d", s, len(s), n)
+		}
+	}
+}
+
+// asserts that the ring holds the given lines.
+func assertLines(t *testing.T, r *ring.Ring, want string) {
+	t.Helper()
+
+	if got := fmt.Sprintf("%q", r.ToSlice()); got != want {
+		t.Errorf("want lines %s, got %s", want, got)
+	}
+}
+
+// tests that each line is inserted without its newline.
+func writerInsertsLines(t *testing.T) {
+	r := newRing(t, 5)
+	w := ringio.NewWriter(r)
+
+	writeLines(t, w, "a\nb\n\nc\n")
+
+	assertLines(t, r, `["a" "b" "" "c"]`)
+}
+
+// tests that the ring keeps only the last lines.
+func writerKeepsLastLines(t *testing.T) {
+	r := newRing(t, 2)
+	w := ringio.NewWriter(r)
+
+	writeLines(t, w, "1\n2\n3\n", "4\n")
+
+	assertLines(t, r, `["3" "4"]`)
+}
+
+// tests that lines split across writes are inserted whole.
+func writerLinesAcrossWrites(t *testing.T) {
+	r := newRing(t, 5)
+	w := ringio.NewWriter(r)
+
+	writeLines(t, w, "he", "llo", "\nwor", "ld\n")
+
+	assertLines(t, r, `["hello" "world"]`)
+}
+
+// tests that the last line is only inserted when its newline is
+// written, or on flush.
+func writerFlushPartial(t *testing.T) {
+	r := newRing(t, 5)
+	w := ringio.NewWriter(r)
+
+	writeLines(t, w, "a\nb")
+	assertLines(t, r, `["a"]`)
+
+	w.Flush()
+	assertLines(t, r, `["a" "b"]`)
+
+	w.Flush()
+	assertLines(t, r, `["a" "b"]`)
+}
+
+// tests that lines longer than the limit are split.
+func writerSplitsLongLines(t *testing.T) {
+	r := newRing(t, 5)
+	w := ringio.NewWriter(r)
+
+	const max = 64 << 10
+
+	tests := map[string][]string{
+		"newline in another write":  {strings.Repeat("x", 3*max+10), "\n"},
+		"newline in the same write": {strings.Repeat("x", 3*max+10) + "\n"},
+		"across writes":             {strings.Repeat("x", max-5), strings.Repeat("x", 2*max+15), "\n"},
+	}
+
+	for name, ss := range tests {
+		r.Clear()
+		writeLines(t, w, ss...)
+
+		var lens []int
+		for _, v := range r.ToSlice() {
+			lens = append(lens, len(v.(string)))
+		}
+
+		if got, want := fmt.Sprint(lens), fmt.Sprint([]int{max, max, max, 10}); got != want {
+			t.Errorf("%s: want lines of %s bytes, got %s", name, want, got)
+		}
+	}
+}
+
+// tests that lines as long as the limit are not split.
+func writerKeepsLinesAtTheLimit(t *testing.T) {
+	r := newRing(t, 5)
+	w := ringio.NewWriter(r)
+
+	const max = 64 << 10
+
+	writeLines(t, w, strings.Repeat("x", max)+"\n")
+
+	if got := r.ToSlice(); len(got) != 1 || len(got[0].(string)) != max {
+		t.Errorf("want a line of %d bytes, got %d lines", max, len(got))
+	}
+}
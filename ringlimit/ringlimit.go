@@ -0,0 +1,107 @@
+/*
+Package ringlimit implements a rate limiter using the sliding window log
+algorithm: it remembers when the last allowed events happened, and
+allows a new event only if less than the maximum amount of events
+happened in the last window of time.
+
+Unlike fixed window limiters, it never allows bursts over the limit at
+the edges of the windows, at the cost of memory proportional to the
+maximum amount of events in a window.
+*/
+package ringlimit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+// Limiter allows up to a maximum amount of events per window of time.
+type Limiter struct {
+	mu     sync.Mutex
+	events *ring.Ring // the last allowed events, timestamped
+	window time.Duration
+	now    func() time.Time
+}
+
+// Option configures a limiter on construction.
+type Option func(*config)
+
+// the configuration set by the options.
+type config struct {
+	clock ring.Clock
+}
+
+// WithClock makes the limiter tell the time with c instead of the time
+// package.  It is meant for tests advancing the time deterministically.
+func WithClock(c ring.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+// NewLimiter returns a limiter allowing up to n events per window, with
+// the given options.
+func NewLimiter(n int, window time.Duration, opts ...Option) (*Limiter, error) {
+	if window <= 0 {
+		return nil, fmt.Errorf("window must be > 0, got %v", window)
+	}
+
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	ringOpts := []ring.Option{ring.WithTimestamps()}
+	now := time.Now
+
+	if c.clock != nil {
+		ringOpts = append(ringOpts, ring.WithClock(c.clock))
+		now = c.clock.Now
+	}
+
+	events, err := ring.New(n, ringOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Limiter{
+		events: events,
+		window: window,
+		now:    now,
+	}, nil
+}
+
+// forgets the events out of the window ending now.
+func (l *Limiter) expire() {
+	_ = l.events.ExtractOlderThan(l.now().Add(-l.window))
+}
+
+// Allow reports whether an event may happen now, and if so, records
+// it.
+func (l *Limiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.expire()
+
+	if l.events.Len() == l.events.Cap() {
+		return false
+	}
+
+	l.events.Insert(struct{}{})
+
+	return true
+}
+
+// Remaining returns how many events would be allowed now.
+func (l *Limiter) Remaining() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.expire()
+
+	return l.events.Cap() - l.events.Len()
+}
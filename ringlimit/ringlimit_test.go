@@ -0,0 +1,134 @@
+package ringlimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring/ringlimit"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestLimiter(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid arguments": limiterInvalidArguments,
+		"limits":            limiterLimits,
+		"slides":            limiterSlides,
+		"manual clock":      limiterManualClock,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a limiter or fails the test.
+func newLimiter(t *testing.T, n int, window time.Duration) *ringlimit.Limiter {
+	t.Helper()
+
+	l, err := ringlimit.NewLimiter(n, window)
+	if err != nil {
+		t.Fatalf("creating limiter: %v", err)
+	}
+
+	return l
+}
+
+// tests that the maximum events and the window must be positive.
+func limiterInvalidArguments(t *testing.T) {
+	if _, err := ringlimit.NewLimiter(0, time.Second); err == nil {
+		t.Error("unexpected success with no events")
+	}
+
+	if _, err := ringlimit.NewLimiter(10, 0); err == nil {
+		t.Error("unexpected success with an empty window")
+	}
+}
+
+// tests that no more than the maximum events are allowed in a window.
+func limiterLimits(t *testing.T) {
+	l := newLimiter(t, 3, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		if got := l.Remaining(); got != 3-i {
+			t.Errorf("want %d remaining events, got %d", 3-i, got)
+		}
+
+		if !l.Allow() {
+			t.Fatalf("event %d not allowed", i)
+		}
+	}
+
+	if l.Allow() {
+		t.Error("event over the limit allowed")
+	}
+
+	if got := l.Remaining(); got != 0 {
+		t.Errorf("want no remaining events, got %d", got)
+	}
+}
+
+// tests that events are allowed again as the old ones leave the window.
+func limiterSlides(t *testing.T) {
+	const window = 200 * time.Millisecond
+
+	l := newLimiter(t, 2, window)
+
+	l.Allow()
+	time.Sleep(window / 2)
+	l.Allow()
+
+	if l.Allow() {
+		t.Fatal("event over the limit allowed")
+	}
+
+	// wait for the first event to leave the window, but not the second
+	deadline := time.Now().Add(5 * time.Second)
+
+	for !l.Allow() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for an event to be allowed")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if l.Allow() {
+		t.Error("event over the limit allowed after sliding")
+	}
+}
+
+// tests that the window slides with the clock of the limiter.
+func limiterManualClock(t *testing.T) {
+	clock := ringtest.NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	l, err := ringlimit.NewLimiter(2, time.Minute, ringlimit.WithClock(clock))
+	if err != nil {
+		t.Fatalf("creating limiter: %v", err)
+	}
+
+	l.Allow()
+	clock.Advance(30 * time.Second)
+	l.Allow()
+
+	if l.Allow() {
+		t.Fatal("event over the limit allowed")
+	}
+
+	clock.Advance(31 * time.Second)
+
+	if got := l.Remaining(); got != 1 {
+		t.Errorf("want 1 remaining event, got %d", got)
+	}
+
+	clock.Advance(30 * time.Second)
+
+	if got := l.Remaining(); got != 2 {
+		t.Errorf("want 2 remaining events, got %d", got)
+	}
+}
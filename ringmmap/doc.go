@@ -0,0 +1,14 @@
+/*
+Package ringmmap implements a bounded circular buffer of fixed-size
+records stored in a memory-mapped file.  When at maximum capacity, it
+drops the oldest records to make room for the new ones.
+
+The records live in the page cache instead of the Go heap, so very
+large rings put no pressure on the garbage collector, and their
+contents survive process restarts.  Changes reach stable storage when
+the operating system decides to write the mapped pages back, or when
+calling Sync.
+
+The package is only available on Linux.
+*/
+package ringmmap
@@ -0,0 +1,283 @@
+package ringmmap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+/*
+The file starts with a header followed by the record slots:
+
+	offset  size  field
+	0       8     magic "RINGMMAP"
+	8       8     version
+	16      8     record size
+	24      8     capacity
+	32      8     head
+	40      8     len
+
+All the integers are little endian.
+*/
+
+const (
+	magic      = "RINGMMAP"
+	version    = 1
+	headerSize = 64
+
+	offVersion    = 8
+	offRecordSize = 16
+	offCap        = 24
+	offHead       = 32
+	offLen        = 40
+)
+
+// Ring is a concurrent bounded circular buffer of fixed-size records
+// stored in a memory-mapped file.
+type Ring struct {
+	mu         sync.Mutex
+	file       *os.File
+	data       []byte // the whole mapped file
+	recordSize int
+	cap        int
+}
+
+// Open maps the ring stored in the file at path, creating it if it does
+// not exist.  Existing files must have been created with the same
+// capacity and record size.
+func Open(path string, cap, recordSize int) (*Ring, error) {
+	if cap < 1 {
+		return nil, fmt.Errorf("ring capacity must be > 0, got %d", cap)
+	}
+
+	if recordSize < 1 {
+		return nil, fmt.Errorf("record size must be > 0, got %d", recordSize)
+	}
+
+	size := headerSize + int64(cap)*int64(recordSize)
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := open(file, size, cap, recordSize)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("opening ring file %s: %w", path, err)
+	}
+
+	return r, nil
+}
+
+func open(file *os.File, size int64, cap, recordSize int) (*Ring, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	isNew := info.Size() == 0
+
+	if isNew {
+		if err := file.Truncate(size); err != nil {
+			return nil, err
+		}
+	} else if info.Size() != size {
+		return nil, fmt.Errorf("file size is %d, want %d for the given capacity and record size",
+			info.Size(), size)
+	}
+
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size),
+		syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("mapping file: %w", err)
+	}
+
+	r := &Ring{
+		file:       file,
+		data:       data,
+		recordSize: recordSize,
+		cap:        cap,
+	}
+
+	if isNew {
+		copy(data, magic)
+		r.put(offVersion, version)
+		r.put(offRecordSize, recordSize)
+		r.put(offCap, cap)
+
+		return r, nil
+	}
+
+	if err := r.checkHeader(); err != nil {
+		_ = syscall.Munmap(data)
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// checks that the header matches the ring parameters.
+func (r *Ring) checkHeader() error {
+	if string(r.data[:len(magic)]) != magic {
+		return errors.New("not a ring file")
+	}
+
+	if v := r.get(offVersion); v != version {
+		return fmt.Errorf("unsupported version %d", v)
+	}
+
+	if rs := r.get(offRecordSize); rs != r.recordSize {
+		return fmt.Errorf("record size is %d, want %d", rs, r.recordSize)
+	}
+
+	if c := r.get(offCap); c != r.cap {
+		return fmt.Errorf("capacity is %d, want %d", c, r.cap)
+	}
+
+	if h, l := r.get(offHead), r.get(offLen); h < 0 || h >= r.cap || l < 0 || l > r.cap {
+		return fmt.Errorf("corrupt header: head %d, len %d", h, l)
+	}
+
+	return nil
+}
+
+// reads a header field.
+func (r *Ring) get(off int) int {
+	return int(binary.LittleEndian.Uint64(r.data[off:]))
+}
+
+// writes a header field.
+func (r *Ring) put(off, v int) {
+	binary.LittleEndian.PutUint64(r.data[off:], uint64(v))
+}
+
+// returns the slot with the given index.
+func (r *Ring) slot(i int) []byte {
+	start := headerSize + i*r.recordSize
+	return r.data[start : start+r.recordSize]
+}
+
+// Insert adds a copy of the record rec to the ring.  If the ring is
+// already at maximum capacity, the oldest record is dropped to make
+// room for the new one.  The length of rec must be the record size.
+func (r *Ring) Insert(rec []byte) error {
+	if len(rec) != r.recordSize {
+		return fmt.Errorf("record size must be %d, got %d", r.recordSize, len(rec))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	head, n := r.get(offHead), r.get(offLen)
+
+	copy(r.slot((head+n)%r.cap), rec)
+
+	if n == r.cap {
+		r.put(offHead, (head+1)%r.cap)
+	} else {
+		r.put(offLen, n+1)
+	}
+
+	return nil
+}
+
+// Extract copies the oldest record in the ring into dst and removes it
+// from the ring.  The length of dst must be at least the record size.
+// It returns false if the ring is empty.
+func (r *Ring) Extract(dst []byte) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	head, n := r.get(offHead), r.get(offLen)
+	if n == 0 {
+		return false
+	}
+
+	copy(dst, r.slot(head))
+	r.put(offHead, (head+1)%r.cap)
+	r.put(offLen, n-1)
+
+	return true
+}
+
+// Peek copies the oldest record in the ring into dst.  The length of
+// dst must be at least the record size.  It returns false if the ring
+// is empty.
+func (r *Ring) Peek(dst []byte) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.get(offLen) == 0 {
+		return false
+	}
+
+	copy(dst, r.slot(r.get(offHead)))
+
+	return true
+}
+
+// Len returns the amount of records in the ring.
+func (r *Ring) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.get(offLen)
+}
+
+// Cap returns the capacity of the ring.
+func (r *Ring) Cap() int {
+	return r.cap
+}
+
+// RecordSize returns the size of the records in the ring.
+func (r *Ring) RecordSize() int {
+	return r.recordSize
+}
+
+// Sync writes the mapped pages back to the file and waits for the
+// write to complete.
+func (r *Ring) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return msync(r.data)
+}
+
+// Close syncs and unmaps the ring file.  The ring must not be used
+// afterwards.
+func (r *Ring) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := msync(r.data)
+
+	if uerr := syscall.Munmap(r.data); err == nil {
+		err = uerr
+	}
+
+	r.data = nil
+
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// msync synchronously flushes the mapped memory b to its file.
+func msync(b []byte) error {
+	const msSync = 0x4 // MS_SYNC
+
+	_, _, errno := syscall.Syscall(syscall.SYS_MSYNC,
+		uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)), msSync)
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package ringmmap_test
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alcortesm/ring/ringmmap"
+)
+
+func TestRing(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid parameters":   ringInvalidParameters,
+		"new ring is empty":    ringNewIsEmpty,
+		"forgets oldest":       ringForgetsOldest,
+		"wrong record size":    ringWrongRecordSize,
+		"survives reopening":   ringSurvivesReopening,
+		"rejects other layout": ringRejectsOtherLayout,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+const recordSize = 8
+
+// returns the path to a file in a temporary directory removed at the
+// end of the test.
+func ringPath(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "ringmmap")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %v", err)
+	}
+
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	return filepath.Join(dir, "ring")
+}
+
+// opens a ring of uint64 records or fails the test.
+func open(t *testing.T, path string, cap int) *ringmmap.Ring {
+	t.Helper()
+
+	r, err := ringmmap.Open(path, cap, recordSize)
+	if err != nil {
+		t.Fatalf("opening ring: %v", err)
+	}
+
+	return r
+}
+
+// closes the ring or fails the test.
+func closeRing(t *testing.T, r *ringmmap.Ring) {
+	t.Helper()
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("closing ring: %v", err)
+	}
+}
+
+// inserts the numbers in the ring or fails the test.
+func insert(t *testing.T, r *ringmmap.Ring, ns ...uint64) {
+	t.Helper()
+
+	rec := make([]byte, recordSize)
+
+	for _, n := range ns {
+		binary.LittleEndian.PutUint64(rec, n)
+
+		if err := r.Insert(rec); err != nil {
+			t.Fatalf("inserting %d: %v", n, err)
+		}
+	}
+}
+
+// asserts that extracting from the ring returns the wanted numbers and
+// leaves it empty.
+func assertDrain(t *testing.T, r *ringmmap.Ring, want ...uint64) {
+	t.Helper()
+
+	if got := r.Len(); got != len(want) {
+		t.Fatalf("wrong length, want %d, got %d", len(want), got)
+	}
+
+	rec := make([]byte, recordSize)
+
+	for _, w := range want {
+		if !r.Extract(rec) {
+			t.Fatalf("when extracting %d: unexpected empty ring", w)
+		}
+
+		if got := binary.LittleEndian.Uint64(rec); got != w {
+			t.Errorf("unexpected extracted value, want %d, got %d", w, got)
+		}
+	}
+
+	if r.Extract(rec) {
+		t.Fatal("want empty ring after draining")
+	}
+}
+
+// tests that capacities and record sizes smaller than 1 are invalid.
+func ringInvalidParameters(t *testing.T) {
+	for _, p := range [][2]int{{0, 1}, {-1, 1}, {1, 0}, {1, -1}} {
+		if _, err := ringmmap.Open(ringPath(t), p[0], p[1]); err == nil {
+			t.Errorf("unexpected success with cap %d and record size %d", p[0], p[1])
+		}
+	}
+}
+
+// tests that new rings start empty.
+func ringNewIsEmpty(t *testing.T) {
+	r := open(t, ringPath(t), 4)
+	defer closeRing(t, r)
+
+	if r.Peek(make([]byte, recordSize)) {
+		t.Error("unexpected successful peek")
+	}
+
+	assertDrain(t, r)
+}
+
+// tests that the ring forgets the oldest records when full.
+func ringForgetsOldest(t *testing.T) {
+	r := open(t, ringPath(t), 3)
+	defer closeRing(t, r)
+
+	insert(t, r, 1, 2, 3, 4, 5)
+
+	rec := make([]byte, recordSize)
+	if !r.Peek(rec) || binary.LittleEndian.Uint64(rec) != 3 {
+		t.Fatalf("want to peek 3, got %v", rec)
+	}
+
+	assertDrain(t, r, 3, 4, 5)
+}
+
+// tests that inserting records of the wrong size fails.
+func ringWrongRecordSize(t *testing.T) {
+	r := open(t, ringPath(t), 3)
+	defer closeRing(t, r)
+
+	for _, size := range []int{0, recordSize - 1, recordSize + 1} {
+		if err := r.Insert(make([]byte, size)); err == nil {
+			t.Errorf("unexpected success inserting %d bytes", size)
+		}
+	}
+
+	assertDrain(t, r)
+}
+
+// tests that the contents are recovered when reopening the ring.
+func ringSurvivesReopening(t *testing.T) {
+	path := ringPath(t)
+
+	r := open(t, path, 3)
+	insert(t, r, 1, 2, 3, 4)
+
+	if err := r.Sync(); err != nil {
+		t.Fatalf("syncing: %v", err)
+	}
+
+	closeRing(t, r)
+
+	r = open(t, path, 3)
+	defer closeRing(t, r)
+
+	assertDrain(t, r, 2, 3, 4)
+}
+
+// tests that opening a ring with a different layout fails.
+func ringRejectsOtherLayout(t *testing.T) {
+	path := ringPath(t)
+	closeRing(t, open(t, path, 3))
+
+	if _, err := ringmmap.Open(path, 4, recordSize); err == nil {
+		t.Error("unexpected success with other capacity")
+	}
+
+	if _, err := ringmmap.Open(path, 3, recordSize*2); err == nil {
+		t.Error("unexpected success with other record size")
+	}
+}
@@ -0,0 +1,123 @@
+package ringrepl
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// DialTransport returns a transport shipping entries over the network
+// connections returned by dial, to a replica served by ServeReplica.
+//
+// Entries are encoded with encoding/gob, so the concrete types of the
+// elements must be registered with gob.Register, unless they are basic
+// types.
+func DialTransport(dial func(ctx context.Context) (net.Conn, error)) Transport {
+	return &connTransport{dial: dial}
+}
+
+type connTransport struct {
+	dial func(ctx context.Context) (net.Conn, error)
+
+	mu   sync.Mutex
+	conn net.Conn // nil until connected
+	enc  *gob.Encoder
+}
+
+func (t *connTransport) Connect(ctx context.Context, epoch uint64) (uint64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.conn != nil {
+		_ = t.conn.Close()
+		t.conn, t.enc = nil, nil
+	}
+
+	conn, err := t.dial(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("connecting to replica: %w", err)
+	}
+
+	enc := gob.NewEncoder(conn)
+	if err := enc.Encode(epoch); err != nil {
+		_ = conn.Close()
+		return 0, fmt.Errorf("sending source epoch: %w", err)
+	}
+
+	var next uint64
+	if err := gob.NewDecoder(conn).Decode(&next); err != nil {
+		_ = conn.Close()
+		return 0, fmt.Errorf("reading replica position: %w", err)
+	}
+
+	t.conn = conn
+	t.enc = enc
+
+	return next, nil
+}
+
+func (t *connTransport) Send(ctx context.Context, entries []Entry) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.conn == nil {
+		return errors.New("not connected to replica")
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = t.conn.SetWriteDeadline(deadline)
+	}
+
+	if err := t.enc.Encode(entries); err != nil {
+		return fmt.Errorf("sending entries: %w", err)
+	}
+
+	return nil
+}
+
+// ServeReplica accepts connections from sources using DialTransport on
+// ln, and applies the entries they send to the replica, serving each
+// connection from its own goroutine.  It blocks until accepting fails,
+// for example because ln is closed, and returns that error.
+func ServeReplica(ln net.Listener, r *Replica) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			defer conn.Close()
+
+			_ = serveSource(conn, r)
+		}()
+	}
+}
+
+// reads the epoch of the source, tells it the position of the replica
+// in that epoch, and applies the entries it sends until the connection
+// fails.
+func serveSource(conn net.Conn, r *Replica) error {
+	dec := gob.NewDecoder(conn)
+
+	var epoch uint64
+	if err := dec.Decode(&epoch); err != nil {
+		return err
+	}
+
+	if err := gob.NewEncoder(conn).Encode(r.join(epoch)); err != nil {
+		return err
+	}
+
+	for {
+		var entries []Entry
+		if err := dec.Decode(&entries); err != nil {
+			return err
+		}
+
+		r.Apply(entries)
+	}
+}
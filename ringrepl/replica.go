@@ -0,0 +1,109 @@
+package ringrepl
+
+import (
+	"context"
+	"sync"
+
+	"github.com/alcortesm/ring"
+)
+
+// Replica applies the entries shipped by a source to a ring.
+type Replica struct {
+	mu     sync.Mutex
+	dst    *ring.Ring
+	epoch  uint64 // epoch of the source of the entries
+	next   uint64 // sequence number of the next expected entry
+	lost   uint64 // entries never received
+	onLoss func(from, to uint64)
+}
+
+// NewReplica returns a new replica inserting the entries it receives
+// in r.  If onLoss is not nil, it is called with the sequence numbers
+// of the entries lost, from included to excluded, each time some are
+// detected.
+func NewReplica(r *ring.Ring, onLoss func(from, to uint64)) *Replica {
+	return &Replica{
+		dst:    r,
+		onLoss: onLoss,
+	}
+}
+
+// Apply inserts the entries in the ring of the replica.  Entries
+// already received are ignored, and gaps in the sequence numbers are
+// counted as lost.  An entry from a new epoch, see Source, starts the
+// sequence numbers over.
+func (r *Replica) Apply(entries []Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range entries {
+		if e.Epoch != r.epoch {
+			r.epoch, r.next = e.Epoch, 0
+		}
+
+		if e.Seq < r.next {
+			continue
+		}
+
+		if e.Seq > r.next {
+			r.lost += e.Seq - r.next
+
+			if r.onLoss != nil {
+				r.onLoss(r.next, e.Seq)
+			}
+		}
+
+		r.dst.Insert(e.Value)
+		r.next = e.Seq + 1
+	}
+}
+
+// Next returns the sequence number of the next entry the replica
+// expects.
+func (r *Replica) Next() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.next
+}
+
+// returns the sequence number of the next entry the replica expects
+// from a source of the given epoch, starting the sequence numbers over
+// if it is a new one.
+func (r *Replica) join(epoch uint64) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if epoch != r.epoch {
+		r.epoch, r.next = epoch, 0
+	}
+
+	return r.next
+}
+
+// Lost returns how many entries were never received.
+func (r *Replica) Lost() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.lost
+}
+
+// LocalTransport returns a transport applying the entries directly to
+// a replica in the same process.
+func LocalTransport(r *Replica) Transport {
+	return localTransport{r: r}
+}
+
+type localTransport struct {
+	r *Replica
+}
+
+func (t localTransport) Connect(_ context.Context, epoch uint64) (uint64, error) {
+	return t.r.join(epoch), nil
+}
+
+func (t localTransport) Send(_ context.Context, entries []Entry) error {
+	t.r.Apply(entries)
+	return nil
+}
@@ -0,0 +1,143 @@
+/*
+Package ringrepl replicates the elements inserted in a ring to replica
+rings, for example to keep warm standbys of a buffer of recent events in
+other processes.
+
+A Source follows its ring and ships each inserted element, along with
+its sequence number, to its replicas through a Transport.  Replication
+is asynchronous: the source ring never waits for its replicas.
+
+When a transport reconnects, its replica tells the source the sequence
+number of the next element it expects, and the source catches it up
+from there.  Elements that left the source ring before being shipped
+cannot be replicated, they are reported as lost by the replica.
+
+Each source has a random epoch, told to the replicas when connecting
+and shipped with every element, as the sequence numbers of a restarted
+source start over.  Replicas expect the first element of the ring of a
+new epoch, instead of the next one of the previous epoch.
+*/
+package ringrepl
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+// the backoff between reconnections to a replica.
+const (
+	minBackoff = 100 * time.Millisecond
+	maxBackoff = 5 * time.Second
+)
+
+// Entry is an element shipped to a replica.
+type Entry struct {
+	Epoch uint64 // the epoch of the source, see Source
+	Seq   uint64 // the sequence number of the element in the source ring
+	Value interface{}
+}
+
+// Transport carries entries from a source to a replica.
+type Transport interface {
+	// Connect establishes the connection to the replica, closing the
+	// previous one, if any, tells it the epoch of the source, and
+	// returns the sequence number of the next entry the replica expects
+	// from that epoch.
+	Connect(ctx context.Context, epoch uint64) (uint64, error)
+	// Send ships the entries to the replica, in order.
+	Send(ctx context.Context, entries []Entry) error
+}
+
+// Source replicates the elements inserted in a ring.
+type Source struct {
+	r          *ring.Ring
+	transports []Transport
+	clock      ring.Clock
+	epoch      uint64
+}
+
+// NewSource returns a new source replicating the ring r through the
+// given transports, one per replica, with a new random epoch.
+func NewSource(r *ring.Ring, transports ...Transport) (*Source, error) {
+	if len(transports) == 0 {
+		return nil, errors.New("source needs at least one transport")
+	}
+
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, fmt.Errorf("generating source epoch: %w", err)
+	}
+
+	return &Source{
+		r:          r,
+		transports: append([]Transport(nil), transports...),
+		clock:      ring.SystemClock{},
+		epoch:      binary.BigEndian.Uint64(b[:]),
+	}, nil
+}
+
+// SetClock makes the source tell the time with c instead of the time
+// package, for the backoff between reconnections.  It is meant for
+// tests advancing the time deterministically, and it must be called
+// before Run.
+func (s *Source) SetClock(c ring.Clock) {
+	s.clock = c
+}
+
+// Run replicates the ring until ctx is done, and then returns the
+// context error.  Failed transports are reconnected with exponential
+// backoff.
+func (s *Source) Run(ctx context.Context) error {
+	done := make(chan struct{})
+
+	for _, t := range s.transports {
+		go func(t Transport) {
+			s.replicate(ctx, t)
+			done <- struct{}{}
+		}(t)
+	}
+
+	for range s.transports {
+		<-done
+	}
+
+	return ctx.Err()
+}
+
+// ships the elements of the ring through t, reconnecting on failures,
+// until ctx is done.
+func (s *Source) replicate(ctx context.Context, t Transport) {
+	backoff := minBackoff
+
+	for {
+		next, err := t.Connect(ctx, s.epoch)
+		if err == nil {
+			backoff = minBackoff
+
+			_ = s.r.Follow(ctx, next, func(seq uint64, v interface{}) error {
+				return t.Send(ctx, []Entry{{Epoch: s.epoch, Seq: seq, Value: v}})
+			})
+		}
+
+		// the first tick of a ticker, as clocks have no timers
+		ticker := s.clock.NewTicker(backoff)
+
+		select {
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		case <-ticker.C():
+			ticker.Stop()
+		}
+
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
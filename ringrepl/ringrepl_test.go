@@ -0,0 +1,327 @@
+package ringrepl_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringrepl"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestReplication(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"no transports":      replicationNoTransports,
+		"local":              replicationLocal,
+		"loss":               replicationLoss,
+		"catch up":           replicationCatchUp,
+		"network":            replicationNetwork,
+		"duplicates ignored": replicationDuplicatesIgnored,
+		"backoff clock":      replicationBackoffClock,
+		"source restart":     replicationSourceRestart,
+		"network restart":    replicationNetworkRestart,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a ring with the given capacity and elements or fails the
+// test.
+func newRing(t *testing.T, cap int, vs ...interface{}) *ring.Ring {
+	t.Helper()
+
+	r, err := ring.New(cap)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	for _, v := range vs {
+		r.Insert(v)
+	}
+
+	return r
+}
+
+// runs a source replicating r through the transports until the end of
+// the test, or fails the test.
+func run(t *testing.T, r *ring.Ring, transports ...ringrepl.Transport) {
+	t.Helper()
+	t.Cleanup(start(t, r, transports...))
+}
+
+// starts a source replicating r through the transports, and returns a
+// function stopping it, or fails the test.
+func start(t *testing.T, r *ring.Ring, transports ...ringrepl.Transport) func() {
+	t.Helper()
+
+	s, err := ringrepl.NewSource(r, transports...)
+	if err != nil {
+		t.Fatalf("creating source: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() { done <- s.Run(ctx) }()
+
+	return func() {
+		cancel()
+
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Errorf("want canceled error, got %v", err)
+		}
+	}
+}
+
+// waits for the ring to hold the wanted elements or fails the test.
+func waitFor(t *testing.T, r *ring.Ring, want string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for fmt.Sprint(r.ToSlice()) != want && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if got := fmt.Sprint(r.ToSlice()); got != want {
+		t.Fatalf("want replica %s, got %s", want, got)
+	}
+}
+
+// tests that sources need transports.
+func replicationNoTransports(t *testing.T) {
+	if _, err := ringrepl.NewSource(newRing(t, 1)); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that the current and new elements are replicated to all the
+// replicas.
+func replicationLocal(t *testing.T) {
+	src := newRing(t, 5, "a", "b")
+	dst1, dst2 := newRing(t, 5), newRing(t, 2)
+
+	run(t, src,
+		ringrepl.LocalTransport(ringrepl.NewReplica(dst1, nil)),
+		ringrepl.LocalTransport(ringrepl.NewReplica(dst2, nil)))
+
+	waitFor(t, dst1, "[a b]")
+
+	src.Insert("c")
+	waitFor(t, dst1, "[a b c]")
+	waitFor(t, dst2, "[b c]")
+}
+
+// tests that elements that left the source before being replicated are
+// reported as lost.
+func replicationLoss(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		losses []string
+	)
+
+	src := newRing(t, 2, "a", "b", "c")
+	dst := newRing(t, 5)
+	replica := ringrepl.NewReplica(dst, func(from, to uint64) {
+		mu.Lock()
+		losses = append(losses, fmt.Sprintf("%d-%d", from, to))
+		mu.Unlock()
+	})
+
+	run(t, src, ringrepl.LocalTransport(replica))
+	waitFor(t, dst, "[b c]")
+
+	if got := replica.Lost(); got != 1 {
+		t.Errorf("want 1 lost entry, got %d", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if got := fmt.Sprint(losses); got != "[0-1]" {
+		t.Errorf("want losses [0-1], got %s", got)
+	}
+}
+
+// a transport failing the first time it sends an entry with the given
+// sequence number.
+type flaky struct {
+	ringrepl.Transport
+	failAt uint64
+
+	mu       sync.Mutex
+	failed   bool
+	connects int
+}
+
+func (f *flaky) Connect(ctx context.Context, epoch uint64) (uint64, error) {
+	f.mu.Lock()
+	f.connects++
+	f.mu.Unlock()
+
+	return f.Transport.Connect(ctx, epoch)
+}
+
+func (f *flaky) Send(ctx context.Context, entries []ringrepl.Entry) error {
+	f.mu.Lock()
+
+	if !f.failed && entries[0].Seq == f.failAt {
+		f.failed = true
+		f.mu.Unlock()
+
+		return errors.New("connection lost")
+	}
+
+	f.mu.Unlock()
+
+	return f.Transport.Send(ctx, entries)
+}
+
+// tests that replicas are caught up after reconnecting.
+func replicationCatchUp(t *testing.T) {
+	src := newRing(t, 5, "a", "b", "c")
+	dst := newRing(t, 5)
+	replica := ringrepl.NewReplica(dst, nil)
+	f := &flaky{Transport: ringrepl.LocalTransport(replica), failAt: 1}
+
+	run(t, src, f)
+	waitFor(t, dst, "[a b c]")
+
+	if got := replica.Lost(); got != 0 {
+		t.Errorf("want no lost entries, got %d", got)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.connects != 2 {
+		t.Errorf("want 2 connections, got %d", f.connects)
+	}
+}
+
+// tests replicating over the network.
+func replicationNetwork(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+
+	defer ln.Close()
+
+	dst := newRing(t, 5)
+	go func() { _ = ringrepl.ServeReplica(ln, ringrepl.NewReplica(dst, nil)) }()
+
+	src := newRing(t, 5, "a", 1)
+	run(t, src, dialTransport(ln))
+
+	waitFor(t, dst, "[a 1]")
+
+	src.Insert(2.5)
+	waitFor(t, dst, "[a 1 2.5]")
+}
+
+// returns a transport dialing the listener.
+func dialTransport(ln net.Listener) ringrepl.Transport {
+	return ringrepl.DialTransport(func(ctx context.Context) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", ln.Addr().String())
+	})
+}
+
+// tests that replicas ignore entries they already received.
+func replicationDuplicatesIgnored(t *testing.T) {
+	dst := newRing(t, 5)
+	replica := ringrepl.NewReplica(dst, nil)
+
+	replica.Apply([]ringrepl.Entry{{Seq: 0, Value: "a"}, {Seq: 1, Value: "b"}})
+	replica.Apply([]ringrepl.Entry{{Seq: 1, Value: "b"}, {Seq: 2, Value: "c"}})
+
+	if got := fmt.Sprint(dst.ToSlice()); got != "[a b c]" {
+		t.Errorf("want [a b c], got %s", got)
+	}
+
+	if got := replica.Next(); got != 3 {
+		t.Errorf("want next 3, got %d", got)
+	}
+}
+
+// tests that the backoff between reconnections is timed by the clock.
+func replicationBackoffClock(t *testing.T) {
+	src := newRing(t, 5, "a", "b", "c")
+	dst := newRing(t, 5)
+	f := &flaky{Transport: ringrepl.LocalTransport(ringrepl.NewReplica(dst, nil)), failAt: 1}
+
+	s, err := ringrepl.NewSource(src, f)
+	if err != nil {
+		t.Fatalf("creating source: %v", err)
+	}
+
+	clock := ringtest.NewClock(time.Unix(0, 0))
+	s.SetClock(clock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() { _ = s.Run(ctx) }()
+
+	clock.WaitTickers(1)
+	waitFor(t, dst, "[a]")
+
+	clock.Advance(time.Hour)
+	waitFor(t, dst, "[a b c]")
+}
+
+// tests that replicas start over when their source restarts, with its
+// sequence numbers.
+func replicationSourceRestart(t *testing.T) {
+	dst := newRing(t, 10)
+	replica := ringrepl.NewReplica(dst, nil)
+
+	stop := start(t, newRing(t, 5, "a", "b", "c"), ringrepl.LocalTransport(replica))
+	waitFor(t, dst, "[a b c]")
+	stop()
+
+	restarted := newRing(t, 5, "x", "y")
+	run(t, restarted, ringrepl.LocalTransport(replica))
+	waitFor(t, dst, "[a b c x y]")
+
+	restarted.Insert("z")
+	waitFor(t, dst, "[a b c x y z]")
+
+	if got := replica.Lost(); got != 0 {
+		t.Errorf("want no lost entries, got %d", got)
+	}
+}
+
+// tests that replicas served over the network start over when their
+// source restarts.
+func replicationNetworkRestart(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+
+	defer ln.Close()
+
+	dst := newRing(t, 10)
+	go func() { _ = ringrepl.ServeReplica(ln, ringrepl.NewReplica(dst, nil)) }()
+
+	stop := start(t, newRing(t, 5, "a", "b", "c"), dialTransport(ln))
+	waitFor(t, dst, "[a b c]")
+	stop()
+
+	run(t, newRing(t, 5, "x", "y"), dialTransport(ln))
+	waitFor(t, dst, "[a b c x y]")
+}
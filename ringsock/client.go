@@ -0,0 +1,142 @@
+package ringsock
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// Record is an element received from a server.
+type Record struct {
+	Seq  uint64 // the sequence number of the element in the ring
+	Data []byte // the encoded element
+}
+
+// Dump returns the current contents of the ring served on the unix
+// domain socket at path.
+func Dump(ctx context.Context, path string) ([]Record, error) {
+	var result []Record
+
+	err := request(ctx, path, "DUMP", func(rec Record) error {
+		result = append(result, rec)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Tail calls fn with the elements of the ring served on the unix domain
+// socket at path, from the sequence number seq onwards, and then with
+// the new elements as they are inserted.  It blocks until ctx is done,
+// fn returns an error, or the server closes the connection, and returns
+// the corresponding error, or nil in the last case.
+func Tail(ctx context.Context, path string, seq uint64, fn func(Record) error) error {
+	return request(ctx, path, fmt.Sprintf("TAIL %d", seq), fn)
+}
+
+// sends the request to the server at path and calls fn with each
+// record in the response, until the server closes the connection.
+func request(ctx context.Context, path, req string, fn func(Record) error) (err error) {
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return err
+	}
+
+	// close the connection when ctx is done, to stop reading
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-stop:
+		}
+	}()
+
+	defer func() {
+		_ = conn.Close()
+
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+	}()
+
+	if _, err := io.WriteString(conn, req+"\n"); err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+
+	if err := readStatus(br); err != nil {
+		return err
+	}
+
+	for {
+		rec, err := readRecord(br)
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// reads the status line of a response and returns the error it holds.
+func readStatus(br *bufio.Reader) error {
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("reading status: %w", err)
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+
+	switch {
+	case line == "OK":
+		return nil
+	case strings.HasPrefix(line, "ERR "):
+		return fmt.Errorf("server error: %s", strings.TrimPrefix(line, "ERR "))
+	default:
+		return fmt.Errorf("malformed status %q", line)
+	}
+}
+
+// reads a record, returning io.EOF if there are no more.
+func readRecord(br *bufio.Reader) (Record, error) {
+	var header [12]byte
+
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return Record{}, errors.New("truncated record header")
+		}
+
+		return Record{}, err
+	}
+
+	rec := Record{
+		Seq:  binary.BigEndian.Uint64(header[:8]),
+		Data: make([]byte, binary.BigEndian.Uint32(header[8:])),
+	}
+
+	if _, err := io.ReadFull(br, rec.Data); err != nil {
+		return Record{}, fmt.Errorf("reading record %d: %w", rec.Seq, err)
+	}
+
+	return rec, nil
+}
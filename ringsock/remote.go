@@ -0,0 +1,273 @@
+package ringsock
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+// Client is a ring served by a Server in another process, accessed
+// through a unix domain socket.  It implements ring.BlockingBuffer, so
+// code written against that interface can use remote rings
+// transparently.
+//
+// As the methods of ring.Buffer cannot return errors, a failed request
+// makes the method return as if the ring were empty, and its error is
+// kept until the next request, see Err.  The connection is
+// reestablished on the next request after a failure.
+type Client struct {
+	path   string
+	encode func(v interface{}) ([]byte, error)
+	decode func(p []byte) (interface{}, error)
+
+	mu   sync.Mutex // serializes the requests
+	conn net.Conn   // nil if not connected
+	br   *bufio.Reader
+	err  error // the error of the last request
+}
+
+var _ ring.BlockingBuffer = (*Client)(nil)
+
+// the bounds of the interval between the requests of ExtractContext
+// while the remote ring is empty.
+const (
+	minPoll = 10 * time.Millisecond
+	maxPoll = 500 * time.Millisecond
+)
+
+// Dial returns a client of the ring served on the unix domain socket at
+// path, encoding the inserted elements with encode and decoding the
+// received ones with decode, which must match the decoder and encoder
+// of the server.
+func Dial(ctx context.Context, path string, encode func(v interface{}) ([]byte, error),
+	decode func(p []byte) (interface{}, error)) (*Client, error) {
+	c := &Client{
+		path:   path,
+		encode: encode,
+		decode: decode,
+	}
+
+	if err := c.connect(ctx); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// connects to the server, if not already connected.
+func (c *Client) connect(ctx context.Context) error {
+	if c.conn != nil {
+		return nil
+	}
+
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "unix", c.path)
+	if err != nil {
+		return err
+	}
+
+	c.conn = conn
+	c.br = bufio.NewReader(conn)
+
+	return nil
+}
+
+// sends a request with the given command, argument and payload, and
+// returns the value in the status line of the response, and the element
+// following it, if any.  It returns false if the response is EMPTY.
+// The error of the request is kept in c.err.
+func (c *Client) request(cmd, arg string, payload []byte) (value string, element []byte, ok bool) {
+	value, element, ok, c.err = c.roundTrip(cmd, arg, payload)
+	if c.err != nil && c.conn != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+
+	return value, element, ok
+}
+
+func (c *Client) roundTrip(cmd, arg string, payload []byte) (string, []byte, bool, error) {
+	if err := c.connect(context.Background()); err != nil {
+		return "", nil, false, err
+	}
+
+	req := cmd
+	if arg != "" {
+		req += " " + arg
+	}
+
+	if _, err := c.conn.Write(append([]byte(req+"\n"), payload...)); err != nil {
+		return "", nil, false, fmt.Errorf("sending request: %w", err)
+	}
+
+	line, err := c.br.ReadString('\n')
+	if err != nil {
+		return "", nil, false, fmt.Errorf("reading status: %w", err)
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+
+	switch {
+	case line == "EMPTY":
+		return "", nil, false, nil
+	case strings.HasPrefix(line, "ERR "):
+		return "", nil, false, fmt.Errorf("server error: %s", strings.TrimPrefix(line, "ERR "))
+	case line != "OK" && !strings.HasPrefix(line, "OK "):
+		return "", nil, false, fmt.Errorf("malformed status %q", line)
+	}
+
+	value := strings.TrimPrefix(strings.TrimPrefix(line, "OK"), " ")
+
+	if cmd != "EXTRACT" && cmd != "PEEK" {
+		return value, nil, true, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || n > maxElement {
+		return "", nil, false, fmt.Errorf("invalid element length %q", value)
+	}
+
+	element := make([]byte, n)
+	if _, err := io.ReadFull(c.br, element); err != nil {
+		return "", nil, false, fmt.Errorf("reading element: %w", err)
+	}
+
+	return value, element, true, nil
+}
+
+// Err returns the error of the last request, nil if it succeeded.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+// Insert adds a new element to the remote ring.  See Err.
+func (c *Client) Insert(v interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, err := c.encode(v)
+	if err != nil {
+		c.err = fmt.Errorf("encoding element: %w", err)
+		return
+	}
+
+	c.request("INSERT", strconv.Itoa(len(p)), p)
+}
+
+// Extract extracts and returns the oldest element in the remote ring.
+// See Err.
+func (c *Client) Extract() (interface{}, bool) {
+	return c.get("EXTRACT")
+}
+
+// ExtractContext extracts and returns the oldest element in the remote
+// ring, polling it while it is empty, with increasing intervals.  It
+// returns the context error if ctx is done before that, and the error
+// of the request if one fails.
+func (c *Client) ExtractContext(ctx context.Context) (interface{}, error) {
+	wait := minPoll
+
+	for {
+		v, ok := c.Extract()
+		if ok {
+			return v, nil
+		}
+
+		if err := c.Err(); err != nil {
+			return nil, err
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if wait *= 2; wait > maxPoll {
+			wait = maxPoll
+		}
+	}
+}
+
+// Peek returns the oldest element in the remote ring.  See Err.
+func (c *Client) Peek() (interface{}, bool) {
+	return c.get("PEEK")
+}
+
+// sends an EXTRACT or PEEK request and returns the decoded element.
+func (c *Client) get(cmd string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, p, ok := c.request(cmd, "", nil)
+	if !ok {
+		return nil, false
+	}
+
+	v, err := c.decode(p)
+	if err != nil {
+		c.err = fmt.Errorf("decoding element: %w", err)
+		return nil, false
+	}
+
+	return v, true
+}
+
+// Len returns the amount of elements in the remote ring.  See Err.
+func (c *Client) Len() int {
+	return c.number("LEN")
+}
+
+// Cap returns the capacity of the remote ring.  See Err.
+func (c *Client) Cap() int {
+	return c.number("CAP")
+}
+
+// sends a LEN or CAP request and returns its value.
+func (c *Client) number(cmd string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	value, _, ok := c.request(cmd, "", nil)
+	if !ok {
+		return 0
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		c.err = fmt.Errorf("invalid %s %q", strings.ToLower(cmd), value)
+		return 0
+	}
+
+	return n
+}
+
+// Close closes the connection to the server.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+
+	err := c.conn.Close()
+	c.conn = nil
+
+	return err
+}
@@ -0,0 +1,275 @@
+package ringsock_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringsock"
+)
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"round trip":          clientRoundTrip,
+		"read-only server":    clientReadOnlyServer,
+		"reconnects":          clientReconnects,
+		"dial missing sock":   clientDialMissingSocket,
+		"used as a buffer":    clientUsedAsBuffer,
+		"extract waits":       clientExtractWaits,
+		"extract canceled":    clientExtractCanceled,
+		"server closed":       clientServerClosed,
+		"extract unencodable": clientExtractUnencodable,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// encodes string elements as their bytes.
+func encodeString(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, errors.New("not a string")
+	}
+
+	return []byte(s), nil
+}
+
+// decodes elements as strings.
+func decodeString(p []byte) (interface{}, error) {
+	return string(p), nil
+}
+
+// serves r read-write and returns a client of it, or fails the test.
+// The client is closed at the end of the test.
+func dial(t *testing.T, r *ring.Ring) *ringsock.Client {
+	t.Helper()
+
+	path := listen(t, ringsock.NewServer(r, encodeString, decodeString))
+
+	c, err := ringsock.Dial(context.Background(), path, encodeString, decodeString)
+	if err != nil {
+		t.Fatalf("dialing: %v", err)
+	}
+
+	t.Cleanup(func() { _ = c.Close() })
+
+	return c
+}
+
+// tests that the client inserts, peeks and extracts elements from the
+// remote ring, and reports its length and capacity.
+func clientRoundTrip(t *testing.T) {
+	r := newRing(t, 3, "a")
+	c := dial(t, r)
+
+	c.Insert("b")
+	c.Insert("multi\nline")
+
+	if got := c.Len(); got != 3 {
+		t.Errorf("want length 3, got %d", got)
+	}
+
+	if got := c.Cap(); got != 3 {
+		t.Errorf("want capacity 3, got %d", got)
+	}
+
+	if v, ok := c.Peek(); !ok || v != "a" {
+		t.Errorf("want to peek a, got %v, %t", v, ok)
+	}
+
+	var got []string
+
+	for {
+		v, ok := c.Extract()
+		if !ok {
+			break
+		}
+
+		got = append(got, v.(string))
+	}
+
+	if err := c.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if s := strings.Join(got, ","); s != "a,b,multi\nline" {
+		t.Errorf("want a,b,multi\\nline, got %q", s)
+	}
+
+	if _, ok := c.Peek(); ok {
+		t.Error("unexpected successful peek on an empty ring")
+	}
+
+	if r.Len() != 0 {
+		t.Errorf("want empty local ring, got length %d", r.Len())
+	}
+}
+
+// tests that a read-only server rejects insertions and extractions,
+// while still serving the rest of the requests.
+func clientReadOnlyServer(t *testing.T) {
+	r := newRing(t, 2, "a")
+	_, path := serve(t, r)
+
+	c, err := ringsock.Dial(context.Background(), path, encodeString, decodeString)
+	if err != nil {
+		t.Fatalf("dialing: %v", err)
+	}
+
+	defer c.Close()
+
+	c.Insert("b")
+
+	if err := c.Err(); err == nil || !strings.Contains(err.Error(), ringsock.ErrReadOnly.Error()) {
+		t.Errorf("want read-only error, got %v", err)
+	}
+
+	if _, ok := c.Extract(); ok {
+		t.Error("unexpected successful extraction")
+	}
+
+	if v, ok := c.Peek(); !ok || v != "a" {
+		t.Errorf("want to peek a, got %v, %t (%v)", v, ok, c.Err())
+	}
+
+	if r.Len() != 1 {
+		t.Errorf("want local length 1, got %d", r.Len())
+	}
+}
+
+// tests that the client reconnects after its connection is closed.
+func clientReconnects(t *testing.T) {
+	c := dial(t, newRing(t, 2))
+
+	c.Insert("a")
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("closing: %v", err)
+	}
+
+	if v, ok := c.Extract(); !ok || v != "a" {
+		t.Errorf("want to extract a, got %v, %t (%v)", v, ok, c.Err())
+	}
+}
+
+// tests that dialing a missing socket fails.
+func clientDialMissingSocket(t *testing.T) {
+	_, err := ringsock.Dial(context.Background(), "/nonexistent/ring.sock", encodeString, decodeString)
+	if err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that code written against ring.Buffer works with a client.
+func clientUsedAsBuffer(t *testing.T) {
+	fill := func(b ring.Buffer) {
+		for b.Len() < b.Cap() {
+			b.Insert("x")
+		}
+	}
+
+	r := newRing(t, 4)
+	fill(dial(t, r))
+
+	if r.Len() != 4 {
+		t.Errorf("want local length 4, got %d", r.Len())
+	}
+}
+
+// tests that ExtractContext waits for an element to be inserted in the
+// remote ring.
+func clientExtractWaits(t *testing.T) {
+	r := newRing(t, 2)
+	c := dial(t, r)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		r.Insert("late")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	v, err := c.ExtractContext(ctx)
+	if err != nil {
+		t.Fatalf("extracting: %v", err)
+	}
+
+	if v != "late" {
+		t.Errorf("want late, got %v", v)
+	}
+}
+
+// tests that ExtractContext returns the context error if it is done
+// while waiting.
+func clientExtractCanceled(t *testing.T) {
+	c := dial(t, newRing(t, 2))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.ExtractContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want deadline exceeded, got %v", err)
+	}
+}
+
+// tests that closing the server does not wait for its idle clients to
+// disconnect.
+func clientServerClosed(t *testing.T) {
+	s := ringsock.NewServer(newRing(t, 2), encodeString, decodeString)
+	path := listen(t, s)
+
+	c, err := ringsock.Dial(context.Background(), path, encodeString, decodeString)
+	if err != nil {
+		t.Fatalf("dialing: %v", err)
+	}
+
+	defer c.Close()
+
+	c.Insert("a")
+
+	if got := c.Len(); got != 1 {
+		t.Fatalf("want length 1, got %d", got)
+	}
+
+	closed := make(chan error, 1)
+	go func() { closed <- s.Close() }()
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Errorf("closing server: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("closing the server waits for its idle client")
+	}
+}
+
+// tests that elements that cannot be encoded are not extracted.
+func clientExtractUnencodable(t *testing.T) {
+	r := newRing(t, 2, 42)
+	c := dial(t, r)
+
+	if _, ok := c.Extract(); ok {
+		t.Error("unexpected successful extraction")
+	}
+
+	if err := c.Err(); err == nil || !strings.Contains(err.Error(), "not a string") {
+		t.Errorf("want encoding error, got %v", err)
+	}
+
+	if r.Len() != 1 {
+		t.Errorf("want local length 1, got %d", r.Len())
+	}
+}
@@ -0,0 +1,447 @@
+/*
+Package ringsock serves a ring over a stream socket, usually a unix
+domain socket, so debugging tools running on the same host can inspect
+and tail the ring of a running process, and other processes can use it
+remotely, see Client.
+
+The protocol is line and length-prefixed based.  Clients send requests,
+lines with a command, and the server answers each of them with a
+status line, "OK", optionally followed by a value, or "ERR <message>",
+after which it closes the connection.  These are the commands:
+
+	DUMP              the current contents of the ring
+	TAIL <seq>        the elements from the sequence number seq onwards,
+	                  followed by the new ones as they are inserted
+	INSERT <length>   inserts the element encoded in the length bytes
+	                  following the request line
+	EXTRACT           extracts the oldest element, once encoded
+	PEEK              returns the oldest element
+	LEN               returns the length of the ring, as "OK <len>"
+	CAP               returns the capacity of the ring, as "OK <cap>"
+
+A successful DUMP or TAIL is followed by records, one per element, from
+oldest to newest.  Each record is made of:
+
+	seq      8 bytes, big endian, the sequence number of the element
+	length   4 bytes, big endian
+	payload  length bytes, the encoded element
+
+After a DUMP, the server closes the connection.  A TAIL goes on until
+the client closes the connection or the server is closed.  Gaps in the
+sequence numbers are elements that were no longer in the ring when the
+server got to them, see ring.Ring.Follow.
+
+A successful EXTRACT or PEEK answers "OK <length>" followed by length
+bytes, the encoded element, or "EMPTY" if the ring is empty.  An
+EXTRACT fails if the oldest element is reserved by someone else, see
+ring.Ring.Reserve.  The connection can be used for more requests after
+them, and after INSERT, LEN and CAP.
+*/
+package ringsock
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alcortesm/ring"
+)
+
+const (
+	maxLine    = 1024     // maximum length of a request line
+	maxElement = 16 << 20 // maximum length of an encoded element
+)
+
+// ErrReadOnly is returned by servers with no decoder when asked to
+// modify their ring.
+var ErrReadOnly = errors.New("read-only ring")
+
+// Server serves a ring over stream sockets.
+type Server struct {
+	r      *ring.Ring
+	encode func(v interface{}) ([]byte, error)
+	decode func(p []byte) (interface{}, error)
+
+	ctx    context.Context // canceled when closing the server
+	cancel context.CancelFunc
+
+	// serializes the extractions, as there is at most one reservation
+	// at a time
+	extracting sync.Mutex
+
+	mu        sync.Mutex
+	listeners []net.Listener
+	conns     map[net.Conn]struct{} // accepted and not closed yet
+	handlers  sync.WaitGroup
+}
+
+// NewServer returns a new server of the ring r, encoding its elements
+// with encode and decoding the inserted ones with decode.  If encode is
+// nil, elements are formatted with the %v verb.  If decode is nil, the
+// server is read-only: it rejects INSERT and EXTRACT requests with
+// ErrReadOnly.
+func NewServer(r *ring.Ring, encode func(v interface{}) ([]byte, error),
+	decode func(p []byte) (interface{}, error)) *Server {
+	if encode == nil {
+		encode = func(v interface{}) ([]byte, error) {
+			return []byte(fmt.Sprint(v)), nil
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Server{
+		r:      r,
+		encode: encode,
+		decode: decode,
+		ctx:    ctx,
+		cancel: cancel,
+		conns:  make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe listens on the unix domain socket at path and serves
+// the connections to it, see Serve.
+func (s *Server) ListenAndServe(path string) error {
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(ln)
+}
+
+// Serve accepts connections on ln and serves each of them from its own
+// goroutine.  It blocks until the server is closed, and then returns
+// nil, or until accepting fails, and then returns that error.  It takes
+// ownership of ln.
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+
+	if s.ctx.Err() != nil {
+		s.mu.Unlock()
+		_ = ln.Close()
+
+		return nil
+	}
+
+	s.listeners = append(s.listeners, ln)
+	s.mu.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		if !s.track(conn) {
+			_ = conn.Close()
+			return nil
+		}
+
+		s.handlers.Add(1)
+
+		go func() {
+			defer s.handlers.Done()
+			defer s.untrack(conn)
+
+			_ = s.handle(conn)
+		}()
+	}
+}
+
+// adds conn to the connections closed by Close, and reports if it did,
+// which it does not if the server is already closed.
+func (s *Server) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx.Err() != nil {
+		return false
+	}
+
+	s.conns[conn] = struct{}{}
+
+	return true
+}
+
+// closes conn and removes it from the connections closed by Close.
+func (s *Server) untrack(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+
+	_ = conn.Close()
+}
+
+// Close stops the server: it closes its listeners and the connections
+// they accepted, even the ones of idle clients, ending the tails and
+// requests in progress, and waits for their handlers to return.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.cancel()
+
+	var err error
+
+	for _, ln := range s.listeners {
+		if cerr := ln.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+
+	s.listeners = nil
+
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+
+	s.mu.Unlock()
+
+	s.handlers.Wait()
+
+	return err
+}
+
+// serves the requests sent on the connection.
+func (s *Server) handle(conn net.Conn) error {
+	br := bufio.NewReaderSize(conn, maxLine)
+
+	for {
+		cmd, arg, err := readRequest(br)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return writeStatus(conn, err)
+		}
+
+		switch cmd {
+		case "DUMP":
+			return s.dump(conn)
+		case "TAIL":
+			seq, err := strconv.ParseUint(arg, 10, 64)
+			if err != nil {
+				return writeStatus(conn, fmt.Errorf("invalid sequence number %q", arg))
+			}
+
+			return s.tail(conn, seq)
+		case "INSERT":
+			err = s.insert(conn, br, arg)
+		case "EXTRACT":
+			if s.decode == nil {
+				return writeStatus(conn, ErrReadOnly)
+			}
+
+			err = s.extract(conn)
+		case "PEEK":
+			err = s.writeElement(conn, s.r.Peek)
+		case "LEN":
+			_, err = fmt.Fprintf(conn, "OK %d\n", s.r.Len())
+		case "CAP":
+			_, err = fmt.Fprintf(conn, "OK %d\n", s.r.Cap())
+		default:
+			return writeStatus(conn, fmt.Errorf("unknown command %q", cmd))
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// inserts the element of length bytes following the request line.
+func (s *Server) insert(w io.Writer, br *bufio.Reader, length string) error {
+	if s.decode == nil {
+		return writeStatus(w, ErrReadOnly)
+	}
+
+	n, err := strconv.Atoi(length)
+	if err != nil || n < 0 || n > maxElement {
+		return writeStatus(w, fmt.Errorf("invalid element length %q", length))
+	}
+
+	p := make([]byte, n)
+	if _, err := io.ReadFull(br, p); err != nil {
+		return fmt.Errorf("reading element: %w", err)
+	}
+
+	v, err := s.decode(p)
+	if err != nil {
+		return writeStatus(w, fmt.Errorf("decoding element: %w", err))
+	}
+
+	s.r.Insert(v)
+
+	return writeStatus(w, nil)
+}
+
+// writes the element returned by get, or EMPTY if there is none.
+func (s *Server) writeElement(w io.Writer, get func() (interface{}, bool)) error {
+	v, ok := get()
+	if !ok {
+		_, err := io.WriteString(w, "EMPTY\n")
+		return err
+	}
+
+	p, err := s.encode(v)
+	if err != nil {
+		return writeStatus(w, fmt.Errorf("encoding element: %w", err))
+	}
+
+	return writeEncoded(w, p)
+}
+
+// writes the oldest element, extracting it only once encoded.
+func (s *Server) extract(w io.Writer) error {
+	s.extracting.Lock()
+	defer s.extracting.Unlock()
+
+	slot, ok := s.r.Reserve()
+	if !ok {
+		if s.r.Len() == 0 {
+			_, err := io.WriteString(w, "EMPTY\n")
+			return err
+		}
+
+		return writeStatus(w, errors.New("oldest element is reserved"))
+	}
+
+	p, err := s.encode(slot.Value())
+	if err != nil {
+		slot.Release()
+		return writeStatus(w, fmt.Errorf("encoding element: %w", err))
+	}
+
+	slot.Commit()
+
+	return writeEncoded(w, p)
+}
+
+// writes the response for an encoded element.
+func writeEncoded(w io.Writer, p []byte) error {
+	_, err := w.Write(append([]byte(fmt.Sprintf("OK %d\n", len(p))), p...))
+	return err
+}
+
+// reads a request line and splits it into its command and argument.
+func readRequest(br *bufio.Reader) (string, string, error) {
+	line, err := br.ReadSlice('\n')
+	if err != nil {
+		if errors.Is(err, bufio.ErrBufferFull) {
+			return "", "", errors.New("request line too long")
+		}
+
+		return "", "", fmt.Errorf("reading request: %w", err)
+	}
+
+	fields := strings.Fields(string(line))
+	switch len(fields) {
+	case 1:
+		return fields[0], "", nil
+	case 2:
+		return fields[0], fields[1], nil
+	default:
+		return "", "", errors.New("malformed request")
+	}
+}
+
+// writes the status line for the given error, nil being OK.
+func writeStatus(w io.Writer, err error) error {
+	if err == nil {
+		_, werr := io.WriteString(w, "OK\n")
+		return werr
+	}
+
+	// the message must fit in a line
+	msg := strings.ReplaceAll(err.Error(), "\n", " ")
+	if _, werr := fmt.Fprintf(w, "ERR %s\n", msg); werr != nil {
+		return werr
+	}
+
+	return err
+}
+
+// writes a record for the element with the given sequence number.
+func (s *Server) writeRecord(w io.Writer, seq uint64, v interface{}) error {
+	payload, err := s.encode(v)
+	if err != nil {
+		return fmt.Errorf("encoding element %d: %w", seq, err)
+	}
+
+	var header [12]byte
+
+	binary.BigEndian.PutUint64(header[:8], seq)
+	binary.BigEndian.PutUint32(header[8:], uint32(len(payload)))
+
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+
+	_, err = w.Write(payload)
+
+	return err
+}
+
+// serves the current contents of the ring.
+func (s *Server) dump(conn net.Conn) error {
+	elements, first := s.r.ReadSince(0)
+
+	bw := bufio.NewWriter(conn)
+
+	if err := writeStatus(bw, nil); err != nil {
+		return err
+	}
+
+	for i, v := range elements {
+		if err := s.writeRecord(bw, first+uint64(i), v); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// serves the elements from seq onwards, until the client closes the
+// connection or the server is closed.
+func (s *Server) tail(conn net.Conn, seq uint64) error {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	// the client sends nothing else, so reading only ends when it
+	// closes the connection
+	go func() {
+		_, _ = io.Copy(ioutil.Discard, conn)
+		cancel()
+	}()
+
+	bw := bufio.NewWriter(conn)
+
+	if err := writeStatus(bw, nil); err != nil {
+		return err
+	}
+
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	return s.r.Follow(ctx, seq, func(seq uint64, v interface{}) error {
+		if err := s.writeRecord(bw, seq, v); err != nil {
+			return err
+		}
+
+		return bw.Flush()
+	})
+}
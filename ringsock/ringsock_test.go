@@ -0,0 +1,211 @@
+package ringsock_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringsock"
+)
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"dump":            serverDump,
+		"tail":            serverTail,
+		"bad requests":    serverBadRequests,
+		"close ends tail": serverCloseEndsTail,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a ring with the given capacity and elements or fails the
+// test.
+func newRing(t *testing.T, cap int, vs ...interface{}) *ring.Ring {
+	t.Helper()
+
+	r, err := ring.New(cap)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	for _, v := range vs {
+		r.Insert(v)
+	}
+
+	return r
+}
+
+// serves r read-only on a unix socket in a temporary directory, and
+// returns the server and the path of the socket.  The server is closed
+// at the end of the test.
+func serve(t *testing.T, r *ring.Ring) (*ringsock.Server, string) {
+	t.Helper()
+
+	s := ringsock.NewServer(r, nil, nil)
+
+	return s, listen(t, s)
+}
+
+// serves s on a unix socket in a temporary directory, and returns the
+// path of the socket.  The server is closed at the end of the test.
+func listen(t *testing.T, s *ringsock.Server) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "ringsock")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %v", err)
+	}
+
+	path := filepath.Join(dir, "ring.sock")
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(ln) }()
+
+	t.Cleanup(func() {
+		if err := s.Close(); err != nil {
+			t.Errorf("closing server: %v", err)
+		}
+
+		if err := <-served; err != nil {
+			t.Errorf("serving: %v", err)
+		}
+
+		_ = os.RemoveAll(dir)
+	})
+
+	return path
+}
+
+// formats the records as seq:data.
+func format(recs []ringsock.Record) string {
+	result := make([]string, len(recs))
+	for i, rec := range recs {
+		result[i] = fmt.Sprintf("%d:%s", rec.Seq, rec.Data)
+	}
+
+	return fmt.Sprint(result)
+}
+
+// tests that dumping returns the current contents of the ring.
+func serverDump(t *testing.T) {
+	_, path := serve(t, newRing(t, 2, "a", "b", "c"))
+
+	recs, err := ringsock.Dump(context.Background(), path)
+	if err != nil {
+		t.Fatalf("dumping: %v", err)
+	}
+
+	if got := format(recs); got != "[1:b 2:c]" {
+		t.Errorf("want [1:b 2:c], got %s", got)
+	}
+}
+
+// tests that tailing returns the elements from a sequence number and
+// then the new ones.
+func serverTail(t *testing.T) {
+	r := newRing(t, 5, "a", "b", "c")
+	_, path := serve(t, r)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		r.Insert("d")
+	}()
+
+	var recs []ringsock.Record
+
+	err := ringsock.Tail(ctx, path, 1, func(rec ringsock.Record) error {
+		recs = append(recs, rec)
+		if len(recs) == 3 {
+			cancel()
+		}
+
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want canceled error, got %v", err)
+	}
+
+	if got := format(recs); got != "[1:b 2:c 3:d]" {
+		t.Errorf("want [1:b 2:c 3:d], got %s", got)
+	}
+}
+
+// tests that the server reports invalid requests.
+func serverBadRequests(t *testing.T) {
+	_, path := serve(t, newRing(t, 1))
+
+	for _, req := range []string{"NOPE", "TAIL", "TAIL x", "TAIL 1 2"} {
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			t.Fatalf("dialing: %v", err)
+		}
+
+		fmt.Fprintf(conn, "%s\n", req)
+
+		status, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			t.Fatalf("%s: reading status: %v", req, err)
+		}
+
+		if len(status) < 4 || status[:4] != "ERR " {
+			t.Errorf("%s: want an error status, got %q", req, status)
+		}
+
+		_ = conn.Close()
+	}
+}
+
+// tests that closing the server ends the tails in progress.
+func serverCloseEndsTail(t *testing.T) {
+	s, path := serve(t, newRing(t, 1, "a"))
+
+	tailed := make(chan error, 1)
+	received := make(chan struct{}, 1)
+
+	go func() {
+		tailed <- ringsock.Tail(context.Background(), path, 0, func(ringsock.Record) error {
+			received <- struct{}{}
+			return nil
+		})
+	}()
+
+	<-received
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("closing server: %v", err)
+	}
+
+	select {
+	case err := <-tailed:
+		if err != nil {
+			t.Errorf("want nil error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("tail still running after closing the server")
+	}
+}
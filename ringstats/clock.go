@@ -0,0 +1,31 @@
+package ringstats
+
+import "github.com/alcortesm/ring"
+
+// Option configures the statistics telling the time, Rate, Counter,
+// Series and Latency, on construction.
+type Option func(*config)
+
+// the configuration set by the options.
+type config struct {
+	clock ring.Clock
+}
+
+// WithClock makes the statistics tell the time with c instead of the
+// time package.  It is meant for tests advancing the time
+// deterministically.
+func WithClock(c ring.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+// returns the clock set by the options, the system one by default.
+func clockOf(opts []Option) ring.Clock {
+	c := config{clock: ring.SystemClock{}}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c.clock
+}
@@ -0,0 +1,61 @@
+package ringstats
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/internal/buckets"
+)
+
+// Counter counts events in a sliding window of time split in buckets,
+// like the errors in the last minute, counted per second.
+//
+// Unlike Rate, it uses constant memory regardless of the amount of
+// events, at the cost of a coarser window: the oldest bucket is
+// forgotten at once as time advances.
+type Counter struct {
+	mu     sync.Mutex
+	window *buckets.Window
+	clock  ring.Clock
+}
+
+// NewCounter returns a counter over a window of n buckets of the given
+// width, with the given options.
+func NewCounter(n int, width time.Duration, opts ...Option) (*Counter, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("buckets must be > 0, got %d", n)
+	}
+
+	if width <= 0 {
+		return nil, fmt.Errorf("bucket width must be > 0, got %v", width)
+	}
+
+	return &Counter{
+		window: buckets.New(n, width),
+		clock:  clockOf(opts),
+	}, nil
+}
+
+// Incr adds n events happening now.
+func (c *Counter) Incr(n int64) {
+	c.IncrAt(c.clock.Now(), n)
+}
+
+// IncrAt adds n events happening at t.  Events older than the window
+// are ignored.
+func (c *Counter) IncrAt(t time.Time, n int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.window.Add(t, n)
+}
+
+// Total returns the amount of events in the window.
+func (c *Counter) Total() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.window.Total(c.clock.Now())
+}
@@ -0,0 +1,105 @@
+package ringstats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring/ringstats"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestCounter(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid arguments": counterInvalidArguments,
+		"total":             counterTotal,
+		"rolls buckets":     counterRollsBuckets,
+		"clock":             counterClock,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a counter or fails the test.
+func newCounter(t *testing.T, n int, width time.Duration) *ringstats.Counter {
+	t.Helper()
+
+	c, err := ringstats.NewCounter(n, width)
+	if err != nil {
+		t.Fatalf("creating counter: %v", err)
+	}
+
+	return c
+}
+
+// tests that the buckets and their width must be positive.
+func counterInvalidArguments(t *testing.T) {
+	if _, err := ringstats.NewCounter(0, time.Second); err == nil {
+		t.Error("unexpected success with no buckets")
+	}
+
+	if _, err := ringstats.NewCounter(10, 0); err == nil {
+		t.Error("unexpected success with empty buckets")
+	}
+}
+
+// tests that the total adds up the events in the window.
+func counterTotal(t *testing.T) {
+	c := newCounter(t, 60, time.Second)
+
+	c.Incr(1)
+	c.Incr(2)
+	c.IncrAt(time.Now().Add(-30*time.Second), 3)
+
+	if got := c.Total(); got != 6 {
+		t.Errorf("want total 6, got %d", got)
+	}
+}
+
+// tests that the events out of the window are forgotten.
+func counterRollsBuckets(t *testing.T) {
+	c := newCounter(t, 6, 10*time.Second)
+	now := time.Now()
+
+	c.IncrAt(now.Add(-5*time.Minute), 100) // out of the window
+	c.IncrAt(now.Add(-2*time.Minute), 10)  // out of the window
+	c.IncrAt(now.Add(-40*time.Second), 1)
+	c.Incr(1)
+	c.IncrAt(now.Add(-2*time.Minute), 10) // now too old to be counted
+
+	if got := c.Total(); got != 2 {
+		t.Errorf("want total 2, got %d", got)
+	}
+}
+
+// tests that the events are counted and forgotten with the time of the
+// clock.
+func counterClock(t *testing.T) {
+	clock := ringtest.NewClock(time.Unix(0, 0))
+
+	c, err := ringstats.NewCounter(3, time.Second, ringstats.WithClock(clock))
+	if err != nil {
+		t.Fatalf("creating counter: %v", err)
+	}
+
+	c.Incr(1)
+	clock.Advance(time.Second)
+	c.Incr(2)
+
+	if got := c.Total(); got != 3 {
+		t.Errorf("want 3 events, got %d", got)
+	}
+
+	clock.Advance(2 * time.Second)
+
+	if got := c.Total(); got != 2 {
+		t.Errorf("want 2 events after the first bucket expired, got %d", got)
+	}
+}
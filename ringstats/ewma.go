@@ -0,0 +1,63 @@
+package ringstats
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MovingAverage is implemented by SMA and EWMA, so callers can switch
+// between smoothing strategies.
+type MovingAverage interface {
+	// Add adds a sample to the average.
+	Add(v float64)
+	// Value returns the current average, or 0 if there are no samples.
+	Value() float64
+}
+
+var (
+	_ MovingAverage = (*SMA)(nil)
+	_ MovingAverage = (*EWMA)(nil)
+)
+
+// EWMA is an exponentially weighted moving average.  Each new sample
+// is weighted by alpha, and the previous average by 1-alpha.
+type EWMA struct {
+	mu    sync.Mutex
+	alpha float64
+	value float64
+	init  bool // whether a sample was added
+}
+
+// NewEWMA returns an exponentially weighted moving average with the
+// given smoothing factor, which must be in (0, 1].  Higher factors
+// discount older samples faster.
+func NewEWMA(alpha float64) (*EWMA, error) {
+	if !(alpha > 0 && alpha <= 1) {
+		return nil, fmt.Errorf("smoothing factor must be in (0, 1], got %v", alpha)
+	}
+
+	return &EWMA{alpha: alpha}, nil
+}
+
+// Add adds a sample.  The first sample becomes the average.
+func (a *EWMA) Add(v float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.init {
+		a.value = v
+		a.init = true
+
+		return
+	}
+
+	a.value += a.alpha * (v - a.value)
+}
+
+// Value returns the average, or 0 if there are no samples.
+func (a *EWMA) Value() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.value
+}
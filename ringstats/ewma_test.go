@@ -0,0 +1,79 @@
+package ringstats_test
+
+import (
+	"testing"
+
+	"github.com/alcortesm/ring/ringstats"
+)
+
+func TestEWMA(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid factor":     ewmaInvalidFactor,
+		"first sample":       ewmaFirstSample,
+		"average":            ewmaAverage,
+		"interchangeability": ewmaInterchangeability,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns an exponentially weighted moving average or fails the test.
+func newEWMA(t *testing.T, alpha float64) *ringstats.EWMA {
+	t.Helper()
+
+	a, err := ringstats.NewEWMA(alpha)
+	if err != nil {
+		t.Fatalf("creating average: %v", err)
+	}
+
+	return a
+}
+
+// tests that smoothing factors out of (0, 1] are invalid.
+func ewmaInvalidFactor(t *testing.T) {
+	for _, alpha := range []float64{0, -0.5, 1.5} {
+		if _, err := ringstats.NewEWMA(alpha); err == nil {
+			t.Errorf("unexpected success with factor %v", alpha)
+		}
+	}
+}
+
+// tests that the first sample becomes the average.
+func ewmaFirstSample(t *testing.T) {
+	a := newEWMA(t, 0.1)
+	assertClose(t, 0, a.Value())
+
+	a.Add(10)
+	assertClose(t, 10, a.Value())
+}
+
+// tests that new samples are weighted by the smoothing factor.
+func ewmaAverage(t *testing.T) {
+	a := newEWMA(t, 0.5)
+
+	a.Add(10)
+	a.Add(20)
+	assertClose(t, 15, a.Value())
+
+	a.Add(5)
+	assertClose(t, 10, a.Value())
+}
+
+// tests that both averages can be used through the same interface.
+func ewmaInterchangeability(t *testing.T) {
+	for _, a := range []ringstats.MovingAverage{newSMA(t, 4), newEWMA(t, 0.5)} {
+		for i := 0; i < 10; i++ {
+			a.Add(3)
+		}
+
+		assertClose(t, 3, a.Value())
+	}
+}
@@ -0,0 +1,140 @@
+package ringstats
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/alcortesm/ring"
+)
+
+// Histogram counts the last samples added to it in buckets.
+type Histogram struct {
+	mu      sync.Mutex
+	samples *ring.Float64s
+	bounds  []float64 // upper bounds of the buckets, but the last one
+	counts  []uint64  // samples per bucket, the last one is +Inf
+}
+
+// Bucket is a bucket of a histogram.
+type Bucket struct {
+	// UpperBound is the inclusive upper bound of the bucket.
+	UpperBound float64
+	// Count is the amount of samples less than or equal to UpperBound,
+	// so it includes the samples of the previous buckets.
+	Count uint64
+}
+
+// NewHistogram returns a histogram of the last n samples with buckets
+// with the given upper bounds, which must be increasing.  A last bucket
+// with +Inf as its upper bound is always added.
+func NewHistogram(n int, bounds []float64) (*Histogram, error) {
+	for i, b := range bounds {
+		if math.IsNaN(b) || (i > 0 && b <= bounds[i-1]) {
+			return nil, fmt.Errorf("bucket bounds must be increasing, got %v", bounds)
+		}
+	}
+
+	samples, err := ring.NewFloat64s(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Histogram{
+		samples: samples,
+		bounds:  append([]float64(nil), bounds...),
+		counts:  make([]uint64, len(bounds)+1),
+	}, nil
+}
+
+// returns the index of the bucket of v.
+func (h *Histogram) bucket(v float64) int {
+	return sort.SearchFloat64s(h.bounds, v)
+}
+
+// Add adds a sample, dropping the oldest one if there are already n
+// samples.  NaN samples are ignored.
+func (h *Histogram) Add(v float64) {
+	if math.IsNaN(v) {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.samples.Len() == h.samples.Cap() {
+		oldest, _ := h.samples.Peek()
+		h.counts[h.bucket(oldest)]--
+	}
+
+	h.samples.Insert(v)
+	h.counts[h.bucket(v)]++
+}
+
+// Buckets returns the buckets of the histogram, with cumulative counts.
+func (h *Histogram) Buckets() []Bucket {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.buckets()
+}
+
+func (h *Histogram) buckets() []Bucket {
+	result := make([]Bucket, len(h.counts))
+
+	var count uint64
+
+	for i, c := range h.counts {
+		count += c
+		result[i].Count = count
+
+		if i < len(h.bounds) {
+			result[i].UpperBound = h.bounds[i]
+		} else {
+			result[i].UpperBound = math.Inf(1)
+		}
+	}
+
+	return result
+}
+
+// Count returns the amount of samples.
+func (h *Histogram) Count() int {
+	return h.samples.Len()
+}
+
+// Sum returns the sum of the samples.
+func (h *Histogram) Sum() float64 {
+	return h.samples.Sum()
+}
+
+// WritePrometheus writes the histogram to w as a Prometheus histogram
+// metric with the given name, in the Prometheus text exposition format.
+func (h *Histogram) WritePrometheus(w io.Writer, name string) error {
+	h.mu.Lock()
+	buckets, sum, count := h.buckets(), h.samples.Sum(), h.samples.Len()
+	h.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# TYPE %s histogram\n", name); err != nil {
+		return err
+	}
+
+	for _, b := range buckets {
+		le := "+Inf"
+		if !math.IsInf(b.UpperBound, 1) {
+			le = strconv.FormatFloat(b.UpperBound, 'g', -1, 64)
+		}
+
+		if _, err := fmt.Fprintf(w, "%s_bucket{le=%q} %d\n", name, le, b.Count); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%s_sum %s\n%s_count %d\n",
+		name, strconv.FormatFloat(sum, 'g', -1, 64), name, count)
+
+	return err
+}
@@ -0,0 +1,127 @@
+package ringstats_test
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/alcortesm/ring/ringstats"
+)
+
+func TestHistogram(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid bounds": histogramInvalidBounds,
+		"buckets":        histogramBuckets,
+		"sliding window": histogramSlidingWindow,
+		"prometheus":     histogramPrometheus,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a histogram of n samples with the given bounds or fails the
+// test.
+func newHistogram(t *testing.T, n int, bounds ...float64) *ringstats.Histogram {
+	t.Helper()
+
+	h, err := ringstats.NewHistogram(n, bounds)
+	if err != nil {
+		t.Fatalf("creating histogram: %v", err)
+	}
+
+	return h
+}
+
+// asserts that the histogram has the wanted cumulative counts.
+func assertCounts(t *testing.T, h *ringstats.Histogram, want ...uint64) {
+	t.Helper()
+
+	buckets := h.Buckets()
+
+	got := make([]uint64, len(buckets))
+	for i, b := range buckets {
+		got[i] = b.Count
+	}
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("want counts %v, got %v", want, got)
+	}
+}
+
+// tests that bounds must be increasing.
+func histogramInvalidBounds(t *testing.T) {
+	for _, bounds := range [][]float64{{1, 1}, {2, 1}, {math.NaN()}} {
+		if _, err := ringstats.NewHistogram(10, bounds); err == nil {
+			t.Errorf("unexpected success with bounds %v", bounds)
+		}
+	}
+
+	if _, err := ringstats.NewHistogram(0, nil); err == nil {
+		t.Error("unexpected success with an empty window")
+	}
+}
+
+// tests that samples are counted in the buckets with inclusive upper
+// bounds.
+func histogramBuckets(t *testing.T) {
+	h := newHistogram(t, 10, 1, 5)
+
+	for _, v := range []float64{0, 1, 2, 5, 6, math.Inf(1)} {
+		h.Add(v)
+	}
+
+	assertCounts(t, h, 2, 4, 6)
+
+	buckets := h.Buckets()
+	if buckets[1].UpperBound != 5 || !math.IsInf(buckets[2].UpperBound, 1) {
+		t.Errorf("unexpected bounds: %v", buckets)
+	}
+}
+
+// tests that the histogram only counts the last samples.
+func histogramSlidingWindow(t *testing.T) {
+	h := newHistogram(t, 3, 10)
+
+	for _, v := range []float64{1, 2, 3, 20, 30} {
+		h.Add(v)
+	}
+
+	assertCounts(t, h, 1, 3)
+
+	if h.Count() != 3 || h.Sum() != 53 {
+		t.Errorf("want count 3 and sum 53, got %d and %v", h.Count(), h.Sum())
+	}
+}
+
+// tests the Prometheus text format.
+func histogramPrometheus(t *testing.T) {
+	h := newHistogram(t, 10, 0.5, 1)
+	h.Add(0.25)
+	h.Add(0.75)
+	h.Add(2)
+
+	var b strings.Builder
+	if err := h.WritePrometheus(&b, "latency_seconds"); err != nil {
+		t.Fatalf("writing: %v", err)
+	}
+
+	want := `# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.5"} 1
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 3
+latency_seconds_sum 3
+latency_seconds_count 3
+`
+	if got := b.String(); got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
@@ -0,0 +1,65 @@
+package ringstats
+
+import (
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+// Latency keeps track of the durations of the last operations, like
+// the latencies of requests to a service.
+type Latency struct {
+	window *Quantiles
+	clock  ring.Clock
+}
+
+// NewLatency returns a latency tracker over the last n durations, with
+// the given options.
+func NewLatency(n int, opts ...Option) (*Latency, error) {
+	window, err := NewQuantiles(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Latency{window: window, clock: clockOf(opts)}, nil
+}
+
+// Record adds a duration, dropping the oldest one if there are already
+// n durations.
+func (l *Latency) Record(d time.Duration) {
+	l.window.Add(float64(d))
+}
+
+// Track calls fn, records how long it took and returns it.
+func (l *Latency) Track(fn func()) time.Duration {
+	start := l.clock.Now()
+	fn()
+	d := l.clock.Now().Sub(start)
+
+	l.Record(d)
+
+	return d
+}
+
+// Count returns the amount of durations.
+func (l *Latency) Count() int {
+	return l.window.Len()
+}
+
+// Mean returns the mean of the durations, or 0 if there are none.
+func (l *Latency) Mean() time.Duration {
+	mean, _ := l.window.samples.Mean()
+	return time.Duration(mean)
+}
+
+// Max returns the longest duration, or 0 if there are none.
+func (l *Latency) Max() time.Duration {
+	return l.Quantile(1)
+}
+
+// Quantile returns the p-quantile of the durations, as explained in
+// Quantiles.Quantile.  For example, Quantile(0.99) is the 99th
+// percentile.
+func (l *Latency) Quantile(p float64) time.Duration {
+	return time.Duration(l.window.Quantile(p))
+}
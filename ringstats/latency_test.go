@@ -0,0 +1,115 @@
+package ringstats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring/ringstats"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestLatency(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid window": latencyInvalidWindow,
+		"empty":          latencyEmpty,
+		"stats":          latencyStats,
+		"track":          latencyTrack,
+		"track clock":    latencyTrackClock,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a latency tracker over n durations or fails the test.
+func newLatency(t *testing.T, n int) *ringstats.Latency {
+	t.Helper()
+
+	l, err := ringstats.NewLatency(n)
+	if err != nil {
+		t.Fatalf("creating latency tracker: %v", err)
+	}
+
+	return l
+}
+
+// tests that windows smaller than 1 duration are invalid.
+func latencyInvalidWindow(t *testing.T) {
+	if _, err := ringstats.NewLatency(0); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests the stats of no durations.
+func latencyEmpty(t *testing.T) {
+	l := newLatency(t, 10)
+
+	if l.Count() != 0 || l.Mean() != 0 || l.Max() != 0 || l.Quantile(0.5) != 0 {
+		t.Errorf("unexpected stats: count %d, mean %v, max %v, median %v",
+			l.Count(), l.Mean(), l.Max(), l.Quantile(0.5))
+	}
+}
+
+// tests the stats of the last durations.
+func latencyStats(t *testing.T) {
+	l := newLatency(t, 100)
+
+	l.Record(time.Hour) // dropped
+
+	for i := 1; i <= 100; i++ {
+		l.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	if got := l.Count(); got != 100 {
+		t.Errorf("want count 100, got %d", got)
+	}
+
+	if got, want := l.Mean(), 50500*time.Microsecond; got != want {
+		t.Errorf("want mean %v, got %v", want, got)
+	}
+
+	if got := l.Max(); got != 100*time.Millisecond {
+		t.Errorf("want max 100ms, got %v", got)
+	}
+
+	if got, want := l.Quantile(0.99), 99010*time.Microsecond; got != want {
+		t.Errorf("want p99 %v, got %v", want, got)
+	}
+}
+
+// tests that tracking a function records its duration.
+func latencyTrack(t *testing.T) {
+	l := newLatency(t, 10)
+
+	d := l.Track(func() { time.Sleep(time.Millisecond) })
+
+	if d < time.Millisecond {
+		t.Errorf("tracked duration too short: %v", d)
+	}
+
+	if l.Count() != 1 || l.Max() != d {
+		t.Errorf("want the tracked duration %v recorded, got count %d, max %v",
+			d, l.Count(), l.Max())
+	}
+}
+
+// tests that tracked durations are measured with the clock.
+func latencyTrackClock(t *testing.T) {
+	clock := ringtest.NewClock(time.Unix(0, 0))
+
+	l, err := ringstats.NewLatency(3, ringstats.WithClock(clock))
+	if err != nil {
+		t.Fatalf("creating latency tracker: %v", err)
+	}
+
+	if got := l.Track(func() { clock.Advance(time.Hour) }); got != time.Hour {
+		t.Errorf("want an hour, got %v", got)
+	}
+}
@@ -0,0 +1,241 @@
+package ringstats
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/alcortesm/ring"
+)
+
+// the largest window with exact quantiles, see Quantiles.
+const maxExactWindow = 1 << 16
+
+// the relative error of the quantiles of larger windows, see Quantiles.
+const quantileError = 0.01
+
+// Quantiles computes quantiles, like the median or the 99th percentile,
+// of the last samples added to it.
+//
+// For windows of up to 65536 samples, quantiles are exact.  Besides the
+// samples in arrival order, it keeps them sorted, so querying a
+// quantile takes constant time, while adding a sample takes time linear
+// in the window size, spent moving memory around.
+//
+// For larger windows, quantiles are estimated with a relative error of
+// at most 1%, counting the samples in buckets of exponentially growing
+// widths, so adding a sample takes constant time, and querying a
+// quantile takes time linear in the amount of buckets in use, a few
+// thousands at most for samples spanning many orders of magnitude.
+// Streaming estimators like P² are not used, as they cannot forget the
+// samples leaving the window.
+type Quantiles struct {
+	mu      sync.Mutex
+	samples *ring.Float64s
+	sorted  []float64   // nil if the window is larger than maxExactWindow
+	buckets *logBuckets // nil if sorted is not
+}
+
+// NewQuantiles returns a quantile estimator over the last n samples.
+func NewQuantiles(n int) (*Quantiles, error) {
+	samples, err := ring.NewFloat64s(n)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Quantiles{
+		samples: samples,
+	}
+
+	if n > maxExactWindow {
+		q.buckets = newLogBuckets(quantileError)
+	} else {
+		q.sorted = make([]float64, 0, n)
+	}
+
+	return q, nil
+}
+
+// Add adds a sample, dropping the oldest one if there are already n
+// samples.  NaN samples are ignored.
+func (q *Quantiles) Add(v float64) {
+	if math.IsNaN(v) {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.buckets != nil {
+		if q.samples.Len() == q.samples.Cap() {
+			oldest, _ := q.samples.Peek()
+			q.buckets.add(oldest, -1)
+		}
+
+		q.samples.Insert(v)
+		q.buckets.add(v, 1)
+
+		return
+	}
+
+	if q.samples.Len() == q.samples.Cap() {
+		oldest, _ := q.samples.Peek()
+		i := sort.SearchFloat64s(q.sorted, oldest)
+		q.sorted = append(q.sorted[:i], q.sorted[i+1:]...)
+	}
+
+	q.samples.Insert(v)
+
+	i := sort.SearchFloat64s(q.sorted, v)
+	q.sorted = append(q.sorted, 0)
+	copy(q.sorted[i+1:], q.sorted[i:])
+	q.sorted[i] = v
+}
+
+// Quantile returns the p-quantile of the samples, for p in [0, 1],
+// interpolating linearly between the closest samples, or estimating it
+// for large windows, see Quantiles.  For example, Quantile(0.5) is the
+// median and Quantile(0.99) the 99th percentile.  It returns 0 if there
+// are no samples.  Values of p out of [0, 1] are clamped.
+func (q *Quantiles) Quantile(p float64) float64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.samples.Len() == 0 {
+		return 0
+	}
+
+	p = math.Max(0, math.Min(1, p))
+
+	if q.buckets != nil {
+		return q.buckets.quantile(p)
+	}
+
+	pos := p * float64(len(q.sorted)-1)
+	i := int(pos)
+
+	if i == len(q.sorted)-1 {
+		return q.sorted[i]
+	}
+
+	frac := pos - float64(i)
+
+	return q.sorted[i] + frac*(q.sorted[i+1]-q.sorted[i])
+}
+
+// Len returns the amount of samples.
+func (q *Quantiles) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.samples.Len()
+}
+
+// counts samples in buckets whose bounds grow exponentially away from
+// zero, so any value in a bucket is within a relative error of its
+// middle, like in DDSketch.  Bucket k of the positive samples holds the
+// ones in (gamma^(k-1), gamma^k], and the negative ones are counted by
+// their absolute value.
+type logBuckets struct {
+	gamma    float64
+	logGamma float64
+	positive map[int]int
+	negative map[int]int
+	zeros    int
+	len      int
+}
+
+// returns buckets for the given relative error, in (0, 1).
+func newLogBuckets(relErr float64) *logBuckets {
+	gamma := (1 + relErr) / (1 - relErr)
+
+	return &logBuckets{
+		gamma:    gamma,
+		logGamma: math.Log(gamma),
+		positive: make(map[int]int),
+		negative: make(map[int]int),
+	}
+}
+
+// the bucket of the infinities.
+const infBucket = math.MaxInt32
+
+// returns the bucket of v, which must be > 0.
+func (b *logBuckets) index(v float64) int {
+	if math.IsInf(v, 1) {
+		return infBucket
+	}
+
+	return int(math.Ceil(math.Log(v) / b.logGamma))
+}
+
+// returns the middle of the bucket k, relative to its bounds, or +Inf
+// for the bucket of the infinities.
+func (b *logBuckets) value(k int) float64 {
+	if k == infBucket {
+		return math.Inf(1)
+	}
+
+	return 2 * math.Pow(b.gamma, float64(k)) / (b.gamma + 1)
+}
+
+// adds delta to the count of the bucket of v.
+func (b *logBuckets) add(v float64, delta int) {
+	b.len += delta
+
+	var counts map[int]int
+
+	switch {
+	case v > 0:
+		counts = b.positive
+	case v < 0:
+		counts, v = b.negative, -v
+	default:
+		b.zeros += delta
+		return
+	}
+
+	k := b.index(v)
+
+	if counts[k] += delta; counts[k] == 0 {
+		delete(counts, k)
+	}
+}
+
+// returns the estimated p-quantile of the samples, for p in [0, 1],
+// there must be some.
+func (b *logBuckets) quantile(p float64) float64 {
+	rank := int(math.Round(p * float64(b.len-1)))
+
+	negative := sortedKeys(b.negative)
+	for i := len(negative) - 1; i >= 0; i-- {
+		if rank -= b.negative[negative[i]]; rank < 0 {
+			return -b.value(negative[i])
+		}
+	}
+
+	if rank -= b.zeros; rank < 0 {
+		return 0
+	}
+
+	positive := sortedKeys(b.positive)
+	for _, k := range positive {
+		if rank -= b.positive[k]; rank < 0 {
+			return b.value(k)
+		}
+	}
+
+	return b.value(positive[len(positive)-1])
+}
+
+// returns the keys of m in increasing order.
+func sortedKeys(m map[int]int) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Ints(keys)
+
+	return keys
+}
@@ -0,0 +1,156 @@
+package ringstats_test
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/alcortesm/ring/ringstats"
+)
+
+func TestQuantiles(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid window": quantilesInvalidWindow,
+		"empty":          quantilesEmpty,
+		"interpolation":  quantilesInterpolation,
+		"ignores NaN":    quantilesIgnoresNaN,
+		"sliding window": quantilesSlidingWindow,
+		"large window":   quantilesLargeWindow,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a quantile estimator over n samples with the given samples
+// or fails the test.
+func newQuantiles(t *testing.T, n int, vs ...float64) *ringstats.Quantiles {
+	t.Helper()
+
+	q, err := ringstats.NewQuantiles(n)
+	if err != nil {
+		t.Fatalf("creating estimator: %v", err)
+	}
+
+	for _, v := range vs {
+		q.Add(v)
+	}
+
+	return q
+}
+
+// tests that windows smaller than 1 sample are invalid.
+func quantilesInvalidWindow(t *testing.T) {
+	if _, err := ringstats.NewQuantiles(0); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that the quantiles of no samples are 0.
+func quantilesEmpty(t *testing.T) {
+	assertClose(t, 0, newQuantiles(t, 3).Quantile(0.5))
+}
+
+// tests that quantiles interpolate between samples and clamp q.
+func quantilesInterpolation(t *testing.T) {
+	q := newQuantiles(t, 10, 40, 10, 30, 20, 50)
+
+	for p, want := range map[float64]float64{
+		-1:    10,
+		0:     10,
+		0.25:  20,
+		0.5:   30,
+		0.875: 45,
+		1:     50,
+		2:     50,
+	} {
+		if got := q.Quantile(p); got != want {
+			t.Errorf("quantile %v: want %v, got %v", p, want, got)
+		}
+	}
+}
+
+// tests that NaN samples are ignored.
+func quantilesIgnoresNaN(t *testing.T) {
+	q := newQuantiles(t, 3, 1, math.NaN(), 3)
+
+	if q.Len() != 2 {
+		t.Fatalf("want 2 samples, got %d", q.Len())
+	}
+
+	assertClose(t, 2, q.Quantile(0.5))
+}
+
+// tests that quantiles only take into account the last samples.
+func quantilesSlidingWindow(t *testing.T) {
+	const n = 50
+
+	rnd := rand.New(rand.NewSource(1))
+	q := newQuantiles(t, n)
+
+	var window []float64
+
+	for i := 0; i < 1000; i++ {
+		v := float64(rnd.Intn(20)) // with repeated samples
+		q.Add(v)
+
+		window = append(window, v)
+		if len(window) > n {
+			window = window[1:]
+		}
+
+		sorted := append([]float64(nil), window...)
+		sort.Float64s(sorted)
+
+		if got, want := q.Quantile(1), sorted[len(sorted)-1]; got != want {
+			t.Fatalf("after %d samples: want max %v, got %v", i+1, want, got)
+		}
+
+		if got, want := q.Quantile(0), sorted[0]; got != want {
+			t.Fatalf("after %d samples: want min %v, got %v", i+1, want, got)
+		}
+	}
+
+	if q.Len() != n {
+		t.Errorf("want %d samples, got %d", n, q.Len())
+	}
+}
+
+// tests that the quantiles of large windows are estimated within 1% of
+// the samples at their rank.
+func quantilesLargeWindow(t *testing.T) {
+	const n = 1 << 17
+
+	rnd := rand.New(rand.NewSource(1))
+	q := newQuantiles(t, n)
+
+	var window []float64
+
+	for i := 0; i < n+n/2; i++ {
+		v := math.Round(rnd.NormFloat64()*1000) / 10 // with zeros and negatives
+		q.Add(v)
+		window = append(window, v)
+	}
+
+	sorted := append([]float64(nil), window[len(window)-n:]...)
+	sort.Float64s(sorted)
+
+	for _, p := range []float64{0, 0.01, 0.25, 0.5, 0.75, 0.99, 0.999, 1} {
+		want := sorted[int(math.Round(p*(n-1)))]
+		if got := q.Quantile(p); math.Abs(got-want) > 0.01*math.Abs(want) {
+			t.Errorf("quantile %v: want %v within 1%%, got %v", p, want, got)
+		}
+	}
+
+	if q.Len() != n {
+		t.Errorf("want %d samples, got %d", n, q.Len())
+	}
+}
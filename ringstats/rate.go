@@ -0,0 +1,114 @@
+package ringstats
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+// Rate counts the events in a sliding window of time, like the
+// requests per second a service is handling.
+//
+// It keeps the timestamps of the events in a ring, so it remembers a
+// bounded amount of events; if more events happen in the window, the
+// rate saturates at that amount.
+type Rate struct {
+	mu     sync.Mutex
+	window time.Duration
+	times  *ring.Ring // timestamps of the events, as time.Time
+	newest time.Time  // timestamp of the newest event
+	clock  ring.Clock
+}
+
+// NewRate returns a rate counter over the given window of time,
+// remembering up to maxEvents events, with the given options.
+func NewRate(window time.Duration, maxEvents int, opts ...Option) (*Rate, error) {
+	if window <= 0 {
+		return nil, fmt.Errorf("window must be > 0, got %v", window)
+	}
+
+	if maxEvents < 1 {
+		return nil, fmt.Errorf("maximum events must be > 0, got %d", maxEvents)
+	}
+
+	times, err := ring.New(maxEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rate{
+		window: window,
+		times:  times,
+		clock:  clockOf(opts),
+	}, nil
+}
+
+// Add records an event happening now.
+func (r *Rate) Add() {
+	r.AddAt(r.clock.Now())
+}
+
+// AddAt records an event happening at t.  Events must be recorded in
+// order, an event older than the previous one is recorded as happening
+// at the same time as it.
+func (r *Rate) AddAt(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.times.Len() > 0 && t.Before(r.newest) {
+		t = r.newest
+	}
+
+	r.times.Insert(t)
+	r.newest = t
+}
+
+// forgets the events out of the window ending at now.
+func (r *Rate) expire(now time.Time) {
+	start := now.Add(-r.window)
+
+	for {
+		oldest, ok := r.times.Peek()
+		if !ok || oldest.(time.Time).After(start) {
+			return
+		}
+
+		_, _ = r.times.Extract()
+	}
+}
+
+// CountWithin returns the amount of events in the last d, which is
+// capped to the window of the counter.
+func (r *Rate) CountWithin(d time.Duration) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	r.expire(now)
+
+	start := now.Add(-d)
+	times := r.times.ToSlice()
+
+	return len(times) - sort.Search(len(times), func(i int) bool {
+		return times[i].(time.Time).After(start)
+	})
+}
+
+// Count returns the amount of events in the window.
+func (r *Rate) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.expire(r.clock.Now())
+
+	return r.times.Len()
+}
+
+// RatePerSecond returns the average amount of events per second in the
+// window.
+func (r *Rate) RatePerSecond() float64 {
+	return float64(r.Count()) / r.window.Seconds()
+}
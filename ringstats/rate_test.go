@@ -0,0 +1,147 @@
+package ringstats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring/ringstats"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestRate(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid arguments": rateInvalidArguments,
+		"count within":      rateCountWithin,
+		"expires events":    rateExpiresEvents,
+		"saturates":         rateSaturates,
+		"out of order":      rateOutOfOrder,
+		"clock":             rateClock,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a rate counter or fails the test.
+func newRate(t *testing.T, window time.Duration, maxEvents int) *ringstats.Rate {
+	t.Helper()
+
+	r, err := ringstats.NewRate(window, maxEvents)
+	if err != nil {
+		t.Fatalf("creating rate counter: %v", err)
+	}
+
+	return r
+}
+
+// tests that windows and maximum events must be positive.
+func rateInvalidArguments(t *testing.T) {
+	if _, err := ringstats.NewRate(0, 10); err == nil {
+		t.Error("unexpected success with an empty window")
+	}
+
+	if _, err := ringstats.NewRate(time.Second, 0); err == nil {
+		t.Error("unexpected success with no events")
+	}
+}
+
+// tests counting the events in part of the window.
+func rateCountWithin(t *testing.T) {
+	r := newRate(t, time.Hour, 100)
+	now := time.Now()
+
+	r.AddAt(now.Add(-30 * time.Minute))
+	r.AddAt(now.Add(-10 * time.Minute))
+	r.AddAt(now.Add(-5 * time.Minute))
+	r.Add()
+
+	for d, want := range map[time.Duration]int{
+		time.Minute:      1,
+		7 * time.Minute:  2,
+		20 * time.Minute: 3,
+		time.Hour:        4,
+		2 * time.Hour:    4,
+	} {
+		if got := r.CountWithin(d); got != want {
+			t.Errorf("within %v: want %d events, got %d", d, want, got)
+		}
+	}
+}
+
+// tests that events out of the window are forgotten.
+func rateExpiresEvents(t *testing.T) {
+	r := newRate(t, time.Minute, 100)
+	now := time.Now()
+
+	r.AddAt(now.Add(-2 * time.Minute))
+	r.AddAt(now.Add(-time.Hour)) // recorded as two minutes ago
+	r.Add()
+	r.Add()
+
+	if got := r.Count(); got != 2 {
+		t.Errorf("want 2 events, got %d", got)
+	}
+
+	if got := r.RatePerSecond(); got != 2.0/60 {
+		t.Errorf("want rate %v, got %v", 2.0/60, got)
+	}
+}
+
+// tests that the counter remembers a bounded amount of events.
+func rateSaturates(t *testing.T) {
+	r := newRate(t, time.Hour, 3)
+
+	for i := 0; i < 10; i++ {
+		r.Add()
+	}
+
+	if got := r.Count(); got != 3 {
+		t.Errorf("want 3 events, got %d", got)
+	}
+}
+
+// tests that events older than the previous one are recorded with its
+// timestamp.
+func rateOutOfOrder(t *testing.T) {
+	r := newRate(t, time.Hour, 10)
+	now := time.Now()
+
+	r.AddAt(now.Add(-time.Minute))
+	r.AddAt(now.Add(-30 * time.Minute))
+
+	if got := r.CountWithin(2 * time.Minute); got != 2 {
+		t.Errorf("want 2 events, got %d", got)
+	}
+}
+
+// tests that the events are added and expired with the time of the
+// clock.
+func rateClock(t *testing.T) {
+	clock := ringtest.NewClock(time.Unix(0, 0))
+
+	r, err := ringstats.NewRate(time.Minute, 10, ringstats.WithClock(clock))
+	if err != nil {
+		t.Fatalf("creating rate counter: %v", err)
+	}
+
+	r.Add()
+	clock.Advance(30 * time.Second)
+	r.Add()
+
+	if got := r.Count(); got != 2 {
+		t.Errorf("want 2 events, got %d", got)
+	}
+
+	clock.Advance(40 * time.Second)
+
+	if got := r.CountWithin(time.Minute); got != 1 {
+		t.Errorf("want 1 event after the first expired, got %d", got)
+	}
+}
@@ -0,0 +1,35 @@
+/*
+Package ringstats implements statistics over the last samples of a
+stream, like moving averages, using rings to hold the samples.
+*/
+package ringstats
+
+import "github.com/alcortesm/ring"
+
+// SMA is the simple moving average of the last samples added to it.
+// Adding samples and getting the average take constant time.
+type SMA struct {
+	samples *ring.Float64s
+}
+
+// NewSMA returns the moving average of the last n samples.
+func NewSMA(n int) (*SMA, error) {
+	samples, err := ring.NewFloat64s(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SMA{samples: samples}, nil
+}
+
+// Add adds a sample, dropping the oldest one if there are already n
+// samples.
+func (a *SMA) Add(v float64) {
+	a.samples.Insert(v)
+}
+
+// Value returns the average of the samples, or 0 if there are none.
+func (a *SMA) Value() float64 {
+	mean, _ := a.samples.Mean()
+	return mean
+}
@@ -0,0 +1,74 @@
+package ringstats_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alcortesm/ring/ringstats"
+)
+
+func TestSMA(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid window": smaInvalidWindow,
+		"empty":          smaEmpty,
+		"average":        smaAverage,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// asserts that got is close enough to want.
+func assertClose(t *testing.T, want, got float64) {
+	t.Helper()
+
+	if math.Abs(want-got) > 1e-9 {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+// returns a simple moving average of n samples or fails the test.
+func newSMA(t *testing.T, n int) *ringstats.SMA {
+	t.Helper()
+
+	a, err := ringstats.NewSMA(n)
+	if err != nil {
+		t.Fatalf("creating average: %v", err)
+	}
+
+	return a
+}
+
+// tests that windows smaller than 1 sample are invalid.
+func smaInvalidWindow(t *testing.T) {
+	if _, err := ringstats.NewSMA(0); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that the average of no samples is 0.
+func smaEmpty(t *testing.T) {
+	assertClose(t, 0, newSMA(t, 3).Value())
+}
+
+// tests that the average only takes into account the last samples.
+func smaAverage(t *testing.T) {
+	a := newSMA(t, 3)
+
+	a.Add(1)
+	assertClose(t, 1, a.Value())
+
+	a.Add(2)
+	a.Add(6)
+	assertClose(t, 3, a.Value())
+
+	a.Add(10) // drops 1
+	assertClose(t, 6, a.Value())
+}
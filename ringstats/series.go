@@ -0,0 +1,107 @@
+package ringstats
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+// Series is a time series holding the last samples added to it, that
+// can be resampled at arbitrary instants.
+type Series struct {
+	mu      sync.Mutex
+	samples *ring.Ring // the last samples, as sample
+	newest  time.Time  // timestamp of the newest sample
+	clock   ring.Clock
+}
+
+// a value of the series and when it was taken.
+type sample struct {
+	t time.Time
+	v float64
+}
+
+// NewSeries returns a time series holding the last n samples, with the
+// given options.
+func NewSeries(n int, opts ...Option) (*Series, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("series capacity must be > 0, got %d", n)
+	}
+
+	samples, err := ring.New(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Series{
+		samples: samples,
+		clock:   clockOf(opts),
+	}, nil
+}
+
+// Add adds a sample taken now.
+func (s *Series) Add(v float64) {
+	s.AddAt(s.clock.Now(), v)
+}
+
+// AddAt adds a sample taken at t, dropping the oldest one if there are
+// already n samples.  Samples must be added in order, a sample older
+// than the previous one is added as taken at the same time as it.
+func (s *Series) AddAt(t time.Time, v float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.samples.Len() > 0 && t.Before(s.newest) {
+		t = s.newest
+	}
+
+	s.samples.Insert(sample{t: t, v: v})
+	s.newest = t
+}
+
+// ValueAt returns the value of the series at t, interpolating linearly
+// between the samples taken right before and after it.  It returns
+// false if t is out of the time range of the samples.
+func (s *Series) ValueAt(t time.Time) (float64, bool) {
+	samples := s.samples.ToSlice()
+
+	at := func(i int) sample {
+		return samples[i].(sample)
+	}
+
+	// the first sample taken after t
+	after := sort.Search(len(samples), func(i int) bool {
+		return at(i).t.After(t)
+	})
+
+	if after == 0 {
+		return 0, false
+	}
+
+	before := at(after - 1)
+
+	if after == len(samples) {
+		// only a sample taken exactly at t is in range
+		if before.t.Equal(t) {
+			return before.v, true
+		}
+
+		return 0, false
+	}
+
+	next := at(after)
+	t0, t1 := before.t, next.t
+	v0, v1 := before.v, next.v
+
+	frac := float64(t.Sub(t0)) / float64(t1.Sub(t0))
+
+	return v0 + frac*(v1-v0), true
+}
+
+// Len returns the amount of samples.
+func (s *Series) Len() int {
+	return s.samples.Len()
+}
@@ -0,0 +1,134 @@
+package ringstats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring/ringstats"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestSeries(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid capacity": seriesInvalidCapacity,
+		"interpolates":     seriesInterpolates,
+		"out of range":     seriesOutOfRange,
+		"keeps last":       seriesKeepsLast,
+		"clock":            seriesClock,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// an arbitrary instant to take samples at.
+var epoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// returns the instant s seconds after the epoch.
+func at(s float64) time.Time {
+	return epoch.Add(time.Duration(s * float64(time.Second)))
+}
+
+// returns a series of n samples with values and timestamps in seconds
+// since the epoch, in pairs, or fails the test.
+func newSeries(t *testing.T, n int, pairs ...float64) *ringstats.Series {
+	t.Helper()
+
+	s, err := ringstats.NewSeries(n)
+	if err != nil {
+		t.Fatalf("creating series: %v", err)
+	}
+
+	for i := 0; i < len(pairs); i += 2 {
+		s.AddAt(at(pairs[i]), pairs[i+1])
+	}
+
+	return s
+}
+
+// asserts the value of the series at the given second.
+func assertValueAt(t *testing.T, s *ringstats.Series, sec, want float64) {
+	t.Helper()
+
+	got, ok := s.ValueAt(at(sec))
+	if !ok {
+		t.Fatalf("no value at %vs", sec)
+	}
+
+	assertClose(t, want, got)
+}
+
+// tests that a capacity smaller than 1 is invalid.
+func seriesInvalidCapacity(t *testing.T) {
+	if _, err := ringstats.NewSeries(0); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that values are interpolated linearly between samples.
+func seriesInterpolates(t *testing.T) {
+	s := newSeries(t, 10, 0, 10, 10, 20, 12, 0)
+
+	assertValueAt(t, s, 0, 10)
+	assertValueAt(t, s, 2.5, 12.5)
+	assertValueAt(t, s, 10, 20)
+	assertValueAt(t, s, 11.5, 5)
+	assertValueAt(t, s, 12, 0)
+}
+
+// tests that there are no values out of the time range of the samples.
+func seriesOutOfRange(t *testing.T) {
+	s := newSeries(t, 10)
+
+	if _, ok := s.ValueAt(epoch); ok {
+		t.Error("unexpected value in empty series")
+	}
+
+	s = newSeries(t, 10, 1, 10, 2, 20)
+
+	for _, sec := range []float64{0.5, 2.5} {
+		if v, ok := s.ValueAt(at(sec)); ok {
+			t.Errorf("unexpected value %v at %vs", v, sec)
+		}
+	}
+}
+
+// tests that only the last samples are kept.
+func seriesKeepsLast(t *testing.T) {
+	s := newSeries(t, 2, 0, 0, 1, 10, 2, 20)
+
+	if _, ok := s.ValueAt(at(0.5)); ok {
+		t.Error("unexpected value before the oldest sample")
+	}
+
+	assertValueAt(t, s, 1.5, 15)
+
+	if s.Len() != 2 {
+		t.Errorf("want 2 samples, got %d", s.Len())
+	}
+}
+
+// tests that samples are taken at the time of the clock.
+func seriesClock(t *testing.T) {
+	clock := ringtest.NewClock(epoch)
+
+	s, err := ringstats.NewSeries(3, ringstats.WithClock(clock))
+	if err != nil {
+		t.Fatalf("creating series: %v", err)
+	}
+
+	s.Add(0)
+	clock.Advance(10 * time.Second)
+	s.Add(10)
+
+	if got, ok := s.ValueAt(at(5)); !ok || got != 5 {
+		t.Errorf("want 5, got %v, %t", got, ok)
+	}
+}
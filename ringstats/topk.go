@@ -0,0 +1,94 @@
+package ringstats
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/alcortesm/ring"
+)
+
+// TopK finds the most frequent keys among the last keys added to it,
+// like the clients making the most requests.
+type TopK struct {
+	mu     sync.Mutex
+	keys   *ring.Ring     // the last keys, as strings
+	counts map[string]int // occurrences of each key in the ring
+}
+
+// KeyCount is a key and its occurrences.
+type KeyCount struct {
+	Key   string
+	Count int
+}
+
+// NewTopK returns a tracker of the most frequent keys among the last n
+// keys.
+func NewTopK(n int) (*TopK, error) {
+	keys, err := ring.New(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TopK{
+		keys:   keys,
+		counts: make(map[string]int),
+	}, nil
+}
+
+// Add adds a key, forgetting the oldest one if there are already n
+// keys.
+func (t *TopK) Add(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.keys.Len() == t.keys.Cap() {
+		v, _ := t.keys.Extract()
+		oldest := v.(string)
+
+		if t.counts[oldest]--; t.counts[oldest] == 0 {
+			delete(t.counts, oldest)
+		}
+	}
+
+	t.keys.Insert(key)
+	t.counts[key]++
+}
+
+// Top returns the k most frequent keys, from most to least frequent.
+// Keys with the same frequency are sorted alphabetically.  It returns
+// nil if k is not greater than zero.
+func (t *TopK) Top(k int) []KeyCount {
+	if k <= 0 {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	all := make([]KeyCount, 0, len(t.counts))
+	for key, count := range t.counts {
+		all = append(all, KeyCount{Key: key, Count: count})
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Count != all[j].Count {
+			return all[i].Count > all[j].Count
+		}
+
+		return all[i].Key < all[j].Key
+	})
+
+	if k < len(all) {
+		all = all[:k]
+	}
+
+	return all
+}
+
+// Count returns the occurrences of key among the last keys.
+func (t *TopK) Count(key string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.counts[key]
+}
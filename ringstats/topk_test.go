@@ -0,0 +1,87 @@
+package ringstats_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alcortesm/ring/ringstats"
+)
+
+func TestTopK(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid window": topKInvalidWindow,
+		"top":            topKTop,
+		"sliding window": topKSlidingWindow,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a top-k tracker over n keys with the given keys or fails the
+// test.
+func newTopK(t *testing.T, n int, keys ...string) *ringstats.TopK {
+	t.Helper()
+
+	tk, err := ringstats.NewTopK(n)
+	if err != nil {
+		t.Fatalf("creating tracker: %v", err)
+	}
+
+	for _, key := range keys {
+		tk.Add(key)
+	}
+
+	return tk
+}
+
+// asserts that the top k keys are the wanted ones.
+func assertTop(t *testing.T, tk *ringstats.TopK, k int, want string) {
+	t.Helper()
+
+	if got := fmt.Sprint(tk.Top(k)); got != want {
+		t.Errorf("want top %d %s, got %s", k, want, got)
+	}
+}
+
+// tests that windows smaller than 1 key are invalid.
+func topKInvalidWindow(t *testing.T) {
+	if _, err := ringstats.NewTopK(0); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that keys are sorted by frequency and then alphabetically.
+func topKTop(t *testing.T) {
+	tk := newTopK(t, 10, "b", "a", "c", "b", "c", "b", "d")
+
+	assertTop(t, tk, 3, "[{b 3} {c 2} {a 1}]")
+	assertTop(t, tk, 10, "[{b 3} {c 2} {a 1} {d 1}]")
+	assertTop(t, tk, 0, "[]")
+	assertTop(t, tk, -1, "[]")
+
+	if got := tk.Top(-1); got != nil {
+		t.Errorf("want nil for a negative k, got %v", got)
+	}
+}
+
+// tests that old keys are forgotten.
+func topKSlidingWindow(t *testing.T) {
+	tk := newTopK(t, 3, "a", "a", "a", "b", "c")
+
+	assertTop(t, tk, 3, "[{a 1} {b 1} {c 1}]")
+
+	tk.Add("c")
+	assertTop(t, tk, 3, "[{c 2} {b 1}]")
+
+	if got := tk.Count("a"); got != 0 {
+		t.Errorf("want count 0 for a forgotten key, got %d", got)
+	}
+}
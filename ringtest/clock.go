@@ -0,0 +1,122 @@
+package ringtest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+// Clock is a ring.Clock whose time only changes when advanced, to test
+// the time-based features of rings and groups without sleeping.  See
+// ring.WithClock and ring.WithGroupClock.
+//
+// The tickers of background goroutines are created asynchronously, so
+// tests should call WaitTickers before advancing the clock, and then
+// wait for the effects of the ticks, for example with
+// AssertEventually.
+type Clock struct {
+	mu      sync.Mutex
+	changed *sync.Cond // broadcast when tickers are added or stopped
+	now     time.Time
+	tickers map[*ticker]struct{}
+}
+
+var _ ring.Clock = (*Clock)(nil)
+
+// NewClock returns a clock starting at the given time.
+func NewClock(start time.Time) *Clock {
+	c := &Clock{
+		now:     start,
+		tickers: make(map[*ticker]struct{}),
+	}
+
+	c.changed = sync.NewCond(&c.mu)
+
+	return c
+}
+
+// Now implements ring.Clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// NewTicker implements ring.Clock.  The ticker ticks when the clock is
+// advanced past its next tick.
+func (c *Clock) NewTicker(d time.Duration) ring.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &ticker{
+		clock: c,
+		every: d,
+		next:  c.now.Add(d),
+		c:     make(chan time.Time, 1),
+	}
+
+	c.tickers[t] = struct{}{}
+	c.changed.Broadcast()
+
+	return t
+}
+
+// Advance moves the clock forward by d, sending a tick to the tickers
+// whose next tick falls in that period.  Like the ones of the time
+// package, tickers drop the ticks their readers are not ready for.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	for t := range c.tickers {
+		if t.next.After(c.now) {
+			continue
+		}
+
+		select {
+		case t.c <- c.now:
+		default:
+		}
+
+		for !t.next.After(c.now) {
+			t.next = t.next.Add(t.every)
+		}
+	}
+}
+
+// WaitTickers blocks until there are at least n active tickers, for
+// example the ones of the background goroutines of a ring.
+func (c *Clock) WaitTickers(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.tickers) < n {
+		c.changed.Wait()
+	}
+}
+
+// ticker is a ticker of a Clock.
+type ticker struct {
+	clock *Clock
+	every time.Duration
+	next  time.Time // time of the next tick
+	c     chan time.Time
+}
+
+func (t *ticker) C() <-chan time.Time { return t.c }
+
+func (t *ticker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	delete(t.clock.tickers, t)
+	t.clock.changed.Broadcast()
+}
@@ -0,0 +1,111 @@
+package ringtest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestClock(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"advance":          clockAdvance,
+		"ticks":            clockTicks,
+		"drops late ticks": clockDropsLateTicks,
+		"stopped tickers":  clockStoppedTickers,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// an arbitrary start time for the clocks.
+var epoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// asserts that the channel has a tick at the given time ready, or no
+// tick if want is the zero time.
+func assertTick(t *testing.T, c <-chan time.Time, want time.Time) {
+	t.Helper()
+
+	select {
+	case got := <-c:
+		if want.IsZero() {
+			t.Errorf("unexpected tick at %v", got)
+		} else if !got.Equal(want) {
+			t.Errorf("want tick at %v, got %v", want, got)
+		}
+	default:
+		if !want.IsZero() {
+			t.Errorf("want tick at %v, got none", want)
+		}
+	}
+}
+
+// tests that the time only changes when advanced.
+func clockAdvance(t *testing.T) {
+	c := ringtest.NewClock(epoch)
+
+	if got := c.Now(); !got.Equal(epoch) {
+		t.Errorf("want %v, got %v", epoch, got)
+	}
+
+	c.Advance(time.Hour)
+
+	if got := c.Now(); !got.Equal(epoch.Add(time.Hour)) {
+		t.Errorf("want %v, got %v", epoch.Add(time.Hour), got)
+	}
+}
+
+// tests that tickers tick when the clock advances past their interval.
+func clockTicks(t *testing.T) {
+	c := ringtest.NewClock(epoch)
+	tk := c.NewTicker(time.Second)
+
+	defer tk.Stop()
+
+	c.WaitTickers(1)
+
+	c.Advance(500 * time.Millisecond)
+	assertTick(t, tk.C(), time.Time{})
+
+	c.Advance(500 * time.Millisecond)
+	assertTick(t, tk.C(), epoch.Add(time.Second))
+
+	c.Advance(1500 * time.Millisecond)
+	assertTick(t, tk.C(), epoch.Add(2500*time.Millisecond))
+
+	c.Advance(500 * time.Millisecond)
+	assertTick(t, tk.C(), epoch.Add(3*time.Second))
+}
+
+// tests that ticks are dropped if the previous one was not received.
+func clockDropsLateTicks(t *testing.T) {
+	c := ringtest.NewClock(epoch)
+	tk := c.NewTicker(time.Second)
+
+	defer tk.Stop()
+
+	c.Advance(time.Second)
+	c.Advance(time.Second)
+
+	assertTick(t, tk.C(), epoch.Add(time.Second))
+	assertTick(t, tk.C(), time.Time{})
+}
+
+// tests that stopped tickers do not tick.
+func clockStoppedTickers(t *testing.T) {
+	c := ringtest.NewClock(epoch)
+	tk := c.NewTicker(time.Second)
+
+	tk.Stop()
+	c.Advance(time.Minute)
+
+	assertTick(t, tk.C(), time.Time{})
+}
@@ -0,0 +1,313 @@
+package ringtest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alcortesm/ring"
+)
+
+// OpKind is the kind of an operation in a history.
+type OpKind int
+
+const (
+	// OpInsert is a call to Insert.
+	OpInsert OpKind = iota
+	// OpExtract is a call to Extract.
+	OpExtract
+	// OpPeek is a call to Peek.
+	OpPeek
+	// OpLen is a call to Len.
+	OpLen
+)
+
+func (k OpKind) String() string {
+	switch k {
+	case OpInsert:
+		return "insert"
+	case OpExtract:
+		return "extract"
+	case OpPeek:
+		return "peek"
+	case OpLen:
+		return "len"
+	default:
+		return fmt.Sprintf("OpKind(%d)", int(k))
+	}
+}
+
+// Op is an operation on a buffer, as recorded in a history.
+type Op struct {
+	Kind   OpKind
+	Worker int         // goroutine performing the operation
+	Value  interface{} // inserted, or returned by Extract and Peek
+	OK     bool        // returned by Extract and Peek
+	Len    int         // returned by Len
+	// logical times of the call and the return of the operation, from a
+	// clock shared by all the workers
+	Call, Return int64
+}
+
+func (o Op) String() string {
+	var result string
+
+	switch o.Kind {
+	case OpInsert:
+		result = fmt.Sprintf("insert(%v)", o.Value)
+	case OpExtract, OpPeek:
+		result = fmt.Sprintf("%v() = %v, %t", o.Kind, o.Value, o.OK)
+	case OpLen:
+		result = fmt.Sprintf("len() = %d", o.Len)
+	default:
+		result = o.Kind.String()
+	}
+
+	return fmt.Sprintf("worker %d [%d, %d]: %s", o.Worker, o.Call, o.Return, result)
+}
+
+// RandomHistory runs workers goroutines concurrently on b, each of them
+// performing ops random operations, and returns the history of all the
+// operations, sorted by call time.
+//
+// The operations are chosen from rnd before starting the workers, and
+// the inserted elements are distinct integers, so the same seed repeats
+// the same operations, although not their interleaving.
+func RandomHistory(b ring.Buffer, workers, ops int, rnd *rand.Rand) []Op {
+	plans := make([][]Op, workers)
+	next := 0
+
+	for w := range plans {
+		plans[w] = make([]Op, ops)
+
+		for i := range plans[w] {
+			op := Op{Worker: w}
+
+			switch n := rnd.Intn(100); {
+			case n < 40:
+				op.Kind = OpInsert
+				op.Value = next
+				next++
+			case n < 70:
+				op.Kind = OpExtract
+			case n < 85:
+				op.Kind = OpPeek
+			default:
+				op.Kind = OpLen
+			}
+
+			plans[w][i] = op
+		}
+	}
+
+	var (
+		clock int64
+		wg    sync.WaitGroup
+	)
+
+	for _, plan := range plans {
+		wg.Add(1)
+
+		go func(plan []Op) {
+			defer wg.Done()
+
+			for i := range plan {
+				op := &plan[i]
+				op.Call = atomic.AddInt64(&clock, 1)
+
+				switch op.Kind {
+				case OpInsert:
+					b.Insert(op.Value)
+				case OpExtract:
+					op.Value, op.OK = b.Extract()
+				case OpPeek:
+					op.Value, op.OK = b.Peek()
+				case OpLen:
+					op.Len = b.Len()
+				}
+
+				op.Return = atomic.AddInt64(&clock, 1)
+			}
+		}(plan)
+	}
+
+	wg.Wait()
+
+	var result []Op
+	for _, plan := range plans {
+		result = append(result, plan...)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Call < result[j].Call
+	})
+
+	return result
+}
+
+// CheckLinearizable checks that a history of operations on an initially
+// empty buffer with the given capacity is linearizable with respect to
+// Model: that each operation can be seen as taking effect atomically at
+// some point between its call and its return, returning what the model
+// would.  Otherwise, it returns an error listing the history.
+//
+// The search is exponential in the worst case, so histories should be
+// kept to a few dozens of operations.
+func CheckLinearizable(cap int, history []Op) error {
+	sorted := append([]Op(nil), history...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Call < sorted[j].Call
+	})
+
+	c := checker{
+		history: sorted,
+		done:    make([]bool, len(sorted)),
+		seen:    make(map[string]bool),
+	}
+
+	if c.search(NewModel(cap), len(sorted)) {
+		return nil
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "history not linearizable with capacity %d:", cap)
+
+	for _, op := range sorted {
+		fmt.Fprintf(&b, "\n\t%v", op)
+	}
+
+	return fmt.Errorf("%s", b.String())
+}
+
+// a depth-first search of a linearization of a history, remembering the
+// states already explored, as in the algorithm by Wing and Gong, with
+// the memoization by Lowe.
+type checker struct {
+	history []Op            // sorted by call time
+	done    []bool          // operations already linearized
+	seen    map[string]bool // explored states, as returned by key
+}
+
+// reports if the operations not done yet can be linearized, starting
+// from the given state of the model.
+func (c *checker) search(m *Model, left int) bool {
+	if left == 0 {
+		return true
+	}
+
+	key := c.key(m)
+	if c.seen[key] {
+		return false
+	}
+
+	c.seen[key] = true
+
+	// only the operations called before any pending one returned can go
+	// next
+	minReturn := int64(math.MaxInt64)
+
+	for i, op := range c.history {
+		if !c.done[i] && op.Return < minReturn {
+			minReturn = op.Return
+		}
+	}
+
+	for i, op := range c.history {
+		if op.Call > minReturn {
+			break
+		}
+
+		if c.done[i] {
+			continue
+		}
+
+		next := m.clone()
+		if !apply(next, op) {
+			continue
+		}
+
+		c.done[i] = true
+		ok := c.search(next, left-1)
+		c.done[i] = false
+
+		if ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// returns a key identifying the operations done and the state of the
+// model.
+func (c *checker) key(m *Model) string {
+	var b strings.Builder
+
+	for _, done := range c.done {
+		if done {
+			b.WriteByte('1')
+		} else {
+			b.WriteByte('0')
+		}
+	}
+
+	fmt.Fprintf(&b, "%#v", m.elems)
+
+	return b.String()
+}
+
+// applies op to m, and reports if the model returns the same as the
+// recorded operation.
+func apply(m *Model, op Op) bool {
+	switch op.Kind {
+	case OpInsert:
+		m.Insert(op.Value)
+		return true
+	case OpExtract:
+		v, ok := m.Extract()
+		return ok == op.OK && (!ok || reflect.DeepEqual(v, op.Value))
+	case OpPeek:
+		v, ok := m.Peek()
+		return ok == op.OK && (!ok || reflect.DeepEqual(v, op.Value))
+	case OpLen:
+		return m.Len() == op.Len
+	default:
+		return false
+	}
+}
+
+// the size of the histories checked by AssertLinearizable.
+const (
+	linearizeWorkers = 4
+	linearizeOps     = 6
+)
+
+// AssertLinearizable asserts that the buffers returned by newBuffer
+// behave like Model under concurrent use, by checking the random
+// histories of several goroutines using them, see RandomHistory and
+// CheckLinearizable.  It checks rounds histories, each of them on a new
+// buffer, which must be empty, with the rounds as seeds.
+//
+// Small capacities, smaller than the number of elements inserted, also
+// check the dropping of the oldest elements.
+func AssertLinearizable(t testing.TB, newBuffer func() ring.Buffer, rounds int) {
+	t.Helper()
+
+	for seed := 0; seed < rounds; seed++ {
+		b := newBuffer()
+		rnd := rand.New(rand.NewSource(int64(seed)))
+		history := RandomHistory(b, linearizeWorkers, linearizeOps, rnd)
+
+		if err := CheckLinearizable(b.Cap(), history); err != nil {
+			t.Errorf("seed %d: %v", seed, err)
+			return
+		}
+	}
+}
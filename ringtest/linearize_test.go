@@ -0,0 +1,191 @@
+package ringtest_test
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestLinearizable(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"sequential history":     linearizableSequentialHistory,
+		"overlapping operations": linearizableOverlappingOperations,
+		"wrong order":            linearizableWrongOrder,
+		"unknown element":        linearizableUnknownElement,
+		"random history":         linearizableRandomHistory,
+		"ring":                   linearizableRing,
+		"broken buffer":          linearizableBrokenBuffer,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns an operation of the given worker, called and returned at the
+// given logical times.
+func op(worker int, call, ret int64, kind ringtest.OpKind, v interface{}, ok bool) ringtest.Op {
+	return ringtest.Op{
+		Kind:   kind,
+		Worker: worker,
+		Value:  v,
+		OK:     ok,
+		Call:   call,
+		Return: ret,
+	}
+}
+
+// tests that sequential histories following the model are linearizable.
+func linearizableSequentialHistory(t *testing.T) {
+	history := []ringtest.Op{
+		op(0, 1, 2, ringtest.OpInsert, 1, false),
+		op(0, 3, 4, ringtest.OpInsert, 2, false),
+		op(0, 5, 6, ringtest.OpInsert, 3, false), // drops 1
+		op(0, 7, 8, ringtest.OpPeek, 2, true),
+		{Kind: ringtest.OpLen, Len: 2, Call: 9, Return: 10},
+		op(0, 11, 12, ringtest.OpExtract, 2, true),
+		op(0, 13, 14, ringtest.OpExtract, 3, true),
+		op(0, 15, 16, ringtest.OpExtract, nil, false),
+	}
+
+	if err := ringtest.CheckLinearizable(2, history); err != nil {
+		t.Error(err)
+	}
+}
+
+// tests that overlapping operations can take effect in any order.
+func linearizableOverlappingOperations(t *testing.T) {
+	// the extraction overlaps both insertions, and sees the second one
+	// first
+	history := []ringtest.Op{
+		op(0, 1, 4, ringtest.OpInsert, 1, false),
+		op(1, 2, 6, ringtest.OpExtract, 2, true),
+		op(2, 3, 5, ringtest.OpInsert, 2, false),
+	}
+
+	if err := ringtest.CheckLinearizable(2, history); err != nil {
+		t.Error(err)
+	}
+}
+
+// tests that extracting elements out of order is not linearizable.
+func linearizableWrongOrder(t *testing.T) {
+	history := []ringtest.Op{
+		op(0, 1, 2, ringtest.OpInsert, 1, false),
+		op(0, 3, 4, ringtest.OpInsert, 2, false),
+		op(1, 5, 6, ringtest.OpExtract, 2, true),
+	}
+
+	if err := ringtest.CheckLinearizable(2, history); err == nil {
+		t.Error("unexpected success")
+	}
+}
+
+// tests that extracting elements never inserted is not linearizable.
+func linearizableUnknownElement(t *testing.T) {
+	history := []ringtest.Op{
+		op(0, 1, 4, ringtest.OpInsert, 1, false),
+		op(1, 2, 3, ringtest.OpPeek, 7, true),
+	}
+
+	if err := ringtest.CheckLinearizable(1, history); err == nil {
+		t.Error("unexpected success")
+	}
+}
+
+// tests that random histories have all the planned operations, sorted
+// by call time.
+func linearizableRandomHistory(t *testing.T) {
+	r := ringtest.New(t, 3)
+	history := ringtest.RandomHistory(r, 3, 5, rand.New(rand.NewSource(1)))
+
+	if got := len(history); got != 15 {
+		t.Fatalf("want 15 operations, got %d", got)
+	}
+
+	for i, op := range history {
+		if op.Return <= op.Call {
+			t.Errorf("operation %d returned before its call: %v", i, op)
+		}
+
+		if i > 0 && op.Call <= history[i-1].Call {
+			t.Errorf("operation %d not sorted by call time: %v", i, op)
+		}
+	}
+}
+
+// tests that rings are linearizable, with and without dropping elements.
+func linearizableRing(t *testing.T) {
+	for _, cap := range []int{1, 3, 100} {
+		cap := cap
+
+		ringtest.AssertLinearizable(t, func() ring.Buffer {
+			return ringtest.New(t, cap)
+		}, 50)
+	}
+}
+
+// stack is a buffer returning the newest element instead of the oldest.
+type stack struct {
+	mu    sync.Mutex
+	elems []interface{}
+}
+
+func (s *stack) Insert(v interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.elems = append(s.elems, v)
+}
+
+func (s *stack) Extract() (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.elems) == 0 {
+		return nil, false
+	}
+
+	v := s.elems[len(s.elems)-1]
+	s.elems = s.elems[:len(s.elems)-1]
+
+	return v, true
+}
+
+func (s *stack) Peek() (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.elems) == 0 {
+		return nil, false
+	}
+
+	return s.elems[len(s.elems)-1], true
+}
+
+func (s *stack) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.elems)
+}
+
+func (s *stack) Cap() int { return 100 }
+
+// tests that buffers not behaving like the model are detected.
+func linearizableBrokenBuffer(t *testing.T) {
+	assertFailures(t, func(tb testing.TB) {
+		ringtest.AssertLinearizable(tb, func() ring.Buffer {
+			return &stack{}
+		}, 50)
+	}, "not linearizable", "worker")
+}
@@ -0,0 +1,85 @@
+package ringtest
+
+import (
+	"fmt"
+
+	"github.com/alcortesm/ring"
+)
+
+// Model is a sequential reference implementation of a ring, holding its
+// elements in a plain slice.  It is deliberately naive, to be obviously
+// correct, and it is not safe for concurrent use.
+//
+// It is meant as the specification other buffers are checked against,
+// see CheckLinearizable.
+type Model struct {
+	cap   int
+	elems []interface{} // from oldest to newest
+}
+
+var _ ring.Buffer = (*Model)(nil)
+
+// NewModel returns an empty model of a ring with the given capacity,
+// which must be greater than 0.
+func NewModel(cap int) *Model {
+	if cap < 1 {
+		panic(fmt.Sprintf("ringtest: model capacity must be > 0, got %d", cap))
+	}
+
+	return &Model{cap: cap}
+}
+
+// Insert adds a new element to the model, dropping the oldest one if
+// the model is full.
+func (m *Model) Insert(v interface{}) {
+	if len(m.elems) == m.cap {
+		m.elems = m.elems[1:]
+	}
+
+	m.elems = append(m.elems, v)
+}
+
+// Extract extracts and returns the oldest element in the model.
+func (m *Model) Extract() (interface{}, bool) {
+	if len(m.elems) == 0 {
+		return nil, false
+	}
+
+	v := m.elems[0]
+	m.elems = m.elems[1:]
+
+	return v, true
+}
+
+// Peek returns the oldest element in the model.
+func (m *Model) Peek() (interface{}, bool) {
+	if len(m.elems) == 0 {
+		return nil, false
+	}
+
+	return m.elems[0], true
+}
+
+// Len returns the amount of elements in the model.
+func (m *Model) Len() int {
+	return len(m.elems)
+}
+
+// Cap returns the capacity of the model.
+func (m *Model) Cap() int {
+	return m.cap
+}
+
+// ToSlice returns the elements in the model, from oldest to newest,
+// without extracting them.
+func (m *Model) ToSlice() []interface{} {
+	return append([]interface{}(nil), m.elems...)
+}
+
+// returns an independent copy of the model.
+func (m *Model) clone() *Model {
+	return &Model{
+		cap:   m.cap,
+		elems: append([]interface{}(nil), m.elems...),
+	}
+}
@@ -0,0 +1,63 @@
+package ringtest_test
+
+import (
+	"testing"
+
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestModel(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"fifo":             modelFIFO,
+		"drops oldest":     modelDropsOldest,
+		"invalid capacity": modelInvalidCapacity,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// tests that the model extracts its elements in insertion order.
+func modelFIFO(t *testing.T) {
+	m := ringtest.NewModel(3)
+	ringtest.AssertEmpty(t, m)
+
+	ringtest.Fill(m, 1, 2)
+	ringtest.AssertLen(t, m, 2)
+
+	if v, ok := m.Peek(); !ok || v != 1 {
+		t.Errorf("want to peek 1, got %v, %t", v, ok)
+	}
+
+	ringtest.AssertDrain(t, m, 1, 2)
+}
+
+// tests that a full model drops its oldest element.
+func modelDropsOldest(t *testing.T) {
+	m := ringtest.NewModel(2)
+	ringtest.Fill(m, 1, 2, 3)
+
+	if got := m.Cap(); got != 2 {
+		t.Errorf("want capacity 2, got %d", got)
+	}
+
+	ringtest.AssertDrain(t, m, 2, 3)
+}
+
+// tests that capacities smaller than 1 panic.
+func modelInvalidCapacity(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want panic, got none")
+		}
+	}()
+
+	ringtest.NewModel(0)
+}
@@ -0,0 +1,156 @@
+/*
+Package ringtest provides helpers to test code using rings: builders of
+pre-filled rings, assertions on their contents, and a manual clock to
+test their time-based features without sleeping.
+
+It also provides Model, a sequential reference implementation of a
+ring, and a checker of the linearizability of concurrent histories
+against it, to validate other implementations of ring.Buffer under
+concurrent use, see AssertLinearizable.
+
+The helpers take a testing.TB, so they work both in tests and
+benchmarks, and report failures as coming from the caller.
+*/
+package ringtest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+// New returns a ring with the given capacity, and the given elements
+// inserted in order, or fails the test.
+func New(t testing.TB, cap int, vs ...interface{}) *ring.Ring {
+	t.Helper()
+
+	r, err := ring.New(cap)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	Fill(r, vs...)
+
+	return r
+}
+
+// NewRange returns a ring with the given capacity, and the integers from
+// 0 to n-1 inserted in order, or fails the test.  Only the last cap of
+// them are kept if n is greater than the capacity.
+func NewRange(t testing.TB, cap, n int) *ring.Ring {
+	t.Helper()
+
+	r := New(t, cap)
+
+	for i := 0; i < n; i++ {
+		r.Insert(i)
+	}
+
+	return r
+}
+
+// Fill inserts the given elements in b, in order, for example to fill
+// rings created with options.
+func Fill(b ring.Buffer, vs ...interface{}) {
+	for _, v := range vs {
+		b.Insert(v)
+	}
+}
+
+// AssertContents asserts that r holds the wanted elements, from oldest
+// to newest, without extracting them.  Elements are compared with
+// reflect.DeepEqual.
+func AssertContents(t testing.TB, r *ring.Ring, want ...interface{}) {
+	t.Helper()
+
+	got := r.ToSlice()
+	if len(want) == 0 && len(got) == 0 {
+		return
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want contents %v, got %v", want, got)
+	}
+}
+
+// AssertDrain asserts that extracting from b returns the wanted
+// elements, in order, and then that b is empty.  Elements are compared
+// with reflect.DeepEqual.
+func AssertDrain(t testing.TB, b ring.Buffer, want ...interface{}) {
+	t.Helper()
+
+	for i, w := range want {
+		got, ok := b.Extract()
+		if !ok {
+			t.Errorf("want element %d to be %v, got an empty ring", i, w)
+			return
+		}
+
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("want element %d to be %v, got %v", i, w, got)
+		}
+	}
+
+	if v, ok := b.Extract(); ok {
+		t.Errorf("want empty ring after %d elements, extracted %v", len(want), v)
+	}
+}
+
+// AssertLen asserts that b holds the wanted number of elements.
+func AssertLen(t testing.TB, b ring.Buffer, want int) {
+	t.Helper()
+
+	if got := b.Len(); got != want {
+		t.Errorf("want length %d, got %d", want, got)
+	}
+}
+
+// AssertEmpty asserts that b holds no elements, without extracting any.
+func AssertEmpty(t testing.TB, b ring.Buffer) {
+	t.Helper()
+
+	if n := b.Len(); n != 0 {
+		t.Errorf("want empty ring, got length %d", n)
+	}
+
+	if v, ok := b.Peek(); ok {
+		t.Errorf("want empty ring, peeked %v", v)
+	}
+}
+
+// the interval between checks of AssertEventually, besides the ones
+// after each insertion.
+const pollEvery = 5 * time.Millisecond
+
+// AssertEventually asserts that pred becomes true for r before the
+// timeout, for example when r is filled by another goroutine.  The
+// predicate is checked after each insertion in r, and periodically, to
+// also notice extractions.
+func AssertEventually(t testing.TB, r *ring.Ring, pred func(r *ring.Ring) bool, timeout time.Duration) {
+	t.Helper()
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	ticker := time.NewTicker(pollEvery)
+	defer ticker.Stop()
+
+	for {
+		// before checking, to not miss insertions in the meantime
+		inserted := r.NextInsert()
+
+		if pred(r) {
+			return
+		}
+
+		select {
+		case <-inserted:
+		case <-ticker.C:
+		case <-deadline.C:
+			t.Errorf("condition not met after %v, ring holds %v", timeout, r.ToSlice())
+			return
+		}
+	}
+}
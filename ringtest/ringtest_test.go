@@ -0,0 +1,200 @@
+package ringtest_test
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestRingtest(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"new":                   ringtestNew,
+		"new invalid capacity":  ringtestNewInvalidCapacity,
+		"new range":             ringtestNewRange,
+		"assert contents":       ringtestAssertContents,
+		"assert drain":          ringtestAssertDrain,
+		"assert len and empty":  ringtestAssertLenAndEmpty,
+		"eventually met":        ringtestEventuallyMet,
+		"eventually by extract": ringtestEventuallyByExtract,
+		"eventually timeout":    ringtestEventuallyTimeout,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// recorder is a testing.TB recording the failures of the helpers.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// runs fn with a recorder, in its own goroutine so it can be stopped by
+// Fatalf, and returns the recorded failures.
+func record(t *testing.T, fn func(tb testing.TB)) []string {
+	rec := &recorder{TB: t}
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		fn(rec)
+	}()
+
+	<-done
+
+	return rec.failures
+}
+
+// asserts that running fn fails with a message containing each of the
+// wanted strings, or that it does not fail if there are none.
+func assertFailures(t *testing.T, fn func(tb testing.TB), want ...string) {
+	t.Helper()
+
+	got := record(t, fn)
+
+	if len(want) == 0 && len(got) > 0 {
+		t.Errorf("unexpected failures %q", got)
+		return
+	}
+
+	if len(want) > 0 && len(got) == 0 {
+		t.Errorf("want failures %q, got none", want)
+		return
+	}
+
+	msg := strings.Join(got, "\n")
+
+	for _, w := range want {
+		if !strings.Contains(msg, w) {
+			t.Errorf("want failure containing %q, got %q", w, got)
+		}
+	}
+}
+
+// tests that New returns a ring with the given elements.
+func ringtestNew(t *testing.T) {
+	r := ringtest.New(t, 2, "a", "b", "c")
+
+	if r.Cap() != 2 {
+		t.Errorf("want capacity 2, got %d", r.Cap())
+	}
+
+	ringtest.AssertContents(t, r, "b", "c")
+}
+
+// tests that New fails the test for invalid capacities.
+func ringtestNewInvalidCapacity(t *testing.T) {
+	assertFailures(t, func(tb testing.TB) { ringtest.New(tb, 0) }, "creating ring")
+}
+
+// tests that NewRange returns a ring with the last integers.
+func ringtestNewRange(t *testing.T) {
+	ringtest.AssertContents(t, ringtest.NewRange(t, 3, 5), 2, 3, 4)
+	ringtest.AssertContents(t, ringtest.NewRange(t, 3, 0))
+}
+
+// tests that AssertContents compares the contents deeply, without
+// extracting them.
+func ringtestAssertContents(t *testing.T) {
+	r := ringtest.New(t, 3, []int{1}, "x")
+
+	assertFailures(t, func(tb testing.TB) { ringtest.AssertContents(tb, r, []int{1}, "x") })
+	assertFailures(t, func(tb testing.TB) { ringtest.AssertContents(tb, r, "x") }, "want contents [x]")
+
+	ringtest.AssertLen(t, r, 2)
+}
+
+// tests that AssertDrain extracts the elements and checks the ring ends
+// empty.
+func ringtestAssertDrain(t *testing.T) {
+	assertFailures(t, func(tb testing.TB) {
+		ringtest.AssertDrain(tb, ringtest.New(tb, 3, 1, 2), 1, 2)
+	})
+
+	assertFailures(t, func(tb testing.TB) {
+		ringtest.AssertDrain(tb, ringtest.New(tb, 3, 1, 2), 1)
+	}, "want empty ring after 1 elements, extracted 2")
+
+	assertFailures(t, func(tb testing.TB) {
+		ringtest.AssertDrain(tb, ringtest.New(tb, 3, 1), 1, 2)
+	}, "want element 1 to be 2, got an empty ring")
+
+	assertFailures(t, func(tb testing.TB) {
+		ringtest.AssertDrain(tb, ringtest.New(tb, 3, 1), 3)
+	}, "want element 0 to be 3, got 1")
+}
+
+// tests AssertLen and AssertEmpty.
+func ringtestAssertLenAndEmpty(t *testing.T) {
+	r := ringtest.New(t, 3, 1)
+
+	assertFailures(t, func(tb testing.TB) { ringtest.AssertLen(tb, r, 2) }, "want length 2, got 1")
+	assertFailures(t, func(tb testing.TB) { ringtest.AssertEmpty(tb, r) }, "got length 1", "peeked 1")
+
+	r.Extract()
+
+	assertFailures(t, func(tb testing.TB) { ringtest.AssertEmpty(tb, r) })
+}
+
+// tests that AssertEventually waits for the insertions of another
+// goroutine.
+func ringtestEventuallyMet(t *testing.T) {
+	r := ringtest.New(t, 10)
+
+	go func() {
+		for i := 0; i < 5; i++ {
+			time.Sleep(time.Millisecond)
+			r.Insert(i)
+		}
+	}()
+
+	assertFailures(t, func(tb testing.TB) {
+		ringtest.AssertEventually(tb, r, func(r *ring.Ring) bool { return r.Len() == 5 }, 5*time.Second)
+	})
+}
+
+// tests that AssertEventually notices extractions.
+func ringtestEventuallyByExtract(t *testing.T) {
+	r := ringtest.New(t, 10, 1)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		r.Extract()
+	}()
+
+	assertFailures(t, func(tb testing.TB) {
+		ringtest.AssertEventually(tb, r, func(r *ring.Ring) bool { return r.Len() == 0 }, 5*time.Second)
+	})
+}
+
+// tests that AssertEventually fails after the timeout.
+func ringtestEventuallyTimeout(t *testing.T) {
+	r := ringtest.New(t, 10, "stuck")
+
+	assertFailures(t, func(tb testing.TB) {
+		ringtest.AssertEventually(tb, r, func(r *ring.Ring) bool { return r.Len() == 0 }, 20*time.Millisecond)
+	}, "condition not met after 20ms, ring holds [stuck]")
+}
@@ -0,0 +1,310 @@
+/*
+Package ringws provides an HTTP handler pushing the elements of a ring
+to WebSocket clients as they are inserted, for example to watch it live
+from a dashboard.
+
+The handler implements the subset of the WebSocket protocol, RFC 6455,
+needed for a server that only pushes messages: it sends each element as
+a text message and answers the pings and close requests of the clients,
+discarding any other message they send.
+*/
+package ringws
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/alcortesm/ring"
+)
+
+// the GUID the accept key of the handshake is derived with.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// frame opcodes.
+const (
+	opText  = 0x1
+	opClose = 0x8
+	opPing  = 0x9
+	opPong  = 0xA
+)
+
+// the maximum payload of a control frame.
+const maxControl = 125
+
+// Handler returns an HTTP handler that upgrades requests to WebSocket
+// connections, sends them the current contents of the ring r, from
+// oldest to newest, and then each new element as it is inserted, until
+// the client closes the connection or the request context is done.
+//
+// Each element is sent as a text message holding the result of
+// encode.  If encode is nil, elements are encoded as JSON.
+func Handler(r *ring.Ring, encode func(v interface{}) ([]byte, error)) http.Handler {
+	if encode == nil {
+		encode = json.Marshal
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		key, err := checkHandshake(req)
+		if err != nil {
+			w.Header().Set("Sec-WebSocket-Version", "13")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			http.Error(w, "connection cannot be hijacked", http.StatusInternalServerError)
+			return
+		}
+
+		conn, brw, err := hj.Hijack()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		defer conn.Close()
+
+		c := &wsConn{conn: conn, bw: brw.Writer}
+
+		if err := c.accept(key); err != nil {
+			return
+		}
+
+		ctx, cancel := context.WithCancel(req.Context())
+		defer cancel()
+
+		go func() {
+			_ = c.readLoop(brw.Reader)
+			cancel()
+		}()
+
+		_ = r.Follow(ctx, 0, func(_ uint64, v interface{}) error {
+			p, err := encode(v)
+			if err != nil {
+				return fmt.Errorf("encoding element: %w", err)
+			}
+
+			return c.writeFrame(opText, p)
+		})
+
+		// tell the client why, unless it asked to close the connection
+		code := uint16(1001) // going away
+		if ctx.Err() == nil {
+			code = 1011 // internal error
+		}
+
+		_ = c.close(code)
+	})
+}
+
+// checks the handshake request and returns its key.
+func checkHandshake(req *http.Request) (string, error) {
+	if req.Method != http.MethodGet {
+		return "", errors.New("websocket handshake must be a GET request")
+	}
+
+	if !headerContains(req.Header, "Connection", "upgrade") ||
+		!headerContains(req.Header, "Upgrade", "websocket") {
+		return "", errors.New("not a websocket handshake")
+	}
+
+	if v := req.Header.Get("Sec-WebSocket-Version"); v != "13" {
+		return "", fmt.Errorf("unsupported websocket version %q", v)
+	}
+
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return "", errors.New("missing websocket key")
+	}
+
+	return key, nil
+}
+
+// reports whether the comma separated values of the header include
+// token, ignoring case.
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h[http.CanonicalHeaderKey(name)] {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// returns the accept key of the handshake response for the given key.
+func acceptKey(key string) string {
+	h := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// a websocket connection, server side.
+type wsConn struct {
+	conn net.Conn
+
+	mu     sync.Mutex // serializes writes
+	bw     *bufio.Writer
+	closed bool // a close frame was sent
+}
+
+// writes the handshake response.
+func (c *wsConn) accept(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fmt.Fprintf(c.bw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", acceptKey(key))
+
+	return c.bw.Flush()
+}
+
+// writes an unfragmented frame.
+func (c *wsConn) writeFrame(op byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return errors.New("websocket closed")
+	}
+
+	if op == opClose {
+		c.closed = true
+	}
+
+	header := []byte{0x80 | op, 0}
+
+	switch n := len(payload); {
+	case n <= 125:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = append(header, byte(n>>8), byte(n))
+	default:
+		header[1] = 127
+
+		var b [8]byte
+
+		binary.BigEndian.PutUint64(b[:], uint64(n))
+		header = append(header, b[:]...)
+	}
+
+	_, _ = c.bw.Write(header)
+	_, _ = c.bw.Write(payload)
+
+	return c.bw.Flush()
+}
+
+// sends a close frame with the given status code.
+func (c *wsConn) close(code uint16) error {
+	var b [2]byte
+
+	binary.BigEndian.PutUint16(b[:], code)
+
+	return c.writeFrame(opClose, b[:])
+}
+
+// reads the frames sent by the client, answering pings and close
+// requests and discarding the rest, until the client closes the
+// connection.
+func (c *wsConn) readLoop(br *bufio.Reader) error {
+	for {
+		op, payload, err := readFrame(br)
+		if err != nil {
+			return err
+		}
+
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return err
+			}
+		case opClose:
+			// echo the status code, if any
+			if len(payload) > 2 {
+				payload = payload[:2]
+			}
+
+			_ = c.writeFrame(opClose, payload)
+
+			return io.EOF
+		}
+	}
+}
+
+// reads a frame from the client, returning its opcode and payload.
+// The payload of data frames is discarded, as the server ignores them.
+func readFrame(br *bufio.Reader) (byte, []byte, error) {
+	var header [2]byte
+
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	op := header[0] & 0x0F
+
+	if header[1]&0x80 == 0 {
+		return 0, nil, errors.New("unmasked client frame")
+	}
+
+	n := uint64(header[1] & 0x7F)
+
+	switch n {
+	case 126:
+		var b [2]byte
+		if _, err := io.ReadFull(br, b[:]); err != nil {
+			return 0, nil, err
+		}
+
+		n = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err := io.ReadFull(br, b[:]); err != nil {
+			return 0, nil, err
+		}
+
+		n = binary.BigEndian.Uint64(b[:])
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(br, mask[:]); err != nil {
+		return 0, nil, err
+	}
+
+	if op < opClose {
+		_, err := io.CopyN(ioutil.Discard, br, int64(n))
+		return op, nil, err
+	}
+
+	if n > maxControl {
+		return 0, nil, errors.New("control frame too long")
+	}
+
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(br, payload); err != nil {
+		return 0, nil, err
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return op, payload, nil
+}
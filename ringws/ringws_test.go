@@ -0,0 +1,213 @@
+package ringws_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringws"
+)
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"not a handshake": handlerNotAHandshake,
+		"replay and live": handlerReplayAndLive,
+		"ping":            handlerPing,
+		"close":           handlerClose,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a ring with the given capacity and elements or fails the
+// test.
+func newRing(t *testing.T, cap int, vs ...interface{}) *ring.Ring {
+	t.Helper()
+
+	r, err := ring.New(cap)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	for _, v := range vs {
+		r.Insert(v)
+	}
+
+	return r
+}
+
+// a websocket client connection.
+type client struct {
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+// serves the handler of r and connects to it, failing the test if the
+// handshake fails.  The connection and the server are closed at the end
+// of the test.
+func dial(t *testing.T, r *ring.Ring) *client {
+	t.Helper()
+
+	srv := httptest.NewServer(ringws.Handler(r, nil))
+	t.Cleanup(srv.Close)
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatalf("dialing: %v", err)
+	}
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	// the example key of RFC 6455
+	_, err = io.WriteString(conn, "GET / HTTP/1.1\r\n"+
+		"Host: example.com\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: keep-alive, Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n")
+	if err != nil {
+		t.Fatalf("sending handshake: %v", err)
+	}
+
+	br := bufio.NewReader(conn)
+
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("reading handshake response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+
+	if got, want := resp.Header.Get("Sec-WebSocket-Accept"), "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="; got != want {
+		t.Fatalf("want accept key %q, got %q", want, got)
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	return &client{conn: conn, br: br}
+}
+
+// sends a masked frame or fails the test.
+func (c *client) send(t *testing.T, op byte, payload []byte) {
+	t.Helper()
+
+	mask := [4]byte{1, 2, 3, 4}
+	frame := []byte{0x80 | op, 0x80 | byte(len(payload))}
+	frame = append(frame, mask[:]...)
+
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	if _, err := c.conn.Write(frame); err != nil {
+		t.Fatalf("sending frame: %v", err)
+	}
+}
+
+// receives an unmasked frame and returns its opcode and payload, or
+// fails the test.
+func (c *client) receive(t *testing.T) (byte, string) {
+	t.Helper()
+
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		t.Fatalf("reading frame header: %v", err)
+	}
+
+	n := int(header[1] & 0x7F)
+	if n == 126 {
+		var b [2]byte
+		if _, err := io.ReadFull(c.br, b[:]); err != nil {
+			t.Fatalf("reading frame length: %v", err)
+		}
+
+		n = int(binary.BigEndian.Uint16(b[:]))
+	}
+
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		t.Fatalf("reading frame payload: %v", err)
+	}
+
+	return header[0] & 0x0F, string(payload)
+}
+
+// asserts that the next frame is a text message with the wanted
+// payload.
+func (c *client) assertText(t *testing.T, want string) {
+	t.Helper()
+
+	if op, got := c.receive(t); op != 0x1 || got != want {
+		t.Fatalf("want text message %s, got opcode %d and %s", want, op, got)
+	}
+}
+
+// tests that requests other than websocket handshakes are rejected.
+func handlerNotAHandshake(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ringws.Handler(newRing(t, 1), nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("want status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+// tests that clients receive the current contents and then the new
+// elements, JSON encoded.
+func handlerReplayAndLive(t *testing.T) {
+	r := newRing(t, 2, "a", "b", "c")
+	c := dial(t, r)
+
+	c.assertText(t, `"b"`)
+	c.assertText(t, `"c"`)
+
+	r.Insert(map[string]int{"d": 4})
+	c.assertText(t, `{"d":4}`)
+
+	r.Insert(strings.Repeat("x", 200))
+	c.assertText(t, `"`+strings.Repeat("x", 200)+`"`)
+}
+
+// tests that pings are answered.
+func handlerPing(t *testing.T) {
+	c := dial(t, newRing(t, 1))
+
+	c.send(t, 0x9, []byte("hello"))
+
+	if op, got := c.receive(t); op != 0xA || got != "hello" {
+		t.Fatalf("want pong with hello, got opcode %d and %q", op, got)
+	}
+}
+
+// tests that close requests are answered, and then the connection is
+// closed.
+func handlerClose(t *testing.T) {
+	c := dial(t, newRing(t, 1))
+
+	c.send(t, 0x8, []byte{0x03, 0xE8})
+
+	if op, got := c.receive(t); op != 0x8 || got != "\x03\xe8" {
+		t.Fatalf("want close with status 1000, got opcode %d and %q", op, got)
+	}
+
+	if _, err := c.br.ReadByte(); err != io.EOF {
+		t.Errorf("want connection closed, got %v", err)
+	}
+}
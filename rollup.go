@@ -0,0 +1,76 @@
+package ring
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// rolls up elements of a child ring into its parent.
+type rollup struct {
+	parent    *Ring
+	aggregate func(elements []interface{}) interface{}
+}
+
+// inserts the given elements, from oldest to newest, in the parent,
+// or their aggregate, if there is an aggregation function.
+func (u *rollup) add(elements []interface{}) {
+	if len(elements) == 0 {
+		return
+	}
+
+	if u.aggregate != nil {
+		u.parent.Insert(u.aggregate(elements))
+		return
+	}
+
+	for _, v := range elements {
+		u.parent.Insert(v)
+	}
+}
+
+// rolls up all the elements in the ring every given interval, and once
+// more when the ring is closed.
+func (r *Ring) rollupEvery(every time.Duration) {
+	defer r.done.Done()
+
+	ticker := r.clock.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			r.rollupAll()
+			return
+		case <-ticker.C():
+			r.rollupAll()
+		}
+	}
+}
+
+// extracts all the elements in the ring and rolls them up.
+func (r *Ring) rollupAll() {
+	r.mu.Lock()
+
+	elements := make([]interface{}, 0, r.len)
+
+	for {
+		v, ok := r.extract()
+		if !ok {
+			break
+		}
+
+		elements = append(elements, v)
+	}
+
+	r.unlock()
+
+	atomic.AddUint64(&r.extracted, uint64(len(elements)))
+
+	if r.observer != nil {
+		for _, v := range elements {
+			r.observer.OnExtract(v)
+		}
+	}
+
+	r.rollup.add(elements)
+}
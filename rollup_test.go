@@ -0,0 +1,139 @@
+package ring_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestRollup(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid arguments":   rollupInvalidArguments,
+		"overflow":            rollupOverflow,
+		"overflow aggregated": rollupOverflowAggregated,
+		"every interval":      rollupEveryInterval,
+		"when closing":        rollupWhenClosing,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a ring rolling up into parent or fails the test.
+func newChild(t *testing.T, cap int, parent *ring.Ring, every time.Duration,
+	aggregate func([]interface{}) interface{}) *ring.Ring {
+	t.Helper()
+
+	r, err := ring.New(cap, ring.WithRollup(parent, every, aggregate))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	t.Cleanup(func() { _ = r.Close() })
+
+	return r
+}
+
+// aggregates int elements by adding them.
+func sum(elements []interface{}) interface{} {
+	var total int
+	for _, v := range elements {
+		total += v.(int)
+	}
+
+	return total
+}
+
+// tests that rollups need a parent and a non-negative interval.
+func rollupInvalidArguments(t *testing.T) {
+	if _, err := ring.New(1, ring.WithRollup(nil, time.Second, sum)); err == nil {
+		t.Error("nil parent: unexpected success")
+	}
+
+	if _, err := ring.New(1, ring.WithRollup(newRing(t, 1), -time.Second, nil)); err == nil {
+		t.Error("negative interval: unexpected success")
+	}
+}
+
+// tests that dropped elements are inserted in the parent.
+func rollupOverflow(t *testing.T) {
+	parent := newRing(t, 10)
+	a := newChild(t, 2, parent, 0, nil)
+	b := newChild(t, 2, parent, 0, nil)
+
+	for i := 1; i <= 4; i++ {
+		a.Insert(i)
+		b.Insert(10 * i)
+	}
+
+	if got := fmt.Sprint(parent.ToSlice()); got != "[1 10 2 20]" {
+		t.Errorf("want parent [1 10 2 20], got %s", got)
+	}
+
+	if got := fmt.Sprint(a.ToSlice()); got != "[3 4]" {
+		t.Errorf("want child [3 4], got %s", got)
+	}
+}
+
+// tests that dropped elements are aggregated one by one.
+func rollupOverflowAggregated(t *testing.T) {
+	parent := newRing(t, 10)
+	child := newChild(t, 1, parent, 0, func(elements []interface{}) interface{} {
+		return fmt.Sprint(elements)
+	})
+
+	child.Insert(1)
+	child.Insert(2)
+	child.Insert(3)
+
+	if got := fmt.Sprint(parent.ToSlice()); got != "[[1] [2]]" {
+		t.Errorf("want parent [[1] [2]], got %s", got)
+	}
+}
+
+// tests that all the elements are rolled up periodically.
+func rollupEveryInterval(t *testing.T) {
+	parent := newRing(t, 10)
+	child := newChild(t, 10, parent, 10*time.Millisecond, sum)
+
+	child.Insert(1)
+	child.Insert(2)
+	child.Insert(3)
+
+	deadline := time.Now().Add(time.Second)
+	for parent.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	assertExtract(t, parent, 6)
+	assertEmpty(t, child)
+
+	if got := child.Stats().Extracted; got != 3 {
+		t.Errorf("want 3 extracted elements, got %d", got)
+	}
+}
+
+// tests that closing the ring rolls up its elements a last time.
+func rollupWhenClosing(t *testing.T) {
+	parent := newRing(t, 10)
+	child := newChild(t, 10, parent, time.Hour, sum)
+
+	child.Insert(1)
+	child.Insert(2)
+
+	if err := child.Close(); err != nil {
+		t.Fatalf("closing: %v", err)
+	}
+
+	assertExtract(t, parent, 3)
+	assertEmpty(t, parent)
+}
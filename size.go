@@ -0,0 +1,32 @@
+package ring
+
+import (
+	"time"
+	"unsafe"
+)
+
+// SizeBytes returns an estimate of the memory held by the ring, in
+// bytes: the ring itself, its buffers, and the memory referenced by its
+// elements, as estimated by sizer.  If sizer is nil, the memory
+// referenced by the elements is not taken into account.
+//
+// Elements held by the ring but also referenced elsewhere are counted
+// too, so the estimate is an upper bound of the memory that would be
+// freed by dropping the ring.
+func (r *Ring) SizeBytes(sizer func(v interface{}) int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	size := int(unsafe.Sizeof(*r))
+	size += r.buf.allocated() * int(unsafe.Sizeof(interface{}(nil)))
+	size += cap(r.times) * int(unsafe.Sizeof(time.Time{}))
+	size += cap(r.counts) * int(unsafe.Sizeof(0))
+
+	if sizer != nil {
+		for i := 0; i < r.len; i++ {
+			size += sizer(r.buf.get((r.head + i) % r.buf.size()))
+		}
+	}
+
+	return size
+}
@@ -0,0 +1,62 @@
+package ring_test
+
+import (
+	"testing"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestSizeBytes(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"grows with capacity": sizeBytesGrowsWithCapacity,
+		"options":             sizeBytesOptions,
+		"sizer":               sizeBytesSizer,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// tests that bigger rings take more memory.
+func sizeBytesGrowsWithCapacity(t *testing.T) {
+	small := newRing(t, 10).SizeBytes(nil)
+	big := newRing(t, 110).SizeBytes(nil)
+
+	// 16 bytes per element on 64 bit platforms, 8 on 32 bit ones
+	if diff := big - small; diff != 100*16 && diff != 100*8 {
+		t.Errorf("want 100 more elements to take 1600 or 800 bytes, got %d", diff)
+	}
+}
+
+// tests that options adding per element storage take more memory.
+func sizeBytesOptions(t *testing.T) {
+	plain := newRing(t, 10).SizeBytes(nil)
+
+	r, err := ring.New(10, ring.WithTimestamps())
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	if got := r.SizeBytes(nil); got <= plain {
+		t.Errorf("want timestamps to take memory, got %d bytes, %d without them", got, plain)
+	}
+}
+
+// tests that the memory referenced by the elements is estimated with
+// the sizer.
+func sizeBytesSizer(t *testing.T) {
+	r := newRing(t, 3, "a", "bb", "ccc", "dddd")
+
+	sizer := func(v interface{}) int { return len(v.(string)) }
+
+	if got, want := r.SizeBytes(sizer)-r.SizeBytes(nil), 9; got != want {
+		t.Errorf("want elements to take %d bytes, got %d", want, got)
+	}
+}
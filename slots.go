@@ -0,0 +1,158 @@
+package ring
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// WithSegments makes the ring store its elements in segments of the
+// given size, instead of in a single buffer allocated by New, for very
+// large capacities, in the tens of millions.  Segments are allocated
+// when elements are first stored in them, and released when all their
+// elements are extracted or dropped, so the memory of the ring grows
+// and shrinks with its length, in steps of a segment, without
+// allocating nor copying huge buffers.
+//
+// The size must be a power of 2.  Rings with capacities up to the size
+// use a single buffer, as usual.  Options storing data per element,
+// like WithTimestamps and WithCoalesce, still allocate it for the whole
+// capacity on creation.
+func WithSegments(size int) Option {
+	return func(c *config) {
+		c.segmentSize = size
+	}
+}
+
+// validates the segment size of the WithSegments option, 0 if not set.
+func validateSegmentSize(size int) error {
+	if size < 0 || bits.OnesCount(uint(size)) > 1 {
+		return fmt.Errorf("segment size must be a power of 2, got %d", size)
+	}
+
+	return nil
+}
+
+// the slots storing the elements of a ring: a single buffer, or
+// segments allocated on demand, see WithSegments.  Free slots hold nil.
+type slots struct {
+	n        int             // number of slots
+	flat     []interface{}   // all the slots, nil if segmented
+	segments [][]interface{} // nil for the segments not allocated
+	shift    uint            // log2 of the segment size
+}
+
+// returns n slots, in segments of the given size if it is not 0 and
+// smaller than n.
+func newSlots(n, segmentSize int) slots {
+	if segmentSize == 0 || segmentSize >= n {
+		return slots{n: n, flat: make([]interface{}, n)}
+	}
+
+	shift := uint(bits.TrailingZeros(uint(segmentSize)))
+
+	return slots{
+		n:        n,
+		segments: make([][]interface{}, (n+segmentSize-1)>>shift),
+		shift:    shift,
+	}
+}
+
+// returns the number of slots, 0 for the slots of the zero Ring.
+func (s *slots) size() int {
+	return s.n
+}
+
+// returns the element in slot i.
+func (s *slots) get(i int) interface{} {
+	if s.segments == nil {
+		return s.flat[i]
+	}
+
+	seg := s.segments[i>>s.shift]
+	if seg == nil {
+		return nil
+	}
+
+	return seg[i&s.mask()]
+}
+
+// stores v in slot i, allocating its segment if needed.
+func (s *slots) set(i int, v interface{}) {
+	if s.segments == nil {
+		s.flat[i] = v
+		return
+	}
+
+	k := i >> s.shift
+
+	if s.segments[k] == nil {
+		start := k << s.shift
+
+		size := s.mask() + 1
+		if start+size > s.n {
+			size = s.n - start
+		}
+
+		s.segments[k] = make([]interface{}, size)
+	}
+
+	s.segments[k][i&s.mask()] = v
+}
+
+// frees slot i, just vacated, releasing its segment if it is the last
+// slot of the segment and none of the n slots from head, the ones still
+// occupied, are in it.
+func (s *slots) free(i, head, n int) {
+	if s.segments == nil {
+		s.flat[i] = nil
+		return
+	}
+
+	s.segments[i>>s.shift][i&s.mask()] = nil
+
+	if i&s.mask() != s.mask() && i != s.n-1 {
+		return
+	}
+
+	// occupied slots are contiguous from head, so they reach into the
+	// segment only by its start
+	start := i &^ s.mask()
+	if (start-head+s.n)%s.n >= n {
+		s.segments[i>>s.shift] = nil
+	}
+}
+
+// frees all the slots, releasing all the segments.
+func (s *slots) reset(head, n int) {
+	if s.segments == nil {
+		for i := 0; i < n; i++ {
+			s.flat[(head+i)%s.n] = nil
+		}
+
+		return
+	}
+
+	for k := range s.segments {
+		s.segments[k] = nil
+	}
+}
+
+// returns the number of slots allocated.
+func (s *slots) allocated() int {
+	if s.segments == nil {
+		return len(s.flat)
+	}
+
+	var result int
+
+	for _, seg := range s.segments {
+		result += len(seg)
+	}
+
+	return result
+}
+
+// returns the mask of the index of a slot in its segment.
+func (s *slots) mask() int {
+	return 1<<s.shift - 1
+}
@@ -0,0 +1,130 @@
+package ring_test
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestSegments(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid sizes":       segmentsInvalidSizes,
+		"behaves like a ring": segmentsBehaveLikeARing,
+		"linearizable":        segmentsLinearizable,
+		"memory follows len":  segmentsMemoryFollowsLen,
+		"clear releases":      segmentsClearReleases,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a ring with the given capacity and segment size, or fails
+// the test.
+func newSegmented(t *testing.T, cap, size int) *ring.Ring {
+	t.Helper()
+
+	r, err := ring.New(cap, ring.WithSegments(size))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	return r
+}
+
+// tests that segment sizes must be powers of 2.
+func segmentsInvalidSizes(t *testing.T) {
+	for _, size := range []int{-1, 3, 12} {
+		if _, err := ring.New(100, ring.WithSegments(size)); err == nil {
+			t.Errorf("size %d: unexpected success", size)
+		}
+	}
+}
+
+// tests that segmented rings, including ones with a smaller last
+// segment, keep the newest elements in order.
+func segmentsBehaveLikeARing(t *testing.T) {
+	for _, cap := range []int{4, 10, 16} {
+		r := newSegmented(t, cap, 4)
+		m := ringtest.NewModel(cap)
+
+		for i := 0; i < 100; i++ {
+			r.Insert(i)
+			m.Insert(i)
+
+			if i%3 == 0 {
+				r.Extract()
+				m.Extract()
+			}
+		}
+
+		ringtest.AssertContents(t, r, m.ToSlice()...)
+	}
+}
+
+// tests that segmented rings are linearizable.
+func segmentsLinearizable(t *testing.T) {
+	ringtest.AssertLinearizable(t, func() ring.Buffer {
+		return newSegmented(t, 6, 2)
+	}, 50)
+}
+
+// the bytes of a slot of a ring.
+const slotBytes = int(unsafe.Sizeof(interface{}(nil)))
+
+// asserts the memory of r, without its elements, compared to an empty
+// ring of the same kind of the given size.
+func assertSlots(t *testing.T, r *ring.Ring, empty, slots int) {
+	t.Helper()
+
+	if got, want := r.SizeBytes(nil), empty+slots*slotBytes; got != want {
+		t.Errorf("want %d bytes, %d slots, got %d bytes", want, slots, got)
+	}
+}
+
+// tests that segments are allocated as the ring fills, and released as
+// it empties.
+func segmentsMemoryFollowsLen(t *testing.T) {
+	r := newSegmented(t, 1024, 64)
+	empty := r.SizeBytes(nil)
+
+	for i := 0; i < 64; i++ {
+		r.Insert(i)
+	}
+
+	assertSlots(t, r, empty, 64)
+
+	r.Insert(64)
+	assertSlots(t, r, empty, 128)
+
+	for i := 0; i < 64; i++ {
+		r.Extract()
+	}
+
+	assertSlots(t, r, empty, 64)
+
+	r.Extract()
+	assertSlots(t, r, empty, 64) // not the last slot of its segment
+}
+
+// tests that clearing a segmented ring releases all the segments.
+func segmentsClearReleases(t *testing.T) {
+	r := newSegmented(t, 100, 8)
+	empty := r.SizeBytes(nil)
+
+	for i := 0; i < 50; i++ {
+		r.Insert(i)
+	}
+
+	r.Clear()
+	assertSlots(t, r, empty, 0)
+}
@@ -0,0 +1,255 @@
+package ring
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+/*
+A snapshot file is made of the following fields:
+
+	magic    8 bytes, the string "RINGSNAP"
+	version  1 byte
+	length   8 bytes, big endian
+	payload  length bytes
+	crc      4 bytes, big endian
+
+The payload is the gob encoding of the elements in the ring, from
+oldest to newest.  The CRC is the IEEE CRC-32 of the payload.
+*/
+
+const (
+	snapshotMagic   = "RINGSNAP"
+	snapshotVersion = 1
+)
+
+// ErrCorruptSnapshot is returned when restoring from a snapshot file
+// that is incomplete or damaged.
+var ErrCorruptSnapshot = errors.New("corrupt ring snapshot")
+
+// the payload of a snapshot.
+type snapshot struct {
+	Elements []interface{}
+}
+
+// Checkpoint saves a snapshot of the contents of the ring to the file
+// at path.
+//
+// The snapshot is written to a temporary file that is synced and then
+// renamed to path, so the file at path always holds a complete
+// snapshot, either the old one or the new one.
+//
+// Elements are encoded with encoding/gob, so their concrete types must
+// be registered with gob.Register, unless they are basic types.
+func (r *Ring) Checkpoint(path string) error {
+	data, err := encodeSnapshot(r.ToSlice())
+	if err != nil {
+		return fmt.Errorf("encoding snapshot: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// checkpoints the ring to path every given interval until the ring is
+// closed.  Checkpoint errors are passed to onErr, if not nil, and the
+// next tick tries again.
+func (r *Ring) autoSnapshot(path string, every time.Duration, onErr func(err error)) {
+	defer r.done.Done()
+
+	ticker := r.clock.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C():
+			if err := r.Checkpoint(path); err != nil && onErr != nil {
+				onErr(err)
+			}
+		}
+	}
+}
+
+// Restore replaces the contents of the ring with the snapshot at path.
+// If the snapshot holds more elements than the ring capacity, only
+// the newest ones are kept.  If the snapshot file is incomplete or
+// damaged, it returns an error wrapping ErrCorruptSnapshot and the
+// ring is left untouched.
+func (r *Ring) Restore(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading snapshot: %w", err)
+	}
+
+	elements, err := decodeSnapshot(data)
+	if err != nil {
+		return fmt.Errorf("decoding snapshot %s: %w", path, err)
+	}
+
+	atomic.AddUint64(&r.inserted, uint64(len(elements)))
+
+	r.mu.Lock()
+
+	for r.len > 0 {
+		_, _ = r.extract()
+	}
+
+	r.head = 0
+
+	dropped := r.insertAll(elements)
+	r.unlock()
+
+	atomic.AddUint64(&r.dropped, dropped)
+
+	return nil
+}
+
+// ScanSnapshot reads a snapshot written by Checkpoint and calls fn with
+// the position and value of each of its elements, from oldest to
+// newest.  The whole snapshot is validated first, so if it is
+// incomplete or damaged, fn is not called and it returns an error
+// wrapping ErrCorruptSnapshot.  It stops at the first error returned
+// by fn, and returns it.
+//
+// It is meant to inspect snapshots without restoring them into a ring.
+func ScanSnapshot(rd io.Reader, fn func(i uint64, v interface{}) error) error {
+	data, err := ioutil.ReadAll(rd)
+	if err != nil {
+		return fmt.Errorf("reading snapshot: %w", err)
+	}
+
+	elements, err := decodeSnapshot(data)
+	if err != nil {
+		return err
+	}
+
+	for i, v := range elements {
+		if err := fn(uint64(i), v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// returns the snapshot file contents for the given elements.
+func encodeSnapshot(elements []interface{}) ([]byte, error) {
+	var payload bytes.Buffer
+
+	if err := gob.NewEncoder(&payload).Encode(snapshot{Elements: elements}); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+
+	b.WriteString(snapshotMagic)
+	b.WriteByte(snapshotVersion)
+
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(payload.Len()))
+	b.Write(n[:])
+
+	b.Write(payload.Bytes())
+
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc32.ChecksumIEEE(payload.Bytes()))
+	b.Write(sum[:])
+
+	return b.Bytes(), nil
+}
+
+// returns the elements in the snapshot file contents.
+func decodeSnapshot(data []byte) ([]interface{}, error) {
+	const headerSize = len(snapshotMagic) + 1 + 8
+
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("%w: truncated header", ErrCorruptSnapshot)
+	}
+
+	if string(data[:len(snapshotMagic)]) != snapshotMagic {
+		return nil, errors.New("not a ring snapshot")
+	}
+
+	if v := data[len(snapshotMagic)]; v != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", v)
+	}
+
+	n := binary.BigEndian.Uint64(data[len(snapshotMagic)+1:])
+	rest := data[headerSize:]
+
+	// compared without adding to n, which could overflow
+	if len(rest) < 4 || n != uint64(len(rest)-4) {
+		return nil, fmt.Errorf("%w: payload length mismatch", ErrCorruptSnapshot)
+	}
+
+	payload, sum := rest[:n], rest[n:]
+
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(sum) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrCorruptSnapshot)
+	}
+
+	var s snapshot
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&s); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorruptSnapshot, err)
+	}
+
+	return s.Elements, nil
+}
+
+// writes data to a temporary file in the same directory as path, syncs
+// it and renames it to path.
+func writeFileAtomic(path string, data []byte) error {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	tmp, err := ioutil.TempFile(dir, base+".tmp*")
+	if err != nil {
+		return err
+	}
+
+	// no-op once renamed
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// make the rename durable, not all platforms support this
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+
+	return nil
+}
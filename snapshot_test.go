@@ -0,0 +1,437 @@
+package ring_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"checkpoint and restore":    snapshotCheckpointAndRestore,
+		"restore keeps newest":      snapshotRestoreKeepsNewest,
+		"restore on construction":   snapshotRestoreOnConstruction,
+		"missing snapshot":          snapshotMissing,
+		"torn snapshot":             snapshotTorn,
+		"damaged snapshot":          snapshotDamaged,
+		"oversized length":          snapshotOversizedLength,
+		"scan":                      snapshotScan,
+		"scan damaged":              snapshotScanDamaged,
+		"checkpoint overwrites old": snapshotCheckpointOverwritesOld,
+		"auto snapshot":             snapshotAuto,
+		"auto snapshot on close":    snapshotAutoOnClose,
+		"auto snapshot interval":    snapshotAutoInterval,
+		"auto snapshot errors":      snapshotAutoErrors,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns the path to a snapshot file in a temporary directory removed
+// at the end of the test.
+func snapshotPath(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "ring")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %v", err)
+	}
+
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	return filepath.Join(dir, "ring.snap")
+}
+
+// returns a ring with the given capacity and elements or fails the
+// test.
+func newRing(t *testing.T, cap int, vs ...interface{}) *ring.Ring {
+	t.Helper()
+
+	r, err := ring.New(cap)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	for _, v := range vs {
+		r.Insert(v)
+	}
+
+	return r
+}
+
+// checkpoints r to path or fails the test.
+func checkpoint(t *testing.T, r *ring.Ring, path string) {
+	t.Helper()
+
+	if err := r.Checkpoint(path); err != nil {
+		t.Fatalf("checkpointing: %v", err)
+	}
+}
+
+// asserts that the ring holds the wanted elements, from oldest to
+// newest.
+func assertContents(t *testing.T, r *ring.Ring, want ...interface{}) {
+	t.Helper()
+
+	got := r.ToSlice()
+	if len(want) == 0 && len(got) == 0 {
+		return
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want contents %v, got %v", want, got)
+	}
+}
+
+// tests that restoring a checkpoint recovers the contents.
+func snapshotCheckpointAndRestore(t *testing.T) {
+	path := snapshotPath(t)
+	checkpoint(t, newRing(t, 3, 1, "two", 3.0, 4), path)
+
+	r := newRing(t, 3, "old")
+	if err := r.Restore(path); err != nil {
+		t.Fatalf("restoring: %v", err)
+	}
+
+	assertContents(t, r, "two", 3.0, 4)
+
+	// the restored ring keeps working as usual
+	r.Insert(5)
+	assertContents(t, r, 3.0, 4, 5)
+}
+
+// tests that restoring into a smaller ring keeps the newest elements.
+func snapshotRestoreKeepsNewest(t *testing.T) {
+	path := snapshotPath(t)
+	checkpoint(t, newRing(t, 4, 1, 2, 3, 4), path)
+
+	r := newRing(t, 2)
+	if err := r.Restore(path); err != nil {
+		t.Fatalf("restoring: %v", err)
+	}
+
+	assertContents(t, r, 3, 4)
+}
+
+// tests the WithRestore option.
+func snapshotRestoreOnConstruction(t *testing.T) {
+	path := snapshotPath(t)
+	checkpoint(t, newRing(t, 3, 1, 2), path)
+
+	r, err := ring.New(3, ring.WithRestore(path))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	assertContents(t, r, 1, 2)
+}
+
+// tests that a missing snapshot is an error for Restore, but not for
+// the WithRestore option.
+func snapshotMissing(t *testing.T) {
+	path := snapshotPath(t)
+
+	err := newRing(t, 3).Restore(path)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("want not exist error, got %v", err)
+	}
+
+	r, err := ring.New(3, ring.WithRestore(path))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	assertEmpty(t, r)
+}
+
+// modifies the file at path or fails the test.
+func modifyFile(t *testing.T, path string, fn func([]byte) []byte) {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading file: %v", err)
+	}
+
+	if err := ioutil.WriteFile(path, fn(data), 0o600); err != nil {
+		t.Fatalf("writing file: %v", err)
+	}
+}
+
+// asserts that restoring from the snapshot at path fails as corrupt
+// and leaves the ring untouched, and that so does New with the
+// WithRestore option.
+func assertCorrupt(t *testing.T, path string) {
+	t.Helper()
+
+	r := newRing(t, 3, "old")
+
+	err := r.Restore(path)
+	if !errors.Is(err, ring.ErrCorruptSnapshot) {
+		t.Errorf("want corrupt snapshot error, got %v", err)
+	}
+
+	assertContents(t, r, "old")
+
+	_, err = ring.New(3, ring.WithRestore(path))
+	if !errors.Is(err, ring.ErrCorruptSnapshot) {
+		t.Errorf("want corrupt snapshot error from New, got %v", err)
+	}
+}
+
+// tests that incomplete snapshots are detected.
+func snapshotTorn(t *testing.T) {
+	for _, keep := range []int{3, 12, 20} {
+		path := snapshotPath(t)
+		checkpoint(t, newRing(t, 3, 1, 2, 3), path)
+
+		modifyFile(t, path, func(b []byte) []byte { return b[:keep] })
+		assertCorrupt(t, path)
+	}
+}
+
+// tests that damaged snapshots are detected.
+func snapshotDamaged(t *testing.T) {
+	path := snapshotPath(t)
+	checkpoint(t, newRing(t, 3, 1, 2, 3), path)
+
+	modifyFile(t, path, func(b []byte) []byte {
+		b[len(b)-6] ^= 0xff
+		return b
+	})
+
+	assertCorrupt(t, path)
+}
+
+// tests that snapshots with lengths past the end of the file, even
+// overflowing when adding the checksum, are detected.
+func snapshotOversizedLength(t *testing.T) {
+	const headerSize = len("RINGSNAP") + 1 + 8
+
+	for _, test := range []struct {
+		n    uint64
+		rest int // bytes kept after the header, -1 for all
+	}{
+		{n: math.MaxUint64 - 3, rest: 0},
+		{n: math.MaxUint64 - 2, rest: 1},
+		{n: math.MaxUint64, rest: -1},
+		{n: 1 << 40, rest: -1},
+	} {
+		path := snapshotPath(t)
+		checkpoint(t, newRing(t, 3, 1, 2, 3), path)
+
+		modifyFile(t, path, func(b []byte) []byte {
+			binary.BigEndian.PutUint64(b[len("RINGSNAP")+1:], test.n)
+
+			if test.rest >= 0 {
+				b = b[:headerSize+test.rest]
+			}
+
+			return b
+		})
+
+		assertCorrupt(t, path)
+	}
+}
+
+// tests that checkpointing replaces previous snapshots without leaving
+// temporary files behind.
+func snapshotCheckpointOverwritesOld(t *testing.T) {
+	path := snapshotPath(t)
+	checkpoint(t, newRing(t, 3, 1, 2, 3), path)
+	checkpoint(t, newRing(t, 3, "a"), path)
+
+	r := newRing(t, 3)
+	if err := r.Restore(path); err != nil {
+		t.Fatalf("restoring: %v", err)
+	}
+
+	assertContents(t, r, "a")
+
+	files, err := ioutil.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("reading directory: %v", err)
+	}
+
+	if len(files) != 1 {
+		t.Errorf("want only the snapshot file, got %d files", len(files))
+	}
+}
+
+// tests that the WithAutoSnapshot option checkpoints the ring
+// periodically.
+func snapshotAuto(t *testing.T) {
+	path := snapshotPath(t)
+
+	r, err := ring.New(3, ring.WithAutoSnapshot(path, time.Millisecond))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	defer r.Close()
+
+	r.Insert(1)
+	r.Insert(2)
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		restored := newRing(t, 3)
+
+		err := restored.Restore(path)
+		if err == nil && restored.Len() == 2 {
+			assertContents(t, restored, 1, 2)
+			return
+		}
+
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("restoring: %v", err)
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for the snapshot")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// tests that closing a ring with the WithAutoSnapshot option writes a
+// last snapshot.
+func snapshotAutoOnClose(t *testing.T) {
+	path := snapshotPath(t)
+
+	r, err := ring.New(3, ring.WithAutoSnapshot(path, time.Hour))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	r.Insert("a")
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("closing: %v", err)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("closing twice: %v", err)
+	}
+
+	restored := newRing(t, 3)
+	if err := restored.Restore(path); err != nil {
+		t.Fatalf("restoring: %v", err)
+	}
+
+	assertContents(t, restored, "a")
+}
+
+// tests that the snapshot interval must be positive.
+func snapshotAutoInterval(t *testing.T) {
+	for _, every := range []time.Duration{0, -time.Second} {
+		_, err := ring.New(3, ring.WithAutoSnapshot(snapshotPath(t), every))
+		if err == nil {
+			t.Errorf("unexpected success with interval %v", every)
+		}
+	}
+}
+
+// tests that the errors of the periodic checkpoints are passed to the
+// hook.
+func snapshotAutoErrors(t *testing.T) {
+	path := filepath.Join(snapshotPath(t), "missing", "snap")
+	clock := ringtest.NewClock(time.Unix(0, 0))
+	errs := make(chan error, 1)
+
+	r, err := ring.New(3,
+		ring.WithAutoSnapshot(path, time.Second),
+		ring.WithSnapshotErrorHook(func(err error) { errs <- err }),
+		ring.WithClock(clock))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	clock.WaitTickers(1)
+	clock.Advance(time.Second)
+
+	if err := <-errs; !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("want not exist error, got %v", err)
+	}
+
+	if err := r.Close(); err == nil {
+		t.Error("closing: unexpected success")
+	}
+}
+
+// returns the elements scanned from the snapshot at path, as
+// position:value, and the error of the scan.
+func scanSnapshot(t *testing.T, path string) (string, error) {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading snapshot: %v", err)
+	}
+
+	var got []string
+
+	err = ring.ScanSnapshot(bytes.NewReader(data), func(i uint64, v interface{}) error {
+		got = append(got, fmt.Sprintf("%d:%v", i, v))
+		return nil
+	})
+
+	return fmt.Sprint(got), err
+}
+
+// tests that scanning a snapshot returns its elements in order.
+func snapshotScan(t *testing.T) {
+	path := snapshotPath(t)
+	checkpoint(t, newRing(t, 3, "a", 2, "c"), path)
+
+	got, err := scanSnapshot(t, path)
+	if err != nil {
+		t.Fatalf("scanning: %v", err)
+	}
+
+	if got != "[0:a 1:2 2:c]" {
+		t.Errorf("want [0:a 1:2 2:c], got %s", got)
+	}
+}
+
+// tests that scanning a damaged snapshot returns no elements and fails
+// as corrupt.
+func snapshotScanDamaged(t *testing.T) {
+	path := snapshotPath(t)
+	checkpoint(t, newRing(t, 3, 1, 2, 3), path)
+
+	modifyFile(t, path, func(b []byte) []byte {
+		b[len(b)-6] ^= 0xff
+		return b
+	})
+
+	got, err := scanSnapshot(t, path)
+	if !errors.Is(err, ring.ErrCorruptSnapshot) {
+		t.Errorf("want corrupt snapshot error, got %v", err)
+	}
+
+	if got != "[]" {
+		t.Errorf("want no elements, got %s", got)
+	}
+}
@@ -0,0 +1,61 @@
+package ring
+
+import "math/rand"
+
+// SoftLimitPolicy decides what a ring does with the elements inserted
+// past its soft limit, see WithSoftLimit.
+type SoftLimitPolicy int
+
+const (
+	// SoftLimitNotify inserts the elements as usual, dropping the
+	// oldest ones only when the ring is at its capacity.
+	SoftLimitNotify SoftLimitPolicy = iota
+	// SoftLimitShed drops new elements instead of inserting them, with a
+	// probability growing linearly from 0 at the soft limit to 1 at the
+	// capacity, like random early detection in routers.  Producers
+	// start losing some elements early, instead of the consumers losing
+	// the oldest ones once the ring is full.
+	SoftLimitShed
+)
+
+// the soft limit of a ring.
+type softLimit struct {
+	limit  int
+	policy SoftLimitPolicy
+	fn     func()
+	armed  bool // the length is below the limit since fn was last called
+}
+
+// WithSoftLimit sets a soft limit on the length of the ring, below its
+// capacity, as an early warning before the ring starts dropping
+// elements.  The limit must be greater than 0 and smaller than the
+// capacity.
+//
+// If fn is not nil, it is called each time an insertion takes the
+// length of the ring to the soft limit, but not again until the length
+// goes below the limit.  It is called from the goroutine inserting,
+// without the ring locked, so concurrent insertions may return before
+// it.  The policy decides what happens to the elements inserted past
+// the limit.
+//
+// Elements shed by the SoftLimitShed policy are counted as dropped and
+// notified to the observer as such, but not as inserted.
+func WithSoftLimit(limit int, policy SoftLimitPolicy, fn func()) Option {
+	return func(c *config) {
+		c.softLimit = &softLimit{
+			limit:  limit,
+			policy: policy,
+			fn:     fn,
+		}
+	}
+}
+
+// reports if a new element must be shed, given the length and capacity
+// of the ring.
+func (s *softLimit) shed(len, cap int) bool {
+	if s.policy != SoftLimitShed || len <= s.limit {
+		return false
+	}
+
+	return rand.Intn(cap-s.limit) < len-s.limit
+}
@@ -0,0 +1,167 @@
+package ring_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestSoftLimit(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid limits":          softLimitInvalidLimits,
+		"notifies once per cross": softLimitNotifiesOncePerCross,
+		"clear rearms":            softLimitClearRearms,
+		"notify keeps inserting":  softLimitNotifyKeepsInserting,
+		"shed drops the newest":   softLimitShedDropsNewest,
+		"shed is gradual":         softLimitShedIsGradual,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// tests that soft limits out of [1, cap) and unknown policies are
+// invalid.
+func softLimitInvalidLimits(t *testing.T) {
+	for _, opt := range []ring.Option{
+		ring.WithSoftLimit(0, ring.SoftLimitNotify, nil),
+		ring.WithSoftLimit(3, ring.SoftLimitNotify, nil),
+		ring.WithSoftLimit(4, ring.SoftLimitShed, nil),
+		ring.WithSoftLimit(1, ring.SoftLimitPolicy(42), nil),
+	} {
+		if _, err := ring.New(3, opt); err == nil {
+			t.Error("unexpected success")
+		}
+	}
+}
+
+// returns a ring with the given capacity and a soft limit with the given
+// policy, counting the calls to its callback, or fails the test.
+func newSoftLimited(t *testing.T, cap, limit int, policy ring.SoftLimitPolicy) (*ring.Ring, *int) {
+	t.Helper()
+
+	var calls int
+
+	r, err := ring.New(cap, ring.WithSoftLimit(limit, policy, func() { calls++ }))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	return r, &calls
+}
+
+// asserts the number of calls to the callback of the soft limit.
+func assertCalls(t *testing.T, calls *int, want int) {
+	t.Helper()
+
+	if *calls != want {
+		t.Errorf("want %d calls, got %d", want, *calls)
+	}
+}
+
+// tests that the callback is called when the length reaches the soft
+// limit, and not again until it goes below it.
+func softLimitNotifiesOncePerCross(t *testing.T) {
+	r, calls := newSoftLimited(t, 4, 2, ring.SoftLimitNotify)
+
+	r.Insert(1)
+	assertCalls(t, calls, 0)
+
+	r.Insert(2)
+	assertCalls(t, calls, 1)
+
+	r.InsertContext(context.Background(), 3)
+	r.Extract() // still at the limit
+	r.Insert(4)
+	assertCalls(t, calls, 1)
+
+	r.Extract()
+	r.Extract() // below the limit
+	r.InsertContext(context.Background(), 5)
+	assertCalls(t, calls, 2)
+}
+
+// tests that clearing the ring lets the callback be called again.
+func softLimitClearRearms(t *testing.T) {
+	r, calls := newSoftLimited(t, 3, 1, ring.SoftLimitNotify)
+
+	r.Insert(1)
+	r.Clear()
+	r.Insert(2)
+
+	assertCalls(t, calls, 2)
+}
+
+// tests that the notify policy fills the ring up to its capacity.
+func softLimitNotifyKeepsInserting(t *testing.T) {
+	r, _ := newSoftLimited(t, 3, 1, ring.SoftLimitNotify)
+
+	for i := 1; i <= 4; i++ {
+		r.Insert(i)
+	}
+
+	if got := fmt.Sprint(r.ToSlice()); got != "[2 3 4]" {
+		t.Errorf("want contents [2 3 4], got %s", got)
+	}
+}
+
+// tests that the shed policy drops the new elements when the ring is at
+// its capacity, keeping the old ones, and notifies them as dropped.
+func softLimitShedDropsNewest(t *testing.T) {
+	o := &recorder{}
+	r := newObserved(t, 2, o, ring.WithSoftLimit(1, ring.SoftLimitShed, nil))
+
+	r.Insert(1)
+	r.Insert(2) // at the soft limit, never shed
+	r.Insert(3) // at the capacity, always shed
+	r.InsertContext(context.Background(), 4)
+
+	if got := fmt.Sprint(r.ToSlice()); got != "[1 2]" {
+		t.Errorf("want contents [1 2], got %s", got)
+	}
+
+	if got := r.Dropped(); got != 2 {
+		t.Errorf("want 2 dropped, got %d", got)
+	}
+
+	assertEvents(t, o, "[insert 1 insert 2 drop 3 drop 4]")
+}
+
+// tests that the shed policy drops only some of the elements between
+// the soft limit and the capacity.
+func softLimitShedIsGradual(t *testing.T) {
+	r, err := ring.New(3, ring.WithSoftLimit(1, ring.SoftLimitShed, nil))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	// with two elements, half of the insertions are shed
+	var shed int
+
+	for i := 0; i < 1000; i++ {
+		r.Insert(1)
+		r.Insert(2)
+
+		before := r.Dropped()
+		r.Insert(3)
+
+		if r.Dropped() > before {
+			shed++
+		}
+
+		r.Clear()
+	}
+
+	if shed < 350 || shed > 650 {
+		t.Errorf("want around 500 elements shed, got %d", shed)
+	}
+}
@@ -0,0 +1,101 @@
+package ring
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// WithSpinLock makes the ring spin, trying to lock itself up to the
+// given number of times, before blocking like a sync.Mutex does.  For
+// pipelines with latencies of a few microseconds, where the critical
+// sections of the ring are much shorter than waking up a blocked
+// goroutine, spinning cuts the tail latency, at the cost of burning
+// CPU while contended.
+//
+// It is only worth it with producers and consumers running in
+// parallel, on different cores, see GOMAXPROCS.
+func WithSpinLock(spins int) Option {
+	return func(c *config) {
+		c.spins = spins
+	}
+}
+
+// validates the spins of the WithSpinLock option, 0 if not set.
+func validateSpins(spins int) error {
+	if spins < 0 {
+		return fmt.Errorf("spins must be >= 0, got %d", spins)
+	}
+
+	return nil
+}
+
+// the spins between yields of the processor while spinning.
+const spinsPerYield = 16
+
+// the lock of a ring: a sync.Mutex, unless spins is greater than 0, in
+// which case it spins that many times before blocking.
+//
+// Spinning locks are held by setting held, and the goroutines blocked
+// after spinning wait on cond until the holder unlocks it.
+type lock struct {
+	mu    sync.Mutex
+	spins int
+
+	held    int32 // 1 if locked, for spinning locks
+	waiters int32 // goroutines blocked in cond
+	cond    *sync.Cond
+}
+
+// makes the lock spin the given times before blocking.
+func (l *lock) setSpins(spins int) {
+	l.spins = spins
+	l.cond = sync.NewCond(&l.mu)
+}
+
+// Lock locks l.
+func (l *lock) Lock() {
+	if l.spins == 0 {
+		l.mu.Lock()
+		return
+	}
+
+	for i := 1; i <= l.spins; i++ {
+		if atomic.LoadInt32(&l.held) == 0 && atomic.CompareAndSwapInt32(&l.held, 0, 1) {
+			return
+		}
+
+		if i%spinsPerYield == 0 {
+			runtime.Gosched()
+		}
+	}
+
+	l.mu.Lock()
+	atomic.AddInt32(&l.waiters, 1)
+
+	// the waiters are counted before checking, so Unlock either sees
+	// them and signals, or it unlocked before the check
+	for !atomic.CompareAndSwapInt32(&l.held, 0, 1) {
+		l.cond.Wait()
+	}
+
+	atomic.AddInt32(&l.waiters, -1)
+	l.mu.Unlock()
+}
+
+// Unlock unlocks l.
+func (l *lock) Unlock() {
+	if l.spins == 0 {
+		l.mu.Unlock()
+		return
+	}
+
+	atomic.StoreInt32(&l.held, 0)
+
+	if atomic.LoadInt32(&l.waiters) > 0 {
+		l.mu.Lock()
+		l.cond.Signal()
+		l.mu.Unlock()
+	}
+}
@@ -0,0 +1,135 @@
+package ring_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestSpinLock(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid spins":      spinLockInvalidSpins,
+		"mutual exclusion":   spinLockMutualExclusion,
+		"linearizable":       spinLockLinearizable,
+		"blocks after spins": spinLockBlocksAfterSpins,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// tests that negative spins are invalid.
+func spinLockInvalidSpins(t *testing.T) {
+	if _, err := ring.New(1, ring.WithSpinLock(-1)); err == nil {
+		t.Error("unexpected success")
+	}
+}
+
+// tests that the counters stay consistent under contention, both when
+// the lock is taken by spinning and after blocking.
+func spinLockMutualExclusion(t *testing.T) {
+	const (
+		workers = 8
+		ops     = 2000
+	)
+
+	for _, spins := range []int{1, 1000} {
+		r, err := ring.New(16, ring.WithSpinLock(spins))
+		if err != nil {
+			t.Fatalf("creating ring: %v", err)
+		}
+
+		var wg sync.WaitGroup
+
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for i := 0; i < ops; i++ {
+					r.Insert(i)
+					r.Extract()
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		s := r.Stats()
+		if s.Inserted != workers*ops || s.Extracted+s.Dropped+uint64(s.Len) != s.Inserted {
+			t.Errorf("spins %d: inconsistent stats %+v", spins, s)
+		}
+	}
+}
+
+// tests that rings with spin locks are linearizable.
+func spinLockLinearizable(t *testing.T) {
+	ringtest.AssertLinearizable(t, func() ring.Buffer {
+		r, err := ring.New(3, ring.WithSpinLock(4))
+		if err != nil {
+			t.Fatalf("creating ring: %v", err)
+		}
+
+		return r
+	}, 50)
+}
+
+// tests that goroutines still waiting after spinning block until the
+// ring is unlocked.
+func spinLockBlocksAfterSpins(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	// the comparison of coalescing rings runs with the ring locked
+	r, err := ring.New(3, ring.WithSpinLock(10), ring.WithCoalesce(func(a, b interface{}) bool {
+		if b == "slow" {
+			close(entered)
+			<-release
+		}
+
+		return a == b
+	}))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	r.Insert(1)
+
+	go r.Insert("slow")
+
+	<-entered
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		r.Insert(2)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("inserted while locked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("still blocked after unlocking")
+	}
+
+	ringtest.AssertContents(t, r, 1, "slow", 2)
+}
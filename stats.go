@@ -0,0 +1,81 @@
+package ring
+
+import "sync/atomic"
+
+// Stats are the lifetime counters of a ring.
+type Stats struct {
+	Len       int    // elements in the ring
+	Cap       int    // capacity of the ring
+	Inserted  uint64 // elements inserted since creation
+	Extracted uint64 // elements extracted since creation
+	// Dropped is the amount of elements dropped to make room for new
+	// ones since creation or the last call to ResetDropped.
+	Dropped uint64
+	// MaxLen is the maximum length of the ring since creation.
+	MaxLen int
+	// PeakLen is the maximum length of the ring since creation or the
+	// last call to ResetPeakLen.
+	PeakLen int
+}
+
+// Stats returns the counters of the ring.
+//
+// The lengths are taken at the same time, but the insertions,
+// extractions and drops are counted outside the critical sections of
+// the ring, to keep them short: the insertions just before inserting,
+// and the extractions and drops just after.  So Extracted + Dropped +
+// Len <= Inserted.
+//
+// They are equal when no operation is in progress, unless elements left
+// the ring without being counted as extracted or dropped, or were
+// counted as inserted without adding to its length: the ones removed by
+// Clear or Restore, the drops forgotten by ResetDropped, the insertions
+// merged by WithCoalesce, and the element reserved by Reserve, until it
+// is committed or released.
+func (r *Ring) Stats() Stats {
+	// the extractions and drops are loaded before the lengths, and the
+	// insertions after, so the elements counted as extracted or dropped
+	// have left the lengths, and the ones in the lengths are counted as
+	// inserted
+	extracted := atomic.LoadUint64(&r.extracted)
+	dropped := atomic.LoadUint64(&r.dropped)
+
+	var s Stats
+
+	if r.lockFree {
+		s = r.reads.Load().(*readSnapshot).stats
+	} else {
+		r.mu.Lock()
+		s = r.stats()
+		r.mu.Unlock()
+	}
+
+	s.Extracted = extracted
+	s.Dropped = dropped
+	s.Inserted = atomic.LoadUint64(&r.inserted)
+
+	return s
+}
+
+// returns the lengths of the ring, which must be locked, without its
+// counters, see Stats.
+func (r *Ring) stats() Stats {
+	return Stats{
+		Len:     r.len,
+		Cap:     r.capacity(),
+		MaxLen:  r.maxLen,
+		PeakLen: r.peakLen,
+	}
+}
+
+// ResetPeakLen resets the peak length of the ring to its current length
+// and returns its previous value.  See Stats.
+func (r *Ring) ResetPeakLen() int {
+	r.mu.Lock()
+	defer r.unlock()
+
+	peak := r.peakLen
+	r.peakLen = r.len
+
+	return peak
+}
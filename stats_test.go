@@ -0,0 +1,181 @@
+package ring_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"new ring":   statsNewRing,
+		"counters":   statsCounters,
+		"all kinds":  statsAllKindsOfExtractions,
+		"max len":    statsMaxLen,
+		"concurrent": statsConcurrent,
+		"uncounted":  statsUncounted,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// asserts that the ring has the wanted stats.
+func assertStats(t *testing.T, r *ring.Ring, want ring.Stats) {
+	t.Helper()
+
+	if got := r.Stats(); got != want {
+		t.Errorf("want stats %+v, got %+v", want, got)
+	}
+}
+
+// tests the stats of a new ring.
+func statsNewRing(t *testing.T) {
+	assertStats(t, newRing(t, 3), ring.Stats{Cap: 3})
+}
+
+// tests that insertions, extractions and drops are counted.
+func statsCounters(t *testing.T) {
+	r := newRing(t, 2, 1, 2, 3)
+	r.Extract()
+	r.Extract()
+	r.Extract()
+
+	assertStats(t, r, ring.Stats{
+		Len:       0,
+		Cap:       2,
+		Inserted:  3,
+		Extracted: 2,
+		Dropped:   1,
+		MaxLen:    2,
+		PeakLen:   2,
+	})
+}
+
+// tests that all the ways of extracting elements are counted.
+func statsAllKindsOfExtractions(t *testing.T) {
+	r, err := ring.New(4, ring.WithTimestamps())
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	r.Insert(1)
+	r.Insert(2)
+	r.Insert(3)
+
+	r.ExtractWithCount()
+	r.ExtractOlderThan(time.Now().Add(time.Hour))
+
+	assertStats(t, r, ring.Stats{
+		Cap:       4,
+		Inserted:  3,
+		Extracted: 3,
+		MaxLen:    3,
+		PeakLen:   3,
+	})
+}
+
+// tests the maximum and peak lengths.
+func statsMaxLen(t *testing.T) {
+	r := newRing(t, 5, 1, 2, 3)
+	r.Extract()
+	r.Extract()
+
+	if got := r.ResetPeakLen(); got != 3 {
+		t.Errorf("want reset to return 3, got %d", got)
+	}
+
+	r.Insert(4)
+
+	stats := r.Stats()
+	if stats.MaxLen != 3 || stats.PeakLen != 2 {
+		t.Errorf("want max length 3 and peak length 2, got %d and %d",
+			stats.MaxLen, stats.PeakLen)
+	}
+}
+
+// tests that the counters never add up to more than the insertions
+// while operations are in progress, and add up to them after.
+func statsConcurrent(t *testing.T) {
+	r, err := ring.New(4)
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 1000; i++ {
+				r.Insert(i)
+
+				if i%2 == 0 {
+					r.Extract()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 1000; i++ {
+		if s := r.Stats(); s.Extracted+s.Dropped+uint64(s.Len) > s.Inserted {
+			t.Fatalf("counters over the insertions: %+v", s)
+		}
+	}
+
+	wg.Wait()
+
+	if s := r.Stats(); s.Inserted != 4000 || s.Extracted+s.Dropped+uint64(s.Len) != s.Inserted {
+		t.Errorf("counters do not add up: %+v", s)
+	}
+}
+
+// tests that the counters do not add up after removing elements without
+// extracting or dropping them.
+func statsUncounted(t *testing.T) {
+	for name, remove := range map[string]func(r *ring.Ring){
+		"clear":          func(r *ring.Ring) { r.Clear() },
+		"reset dropped":  func(r *ring.Ring) { r.ResetDropped() },
+		"reserve":        func(r *ring.Ring) { r.Reserve() },
+		"coalesced":      func(r *ring.Ring) { r.Insert(3) },
+		"reserve commit": func(r *ring.Ring) { s, _ := r.Reserve(); s.Commit() },
+	} {
+		r, err := ring.New(2, ring.WithCoalesce(func(a, b interface{}) bool { return a == b }))
+		if err != nil {
+			t.Fatalf("creating ring: %v", err)
+		}
+
+		for _, v := range []int{1, 2, 3} {
+			r.Insert(v)
+		}
+
+		remove(r)
+
+		s := r.Stats()
+		sum := s.Extracted + s.Dropped + uint64(s.Len)
+
+		if name == "reserve commit" {
+			if sum != s.Inserted {
+				t.Errorf("%s: counters do not add up: %+v", name, s)
+			}
+
+			continue
+		}
+
+		if sum >= s.Inserted {
+			t.Errorf("%s: want counters under the insertions, got %+v", name, s)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package ring
+
+import "context"
+
+// NextSeq returns the sequence number of the next element to be
+// inserted in the ring.  Elements are numbered from 0, in insertion
+// order, since the creation of the ring.
+func (r *Ring) NextSeq() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.seq
+}
+
+// ReadSince returns the elements in the ring with sequence numbers from
+// seq onwards, from oldest to newest, without extracting them, and the
+// sequence number of the first one.  If it is greater than seq, the
+// elements in between are no longer in the ring.  If there are no such
+// elements, it returns the sequence number of the next element to be
+// inserted.
+func (r *Ring) ReadSince(seq uint64) ([]interface{}, uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.readSince(seq)
+}
+
+// returns the elements from seq onwards and the sequence number of the
+// first one.
+func (r *Ring) readSince(seq uint64) ([]interface{}, uint64) {
+	head := r.seq - uint64(r.len)
+	if seq < head {
+		seq = head
+	}
+
+	if seq >= r.seq {
+		return nil, r.seq
+	}
+
+	result := make([]interface{}, r.seq-seq)
+	start := r.len - len(result)
+
+	for i := range result {
+		result[i] = r.buf.get((r.head + start + i) % r.buf.size())
+	}
+
+	return result, seq
+}
+
+// Follow calls fn with each element in the ring with a sequence number
+// from seq onwards, from oldest to newest, and then with each new
+// element as it is inserted, along with its sequence number, without
+// extracting them.  It blocks until ctx is done or fn returns an error,
+// and returns that error.
+//
+// Elements extracted or dropped before Follow gets to them are skipped,
+// which shows as gaps in the sequence numbers passed to fn.  Elements
+// coalesced into the newest one, see WithCoalesce, are not followed.
+func (r *Ring) Follow(ctx context.Context, seq uint64, fn func(seq uint64, v interface{}) error) error {
+	for {
+		r.mu.Lock()
+
+		elements, first := r.readSince(seq)
+
+		if r.ready == nil {
+			r.ready = make(chan struct{})
+		}
+
+		ready := r.ready
+		r.mu.Unlock()
+
+		for i, v := range elements {
+			if err := fn(first+uint64(i), v); err != nil {
+				return err
+			}
+		}
+
+		seq = first + uint64(len(elements))
+
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
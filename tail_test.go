@@ -0,0 +1,132 @@
+package ring_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTail(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"next seq":         tailNextSeq,
+		"read since":       tailReadSince,
+		"follow":           tailFollow,
+		"follow fn error":  tailFollowFnError,
+		"follow cancelled": tailFollowCancelled,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// tests that sequence numbers count the inserted elements.
+func tailNextSeq(t *testing.T) {
+	r := newRing(t, 2)
+
+	if got := r.NextSeq(); got != 0 {
+		t.Errorf("want next seq 0 on a new ring, got %d", got)
+	}
+
+	r.Insert(1)
+	r.Insert(2)
+	r.Insert(3)
+	r.Extract()
+
+	if got := r.NextSeq(); got != 3 {
+		t.Errorf("want next seq 3, got %d", got)
+	}
+}
+
+// tests reading the elements from a sequence number.
+func tailReadSince(t *testing.T) {
+	r := newRing(t, 3, "a", "b", "c", "d", "e") // a and b dropped
+
+	for _, test := range []struct {
+		seq       uint64
+		want      string
+		wantFirst uint64
+	}{
+		{seq: 0, want: "[c d e]", wantFirst: 2},
+		{seq: 3, want: "[d e]", wantFirst: 3},
+		{seq: 5, want: "[]", wantFirst: 5},
+		{seq: 42, want: "[]", wantFirst: 5},
+	} {
+		got, first := r.ReadSince(test.seq)
+		if fmt.Sprint(got) != test.want || first != test.wantFirst {
+			t.Errorf("seq %d: want %s from %d, got %v from %d",
+				test.seq, test.want, test.wantFirst, got, first)
+		}
+	}
+}
+
+// tests that following a ring reports the current and new elements with
+// their sequence numbers, and the gaps of the ones missed.
+func tailFollow(t *testing.T) {
+	r := newRing(t, 2, "a", "b", "c")
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		r.Insert("d")
+	}()
+
+	var got []string
+
+	err := r.Follow(context.Background(), 0, func(seq uint64, v interface{}) error {
+		got = append(got, fmt.Sprint(seq, v))
+
+		if v == "d" {
+			return errors.New("done")
+		}
+
+		return nil
+	})
+	if err == nil || err.Error() != "done" {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if fmt.Sprint(got) != "[1b 2c 3d]" {
+		t.Errorf("want to follow [1b 2c 3d], got %v", got)
+	}
+}
+
+// tests that following stops when fn fails, without reading more.
+func tailFollowFnError(t *testing.T) {
+	r := newRing(t, 3, 1, 2, 3)
+
+	var calls int
+
+	boom := errors.New("boom")
+
+	err := r.Follow(context.Background(), 0, func(uint64, interface{}) error {
+		calls++
+		return boom
+	})
+	if !errors.Is(err, boom) || calls != 1 {
+		t.Errorf("want error %v after 1 call, got %v after %d", boom, err, calls)
+	}
+}
+
+// tests that following stops when the context is done.
+func tailFollowCancelled(t *testing.T) {
+	r := newRing(t, 3, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := r.Follow(ctx, r.NextSeq(), func(uint64, interface{}) error {
+		t.Error("unexpected element")
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded error, got %v", err)
+	}
+}
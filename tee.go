@@ -0,0 +1,40 @@
+package ring
+
+import (
+	"errors"
+	"sync"
+)
+
+// Tee inserts each element into several rings, for example to keep a
+// short history for display and a long one for auditing.
+//
+// Elements inserted through the same tee are inserted in all its rings
+// in the same order, even when inserting concurrently.  Insertions into
+// the rings that do not go through the tee are not ordered with respect
+// to the ones that do.
+type Tee struct {
+	mu    sync.Mutex
+	rings []*Ring
+}
+
+// NewTee returns a new tee inserting into the given rings.
+func NewTee(rings ...*Ring) (*Tee, error) {
+	if len(rings) == 0 {
+		return nil, errors.New("tee needs at least one ring")
+	}
+
+	return &Tee{
+		rings: append([]*Ring(nil), rings...),
+	}, nil
+}
+
+// Insert adds a new element to all the rings of the tee, see
+// Ring.Insert.
+func (t *Tee) Insert(v interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, r := range t.rings {
+		r.Insert(v)
+	}
+}
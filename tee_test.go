@@ -0,0 +1,96 @@
+package ring_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestTee(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"no rings":         teeNoRings,
+		"inserts in all":   teeInsertsInAll,
+		"concurrent order": teeConcurrentOrder,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a tee inserting into the given rings or fails the test.
+func newTee(t *testing.T, rings ...*ring.Ring) *ring.Tee {
+	t.Helper()
+
+	tee, err := ring.NewTee(rings...)
+	if err != nil {
+		t.Fatalf("creating tee: %v", err)
+	}
+
+	return tee
+}
+
+// tests that tees need at least one ring.
+func teeNoRings(t *testing.T) {
+	if _, err := ring.NewTee(); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
+
+// tests that each ring keeps its newest elements.
+func teeInsertsInAll(t *testing.T) {
+	short, long := newRing(t, 2), newRing(t, 5)
+	tee := newTee(t, short, long)
+
+	for i := 1; i <= 4; i++ {
+		tee.Insert(i)
+	}
+
+	if got := fmt.Sprint(short.ToSlice()); got != "[3 4]" {
+		t.Errorf("want short ring [3 4], got %s", got)
+	}
+
+	if got := fmt.Sprint(long.ToSlice()); got != "[1 2 3 4]" {
+		t.Errorf("want long ring [1 2 3 4], got %s", got)
+	}
+}
+
+// tests that concurrent insertions end up in the same order in all the
+// rings.
+func teeConcurrentOrder(t *testing.T) {
+	const (
+		goroutines = 8
+		inserts    = 100
+	)
+
+	a, b := newRing(t, goroutines*inserts), newRing(t, goroutines*inserts)
+	tee := newTee(t, a, b)
+
+	var wg sync.WaitGroup
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+
+		go func(g int) {
+			defer wg.Done()
+
+			for i := 0; i < inserts; i++ {
+				tee.Insert(g*inserts + i)
+			}
+		}(g)
+	}
+
+	wg.Wait()
+
+	if fmt.Sprint(a.ToSlice()) != fmt.Sprint(b.ToSlice()) {
+		t.Error("rings with different orders")
+	}
+}
@@ -0,0 +1,150 @@
+package ring
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// OldestAge returns how long ago the oldest element in the ring was
+// inserted.  It returns false if the ring is empty or was not created
+// with the WithTimestamps option.
+func (r *Ring) OldestAge() (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.times == nil || r.len == 0 {
+		return 0, false
+	}
+
+	return r.now().Sub(r.times[r.head]), true
+}
+
+// NewestAge returns how long ago the newest element in the ring was
+// inserted.  It returns false if the ring is empty or was not created
+// with the WithTimestamps option.
+func (r *Ring) NewestAge() (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.times == nil || r.len == 0 {
+		return 0, false
+	}
+
+	newest := (r.head + r.len - 1) % r.buf.size()
+
+	return r.now().Sub(r.times[newest]), true
+}
+
+// ExtractOlderThan atomically extracts and returns the elements
+// inserted before t, from oldest to newest.  It returns nil if there
+// are none or the ring was not created with the WithTimestamps option.
+func (r *Ring) ExtractOlderThan(t time.Time) []interface{} {
+	r.mu.Lock()
+
+	if r.times == nil {
+		r.mu.Unlock()
+		return nil
+	}
+
+	var result []interface{}
+
+	for r.len > 0 && r.times[r.head].Before(t) {
+		v, _ := r.extract()
+		result = append(result, v)
+	}
+
+	r.unlock()
+
+	atomic.AddUint64(&r.extracted, uint64(len(result)))
+
+	if r.observer != nil {
+		for _, v := range result {
+			r.observer.OnExtract(v)
+		}
+	}
+
+	return result
+}
+
+// Between returns the elements inserted at or after from and before
+// to, from oldest to newest, without extracting them.  It returns nil
+// if there are none or the ring was not created with the WithTimestamps
+// option.
+//
+// As insertion times are ordered, the elements are found using binary
+// search, in logarithmic time.
+func (r *Ring) Between(from, to time.Time) []interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.times == nil {
+		return nil
+	}
+
+	at := func(i int) time.Time {
+		return r.times[(r.head+i)%r.buf.size()]
+	}
+
+	first := sort.Search(r.len, func(i int) bool { return !at(i).Before(from) })
+	last := sort.Search(r.len, func(i int) bool { return !at(i).Before(to) })
+
+	if first >= last {
+		return nil
+	}
+
+	result := make([]interface{}, last-first)
+	for i := range result {
+		result[i] = r.buf.get((r.head + first + i) % r.buf.size())
+	}
+
+	return result
+}
+
+// checks periodically whether the oldest element is older than the
+// threshold, and if so, calls fn once for it.
+func (r *Ring) staleAlarm(threshold time.Duration, fn func(age time.Duration)) {
+	defer r.done.Done()
+
+	every := threshold / 4
+	if every == 0 {
+		every = threshold
+	}
+
+	ticker := r.clock.NewTicker(every)
+	defer ticker.Stop()
+
+	// the sequence number of the last element reported as stale, plus
+	// one, so the zero value reports none
+	var reported uint64
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C():
+		}
+
+		r.mu.Lock()
+
+		var (
+			age   time.Duration
+			stale bool
+		)
+
+		if head := r.seq - uint64(r.len) + 1; r.len > 0 && head != reported {
+			age = r.now().Sub(r.times[r.head])
+			stale = age > threshold
+
+			if stale {
+				reported = head
+			}
+		}
+
+		r.mu.Unlock()
+
+		if stale {
+			fn(age)
+		}
+	}
+}
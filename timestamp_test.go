@@ -0,0 +1,212 @@
+package ring_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alcortesm/ring"
+)
+
+func TestTimestamps(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"disabled":           timestampsDisabled,
+		"empty":              timestampsEmpty,
+		"ages":               timestampsAges,
+		"extract older than": timestampsExtractOlderThan,
+		"between":            timestampsBetween,
+		"stale alarm":        timestampsStaleAlarm,
+		"stale threshold":    timestampsStaleThreshold,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns a ring with timestamps with the given capacity and elements
+// or fails the test.
+func newTimestamped(t *testing.T, cap int, vs ...interface{}) *ring.Ring {
+	t.Helper()
+
+	r, err := ring.New(cap, ring.WithTimestamps())
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	for _, v := range vs {
+		r.Insert(v)
+	}
+
+	return r
+}
+
+// tests that rings without the WithTimestamps option have no ages.
+func timestampsDisabled(t *testing.T) {
+	r := newRing(t, 3, 1, 2)
+
+	if _, ok := r.OldestAge(); ok {
+		t.Error("unexpected oldest age")
+	}
+
+	if _, ok := r.NewestAge(); ok {
+		t.Error("unexpected newest age")
+	}
+}
+
+// tests that empty rings have no ages.
+func timestampsEmpty(t *testing.T) {
+	r := newTimestamped(t, 3, 1)
+	r.Extract()
+
+	if _, ok := r.OldestAge(); ok {
+		t.Error("unexpected oldest age")
+	}
+
+	if _, ok := r.NewestAge(); ok {
+		t.Error("unexpected newest age")
+	}
+}
+
+// tests the ages of the oldest and newest elements.
+func timestampsAges(t *testing.T) {
+	const pause = 20 * time.Millisecond
+
+	start := time.Now()
+	r := newTimestamped(t, 2, 1)
+
+	time.Sleep(pause)
+	r.Insert(2)
+
+	oldest, ok := r.OldestAge()
+	if !ok || oldest < pause || oldest > time.Since(start) {
+		t.Errorf("unexpected oldest age %v, %t", oldest, ok)
+	}
+
+	newest, ok := r.NewestAge()
+	if !ok || newest > oldest-pause {
+		t.Errorf("unexpected newest age %v, %t, with oldest age %v", newest, ok, oldest)
+	}
+
+	// dropping the oldest element makes the ring younger
+	r.Insert(3)
+
+	if age, _ := r.OldestAge(); age > time.Since(start)-pause {
+		t.Errorf("unexpected oldest age %v after dropping the oldest element", age)
+	}
+}
+
+// tests extracting the elements inserted before some time.
+func timestampsExtractOlderThan(t *testing.T) {
+	r := newTimestamped(t, 5, 1, 2)
+
+	time.Sleep(time.Millisecond)
+	deadline := time.Now()
+	time.Sleep(time.Millisecond)
+
+	r.Insert(3)
+
+	if got := r.ExtractOlderThan(deadline.Add(-time.Hour)); got != nil {
+		t.Errorf("want nothing extracted, got %v", got)
+	}
+
+	if got := r.ExtractOlderThan(deadline); fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("want [1 2] extracted, got %v", got)
+	}
+
+	assertContents(t, r, 3)
+
+	if got := newRing(t, 3, 1).ExtractOlderThan(time.Now()); got != nil {
+		t.Errorf("want nothing extracted without timestamps, got %v", got)
+	}
+}
+
+// tests querying the elements inserted in a time range.
+func timestampsBetween(t *testing.T) {
+	r := newTimestamped(t, 4)
+
+	var times []time.Time
+
+	// the ring wraps around, 0 and 1 are dropped
+	for i := 0; i < 6; i++ {
+		times = append(times, time.Now())
+		r.Insert(i)
+		time.Sleep(time.Millisecond)
+	}
+
+	end := time.Now()
+
+	for _, test := range []struct {
+		from, to time.Time
+		want     string
+	}{
+		{times[0], end, "[2 3 4 5]"},
+		{times[3], times[5], "[3 4]"},
+		{times[4], times[4], "[]"},
+		{end, end.Add(time.Hour), "[]"},
+		{times[5], times[2], "[]"},
+	} {
+		if got := fmt.Sprint(r.Between(test.from, test.to)); got != test.want {
+			t.Errorf("want %s, got %s", test.want, got)
+		}
+	}
+
+	assertContents(t, r, 2, 3, 4, 5)
+}
+
+// tests that the stale alarm is raised once per stale element.
+func timestampsStaleAlarm(t *testing.T) {
+	const threshold = 20 * time.Millisecond
+
+	ages := make(chan time.Duration, 10)
+
+	r, err := ring.New(2, ring.WithStaleAlarm(threshold, func(age time.Duration) {
+		ages <- age
+	}))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	defer r.Close()
+
+	assertAlarm := func() {
+		t.Helper()
+
+		select {
+		case age := <-ages:
+			if age <= threshold {
+				t.Errorf("alarm raised for a fresh element, age %v", age)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for the alarm")
+		}
+	}
+
+	r.Insert(1)
+	r.Insert(2)
+	assertAlarm()
+
+	// the same stale element does not raise the alarm again
+	time.Sleep(5 * threshold)
+
+	if len(ages) != 0 {
+		t.Fatalf("alarm raised %d more times for the same element", len(ages))
+	}
+
+	// the next element becomes stale too
+	r.Extract()
+	assertAlarm()
+}
+
+// tests that the stale threshold must be positive.
+func timestampsStaleThreshold(t *testing.T) {
+	if _, err := ring.New(2, ring.WithStaleAlarm(0, func(time.Duration) {})); err == nil {
+		t.Fatal("unexpected success")
+	}
+}
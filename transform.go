@@ -0,0 +1,103 @@
+package ring
+
+import "fmt"
+
+// MapTo returns a new ring, with the same capacity as r, holding the
+// results of calling fn with each element in r, from oldest to newest,
+// without extracting them.  The elements are taken from a consistent
+// snapshot of r, as fn runs while holding its lock, which is also what
+// elements that are mutable buffers need, see PeekFunc.  For example, it
+// exports a sanitized view of a ring holding sensitive data.
+//
+// The new ring is a plain one, as if created with New without options,
+// where the results are counted as inserted.  As r stays locked until
+// all the calls return, fn should be quick, and it must not call any
+// method of r, or it deadlocks.
+func (r *Ring) MapTo(fn func(v interface{}) interface{}) *Ring {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := &Ring{buf: newSlots(r.capacity(), 0)}
+
+	for i := 0; i < r.len; i++ {
+		result.Insert(fn(r.buf.get((r.head + i) % r.buf.size())))
+	}
+
+	return result
+}
+
+// FilterTo returns a new ring, with the same capacity as r, holding the
+// elements in r for which pred returns true, from oldest to newest,
+// without extracting them.  For example, it queries the error events in
+// a ring holding the recent events of all levels.
+//
+// Like with MapTo, the elements are taken from a consistent snapshot of
+// r, the new ring is a plain one, and pred must not call any method of
+// r.
+func (r *Ring) FilterTo(pred func(v interface{}) bool) *Ring {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := &Ring{buf: newSlots(r.capacity(), 0)}
+
+	for i := 0; i < r.len; i++ {
+		if v := r.buf.get((r.head + i) % r.buf.size()); pred(v) {
+			result.Insert(v)
+		}
+	}
+
+	return result
+}
+
+// Reduce folds the elements in r, from oldest to newest, without
+// extracting them: it calls fn with init and the oldest element, then
+// with its result and the next element, and so on, and returns the last
+// result, or init if r is empty.  For example, it sums or averages the
+// elements of a ring of samples.
+//
+// Like with MapTo, the elements are taken from a consistent snapshot of
+// r, and fn must not call any method of r.
+func (r *Ring) Reduce(init interface{}, fn func(acc, v interface{}) interface{}) interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	acc := init
+
+	for i := 0; i < r.len; i++ {
+		acc = fn(acc, r.buf.get((r.head+i)%r.buf.size()))
+	}
+
+	return acc
+}
+
+// Batches calls fn with successive batches of up to n elements from r,
+// from oldest to newest, without extracting them, until all of them
+// are passed or fn returns an error, which it returns.  For example, it
+// feeds sinks that take elements in bulk, like batch HTTP APIs.
+//
+// The elements are taken from a consistent snapshot of r, but, unlike
+// with MapTo, fn is called after unlocking r, so it can take its time
+// and use r, although the changes made to r meanwhile are not seen.
+// Batches passed to fn are not reused, so fn can keep them.
+func (r *Ring) Batches(n int, fn func(batch []interface{}) error) error {
+	if n < 1 {
+		return fmt.Errorf("batch size must be > 0, got %d", n)
+	}
+
+	elements := r.ToSlice()
+
+	for len(elements) > 0 {
+		size := n
+		if size > len(elements) {
+			size = len(elements)
+		}
+
+		if err := fn(elements[:size:size]); err != nil {
+			return err
+		}
+
+		elements = elements[size:]
+	}
+
+	return nil
+}
@@ -0,0 +1,374 @@
+package ring_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestMapTo(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"empty ring":    mapToEmptyRing,
+		"elements":      mapToElements,
+		"zero ring":     mapToZeroRing,
+		"independent":   mapToIndependent,
+		"plain options": mapToPlainOptions,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// masks all the characters of a string but the first one.
+func mask(v interface{}) interface{} {
+	s := v.(string)
+	return s[:1] + strings.Repeat("*", len(s)-1)
+}
+
+// tests that mapping empty rings returns empty rings with the same
+// capacity.
+func mapToEmptyRing(t *testing.T) {
+	got := newRing(t, 3).MapTo(mask)
+
+	assertEmpty(t, got)
+
+	if got.Cap() != 3 {
+		t.Errorf("want capacity 3, got %d", got.Cap())
+	}
+}
+
+// tests that the new ring holds the mapped elements, in the same order,
+// and that the original one keeps its elements.
+func mapToElements(t *testing.T) {
+	r := newRing(t, 3, "alice", "bob", "carol", "dave") // wraps around
+
+	got := r.MapTo(mask)
+
+	ringtest.AssertDrain(t, got, "b**", "c****", "d***")
+	ringtest.AssertContents(t, r, "bob", "carol", "dave")
+
+	if n := got.Stats().Inserted; n != 3 {
+		t.Errorf("want 3 inserted, got %d", n)
+	}
+}
+
+// tests that zero rings map to rings with the default capacity.
+func mapToZeroRing(t *testing.T) {
+	var r ring.Ring
+
+	if got := r.MapTo(mask).Cap(); got != ring.DefaultCapacity {
+		t.Errorf("want capacity %d, got %d", ring.DefaultCapacity, got)
+	}
+}
+
+// tests that changes to the new ring do not affect the original one,
+// and the other way around.
+func mapToIndependent(t *testing.T) {
+	r := newRing(t, 2, "alice")
+	got := r.MapTo(mask)
+
+	r.Insert("bob")
+	got.Insert("c****")
+
+	ringtest.AssertDrain(t, r, "alice", "bob")
+	ringtest.AssertDrain(t, got, "a****", "c****")
+}
+
+// tests that the new ring does not inherit the options of the original
+// one.
+func mapToPlainOptions(t *testing.T) {
+	o := &recorder{}
+	r := newObserved(t, 2, o, ring.WithCoalesce(equal))
+	ringtest.Fill(r, "alice", "alice")
+
+	got := r.MapTo(mask)
+	got.Insert("a****")
+
+	ringtest.AssertDrain(t, got, "a****", "a****")
+	assertEvents(t, o, "[insert alice insert alice]")
+}
+
+func TestFilterTo(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"empty ring":  filterToEmptyRing,
+		"elements":    filterToElements,
+		"none match":  filterToNoneMatch,
+		"independent": filterToIndependent,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// reports if an event is an error.
+func isError(v interface{}) bool {
+	return strings.HasPrefix(v.(string), "error")
+}
+
+// tests that filtering empty rings returns empty rings with the same
+// capacity.
+func filterToEmptyRing(t *testing.T) {
+	got := newRing(t, 3).FilterTo(isError)
+
+	assertEmpty(t, got)
+
+	if got.Cap() != 3 {
+		t.Errorf("want capacity 3, got %d", got.Cap())
+	}
+}
+
+// tests that the new ring holds the matching elements, in the same
+// order, and that the original one keeps all its elements.
+func filterToElements(t *testing.T) {
+	r := newRing(t, 4, "error: a", "info: b", "error: c", "debug: d", "error: e")
+
+	got := r.FilterTo(isError)
+
+	ringtest.AssertDrain(t, got, "error: c", "error: e")
+	ringtest.AssertContents(t, r, "info: b", "error: c", "debug: d", "error: e")
+}
+
+// tests that filtering out all the elements returns an empty ring.
+func filterToNoneMatch(t *testing.T) {
+	assertEmpty(t, newRing(t, 2, "info: a", "debug: b").FilterTo(isError))
+}
+
+// tests that changes to the new ring do not affect the original one.
+func filterToIndependent(t *testing.T) {
+	r := newRing(t, 2, "error: a")
+	got := r.FilterTo(isError)
+
+	got.Insert("error: b")
+	got.Insert("error: c")
+
+	ringtest.AssertDrain(t, r, "error: a")
+	ringtest.AssertDrain(t, got, "error: b", "error: c")
+}
+
+func TestReduce(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"empty ring": reduceEmptyRing,
+		"sum":        reduceSum,
+		"order":      reduceOrder,
+		"concurrent": reduceConcurrent,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// adds two ints.
+func addInts(acc, v interface{}) interface{} {
+	return acc.(int) + v.(int)
+}
+
+// tests that reducing empty rings returns the initial value.
+func reduceEmptyRing(t *testing.T) {
+	if got := newRing(t, 2).Reduce(42, addInts); got != 42 {
+		t.Errorf("want 42, got %v", got)
+	}
+}
+
+// tests that Reduce folds all the elements, without extracting them.
+func reduceSum(t *testing.T) {
+	r := newRing(t, 3, 1, 2, 3, 4)
+
+	if got := r.Reduce(0, addInts); got != 9 {
+		t.Errorf("want 9, got %v", got)
+	}
+
+	assertLen(t, r, 3)
+}
+
+// tests that Reduce folds the elements from oldest to newest.
+func reduceOrder(t *testing.T) {
+	r := newRing(t, 3, "a", "b", "c", "d")
+
+	concat := func(acc, v interface{}) interface{} {
+		return acc.(string) + v.(string)
+	}
+
+	if got := r.Reduce(">", concat); got != ">bcd" {
+		t.Errorf("want >bcd, got %v", got)
+	}
+}
+
+// tests that Reduce sees consistent snapshots while the ring changes:
+// consecutive numbers are inserted, so the elements folded must be
+// consecutive too.
+func reduceConcurrent(t *testing.T) {
+	const n = 1000
+
+	r := newRing(t, 4)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 0; i < n; i++ {
+			r.Insert(i)
+		}
+	}()
+
+	collect := func(acc, v interface{}) interface{} {
+		return append(acc.([]int), v.(int))
+	}
+
+	for i := 0; i < n; i++ {
+		got := r.Reduce([]int(nil), collect).([]int)
+
+		for j := 1; j < len(got); j++ {
+			if got[j] != got[j-1]+1 {
+				t.Fatalf("inconsistent snapshot %v", got)
+			}
+		}
+	}
+
+	<-done
+}
+
+func TestBatches(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"invalid size": batchesInvalidSize,
+		"empty ring":   batchesEmptyRing,
+		"sizes":        batchesSizes,
+		"error":        batchesError,
+		"snapshot":     batchesSnapshot,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// returns the batches of up to n elements of r.
+func batches(t *testing.T, r *ring.Ring, n int) [][]interface{} {
+	t.Helper()
+
+	var result [][]interface{}
+
+	err := r.Batches(n, func(batch []interface{}) error {
+		result = append(result, batch)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return result
+}
+
+// tests that batch sizes smaller than 1 are rejected.
+func batchesInvalidSize(t *testing.T) {
+	r := newRing(t, 2, 1)
+
+	for _, n := range []int{0, -1} {
+		err := r.Batches(n, func([]interface{}) error { return nil })
+		if err == nil {
+			t.Errorf("size %d: unexpected success", n)
+		}
+	}
+}
+
+// tests that there are no batches in empty rings.
+func batchesEmptyRing(t *testing.T) {
+	if got := batches(t, newRing(t, 2), 1); got != nil {
+		t.Errorf("want no batches, got %v", got)
+	}
+}
+
+// tests that the elements are split in batches of up to the size, from
+// oldest to newest, without extracting them.
+func batchesSizes(t *testing.T) {
+	r := newRing(t, 5, 0, 1, 2, 3, 4, 5)
+
+	tests := map[int]string{
+		1:  "[[1] [2] [3] [4] [5]]",
+		2:  "[[1 2] [3 4] [5]]",
+		5:  "[[1 2 3 4 5]]",
+		10: "[[1 2 3 4 5]]",
+	}
+
+	for n, want := range tests {
+		if got := fmt.Sprint(batches(t, r, n)); got != want {
+			t.Errorf("size %d: want %s, got %s", n, want, got)
+		}
+	}
+
+	assertLen(t, r, 5)
+}
+
+// tests that errors from the function stop the batches.
+func batchesError(t *testing.T) {
+	r := newRing(t, 5, 1, 2, 3, 4, 5)
+	errSink := errors.New("sink failure")
+
+	var calls int
+
+	err := r.Batches(2, func([]interface{}) error {
+		calls++
+		return errSink
+	})
+
+	if err != errSink {
+		t.Errorf("want %v, got %v", errSink, err)
+	}
+
+	if calls != 1 {
+		t.Errorf("want 1 call, got %d", calls)
+	}
+}
+
+// tests that the batches come from a snapshot of the ring, taken before
+// the first call to the function, which can use the ring.
+func batchesSnapshot(t *testing.T) {
+	r := newRing(t, 4, 1, 2, 3)
+
+	var got []interface{}
+
+	err := r.Batches(1, func(batch []interface{}) error {
+		got = append(got, batch...)
+		r.Insert(4)
+		_, _ = r.Extract()
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := "[1 2 3]"; fmt.Sprint(got) != want {
+		t.Errorf("want %s, got %v", want, got)
+	}
+}
@@ -0,0 +1,40 @@
+package ring
+
+// UpdateHead replaces the oldest element in the ring with the one
+// returned by fn, called with it, and reports if there was one to
+// update.  The element is read, and its replacement written, under the
+// lock of the ring, so no other method can see or change it in between.
+//
+// The replacement keeps the place of the element in the ring, and its
+// timestamp and count, if any, see WithTimestamps and WithCoalesce.  It
+// is neither an insertion nor an extraction, so it is not counted in the
+// Stats of the ring nor notified to its Observer.
+//
+// As the ring stays locked until fn returns, fn should be quick, and it
+// must not call any method of the ring, or it deadlocks.
+func (r *Ring) UpdateHead(fn func(v interface{}) interface{}) bool {
+	return r.UpdateAt(0, fn)
+}
+
+// UpdateAt is like UpdateHead, but for the element at index i, from 0
+// for the oldest element to Len-1 for the newest one.  Negative indexes
+// count backwards from the newest element, so -1 updates the newest
+// one, like when aggregating into the latest of a series of buckets.  It
+// reports false if there is no element at i.
+func (r *Ring) UpdateAt(i int, fn func(v interface{}) interface{}) bool {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if i < 0 {
+		i += r.len
+	}
+
+	if i < 0 || i >= r.len {
+		return false
+	}
+
+	j := (r.head + i) % r.buf.size()
+	r.buf.set(j, fn(r.buf.get(j)))
+
+	return true
+}
@@ -0,0 +1,136 @@
+package ring_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/alcortesm/ring"
+	"github.com/alcortesm/ring/ringtest"
+)
+
+func TestUpdate(t *testing.T) {
+	t.Parallel()
+
+	subtests := map[string]func(*testing.T){
+		"empty ring":        updateEmptyRing,
+		"head":              updateHead,
+		"at":                updateAt,
+		"keeps counts":      updateKeepsCounts,
+		"lock-free reads":   updateLockFreeReads,
+		"concurrent update": updateConcurrent,
+	}
+
+	for name, testFn := range subtests {
+		testFn := testFn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testFn(t)
+		})
+	}
+}
+
+// adds n to an int.
+func add(n int) func(v interface{}) interface{} {
+	return func(v interface{}) interface{} {
+		return v.(int) + n
+	}
+}
+
+// tests that there is nothing to update in empty rings.
+func updateEmptyRing(t *testing.T) {
+	r := newRing(t, 2)
+
+	if r.UpdateHead(add(1)) || r.UpdateAt(-1, add(1)) {
+		t.Error("unexpected update")
+	}
+}
+
+// tests that UpdateHead replaces the oldest element.
+func updateHead(t *testing.T) {
+	r := newRing(t, 3, 1, 2, 3)
+
+	if !r.UpdateHead(add(10)) {
+		t.Fatal("unexpected failure to update")
+	}
+
+	ringtest.AssertDrain(t, r, 11, 2, 3)
+}
+
+// tests that UpdateAt replaces the element at an index, counting from
+// the newest element if it is negative.
+func updateAt(t *testing.T) {
+	r := newRing(t, 3, 0, 1, 2, 3) // wraps around
+
+	for _, i := range []int{0, 1, -1, -3} {
+		if !r.UpdateAt(i, add(10)) {
+			t.Errorf("index %d: unexpected failure to update", i)
+		}
+	}
+
+	for _, i := range []int{3, -4} {
+		if r.UpdateAt(i, add(10)) {
+			t.Errorf("index %d: unexpected update", i)
+		}
+	}
+
+	ringtest.AssertDrain(t, r, 21, 12, 13)
+}
+
+// tests that updated elements keep their counts, and that updates are
+// not counted as insertions nor extractions.
+func updateKeepsCounts(t *testing.T) {
+	r, err := ring.New(3, ring.WithCoalesce(equal))
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	ringtest.Fill(r, 1, 1, 2)
+	r.UpdateHead(add(10))
+
+	if got := r.Stats(); got.Inserted != 3 || got.Extracted != 0 {
+		t.Errorf("want 3 inserted and 0 extracted, got %+v", got)
+	}
+
+	if v, n, _ := r.ExtractWithCount(); v != 11 || n != 2 {
+		t.Errorf("want 11 inserted twice, got %v inserted %d times", v, n)
+	}
+}
+
+// tests that lock-free reads see the updated oldest element.
+func updateLockFreeReads(t *testing.T) {
+	r, err := ring.New(2, ring.WithLockFreeReads())
+	if err != nil {
+		t.Fatalf("creating ring: %v", err)
+	}
+
+	ringtest.Fill(r, 1, 2)
+	r.UpdateHead(add(10))
+
+	if v, ok := r.Peek(); !ok || v != 11 {
+		t.Errorf("want to peek 11, got %v, %t", v, ok)
+	}
+}
+
+// tests that concurrent updates do not lose any of them.
+func updateConcurrent(t *testing.T) {
+	const workers, updates = 4, 1000
+
+	r := newRing(t, 2, 0, 0)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < updates; i++ {
+				r.UpdateAt(-1, add(1))
+			}
+		}()
+	}
+
+	wg.Wait()
+	ringtest.AssertDrain(t, r, 0, workers*updates)
+}
@@ -0,0 +1,82 @@
+package ring
+
+// seqDeque is a double-ended queue of sequence numbers of the elements
+// in a numeric ring, used to find the minimum and maximum elements in
+// constant amortized time.
+//
+// The deque is kept monotonic: after pushing the sequence number of a
+// new element, all the elements in the deque are no worse than it, in
+// the order of the deque, and it holds the best element of the ring at
+// its front.
+type seqDeque struct {
+	buf  []uint64
+	head int
+	len  int
+}
+
+func newSeqDeque(cap int) seqDeque {
+	return seqDeque{buf: make([]uint64, cap)}
+}
+
+func (d *seqDeque) empty() bool {
+	return d.len == 0
+}
+
+func (d *seqDeque) front() uint64 {
+	return d.buf[d.head]
+}
+
+func (d *seqDeque) back() uint64 {
+	return d.buf[(d.head+d.len-1)%len(d.buf)]
+}
+
+func (d *seqDeque) push(seq uint64) {
+	d.buf[(d.head+d.len)%len(d.buf)] = seq
+	d.len++
+}
+
+func (d *seqDeque) popFront() {
+	d.head = (d.head + 1) % len(d.buf)
+	d.len--
+}
+
+func (d *seqDeque) popBack() {
+	d.len--
+}
+
+// moments keeps track of the mean and variance of a window of samples,
+// using Welford's algorithm extended to support removing samples.
+type moments struct {
+	n    int
+	mean float64
+	m2   float64 // sum of squared differences from the mean
+}
+
+func (m *moments) add(x float64) {
+	m.n++
+	d := x - m.mean
+	m.mean += d / float64(m.n)
+	m.m2 += d * (x - m.mean)
+}
+
+func (m *moments) remove(x float64) {
+	if m.n == 1 {
+		*m = moments{}
+		return
+	}
+
+	m.n--
+	d := x - m.mean
+	m.mean -= d / float64(m.n)
+	m.m2 -= d * (x - m.mean)
+
+	// rounding errors could make it slightly negative
+	if m.m2 < 0 {
+		m.m2 = 0
+	}
+}
+
+// returns the population variance of the samples.
+func (m *moments) variance() float64 {
+	return m.m2 / float64(m.n)
+}